@@ -236,6 +236,58 @@ func (x *SearchRequest) GetMaxResults() int32 {
 	return 0
 }
 
+type FindSimilarRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
+	MaxResults    int32                  `protobuf:"varint,2,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindSimilarRequest) Reset() {
+	*x = FindSimilarRequest{}
+	mi := &file_cotune_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindSimilarRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindSimilarRequest) ProtoMessage() {}
+
+func (x *FindSimilarRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindSimilarRequest.ProtoReflect.Descriptor instead.
+func (*FindSimilarRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FindSimilarRequest) GetCtid() string {
+	if x != nil {
+		return x.Ctid
+	}
+	return ""
+}
+
+func (x *FindSimilarRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
 type SearchProvidersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
@@ -246,7 +298,7 @@ type SearchProvidersRequest struct {
 
 func (x *SearchProvidersRequest) Reset() {
 	*x = SearchProvidersRequest{}
-	mi := &file_cotune_proto_msgTypes[4]
+	mi := &file_cotune_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -258,7 +310,7 @@ func (x *SearchProvidersRequest) String() string {
 func (*SearchProvidersRequest) ProtoMessage() {}
 
 func (x *SearchProvidersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[4]
+	mi := &file_cotune_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +323,7 @@ func (x *SearchProvidersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchProvidersRequest.ProtoReflect.Descriptor instead.
 func (*SearchProvidersRequest) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{4}
+	return file_cotune_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SearchProvidersRequest) GetCtid() string {
@@ -291,15 +343,17 @@ func (x *SearchProvidersRequest) GetMax() int32 {
 type FetchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
-	PeerId        string                 `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"` // optional, preferred peer
-	OutputPath    string                 `protobuf:"bytes,3,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
+	PeerId        string                 `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`             // optional, preferred peer
+	OutputPath    string                 `protobuf:"bytes,3,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"` // empty keeps the file in the daemon's cache
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`                           // optional: "opus" or "mp3" asks providers to transcode
+	Bitrate       int32                  `protobuf:"varint,5,opt,name=bitrate,proto3" json:"bitrate,omitempty"`                        // bits per second; 0 for the profile default
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FetchRequest) Reset() {
 	*x = FetchRequest{}
-	mi := &file_cotune_proto_msgTypes[5]
+	mi := &file_cotune_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -311,7 +365,7 @@ func (x *FetchRequest) String() string {
 func (*FetchRequest) ProtoMessage() {}
 
 func (x *FetchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[5]
+	mi := &file_cotune_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -324,7 +378,7 @@ func (x *FetchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FetchRequest.ProtoReflect.Descriptor instead.
 func (*FetchRequest) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{5}
+	return file_cotune_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *FetchRequest) GetCtid() string {
@@ -348,6 +402,64 @@ func (x *FetchRequest) GetOutputPath() string {
 	return ""
 }
 
+func (x *FetchRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FetchRequest) GetBitrate() int32 {
+	if x != nil {
+		return x.Bitrate
+	}
+	return 0
+}
+
+type TranscodeProfilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"` // empty for this daemon
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscodeProfilesRequest) Reset() {
+	*x = TranscodeProfilesRequest{}
+	mi := &file_cotune_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeProfilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeProfilesRequest) ProtoMessage() {}
+
+func (x *TranscodeProfilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeProfilesRequest.ProtoReflect.Descriptor instead.
+func (*TranscodeProfilesRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TranscodeProfilesRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
 type ShareRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TrackId       string                 `protobuf:"bytes,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
@@ -355,14 +467,15 @@ type ShareRequest struct {
 	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
 	Artist        string                 `protobuf:"bytes,4,opt,name=artist,proto3" json:"artist,omitempty"`
 	Recognized    bool                   `protobuf:"varint,5,opt,name=recognized,proto3" json:"recognized,omitempty"`
-	Checksum      string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"` // legacy, deprecated
+	Checksum      string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`               // legacy, deprecated
+	InPlace       bool                   `protobuf:"varint,7,opt,name=in_place,json=inPlace,proto3" json:"in_place,omitempty"` // reference the file where it is instead of importing it into the media store
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShareRequest) Reset() {
 	*x = ShareRequest{}
-	mi := &file_cotune_proto_msgTypes[6]
+	mi := &file_cotune_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -374,7 +487,7 @@ func (x *ShareRequest) String() string {
 func (*ShareRequest) ProtoMessage() {}
 
 func (x *ShareRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[6]
+	mi := &file_cotune_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -387,7 +500,7 @@ func (x *ShareRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShareRequest.ProtoReflect.Descriptor instead.
 func (*ShareRequest) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{6}
+	return file_cotune_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ShareRequest) GetTrackId() string {
@@ -432,63 +545,36 @@ func (x *ShareRequest) GetChecksum() string {
 	return ""
 }
 
-type AnnounceRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *AnnounceRequest) Reset() {
-	*x = AnnounceRequest{}
-	mi := &file_cotune_proto_msgTypes[7]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *AnnounceRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*AnnounceRequest) ProtoMessage() {}
-
-func (x *AnnounceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[7]
+func (x *ShareRequest) GetInPlace() bool {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.InPlace
 	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use AnnounceRequest.ProtoReflect.Descriptor instead.
-func (*AnnounceRequest) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{7}
+	return false
 }
 
-type RelaysRequest struct {
+type ArtworkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
+	Size          string                 `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"` // small, medium or original; empty for medium
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RelaysRequest) Reset() {
-	*x = RelaysRequest{}
-	mi := &file_cotune_proto_msgTypes[8]
+func (x *ArtworkRequest) Reset() {
+	*x = ArtworkRequest{}
+	mi := &file_cotune_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RelaysRequest) String() string {
+func (x *ArtworkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RelaysRequest) ProtoMessage() {}
+func (*ArtworkRequest) ProtoMessage() {}
 
-func (x *RelaysRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[8]
+func (x *ArtworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -499,68 +585,47 @@ func (x *RelaysRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RelaysRequest.ProtoReflect.Descriptor instead.
-func (*RelaysRequest) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{8}
-}
-
-type RelayEnableRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *RelayEnableRequest) Reset() {
-	*x = RelayEnableRequest{}
-	mi := &file_cotune_proto_msgTypes[9]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *RelayEnableRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+// Deprecated: Use ArtworkRequest.ProtoReflect.Descriptor instead.
+func (*ArtworkRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{9}
 }
 
-func (*RelayEnableRequest) ProtoMessage() {}
-
-func (x *RelayEnableRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[9]
+func (x *ArtworkRequest) GetCtid() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Ctid
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use RelayEnableRequest.ProtoReflect.Descriptor instead.
-func (*RelayEnableRequest) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{9}
+func (x *ArtworkRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
 }
 
-type RelayRequestRequest struct {
+type WaveformRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
+	MaxPeaks      int32                  `protobuf:"varint,2,opt,name=max_peaks,json=maxPeaks,proto3" json:"max_peaks,omitempty"` // keep only levels with at most this many peaks; 0 for all
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RelayRequestRequest) Reset() {
-	*x = RelayRequestRequest{}
+func (x *WaveformRequest) Reset() {
+	*x = WaveformRequest{}
 	mi := &file_cotune_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RelayRequestRequest) String() string {
+func (x *WaveformRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RelayRequestRequest) ProtoMessage() {}
+func (*WaveformRequest) ProtoMessage() {}
 
-func (x *RelayRequestRequest) ProtoReflect() protoreflect.Message {
+func (x *WaveformRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_cotune_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -572,41 +637,47 @@ func (x *RelayRequestRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RelayRequestRequest.ProtoReflect.Descriptor instead.
-func (*RelayRequestRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use WaveformRequest.ProtoReflect.Descriptor instead.
+func (*WaveformRequest) Descriptor() ([]byte, []int) {
 	return file_cotune_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *RelayRequestRequest) GetPeerId() string {
+func (x *WaveformRequest) GetCtid() string {
 	if x != nil {
-		return x.PeerId
+		return x.Ctid
 	}
 	return ""
 }
 
-// Response messages
-type StatusResponse struct {
+func (x *WaveformRequest) GetMaxPeaks() int32 {
+	if x != nil {
+		return x.MaxPeaks
+	}
+	return 0
+}
+
+type LoudnessRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Running       bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	TrackId       string                 `protobuf:"bytes,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"` // local track ID; takes precedence over ctid
+	Ctid          string                 `protobuf:"bytes,2,opt,name=ctid,proto3" json:"ctid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StatusResponse) Reset() {
-	*x = StatusResponse{}
+func (x *LoudnessRequest) Reset() {
+	*x = LoudnessRequest{}
 	mi := &file_cotune_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StatusResponse) String() string {
+func (x *LoudnessRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatusResponse) ProtoMessage() {}
+func (*LoudnessRequest) ProtoMessage() {}
 
-func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+func (x *LoudnessRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_cotune_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -618,47 +689,46 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
-func (*StatusResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoudnessRequest.ProtoReflect.Descriptor instead.
+func (*LoudnessRequest) Descriptor() ([]byte, []int) {
 	return file_cotune_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *StatusResponse) GetRunning() bool {
+func (x *LoudnessRequest) GetTrackId() string {
 	if x != nil {
-		return x.Running
+		return x.TrackId
 	}
-	return false
+	return ""
 }
 
-func (x *StatusResponse) GetVersion() string {
+func (x *LoudnessRequest) GetCtid() string {
 	if x != nil {
-		return x.Version
+		return x.Ctid
 	}
 	return ""
 }
 
-type PeerInfo struct {
+type ListJobsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
-	Addresses     []string               `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // queued, decoding, hashing, done or failed; empty for all
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PeerInfo) Reset() {
-	*x = PeerInfo{}
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
 	mi := &file_cotune_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PeerInfo) String() string {
+func (x *ListJobsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PeerInfo) ProtoMessage() {}
+func (*ListJobsRequest) ProtoMessage() {}
 
-func (x *PeerInfo) ProtoReflect() protoreflect.Message {
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_cotune_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -670,46 +740,39 @@ func (x *PeerInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PeerInfo.ProtoReflect.Descriptor instead.
-func (*PeerInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
 	return file_cotune_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *PeerInfo) GetPeerId() string {
+func (x *ListJobsRequest) GetState() string {
 	if x != nil {
-		return x.PeerId
+		return x.State
 	}
 	return ""
 }
 
-func (x *PeerInfo) GetAddresses() []string {
-	if x != nil {
-		return x.Addresses
-	}
-	return nil
-}
-
-type PeerInfoResponse struct {
+type RetryJobsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PeerInfo      *PeerInfo              `protobuf:"bytes,1,opt,name=peer_info,json=peerInfo,proto3" json:"peer_info,omitempty"`
+	JobIds        []string               `protobuf:"bytes,1,rep,name=job_ids,json=jobIds,proto3" json:"job_ids,omitempty"` // empty retries every failed job
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PeerInfoResponse) Reset() {
-	*x = PeerInfoResponse{}
+func (x *RetryJobsRequest) Reset() {
+	*x = RetryJobsRequest{}
 	mi := &file_cotune_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PeerInfoResponse) String() string {
+func (x *RetryJobsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PeerInfoResponse) ProtoMessage() {}
+func (*RetryJobsRequest) ProtoMessage() {}
 
-func (x *PeerInfoResponse) ProtoReflect() protoreflect.Message {
+func (x *RetryJobsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_cotune_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -721,39 +784,40 @@ func (x *PeerInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PeerInfoResponse.ProtoReflect.Descriptor instead.
-func (*PeerInfoResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RetryJobsRequest.ProtoReflect.Descriptor instead.
+func (*RetryJobsRequest) Descriptor() ([]byte, []int) {
 	return file_cotune_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *PeerInfoResponse) GetPeerInfo() *PeerInfo {
+func (x *RetryJobsRequest) GetJobIds() []string {
 	if x != nil {
-		return x.PeerInfo
+		return x.JobIds
 	}
 	return nil
 }
 
-type KnownPeersResponse struct {
+type DeleteTrackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Peers         []*PeerInfo            `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	TrackId       string                 `protobuf:"bytes,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	DeleteFile    bool                   `protobuf:"varint,2,opt,name=delete_file,json=deleteFile,proto3" json:"delete_file,omitempty"` // also delete the audio file unless another track references it
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *KnownPeersResponse) Reset() {
-	*x = KnownPeersResponse{}
+func (x *DeleteTrackRequest) Reset() {
+	*x = DeleteTrackRequest{}
 	mi := &file_cotune_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *KnownPeersResponse) String() string {
+func (x *DeleteTrackRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*KnownPeersResponse) ProtoMessage() {}
+func (*DeleteTrackRequest) ProtoMessage() {}
 
-func (x *KnownPeersResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteTrackRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_cotune_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -765,41 +829,3606 @@ func (x *KnownPeersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use KnownPeersResponse.ProtoReflect.Descriptor instead.
-func (*KnownPeersResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteTrackRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTrackRequest) Descriptor() ([]byte, []int) {
 	return file_cotune_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *KnownPeersResponse) GetPeers() []*PeerInfo {
+func (x *DeleteTrackRequest) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *DeleteTrackRequest) GetDeleteFile() bool {
+	if x != nil {
+		return x.DeleteFile
+	}
+	return false
+}
+
+type UpdateTrackMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       string                 `protobuf:"bytes,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Artist        string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
+	Album         string                 `protobuf:"bytes,4,opt,name=album,proto3" json:"album,omitempty"`
+	TrackNumber   int32                  `protobuf:"varint,5,opt,name=track_number,json=trackNumber,proto3" json:"track_number,omitempty"`
+	Year          int32                  `protobuf:"varint,6,opt,name=year,proto3" json:"year,omitempty"`
+	Genre         string                 `protobuf:"bytes,7,opt,name=genre,proto3" json:"genre,omitempty"`
+	Fields        []string               `protobuf:"bytes,8,rep,name=fields,proto3" json:"fields,omitempty"` // names of the fields to set, e.g. "album"; empty sets every non-empty field
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrackMetadataRequest) Reset() {
+	*x = UpdateTrackMetadataRequest{}
+	mi := &file_cotune_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrackMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrackMetadataRequest) ProtoMessage() {}
+
+func (x *UpdateTrackMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrackMetadataRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTrackMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateTrackMetadataRequest) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *UpdateTrackMetadataRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateTrackMetadataRequest) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *UpdateTrackMetadataRequest) GetAlbum() string {
+	if x != nil {
+		return x.Album
+	}
+	return ""
+}
+
+func (x *UpdateTrackMetadataRequest) GetTrackNumber() int32 {
+	if x != nil {
+		return x.TrackNumber
+	}
+	return 0
+}
+
+func (x *UpdateTrackMetadataRequest) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *UpdateTrackMetadataRequest) GetGenre() string {
+	if x != nil {
+		return x.Genre
+	}
+	return ""
+}
+
+func (x *UpdateTrackMetadataRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type UnshareTrackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       string                 `protobuf:"bytes,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnshareTrackRequest) Reset() {
+	*x = UnshareTrackRequest{}
+	mi := &file_cotune_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnshareTrackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnshareTrackRequest) ProtoMessage() {}
+
+func (x *UnshareTrackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnshareTrackRequest.ProtoReflect.Descriptor instead.
+func (*UnshareTrackRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UnshareTrackRequest) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+type CreatePlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Ctids         []string               `protobuf:"bytes,3,rep,name=ctids,proto3" json:"ctids,omitempty"` // tracks in order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePlaylistRequest) Reset() {
+	*x = CreatePlaylistRequest{}
+	mi := &file_cotune_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePlaylistRequest) ProtoMessage() {}
+
+func (x *CreatePlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePlaylistRequest.ProtoReflect.Descriptor instead.
+func (*CreatePlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreatePlaylistRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreatePlaylistRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreatePlaylistRequest) GetCtids() []string {
+	if x != nil {
+		return x.Ctids
+	}
+	return nil
+}
+
+type ListPlaylistsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlaylistsRequest) Reset() {
+	*x = ListPlaylistsRequest{}
+	mi := &file_cotune_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlaylistsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlaylistsRequest) ProtoMessage() {}
+
+func (x *ListPlaylistsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlaylistsRequest.ProtoReflect.Descriptor instead.
+func (*ListPlaylistsRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{18}
+}
+
+type UpdatePlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlaylistId    string                 `protobuf:"bytes,1,opt,name=playlist_id,json=playlistId,proto3" json:"playlist_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Ctids         []string               `protobuf:"bytes,4,rep,name=ctids,proto3" json:"ctids,omitempty"`
+	Fields        []string               `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty"` // names of the fields to set, e.g. "ctids"; empty sets every non-empty field
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePlaylistRequest) Reset() {
+	*x = UpdatePlaylistRequest{}
+	mi := &file_cotune_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePlaylistRequest) ProtoMessage() {}
+
+func (x *UpdatePlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePlaylistRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdatePlaylistRequest) GetPlaylistId() string {
+	if x != nil {
+		return x.PlaylistId
+	}
+	return ""
+}
+
+func (x *UpdatePlaylistRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdatePlaylistRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdatePlaylistRequest) GetCtids() []string {
+	if x != nil {
+		return x.Ctids
+	}
+	return nil
+}
+
+func (x *UpdatePlaylistRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type DeletePlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlaylistId    string                 `protobuf:"bytes,1,opt,name=playlist_id,json=playlistId,proto3" json:"playlist_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePlaylistRequest) Reset() {
+	*x = DeletePlaylistRequest{}
+	mi := &file_cotune_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePlaylistRequest) ProtoMessage() {}
+
+func (x *DeletePlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePlaylistRequest.ProtoReflect.Descriptor instead.
+func (*DeletePlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeletePlaylistRequest) GetPlaylistId() string {
+	if x != nil {
+		return x.PlaylistId
+	}
+	return ""
+}
+
+type SharePlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlaylistId    string                 `protobuf:"bytes,1,opt,name=playlist_id,json=playlistId,proto3" json:"playlist_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SharePlaylistRequest) Reset() {
+	*x = SharePlaylistRequest{}
+	mi := &file_cotune_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SharePlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SharePlaylistRequest) ProtoMessage() {}
+
+func (x *SharePlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SharePlaylistRequest.ProtoReflect.Descriptor instead.
+func (*SharePlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SharePlaylistRequest) GetPlaylistId() string {
+	if x != nil {
+		return x.PlaylistId
+	}
+	return ""
+}
+
+type UnsharePlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlaylistId    string                 `protobuf:"bytes,1,opt,name=playlist_id,json=playlistId,proto3" json:"playlist_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsharePlaylistRequest) Reset() {
+	*x = UnsharePlaylistRequest{}
+	mi := &file_cotune_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsharePlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsharePlaylistRequest) ProtoMessage() {}
+
+func (x *UnsharePlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsharePlaylistRequest.ProtoReflect.Descriptor instead.
+func (*UnsharePlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UnsharePlaylistRequest) GetPlaylistId() string {
+	if x != nil {
+		return x.PlaylistId
+	}
+	return ""
+}
+
+type OpenPlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecordId      string                 `protobuf:"bytes,1,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Save          bool                   `protobuf:"varint,2,opt,name=save,proto3" json:"save,omitempty"` // keep a local copy
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenPlaylistRequest) Reset() {
+	*x = OpenPlaylistRequest{}
+	mi := &file_cotune_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenPlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenPlaylistRequest) ProtoMessage() {}
+
+func (x *OpenPlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenPlaylistRequest.ProtoReflect.Descriptor instead.
+func (*OpenPlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *OpenPlaylistRequest) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *OpenPlaylistRequest) GetSave() bool {
+	if x != nil {
+		return x.Save
+	}
+	return false
+}
+
+type BackupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OutputPath    string                 `protobuf:"bytes,1,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`        // archive written by the daemon
+	IncludeMedia  bool                   `protobuf:"varint,2,opt,name=include_media,json=includeMedia,proto3" json:"include_media,omitempty"` // also archive the audio files
+	Secret        *VaultSecret           `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"`                                  // seals the identity key and library; on an encrypted node, its secret
+	Plaintext     bool                   `protobuf:"varint,4,opt,name=plaintext,proto3" json:"plaintext,omitempty"`                           // back up an encrypted node without secret, unsealed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_cotune_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BackupRequest) GetOutputPath() string {
+	if x != nil {
+		return x.OutputPath
+	}
+	return ""
+}
+
+func (x *BackupRequest) GetIncludeMedia() bool {
+	if x != nil {
+		return x.IncludeMedia
+	}
+	return false
+}
+
+func (x *BackupRequest) GetSecret() *VaultSecret {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+func (x *BackupRequest) GetPlaintext() bool {
+	if x != nil {
+		return x.Plaintext
+	}
+	return false
+}
+
+type ExportLibraryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OutputPath    string                 `protobuf:"bytes,1,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`                           // "json" or "m3u8"; empty picks by the extension of output_path
+	PlaylistId    string                 `protobuf:"bytes,3,opt,name=playlist_id,json=playlistId,proto3" json:"playlist_id,omitempty"` // export one playlist; empty exports the library
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportLibraryRequest) Reset() {
+	*x = ExportLibraryRequest{}
+	mi := &file_cotune_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportLibraryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLibraryRequest) ProtoMessage() {}
+
+func (x *ExportLibraryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLibraryRequest.ProtoReflect.Descriptor instead.
+func (*ExportLibraryRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExportLibraryRequest) GetOutputPath() string {
+	if x != nil {
+		return x.OutputPath
+	}
+	return ""
+}
+
+func (x *ExportLibraryRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ExportLibraryRequest) GetPlaylistId() string {
+	if x != nil {
+		return x.PlaylistId
+	}
+	return ""
+}
+
+type ImportLibraryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`     // JSON library export or M3U/M3U8 playlist
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"` // empty picks by the extension of path
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportLibraryRequest) Reset() {
+	*x = ImportLibraryRequest{}
+	mi := &file_cotune_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportLibraryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportLibraryRequest) ProtoMessage() {}
+
+func (x *ImportLibraryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportLibraryRequest.ProtoReflect.Descriptor instead.
+func (*ImportLibraryRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ImportLibraryRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ImportLibraryRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type WatchFoldersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchFoldersRequest) Reset() {
+	*x = WatchFoldersRequest{}
+	mi := &file_cotune_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchFoldersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchFoldersRequest) ProtoMessage() {}
+
+func (x *WatchFoldersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchFoldersRequest.ProtoReflect.Descriptor instead.
+func (*WatchFoldersRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{27}
+}
+
+type AddWatchFolderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddWatchFolderRequest) Reset() {
+	*x = AddWatchFolderRequest{}
+	mi := &file_cotune_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddWatchFolderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddWatchFolderRequest) ProtoMessage() {}
+
+func (x *AddWatchFolderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddWatchFolderRequest.ProtoReflect.Descriptor instead.
+func (*AddWatchFolderRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AddWatchFolderRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type RemoveWatchFolderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // tracks imported from it stay in the library
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveWatchFolderRequest) Reset() {
+	*x = RemoveWatchFolderRequest{}
+	mi := &file_cotune_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveWatchFolderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveWatchFolderRequest) ProtoMessage() {}
+
+func (x *RemoveWatchFolderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveWatchFolderRequest.ProtoReflect.Descriptor instead.
+func (*RemoveWatchFolderRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RemoveWatchFolderRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// A passphrase, or a 32-byte key held by the platform keystore
+type VaultSecret struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Passphrase    string                 `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	Key           []byte                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VaultSecret) Reset() {
+	*x = VaultSecret{}
+	mi := &file_cotune_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultSecret) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultSecret) ProtoMessage() {}
+
+func (x *VaultSecret) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultSecret.ProtoReflect.Descriptor instead.
+func (*VaultSecret) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *VaultSecret) GetPassphrase() string {
+	if x != nil {
+		return x.Passphrase
+	}
+	return ""
+}
+
+func (x *VaultSecret) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type UnlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secret        *VaultSecret           `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnlockRequest) Reset() {
+	*x = UnlockRequest{}
+	mi := &file_cotune_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockRequest) ProtoMessage() {}
+
+func (x *UnlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockRequest.ProtoReflect.Descriptor instead.
+func (*UnlockRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UnlockRequest) GetSecret() *VaultSecret {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+type LockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockRequest) Reset() {
+	*x = LockRequest{}
+	mi := &file_cotune_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockRequest) ProtoMessage() {}
+
+func (x *LockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockRequest.ProtoReflect.Descriptor instead.
+func (*LockRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{32}
+}
+
+type VaultStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VaultStatusRequest) Reset() {
+	*x = VaultStatusRequest{}
+	mi := &file_cotune_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VaultStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultStatusRequest) ProtoMessage() {}
+
+func (x *VaultStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultStatusRequest.ProtoReflect.Descriptor instead.
+func (*VaultStatusRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{33}
+}
+
+type EnableEncryptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secret        *VaultSecret           `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableEncryptionRequest) Reset() {
+	*x = EnableEncryptionRequest{}
+	mi := &file_cotune_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableEncryptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableEncryptionRequest) ProtoMessage() {}
+
+func (x *EnableEncryptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableEncryptionRequest.ProtoReflect.Descriptor instead.
+func (*EnableEncryptionRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *EnableEncryptionRequest) GetSecret() *VaultSecret {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+type ChangeVaultSecretRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldSecret     *VaultSecret           `protobuf:"bytes,1,opt,name=old_secret,json=oldSecret,proto3" json:"old_secret,omitempty"`
+	NewSecret     *VaultSecret           `protobuf:"bytes,2,opt,name=new_secret,json=newSecret,proto3" json:"new_secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeVaultSecretRequest) Reset() {
+	*x = ChangeVaultSecretRequest{}
+	mi := &file_cotune_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeVaultSecretRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeVaultSecretRequest) ProtoMessage() {}
+
+func (x *ChangeVaultSecretRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeVaultSecretRequest.ProtoReflect.Descriptor instead.
+func (*ChangeVaultSecretRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ChangeVaultSecretRequest) GetOldSecret() *VaultSecret {
+	if x != nil {
+		return x.OldSecret
+	}
+	return nil
+}
+
+func (x *ChangeVaultSecretRequest) GetNewSecret() *VaultSecret {
+	if x != nil {
+		return x.NewSecret
+	}
+	return nil
+}
+
+type RotateVaultKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secret        *VaultSecret           `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateVaultKeyRequest) Reset() {
+	*x = RotateVaultKeyRequest{}
+	mi := &file_cotune_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateVaultKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateVaultKeyRequest) ProtoMessage() {}
+
+func (x *RotateVaultKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateVaultKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateVaultKeyRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RotateVaultKeyRequest) GetSecret() *VaultSecret {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+type AnnounceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnnounceRequest) Reset() {
+	*x = AnnounceRequest{}
+	mi := &file_cotune_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnounceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnounceRequest) ProtoMessage() {}
+
+func (x *AnnounceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnounceRequest.ProtoReflect.Descriptor instead.
+func (*AnnounceRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{37}
+}
+
+type RelaysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelaysRequest) Reset() {
+	*x = RelaysRequest{}
+	mi := &file_cotune_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelaysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelaysRequest) ProtoMessage() {}
+
+func (x *RelaysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelaysRequest.ProtoReflect.Descriptor instead.
+func (*RelaysRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{38}
+}
+
+type RelayEnableRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayEnableRequest) Reset() {
+	*x = RelayEnableRequest{}
+	mi := &file_cotune_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayEnableRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayEnableRequest) ProtoMessage() {}
+
+func (x *RelayEnableRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayEnableRequest.ProtoReflect.Descriptor instead.
+func (*RelayEnableRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{39}
+}
+
+type RelayRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayRequestRequest) Reset() {
+	*x = RelayRequestRequest{}
+	mi := &file_cotune_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayRequestRequest) ProtoMessage() {}
+
+func (x *RelayRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayRequestRequest.ProtoReflect.Descriptor instead.
+func (*RelayRequestRequest) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *RelayRequestRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+// Response messages
+type StatusResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Running         bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	Version         string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	FfmpegAvailable bool                   `protobuf:"varint,3,opt,name=ffmpeg_available,json=ffmpegAvailable,proto3" json:"ffmpeg_available,omitempty"` // false limits decoding to the built-in decoders
+	FfmpegVersion   string                 `protobuf:"bytes,4,opt,name=ffmpeg_version,json=ffmpegVersion,proto3" json:"ffmpeg_version,omitempty"`
+	FfmpegError     string                 `protobuf:"bytes,5,opt,name=ffmpeg_error,json=ffmpegError,proto3" json:"ffmpeg_error,omitempty"` // why the ffmpeg probe failed
+	Locked          bool                   `protobuf:"varint,6,opt,name=locked,proto3" json:"locked,omitempty"`                             // encrypted and waiting for Unlock; other RPCs are unavailable
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_cotune_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *StatusResponse) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *StatusResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetFfmpegAvailable() bool {
+	if x != nil {
+		return x.FfmpegAvailable
+	}
+	return false
+}
+
+func (x *StatusResponse) GetFfmpegVersion() string {
+	if x != nil {
+		return x.FfmpegVersion
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetFfmpegError() string {
+	if x != nil {
+		return x.FfmpegError
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+type PeerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Addresses     []string               `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerInfo) Reset() {
+	*x = PeerInfo{}
+	mi := &file_cotune_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfo) ProtoMessage() {}
+
+func (x *PeerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfo.ProtoReflect.Descriptor instead.
+func (*PeerInfo) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *PeerInfo) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *PeerInfo) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+type PeerInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerInfo      *PeerInfo              `protobuf:"bytes,1,opt,name=peer_info,json=peerInfo,proto3" json:"peer_info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerInfoResponse) Reset() {
+	*x = PeerInfoResponse{}
+	mi := &file_cotune_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfoResponse) ProtoMessage() {}
+
+func (x *PeerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfoResponse.ProtoReflect.Descriptor instead.
+func (*PeerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *PeerInfoResponse) GetPeerInfo() *PeerInfo {
+	if x != nil {
+		return x.PeerInfo
+	}
+	return nil
+}
+
+type KnownPeersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Peers         []*PeerInfo            `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KnownPeersResponse) Reset() {
+	*x = KnownPeersResponse{}
+	mi := &file_cotune_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KnownPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KnownPeersResponse) ProtoMessage() {}
+
+func (x *KnownPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KnownPeersResponse.ProtoReflect.Descriptor instead.
+func (*KnownPeersResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *KnownPeersResponse) GetPeers() []*PeerInfo {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type ConnectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectResponse) Reset() {
+	*x = ConnectResponse{}
+	mi := &file_cotune_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectResponse) ProtoMessage() {}
+
+func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectResponse.ProtoReflect.Descriptor instead.
+func (*ConnectResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ConnectResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ConnectResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SearchResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Ctid           string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
+	Title          string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Artist         string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
+	Recognized     bool                   `protobuf:"varint,4,opt,name=recognized,proto3" json:"recognized,omitempty"`
+	Providers      []string               `protobuf:"bytes,5,rep,name=providers,proto3" json:"providers,omitempty"`
+	FingerprintKey string                 `protobuf:"bytes,6,opt,name=fingerprint_key,json=fingerprintKey,proto3" json:"fingerprint_key,omitempty"`
+	Alternates     []string               `protobuf:"bytes,7,rep,name=alternates,proto3" json:"alternates,omitempty"` // near-identical recordings grouped under this result
+	Codec          string                 `protobuf:"bytes,8,opt,name=codec,proto3" json:"codec,omitempty"`
+	SampleRate     int32                  `protobuf:"varint,9,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"` // Hz
+	Channels       int32                  `protobuf:"varint,10,opt,name=channels,proto3" json:"channels,omitempty"`
+	Bitrate        int32                  `protobuf:"varint,11,opt,name=bitrate,proto3" json:"bitrate,omitempty"` // average bits per second
+	DurationMs     int64                  `protobuf:"varint,12,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	FileSize       int64                  `protobuf:"varint,13,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`          // bytes
+	Artwork        string                 `protobuf:"bytes,14,opt,name=artwork,proto3" json:"artwork,omitempty"`                             // cover hash, empty if none; fetch with GetArtwork
+	HasLoudness    bool                   `protobuf:"varint,15,opt,name=has_loudness,json=hasLoudness,proto3" json:"has_loudness,omitempty"` // false until the provider has measured the track
+	IntegratedLufs float64                `protobuf:"fixed64,16,opt,name=integrated_lufs,json=integratedLufs,proto3" json:"integrated_lufs,omitempty"`
+	RangeLu        float64                `protobuf:"fixed64,17,opt,name=range_lu,json=rangeLu,proto3" json:"range_lu,omitempty"`
+	TruePeakDbtp   float64                `protobuf:"fixed64,18,opt,name=true_peak_dbtp,json=truePeakDbtp,proto3" json:"true_peak_dbtp,omitempty"`
+	GainDb         float64                `protobuf:"fixed64,19,opt,name=gain_db,json=gainDb,proto3" json:"gain_db,omitempty"` // ReplayGain playback gain
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_cotune_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *SearchResult) GetCtid() string {
+	if x != nil {
+		return x.Ctid
+	}
+	return ""
+}
+
+func (x *SearchResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SearchResult) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *SearchResult) GetRecognized() bool {
+	if x != nil {
+		return x.Recognized
+	}
+	return false
+}
+
+func (x *SearchResult) GetProviders() []string {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *SearchResult) GetFingerprintKey() string {
+	if x != nil {
+		return x.FingerprintKey
+	}
+	return ""
+}
+
+func (x *SearchResult) GetAlternates() []string {
+	if x != nil {
+		return x.Alternates
+	}
+	return nil
+}
+
+func (x *SearchResult) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *SearchResult) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *SearchResult) GetChannels() int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *SearchResult) GetBitrate() int32 {
+	if x != nil {
+		return x.Bitrate
+	}
+	return 0
+}
+
+func (x *SearchResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *SearchResult) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *SearchResult) GetArtwork() string {
+	if x != nil {
+		return x.Artwork
+	}
+	return ""
+}
+
+func (x *SearchResult) GetHasLoudness() bool {
+	if x != nil {
+		return x.HasLoudness
+	}
+	return false
+}
+
+func (x *SearchResult) GetIntegratedLufs() float64 {
+	if x != nil {
+		return x.IntegratedLufs
+	}
+	return 0
+}
+
+func (x *SearchResult) GetRangeLu() float64 {
+	if x != nil {
+		return x.RangeLu
+	}
+	return 0
+}
+
+func (x *SearchResult) GetTruePeakDbtp() float64 {
+	if x != nil {
+		return x.TruePeakDbtp
+	}
+	return 0
+}
+
+func (x *SearchResult) GetGainDb() float64 {
+	if x != nil {
+		return x.GainDb
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_cotune_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SimilarTrack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Artist        string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
+	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"` // fingerprint similarity in [0, 1]
+	Providers     []string               `protobuf:"bytes,5,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimilarTrack) Reset() {
+	*x = SimilarTrack{}
+	mi := &file_cotune_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimilarTrack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimilarTrack) ProtoMessage() {}
+
+func (x *SimilarTrack) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimilarTrack.ProtoReflect.Descriptor instead.
+func (*SimilarTrack) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *SimilarTrack) GetCtid() string {
+	if x != nil {
+		return x.Ctid
+	}
+	return ""
+}
+
+func (x *SimilarTrack) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SimilarTrack) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *SimilarTrack) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SimilarTrack) GetProviders() []string {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type FindSimilarResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SimilarTrack        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindSimilarResponse) Reset() {
+	*x = FindSimilarResponse{}
+	mi := &file_cotune_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindSimilarResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindSimilarResponse) ProtoMessage() {}
+
+func (x *FindSimilarResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindSimilarResponse.ProtoReflect.Descriptor instead.
+func (*FindSimilarResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *FindSimilarResponse) GetResults() []*SimilarTrack {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *FindSimilarResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SearchProvidersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProviderIds   []string               `protobuf:"bytes,1,rep,name=provider_ids,json=providerIds,proto3" json:"provider_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchProvidersResponse) Reset() {
+	*x = SearchProvidersResponse{}
+	mi := &file_cotune_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchProvidersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchProvidersResponse) ProtoMessage() {}
+
+func (x *SearchProvidersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchProvidersResponse.ProtoReflect.Descriptor instead.
+func (*SearchProvidersResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *SearchProvidersResponse) GetProviderIds() []string {
+	if x != nil {
+		return x.ProviderIds
+	}
+	return nil
+}
+
+type FetchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Transcoded    bool                   `protobuf:"varint,4,opt,name=transcoded,proto3" json:"transcoded,omitempty"` // false if the provider sent the original file
+	Format        string                 `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`          // format received, set when a format was requested
+	Bitrate       int32                  `protobuf:"varint,6,opt,name=bitrate,proto3" json:"bitrate,omitempty"`
+	MimeType      string                 `protobuf:"bytes,7,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	FetchedCtid   string                 `protobuf:"bytes,8,opt,name=fetched_ctid,json=fetchedCtid,proto3" json:"fetched_ctid,omitempty"` // CTID fetched; differs from the request when a near-identical recording stood in
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchResponse) Reset() {
+	*x = FetchResponse{}
+	mi := &file_cotune_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchResponse) ProtoMessage() {}
+
+func (x *FetchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchResponse.ProtoReflect.Descriptor instead.
+func (*FetchResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *FetchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *FetchResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FetchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FetchResponse) GetTranscoded() bool {
+	if x != nil {
+		return x.Transcoded
+	}
+	return false
+}
+
+func (x *FetchResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FetchResponse) GetBitrate() int32 {
+	if x != nil {
+		return x.Bitrate
+	}
+	return 0
+}
+
+func (x *FetchResponse) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *FetchResponse) GetFetchedCtid() string {
+	if x != nil {
+		return x.FetchedCtid
+	}
+	return ""
+}
+
+type TranscodeProfile struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Format         string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	MimeType       string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	MinBitrate     int32                  `protobuf:"varint,3,opt,name=min_bitrate,json=minBitrate,proto3" json:"min_bitrate,omitempty"`
+	MaxBitrate     int32                  `protobuf:"varint,4,opt,name=max_bitrate,json=maxBitrate,proto3" json:"max_bitrate,omitempty"`
+	DefaultBitrate int32                  `protobuf:"varint,5,opt,name=default_bitrate,json=defaultBitrate,proto3" json:"default_bitrate,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TranscodeProfile) Reset() {
+	*x = TranscodeProfile{}
+	mi := &file_cotune_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeProfile) ProtoMessage() {}
+
+func (x *TranscodeProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeProfile.ProtoReflect.Descriptor instead.
+func (*TranscodeProfile) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *TranscodeProfile) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *TranscodeProfile) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *TranscodeProfile) GetMinBitrate() int32 {
+	if x != nil {
+		return x.MinBitrate
+	}
+	return 0
+}
+
+func (x *TranscodeProfile) GetMaxBitrate() int32 {
+	if x != nil {
+		return x.MaxBitrate
+	}
+	return 0
+}
+
+func (x *TranscodeProfile) GetDefaultBitrate() int32 {
+	if x != nil {
+		return x.DefaultBitrate
+	}
+	return 0
+}
+
+type TranscodeProfilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Profiles      []*TranscodeProfile    `protobuf:"bytes,1,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscodeProfilesResponse) Reset() {
+	*x = TranscodeProfilesResponse{}
+	mi := &file_cotune_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeProfilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeProfilesResponse) ProtoMessage() {}
+
+func (x *TranscodeProfilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeProfilesResponse.ProtoReflect.Descriptor instead.
+func (*TranscodeProfilesResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *TranscodeProfilesResponse) GetProfiles() []*TranscodeProfile {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *TranscodeProfilesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ArtworkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	MimeType      string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtworkResponse) Reset() {
+	*x = ArtworkResponse{}
+	mi := &file_cotune_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtworkResponse) ProtoMessage() {}
+
+func (x *ArtworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtworkResponse.ProtoReflect.Descriptor instead.
+func (*ArtworkResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ArtworkResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ArtworkResponse) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *ArtworkResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type WaveformLevel struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SamplesPerPeak int32                  `protobuf:"varint,1,opt,name=samples_per_peak,json=samplesPerPeak,proto3" json:"samples_per_peak,omitempty"`
+	Peaks          []byte                 `protobuf:"bytes,2,opt,name=peaks,proto3" json:"peaks,omitempty"` // interleaved signed 8-bit min/max pairs
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WaveformLevel) Reset() {
+	*x = WaveformLevel{}
+	mi := &file_cotune_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaveformLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaveformLevel) ProtoMessage() {}
+
+func (x *WaveformLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaveformLevel.ProtoReflect.Descriptor instead.
+func (*WaveformLevel) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *WaveformLevel) GetSamplesPerPeak() int32 {
+	if x != nil {
+		return x.SamplesPerPeak
+	}
+	return 0
+}
+
+func (x *WaveformLevel) GetPeaks() []byte {
+	if x != nil {
+		return x.Peaks
+	}
+	return nil
+}
+
+type WaveformResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SampleRate    int32                  `protobuf:"varint,1,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	Levels        []*WaveformLevel       `protobuf:"bytes,2,rep,name=levels,proto3" json:"levels,omitempty"` // finest first
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaveformResponse) Reset() {
+	*x = WaveformResponse{}
+	mi := &file_cotune_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaveformResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaveformResponse) ProtoMessage() {}
+
+func (x *WaveformResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaveformResponse.ProtoReflect.Descriptor instead.
+func (*WaveformResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *WaveformResponse) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *WaveformResponse) GetLevels() []*WaveformLevel {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+func (x *WaveformResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type LoudnessResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IntegratedLufs float64                `protobuf:"fixed64,1,opt,name=integrated_lufs,json=integratedLufs,proto3" json:"integrated_lufs,omitempty"` // EBU R128
+	RangeLu        float64                `protobuf:"fixed64,2,opt,name=range_lu,json=rangeLu,proto3" json:"range_lu,omitempty"`
+	TruePeakDbtp   float64                `protobuf:"fixed64,3,opt,name=true_peak_dbtp,json=truePeakDbtp,proto3" json:"true_peak_dbtp,omitempty"`
+	GainDb         float64                `protobuf:"fixed64,4,opt,name=gain_db,json=gainDb,proto3" json:"gain_db,omitempty"` // playback gain to the -18 LUFS ReplayGain reference
+	Error          string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LoudnessResponse) Reset() {
+	*x = LoudnessResponse{}
+	mi := &file_cotune_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoudnessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoudnessResponse) ProtoMessage() {}
+
+func (x *LoudnessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoudnessResponse.ProtoReflect.Descriptor instead.
+func (*LoudnessResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *LoudnessResponse) GetIntegratedLufs() float64 {
+	if x != nil {
+		return x.IntegratedLufs
+	}
+	return 0
+}
+
+func (x *LoudnessResponse) GetRangeLu() float64 {
+	if x != nil {
+		return x.RangeLu
+	}
+	return 0
+}
+
+func (x *LoudnessResponse) GetTruePeakDbtp() float64 {
+	if x != nil {
+		return x.TruePeakDbtp
+	}
+	return 0
+}
+
+func (x *LoudnessResponse) GetGainDb() float64 {
+	if x != nil {
+		return x.GainDb
+	}
+	return 0
+}
+
+func (x *LoudnessResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ShareResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareResponse) Reset() {
+	*x = ShareResponse{}
+	mi := &file_cotune_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareResponse) ProtoMessage() {}
+
+func (x *ShareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareResponse.ProtoReflect.Descriptor instead.
+func (*ShareResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ShareResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ShareResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ShareResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TrackId       string                 `protobuf:"bytes,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Attempts      int32                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	NextAttempt   int64                  `protobuf:"varint,6,opt,name=next_attempt,json=nextAttempt,proto3" json:"next_attempt,omitempty"` // unix seconds
+	CreatedAt     int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_cotune_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *Job) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Job) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Job) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Job) GetNextAttempt() int64 {
+	if x != nil {
+		return x.NextAttempt
+	}
+	return 0
+}
+
+func (x *Job) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Job) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_cotune_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListJobsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RetryJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Retried       int32                  `protobuf:"varint,1,opt,name=retried,proto3" json:"retried,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryJobsResponse) Reset() {
+	*x = RetryJobsResponse{}
+	mi := &file_cotune_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryJobsResponse) ProtoMessage() {}
+
+func (x *RetryJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryJobsResponse.ProtoReflect.Descriptor instead.
+func (*RetryJobsResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *RetryJobsResponse) GetRetried() int32 {
+	if x != nil {
+		return x.Retried
+	}
+	return 0
+}
+
+func (x *RetryJobsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeleteTrackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTrackResponse) Reset() {
+	*x = DeleteTrackResponse{}
+	mi := &file_cotune_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTrackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTrackResponse) ProtoMessage() {}
+
+func (x *DeleteTrackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTrackResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTrackResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *DeleteTrackResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteTrackResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateTrackMetadataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Recognized    bool                   `protobuf:"varint,2,opt,name=recognized,proto3" json:"recognized,omitempty"` // title and artist are set, so the track can be shared
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrackMetadataResponse) Reset() {
+	*x = UpdateTrackMetadataResponse{}
+	mi := &file_cotune_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrackMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrackMetadataResponse) ProtoMessage() {}
+
+func (x *UpdateTrackMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrackMetadataResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTrackMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *UpdateTrackMetadataResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateTrackMetadataResponse) GetRecognized() bool {
+	if x != nil {
+		return x.Recognized
+	}
+	return false
+}
+
+func (x *UpdateTrackMetadataResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UnshareTrackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnshareTrackResponse) Reset() {
+	*x = UnshareTrackResponse{}
+	mi := &file_cotune_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnshareTrackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnshareTrackResponse) ProtoMessage() {}
+
+func (x *UnshareTrackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnshareTrackResponse.ProtoReflect.Descriptor instead.
+func (*UnshareTrackResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *UnshareTrackResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UnshareTrackResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PlaylistEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Artist        string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Local         bool                   `protobuf:"varint,5,opt,name=local,proto3" json:"local,omitempty"`        // OpenPlaylist only: held in the local library
+	Providers     []string               `protobuf:"bytes,6,rep,name=providers,proto3" json:"providers,omitempty"` // OpenPlaylist only: peer IDs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaylistEntry) Reset() {
+	*x = PlaylistEntry{}
+	mi := &file_cotune_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaylistEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaylistEntry) ProtoMessage() {}
+
+func (x *PlaylistEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaylistEntry.ProtoReflect.Descriptor instead.
+func (*PlaylistEntry) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *PlaylistEntry) GetCtid() string {
+	if x != nil {
+		return x.Ctid
+	}
+	return ""
+}
+
+func (x *PlaylistEntry) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *PlaylistEntry) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *PlaylistEntry) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *PlaylistEntry) GetLocal() bool {
+	if x != nil {
+		return x.Local
+	}
+	return false
+}
+
+func (x *PlaylistEntry) GetProviders() []string {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type Playlist struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Entries       []*PlaylistEntry       `protobuf:"bytes,4,rep,name=entries,proto3" json:"entries,omitempty"`
+	Author        string                 `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"` // peer ID of the author of a playlist saved from the network
+	Shared        bool                   `protobuf:"varint,6,opt,name=shared,proto3" json:"shared,omitempty"`
+	RecordId      string                 `protobuf:"bytes,7,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"` // ID peers open a shared playlist by
+	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Playlist) Reset() {
+	*x = Playlist{}
+	mi := &file_cotune_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Playlist) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Playlist) ProtoMessage() {}
+
+func (x *Playlist) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Playlist.ProtoReflect.Descriptor instead.
+func (*Playlist) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *Playlist) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Playlist) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Playlist) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Playlist) GetEntries() []*PlaylistEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *Playlist) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Playlist) GetShared() bool {
+	if x != nil {
+		return x.Shared
+	}
+	return false
+}
+
+func (x *Playlist) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *Playlist) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Playlist) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type PlaylistResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Playlist      *Playlist              `protobuf:"bytes,1,opt,name=playlist,proto3" json:"playlist,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaylistResponse) Reset() {
+	*x = PlaylistResponse{}
+	mi := &file_cotune_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaylistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaylistResponse) ProtoMessage() {}
+
+func (x *PlaylistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaylistResponse.ProtoReflect.Descriptor instead.
+func (*PlaylistResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *PlaylistResponse) GetPlaylist() *Playlist {
+	if x != nil {
+		return x.Playlist
+	}
+	return nil
+}
+
+func (x *PlaylistResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListPlaylistsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Playlists     []*Playlist            `protobuf:"bytes,1,rep,name=playlists,proto3" json:"playlists,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlaylistsResponse) Reset() {
+	*x = ListPlaylistsResponse{}
+	mi := &file_cotune_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlaylistsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlaylistsResponse) ProtoMessage() {}
+
+func (x *ListPlaylistsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlaylistsResponse.ProtoReflect.Descriptor instead.
+func (*ListPlaylistsResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *ListPlaylistsResponse) GetPlaylists() []*Playlist {
+	if x != nil {
+		return x.Playlists
+	}
+	return nil
+}
+
+func (x *ListPlaylistsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeletePlaylistResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePlaylistResponse) Reset() {
+	*x = DeletePlaylistResponse{}
+	mi := &file_cotune_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePlaylistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePlaylistResponse) ProtoMessage() {}
+
+func (x *DeletePlaylistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePlaylistResponse.ProtoReflect.Descriptor instead.
+func (*DeletePlaylistResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *DeletePlaylistResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeletePlaylistResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SharePlaylistResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	RecordId      string                 `protobuf:"bytes,2,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SharePlaylistResponse) Reset() {
+	*x = SharePlaylistResponse{}
+	mi := &file_cotune_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SharePlaylistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SharePlaylistResponse) ProtoMessage() {}
+
+func (x *SharePlaylistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SharePlaylistResponse.ProtoReflect.Descriptor instead.
+func (*SharePlaylistResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *SharePlaylistResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SharePlaylistResponse) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *SharePlaylistResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UnsharePlaylistResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsharePlaylistResponse) Reset() {
+	*x = UnsharePlaylistResponse{}
+	mi := &file_cotune_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsharePlaylistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsharePlaylistResponse) ProtoMessage() {}
+
+func (x *UnsharePlaylistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsharePlaylistResponse.ProtoReflect.Descriptor instead.
+func (*UnsharePlaylistResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *UnsharePlaylistResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UnsharePlaylistResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type OpenPlaylistResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecordId      string                 `protobuf:"bytes,1,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Entries       []*PlaylistEntry       `protobuf:"bytes,5,rep,name=entries,proto3" json:"entries,omitempty"`
+	Saved         *Playlist              `protobuf:"bytes,6,opt,name=saved,proto3" json:"saved,omitempty"` // local copy when save was set
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenPlaylistResponse) Reset() {
+	*x = OpenPlaylistResponse{}
+	mi := &file_cotune_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenPlaylistResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenPlaylistResponse) ProtoMessage() {}
+
+func (x *OpenPlaylistResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenPlaylistResponse.ProtoReflect.Descriptor instead.
+func (*OpenPlaylistResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *OpenPlaylistResponse) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *OpenPlaylistResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OpenPlaylistResponse) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *OpenPlaylistResponse) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *OpenPlaylistResponse) GetEntries() []*PlaylistEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *OpenPlaylistResponse) GetSaved() *Playlist {
+	if x != nil {
+		return x.Saved
+	}
+	return nil
+}
+
+func (x *OpenPlaylistResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BackupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Tracks        int32                  `protobuf:"varint,3,opt,name=tracks,proto3" json:"tracks,omitempty"`
+	Playlists     int32                  `protobuf:"varint,4,opt,name=playlists,proto3" json:"playlists,omitempty"`
+	MediaFiles    int32                  `protobuf:"varint,5,opt,name=media_files,json=mediaFiles,proto3" json:"media_files,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Sealed        bool                   `protobuf:"varint,7,opt,name=sealed,proto3" json:"sealed,omitempty"` // the key and library need the secret to restore
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupResponse) Reset() {
+	*x = BackupResponse{}
+	mi := &file_cotune_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupResponse) ProtoMessage() {}
+
+func (x *BackupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupResponse.ProtoReflect.Descriptor instead.
+func (*BackupResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *BackupResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BackupResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *BackupResponse) GetTracks() int32 {
+	if x != nil {
+		return x.Tracks
+	}
+	return 0
+}
+
+func (x *BackupResponse) GetPlaylists() int32 {
+	if x != nil {
+		return x.Playlists
+	}
+	return 0
+}
+
+func (x *BackupResponse) GetMediaFiles() int32 {
+	if x != nil {
+		return x.MediaFiles
+	}
+	return 0
+}
+
+func (x *BackupResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BackupResponse) GetSealed() bool {
+	if x != nil {
+		return x.Sealed
+	}
+	return false
+}
+
+type ExportLibraryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportLibraryResponse) Reset() {
+	*x = ExportLibraryResponse{}
+	mi := &file_cotune_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportLibraryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportLibraryResponse) ProtoMessage() {}
+
+func (x *ExportLibraryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportLibraryResponse.ProtoReflect.Descriptor instead.
+func (*ExportLibraryResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *ExportLibraryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ExportLibraryResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ExportLibraryResponse) GetError() string {
 	if x != nil {
-		return x.Peers
+		return x.Error
 	}
-	return nil
+	return ""
 }
 
-type ConnectResponse struct {
+type ImportLibraryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Tracks        int32                  `protobuf:"varint,2,opt,name=tracks,proto3" json:"tracks,omitempty"`
+	Playlists     int32                  `protobuf:"varint,3,opt,name=playlists,proto3" json:"playlists,omitempty"`
+	Existing      int32                  `protobuf:"varint,4,opt,name=existing,proto3" json:"existing,omitempty"` // already in the library
+	Missing       int32                  `protobuf:"varint,5,opt,name=missing,proto3" json:"missing,omitempty"`   // files not on this device
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConnectResponse) Reset() {
-	*x = ConnectResponse{}
-	mi := &file_cotune_proto_msgTypes[15]
+func (x *ImportLibraryResponse) Reset() {
+	*x = ImportLibraryResponse{}
+	mi := &file_cotune_proto_msgTypes[75]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConnectResponse) String() string {
+func (x *ImportLibraryResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConnectResponse) ProtoMessage() {}
+func (*ImportLibraryResponse) ProtoMessage() {}
 
-func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[15]
+func (x *ImportLibraryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[75]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -810,51 +4439,86 @@ func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConnectResponse.ProtoReflect.Descriptor instead.
-func (*ConnectResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use ImportLibraryResponse.ProtoReflect.Descriptor instead.
+func (*ImportLibraryResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{75}
 }
 
-func (x *ConnectResponse) GetSuccess() bool {
+func (x *ImportLibraryResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ConnectResponse) GetError() string {
+func (x *ImportLibraryResponse) GetTracks() int32 {
+	if x != nil {
+		return x.Tracks
+	}
+	return 0
+}
+
+func (x *ImportLibraryResponse) GetPlaylists() int32 {
+	if x != nil {
+		return x.Playlists
+	}
+	return 0
+}
+
+func (x *ImportLibraryResponse) GetExisting() int32 {
+	if x != nil {
+		return x.Existing
+	}
+	return 0
+}
+
+func (x *ImportLibraryResponse) GetMissing() int32 {
+	if x != nil {
+		return x.Missing
+	}
+	return 0
+}
+
+func (x *ImportLibraryResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type SearchResult struct {
+type WatchFoldersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ctid          string                 `protobuf:"bytes,1,opt,name=ctid,proto3" json:"ctid,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Artist        string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
-	Recognized    bool                   `protobuf:"varint,4,opt,name=recognized,proto3" json:"recognized,omitempty"`
-	Providers     []string               `protobuf:"bytes,5,rep,name=providers,proto3" json:"providers,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Folders       []string               `protobuf:"bytes,2,rep,name=folders,proto3" json:"folders,omitempty"`
+	Scanning      bool                   `protobuf:"varint,3,opt,name=scanning,proto3" json:"scanning,omitempty"`
+	Found         int32                  `protobuf:"varint,4,opt,name=found,proto3" json:"found,omitempty"` // audio files found by the current or last scan
+	Scanned       int32                  `protobuf:"varint,5,opt,name=scanned,proto3" json:"scanned,omitempty"`
+	Imported      int32                  `protobuf:"varint,6,opt,name=imported,proto3" json:"imported,omitempty"` // counts since the daemon started
+	Moved         int32                  `protobuf:"varint,7,opt,name=moved,proto3" json:"moved,omitempty"`
+	Removed       int32                  `protobuf:"varint,8,opt,name=removed,proto3" json:"removed,omitempty"`
+	Duplicates    int32                  `protobuf:"varint,9,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Failed        int32                  `protobuf:"varint,10,opt,name=failed,proto3" json:"failed,omitempty"`
+	LastScan      int64                  `protobuf:"varint,11,opt,name=last_scan,json=lastScan,proto3" json:"last_scan,omitempty"` // unix seconds
+	Error         string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchResult) Reset() {
-	*x = SearchResult{}
-	mi := &file_cotune_proto_msgTypes[16]
+func (x *WatchFoldersResponse) Reset() {
+	*x = WatchFoldersResponse{}
+	mi := &file_cotune_proto_msgTypes[76]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchResult) String() string {
+func (x *WatchFoldersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchResult) ProtoMessage() {}
+func (*WatchFoldersResponse) ProtoMessage() {}
 
-func (x *SearchResult) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[16]
+func (x *WatchFoldersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[76]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -865,112 +4529,118 @@ func (x *SearchResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
-func (*SearchResult) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use WatchFoldersResponse.ProtoReflect.Descriptor instead.
+func (*WatchFoldersResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{76}
 }
 
-func (x *SearchResult) GetCtid() string {
+func (x *WatchFoldersResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Ctid
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *SearchResult) GetTitle() string {
+func (x *WatchFoldersResponse) GetFolders() []string {
 	if x != nil {
-		return x.Title
+		return x.Folders
 	}
-	return ""
+	return nil
 }
 
-func (x *SearchResult) GetArtist() string {
+func (x *WatchFoldersResponse) GetScanning() bool {
 	if x != nil {
-		return x.Artist
+		return x.Scanning
 	}
-	return ""
+	return false
 }
 
-func (x *SearchResult) GetRecognized() bool {
+func (x *WatchFoldersResponse) GetFound() int32 {
 	if x != nil {
-		return x.Recognized
+		return x.Found
 	}
-	return false
+	return 0
 }
 
-func (x *SearchResult) GetProviders() []string {
+func (x *WatchFoldersResponse) GetScanned() int32 {
 	if x != nil {
-		return x.Providers
+		return x.Scanned
 	}
-	return nil
+	return 0
 }
 
-type SearchResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *WatchFoldersResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
 }
 
-func (x *SearchResponse) Reset() {
-	*x = SearchResponse{}
-	mi := &file_cotune_proto_msgTypes[17]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *WatchFoldersResponse) GetMoved() int32 {
+	if x != nil {
+		return x.Moved
+	}
+	return 0
 }
 
-func (x *SearchResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *WatchFoldersResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
 }
 
-func (*SearchResponse) ProtoMessage() {}
+func (x *WatchFoldersResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
 
-func (x *SearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[17]
+func (x *WatchFoldersResponse) GetFailed() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Failed
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
-func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{17}
+func (x *WatchFoldersResponse) GetLastScan() int64 {
+	if x != nil {
+		return x.LastScan
+	}
+	return 0
 }
 
-func (x *SearchResponse) GetResults() []*SearchResult {
+func (x *WatchFoldersResponse) GetError() string {
 	if x != nil {
-		return x.Results
+		return x.Error
 	}
-	return nil
+	return ""
 }
 
-type SearchProvidersResponse struct {
+type UnlockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProviderIds   []string               `protobuf:"bytes,1,rep,name=provider_ids,json=providerIds,proto3" json:"provider_ids,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchProvidersResponse) Reset() {
-	*x = SearchProvidersResponse{}
-	mi := &file_cotune_proto_msgTypes[18]
+func (x *UnlockResponse) Reset() {
+	*x = UnlockResponse{}
+	mi := &file_cotune_proto_msgTypes[77]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchProvidersResponse) String() string {
+func (x *UnlockResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchProvidersResponse) ProtoMessage() {}
+func (*UnlockResponse) ProtoMessage() {}
 
-func (x *SearchProvidersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[18]
+func (x *UnlockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[77]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -981,42 +4651,48 @@ func (x *SearchProvidersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchProvidersResponse.ProtoReflect.Descriptor instead.
-func (*SearchProvidersResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use UnlockResponse.ProtoReflect.Descriptor instead.
+func (*UnlockResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{77}
 }
 
-func (x *SearchProvidersResponse) GetProviderIds() []string {
+func (x *UnlockResponse) GetSuccess() bool {
 	if x != nil {
-		return x.ProviderIds
+		return x.Success
 	}
-	return nil
+	return false
 }
 
-type FetchResponse struct {
+func (x *UnlockResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type LockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FetchResponse) Reset() {
-	*x = FetchResponse{}
-	mi := &file_cotune_proto_msgTypes[19]
+func (x *LockResponse) Reset() {
+	*x = LockResponse{}
+	mi := &file_cotune_proto_msgTypes[78]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FetchResponse) String() string {
+func (x *LockResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FetchResponse) ProtoMessage() {}
+func (*LockResponse) ProtoMessage() {}
 
-func (x *FetchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[19]
+func (x *LockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[78]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1027,56 +4703,54 @@ func (x *FetchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FetchResponse.ProtoReflect.Descriptor instead.
-func (*FetchResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use LockResponse.ProtoReflect.Descriptor instead.
+func (*LockResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{78}
 }
 
-func (x *FetchResponse) GetSuccess() bool {
+func (x *LockResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *FetchResponse) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *FetchResponse) GetError() string {
+func (x *LockResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type ShareResponse struct {
+type VaultStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Encrypted     bool                   `protobuf:"varint,2,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	Kdf           string                 `protobuf:"bytes,3,opt,name=kdf,proto3" json:"kdf,omitempty"`                               // "argon2id" for a passphrase, "raw" for a keystore key
+	KeyId         string                 `protobuf:"bytes,4,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`              // data key the library is sealed with
+	Rotating      bool                   `protobuf:"varint,5,opt,name=rotating,proto3" json:"rotating,omitempty"`                    // a key rotation was interrupted and resumes on unlock
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // unix seconds
+	RotatedAt     int64                  `protobuf:"varint,7,opt,name=rotated_at,json=rotatedAt,proto3" json:"rotated_at,omitempty"`
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShareResponse) Reset() {
-	*x = ShareResponse{}
-	mi := &file_cotune_proto_msgTypes[20]
+func (x *VaultStatusResponse) Reset() {
+	*x = VaultStatusResponse{}
+	mi := &file_cotune_proto_msgTypes[79]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShareResponse) String() string {
+func (x *VaultStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShareResponse) ProtoMessage() {}
+func (*VaultStatusResponse) ProtoMessage() {}
 
-func (x *ShareResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[20]
+func (x *VaultStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cotune_proto_msgTypes[79]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1087,26 +4761,61 @@ func (x *ShareResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShareResponse.ProtoReflect.Descriptor instead.
-func (*ShareResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use VaultStatusResponse.ProtoReflect.Descriptor instead.
+func (*VaultStatusResponse) Descriptor() ([]byte, []int) {
+	return file_cotune_proto_rawDescGZIP(), []int{79}
 }
 
-func (x *ShareResponse) GetSuccess() bool {
+func (x *VaultStatusResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ShareResponse) GetPath() string {
+func (x *VaultStatusResponse) GetEncrypted() bool {
 	if x != nil {
-		return x.Path
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *VaultStatusResponse) GetKdf() string {
+	if x != nil {
+		return x.Kdf
 	}
 	return ""
 }
 
-func (x *ShareResponse) GetError() string {
+func (x *VaultStatusResponse) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *VaultStatusResponse) GetRotating() bool {
+	if x != nil {
+		return x.Rotating
+	}
+	return false
+}
+
+func (x *VaultStatusResponse) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *VaultStatusResponse) GetRotatedAt() int64 {
+	if x != nil {
+		return x.RotatedAt
+	}
+	return 0
+}
+
+func (x *VaultStatusResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
@@ -1122,7 +4831,7 @@ type AnnounceResponse struct {
 
 func (x *AnnounceResponse) Reset() {
 	*x = AnnounceResponse{}
-	mi := &file_cotune_proto_msgTypes[21]
+	mi := &file_cotune_proto_msgTypes[80]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1134,7 +4843,7 @@ func (x *AnnounceResponse) String() string {
 func (*AnnounceResponse) ProtoMessage() {}
 
 func (x *AnnounceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[21]
+	mi := &file_cotune_proto_msgTypes[80]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1147,7 +4856,7 @@ func (x *AnnounceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AnnounceResponse.ProtoReflect.Descriptor instead.
 func (*AnnounceResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{21}
+	return file_cotune_proto_rawDescGZIP(), []int{80}
 }
 
 func (x *AnnounceResponse) GetSuccess() bool {
@@ -1166,7 +4875,7 @@ type RelaysResponse struct {
 
 func (x *RelaysResponse) Reset() {
 	*x = RelaysResponse{}
-	mi := &file_cotune_proto_msgTypes[22]
+	mi := &file_cotune_proto_msgTypes[81]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1178,7 +4887,7 @@ func (x *RelaysResponse) String() string {
 func (*RelaysResponse) ProtoMessage() {}
 
 func (x *RelaysResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[22]
+	mi := &file_cotune_proto_msgTypes[81]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1191,7 +4900,7 @@ func (x *RelaysResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelaysResponse.ProtoReflect.Descriptor instead.
 func (*RelaysResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{22}
+	return file_cotune_proto_rawDescGZIP(), []int{81}
 }
 
 func (x *RelaysResponse) GetRelayAddresses() []string {
@@ -1210,7 +4919,7 @@ type RelayEnableResponse struct {
 
 func (x *RelayEnableResponse) Reset() {
 	*x = RelayEnableResponse{}
-	mi := &file_cotune_proto_msgTypes[23]
+	mi := &file_cotune_proto_msgTypes[82]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1222,7 +4931,7 @@ func (x *RelayEnableResponse) String() string {
 func (*RelayEnableResponse) ProtoMessage() {}
 
 func (x *RelayEnableResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[23]
+	mi := &file_cotune_proto_msgTypes[82]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1235,7 +4944,7 @@ func (x *RelayEnableResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayEnableResponse.ProtoReflect.Descriptor instead.
 func (*RelayEnableResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{23}
+	return file_cotune_proto_rawDescGZIP(), []int{82}
 }
 
 func (x *RelayEnableResponse) GetSuccess() bool {
@@ -1255,7 +4964,7 @@ type RelayRequestResponse struct {
 
 func (x *RelayRequestResponse) Reset() {
 	*x = RelayRequestResponse{}
-	mi := &file_cotune_proto_msgTypes[24]
+	mi := &file_cotune_proto_msgTypes[83]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1267,7 +4976,7 @@ func (x *RelayRequestResponse) String() string {
 func (*RelayRequestResponse) ProtoMessage() {}
 
 func (x *RelayRequestResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_cotune_proto_msgTypes[24]
+	mi := &file_cotune_proto_msgTypes[83]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1280,7 +4989,7 @@ func (x *RelayRequestResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RelayRequestResponse.ProtoReflect.Descriptor instead.
 func (*RelayRequestResponse) Descriptor() ([]byte, []int) {
-	return file_cotune_proto_rawDescGZIP(), []int{24}
+	return file_cotune_proto_rawDescGZIP(), []int{83}
 }
 
 func (x *RelayRequestResponse) GetSuccess() bool {
@@ -1312,15 +5021,23 @@ const file_cotune_proto_rawDesc = "" +
 	"\rSearchRequest\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1f\n" +
 	"\vmax_results\x18\x02 \x01(\x05R\n" +
+	"maxResults\"I\n" +
+	"\x12FindSimilarRequest\x12\x12\n" +
+	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x1f\n" +
+	"\vmax_results\x18\x02 \x01(\x05R\n" +
 	"maxResults\">\n" +
 	"\x16SearchProvidersRequest\x12\x12\n" +
 	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x10\n" +
-	"\x03max\x18\x02 \x01(\x05R\x03max\"\\\n" +
+	"\x03max\x18\x02 \x01(\x05R\x03max\"\x8e\x01\n" +
 	"\fFetchRequest\x12\x12\n" +
 	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x17\n" +
 	"\apeer_id\x18\x02 \x01(\tR\x06peerId\x12\x1f\n" +
 	"\voutput_path\x18\x03 \x01(\tR\n" +
-	"outputPath\"\xa7\x01\n" +
+	"outputPath\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x18\n" +
+	"\abitrate\x18\x05 \x01(\x05R\abitrate\"3\n" +
+	"\x18TranscodeProfilesRequest\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\"\xc2\x01\n" +
 	"\fShareRequest\x12\x19\n" +
 	"\btrack_id\x18\x01 \x01(\tR\atrackId\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
@@ -1329,15 +5046,110 @@ const file_cotune_proto_rawDesc = "" +
 	"\n" +
 	"recognized\x18\x05 \x01(\bR\n" +
 	"recognized\x12\x1a\n" +
-	"\bchecksum\x18\x06 \x01(\tR\bchecksum\"\x11\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\x12\x19\n" +
+	"\bin_place\x18\a \x01(\bR\ainPlace\"8\n" +
+	"\x0eArtworkRequest\x12\x12\n" +
+	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\tR\x04size\"B\n" +
+	"\x0fWaveformRequest\x12\x12\n" +
+	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x1b\n" +
+	"\tmax_peaks\x18\x02 \x01(\x05R\bmaxPeaks\"@\n" +
+	"\x0fLoudnessRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\tR\atrackId\x12\x12\n" +
+	"\x04ctid\x18\x02 \x01(\tR\x04ctid\"'\n" +
+	"\x0fListJobsRequest\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\"+\n" +
+	"\x10RetryJobsRequest\x12\x17\n" +
+	"\ajob_ids\x18\x01 \x03(\tR\x06jobIds\"P\n" +
+	"\x12DeleteTrackRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\tR\atrackId\x12\x1f\n" +
+	"\vdelete_file\x18\x02 \x01(\bR\n" +
+	"deleteFile\"\xe0\x01\n" +
+	"\x1aUpdateTrackMetadataRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\tR\atrackId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06artist\x18\x03 \x01(\tR\x06artist\x12\x14\n" +
+	"\x05album\x18\x04 \x01(\tR\x05album\x12!\n" +
+	"\ftrack_number\x18\x05 \x01(\x05R\vtrackNumber\x12\x12\n" +
+	"\x04year\x18\x06 \x01(\x05R\x04year\x12\x14\n" +
+	"\x05genre\x18\a \x01(\tR\x05genre\x12\x16\n" +
+	"\x06fields\x18\b \x03(\tR\x06fields\"0\n" +
+	"\x13UnshareTrackRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\tR\atrackId\"c\n" +
+	"\x15CreatePlaylistRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05ctids\x18\x03 \x03(\tR\x05ctids\"\x16\n" +
+	"\x14ListPlaylistsRequest\"\x9c\x01\n" +
+	"\x15UpdatePlaylistRequest\x12\x1f\n" +
+	"\vplaylist_id\x18\x01 \x01(\tR\n" +
+	"playlistId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05ctids\x18\x04 \x03(\tR\x05ctids\x12\x16\n" +
+	"\x06fields\x18\x05 \x03(\tR\x06fields\"8\n" +
+	"\x15DeletePlaylistRequest\x12\x1f\n" +
+	"\vplaylist_id\x18\x01 \x01(\tR\n" +
+	"playlistId\"7\n" +
+	"\x14SharePlaylistRequest\x12\x1f\n" +
+	"\vplaylist_id\x18\x01 \x01(\tR\n" +
+	"playlistId\"9\n" +
+	"\x16UnsharePlaylistRequest\x12\x1f\n" +
+	"\vplaylist_id\x18\x01 \x01(\tR\n" +
+	"playlistId\"F\n" +
+	"\x13OpenPlaylistRequest\x12\x1b\n" +
+	"\trecord_id\x18\x01 \x01(\tR\brecordId\x12\x12\n" +
+	"\x04save\x18\x02 \x01(\bR\x04save\"\xa0\x01\n" +
+	"\rBackupRequest\x12\x1f\n" +
+	"\voutput_path\x18\x01 \x01(\tR\n" +
+	"outputPath\x12#\n" +
+	"\rinclude_media\x18\x02 \x01(\bR\fincludeMedia\x12+\n" +
+	"\x06secret\x18\x03 \x01(\v2\x13.cotune.VaultSecretR\x06secret\x12\x1c\n" +
+	"\tplaintext\x18\x04 \x01(\bR\tplaintext\"p\n" +
+	"\x14ExportLibraryRequest\x12\x1f\n" +
+	"\voutput_path\x18\x01 \x01(\tR\n" +
+	"outputPath\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x1f\n" +
+	"\vplaylist_id\x18\x03 \x01(\tR\n" +
+	"playlistId\"B\n" +
+	"\x14ImportLibraryRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\"\x15\n" +
+	"\x13WatchFoldersRequest\"+\n" +
+	"\x15AddWatchFolderRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\".\n" +
+	"\x18RemoveWatchFolderRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"?\n" +
+	"\vVaultSecret\x12\x1e\n" +
+	"\n" +
+	"passphrase\x18\x01 \x01(\tR\n" +
+	"passphrase\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\fR\x03key\"<\n" +
+	"\rUnlockRequest\x12+\n" +
+	"\x06secret\x18\x01 \x01(\v2\x13.cotune.VaultSecretR\x06secret\"\r\n" +
+	"\vLockRequest\"\x14\n" +
+	"\x12VaultStatusRequest\"F\n" +
+	"\x17EnableEncryptionRequest\x12+\n" +
+	"\x06secret\x18\x01 \x01(\v2\x13.cotune.VaultSecretR\x06secret\"\x82\x01\n" +
+	"\x18ChangeVaultSecretRequest\x122\n" +
+	"\n" +
+	"old_secret\x18\x01 \x01(\v2\x13.cotune.VaultSecretR\toldSecret\x122\n" +
+	"\n" +
+	"new_secret\x18\x02 \x01(\v2\x13.cotune.VaultSecretR\tnewSecret\"D\n" +
+	"\x15RotateVaultKeyRequest\x12+\n" +
+	"\x06secret\x18\x01 \x01(\v2\x13.cotune.VaultSecretR\x06secret\"\x11\n" +
 	"\x0fAnnounceRequest\"\x0f\n" +
 	"\rRelaysRequest\"\x14\n" +
 	"\x12RelayEnableRequest\".\n" +
 	"\x13RelayRequestRequest\x12\x17\n" +
-	"\apeer_id\x18\x01 \x01(\tR\x06peerId\"D\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\"\xd1\x01\n" +
 	"\x0eStatusResponse\x12\x18\n" +
 	"\arunning\x18\x01 \x01(\bR\arunning\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\"A\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12)\n" +
+	"\x10ffmpeg_available\x18\x03 \x01(\bR\x0fffmpegAvailable\x12%\n" +
+	"\x0effmpeg_version\x18\x04 \x01(\tR\rffmpegVersion\x12!\n" +
+	"\fffmpeg_error\x18\x05 \x01(\tR\vffmpegError\x12\x16\n" +
+	"\x06locked\x18\x06 \x01(\bR\x06locked\"A\n" +
 	"\bPeerInfo\x12\x17\n" +
 	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12\x1c\n" +
 	"\taddresses\x18\x02 \x03(\tR\taddresses\"A\n" +
@@ -1347,7 +5159,7 @@ const file_cotune_proto_rawDesc = "" +
 	"\x05peers\x18\x01 \x03(\v2\x10.cotune.PeerInfoR\x05peers\"A\n" +
 	"\x0fConnectResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x8e\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xc2\x04\n" +
 	"\fSearchResult\x12\x12\n" +
 	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
@@ -1355,19 +5167,210 @@ const file_cotune_proto_rawDesc = "" +
 	"\n" +
 	"recognized\x18\x04 \x01(\bR\n" +
 	"recognized\x12\x1c\n" +
-	"\tproviders\x18\x05 \x03(\tR\tproviders\"@\n" +
+	"\tproviders\x18\x05 \x03(\tR\tproviders\x12'\n" +
+	"\x0ffingerprint_key\x18\x06 \x01(\tR\x0efingerprintKey\x12\x1e\n" +
+	"\n" +
+	"alternates\x18\a \x03(\tR\n" +
+	"alternates\x12\x14\n" +
+	"\x05codec\x18\b \x01(\tR\x05codec\x12\x1f\n" +
+	"\vsample_rate\x18\t \x01(\x05R\n" +
+	"sampleRate\x12\x1a\n" +
+	"\bchannels\x18\n" +
+	" \x01(\x05R\bchannels\x12\x18\n" +
+	"\abitrate\x18\v \x01(\x05R\abitrate\x12\x1f\n" +
+	"\vduration_ms\x18\f \x01(\x03R\n" +
+	"durationMs\x12\x1b\n" +
+	"\tfile_size\x18\r \x01(\x03R\bfileSize\x12\x18\n" +
+	"\aartwork\x18\x0e \x01(\tR\aartwork\x12!\n" +
+	"\fhas_loudness\x18\x0f \x01(\bR\vhasLoudness\x12'\n" +
+	"\x0fintegrated_lufs\x18\x10 \x01(\x01R\x0eintegratedLufs\x12\x19\n" +
+	"\brange_lu\x18\x11 \x01(\x01R\arangeLu\x12$\n" +
+	"\x0etrue_peak_dbtp\x18\x12 \x01(\x01R\ftruePeakDbtp\x12\x17\n" +
+	"\again_db\x18\x13 \x01(\x01R\x06gainDb\"@\n" +
 	"\x0eSearchResponse\x12.\n" +
-	"\aresults\x18\x01 \x03(\v2\x14.cotune.SearchResultR\aresults\"<\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.cotune.SearchResultR\aresults\"\x84\x01\n" +
+	"\fSimilarTrack\x12\x12\n" +
+	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06artist\x18\x03 \x01(\tR\x06artist\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x1c\n" +
+	"\tproviders\x18\x05 \x03(\tR\tproviders\"[\n" +
+	"\x13FindSimilarResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.cotune.SimilarTrackR\aresults\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"<\n" +
 	"\x17SearchProvidersResponse\x12!\n" +
-	"\fprovider_ids\x18\x01 \x03(\tR\vproviderIds\"S\n" +
+	"\fprovider_ids\x18\x01 \x03(\tR\vproviderIds\"\xe5\x01\n" +
 	"\rFetchResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"S\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1e\n" +
+	"\n" +
+	"transcoded\x18\x04 \x01(\bR\n" +
+	"transcoded\x12\x16\n" +
+	"\x06format\x18\x05 \x01(\tR\x06format\x12\x18\n" +
+	"\abitrate\x18\x06 \x01(\x05R\abitrate\x12\x1b\n" +
+	"\tmime_type\x18\a \x01(\tR\bmimeType\x12!\n" +
+	"\ffetched_ctid\x18\b \x01(\tR\vfetchedCtid\"\xb2\x01\n" +
+	"\x10TranscodeProfile\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x1f\n" +
+	"\vmin_bitrate\x18\x03 \x01(\x05R\n" +
+	"minBitrate\x12\x1f\n" +
+	"\vmax_bitrate\x18\x04 \x01(\x05R\n" +
+	"maxBitrate\x12'\n" +
+	"\x0fdefault_bitrate\x18\x05 \x01(\x05R\x0edefaultBitrate\"g\n" +
+	"\x19TranscodeProfilesResponse\x124\n" +
+	"\bprofiles\x18\x01 \x03(\v2\x18.cotune.TranscodeProfileR\bprofiles\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"X\n" +
+	"\x0fArtworkResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"O\n" +
+	"\rWaveformLevel\x12(\n" +
+	"\x10samples_per_peak\x18\x01 \x01(\x05R\x0esamplesPerPeak\x12\x14\n" +
+	"\x05peaks\x18\x02 \x01(\fR\x05peaks\"x\n" +
+	"\x10WaveformResponse\x12\x1f\n" +
+	"\vsample_rate\x18\x01 \x01(\x05R\n" +
+	"sampleRate\x12-\n" +
+	"\x06levels\x18\x02 \x03(\v2\x15.cotune.WaveformLevelR\x06levels\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xab\x01\n" +
+	"\x10LoudnessResponse\x12'\n" +
+	"\x0fintegrated_lufs\x18\x01 \x01(\x01R\x0eintegratedLufs\x12\x19\n" +
+	"\brange_lu\x18\x02 \x01(\x01R\arangeLu\x12$\n" +
+	"\x0etrue_peak_dbtp\x18\x03 \x01(\x01R\ftruePeakDbtp\x12\x17\n" +
+	"\again_db\x18\x04 \x01(\x01R\x06gainDb\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"S\n" +
 	"\rShareResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\",\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xe2\x01\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\tR\atrackId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x12!\n" +
+	"\fnext_attempt\x18\x06 \x01(\x03R\vnextAttempt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\x03R\tupdatedAt\"I\n" +
+	"\x10ListJobsResponse\x12\x1f\n" +
+	"\x04jobs\x18\x01 \x03(\v2\v.cotune.JobR\x04jobs\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"C\n" +
+	"\x11RetryJobsResponse\x12\x18\n" +
+	"\aretried\x18\x01 \x01(\x05R\aretried\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"E\n" +
+	"\x13DeleteTrackResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"m\n" +
+	"\x1bUpdateTrackMetadataResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1e\n" +
+	"\n" +
+	"recognized\x18\x02 \x01(\bR\n" +
+	"recognized\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"F\n" +
+	"\x14UnshareTrackResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xa6\x01\n" +
+	"\rPlaylistEntry\x12\x12\n" +
+	"\x04ctid\x18\x01 \x01(\tR\x04ctid\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06artist\x18\x03 \x01(\tR\x06artist\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x03R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05local\x18\x05 \x01(\bR\x05local\x12\x1c\n" +
+	"\tproviders\x18\x06 \x03(\tR\tproviders\"\x8c\x02\n" +
+	"\bPlaylist\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12/\n" +
+	"\aentries\x18\x04 \x03(\v2\x15.cotune.PlaylistEntryR\aentries\x12\x16\n" +
+	"\x06author\x18\x05 \x01(\tR\x06author\x12\x16\n" +
+	"\x06shared\x18\x06 \x01(\bR\x06shared\x12\x1b\n" +
+	"\trecord_id\x18\a \x01(\tR\brecordId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\x03R\tupdatedAt\"V\n" +
+	"\x10PlaylistResponse\x12,\n" +
+	"\bplaylist\x18\x01 \x01(\v2\x10.cotune.PlaylistR\bplaylist\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"]\n" +
+	"\x15ListPlaylistsResponse\x12.\n" +
+	"\tplaylists\x18\x01 \x03(\v2\x10.cotune.PlaylistR\tplaylists\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"H\n" +
+	"\x16DeletePlaylistResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"d\n" +
+	"\x15SharePlaylistResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
+	"\trecord_id\x18\x02 \x01(\tR\brecordId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"I\n" +
+	"\x17UnsharePlaylistResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xf0\x01\n" +
+	"\x14OpenPlaylistResponse\x12\x1b\n" +
+	"\trecord_id\x18\x01 \x01(\tR\brecordId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12/\n" +
+	"\aentries\x18\x05 \x03(\v2\x15.cotune.PlaylistEntryR\aentries\x12&\n" +
+	"\x05saved\x18\x06 \x01(\v2\x10.cotune.PlaylistR\x05saved\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xc3\x01\n" +
+	"\x0eBackupResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06tracks\x18\x03 \x01(\x05R\x06tracks\x12\x1c\n" +
+	"\tplaylists\x18\x04 \x01(\x05R\tplaylists\x12\x1f\n" +
+	"\vmedia_files\x18\x05 \x01(\x05R\n" +
+	"mediaFiles\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x16\n" +
+	"\x06sealed\x18\a \x01(\bR\x06sealed\"[\n" +
+	"\x15ExportLibraryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xb3\x01\n" +
+	"\x15ImportLibraryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x16\n" +
+	"\x06tracks\x18\x02 \x01(\x05R\x06tracks\x12\x1c\n" +
+	"\tplaylists\x18\x03 \x01(\x05R\tplaylists\x12\x1a\n" +
+	"\bexisting\x18\x04 \x01(\x05R\bexisting\x12\x18\n" +
+	"\amissing\x18\x05 \x01(\x05R\amissing\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xcd\x02\n" +
+	"\x14WatchFoldersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\afolders\x18\x02 \x03(\tR\afolders\x12\x1a\n" +
+	"\bscanning\x18\x03 \x01(\bR\bscanning\x12\x14\n" +
+	"\x05found\x18\x04 \x01(\x05R\x05found\x12\x18\n" +
+	"\ascanned\x18\x05 \x01(\x05R\ascanned\x12\x1a\n" +
+	"\bimported\x18\x06 \x01(\x05R\bimported\x12\x14\n" +
+	"\x05moved\x18\a \x01(\x05R\x05moved\x12\x18\n" +
+	"\aremoved\x18\b \x01(\x05R\aremoved\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\t \x01(\x05R\n" +
+	"duplicates\x12\x16\n" +
+	"\x06failed\x18\n" +
+	" \x01(\x05R\x06failed\x12\x1b\n" +
+	"\tlast_scan\x18\v \x01(\x03R\blastScan\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\"@\n" +
+	"\x0eUnlockResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\">\n" +
+	"\fLockResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xe6\x01\n" +
+	"\x13VaultStatusResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1c\n" +
+	"\tencrypted\x18\x02 \x01(\bR\tencrypted\x12\x10\n" +
+	"\x03kdf\x18\x03 \x01(\tR\x03kdf\x12\x15\n" +
+	"\x06key_id\x18\x04 \x01(\tR\x05keyId\x12\x1a\n" +
+	"\brotating\x18\x05 \x01(\bR\brotating\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"rotated_at\x18\a \x01(\x03R\trotatedAt\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\",\n" +
 	"\x10AnnounceResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"9\n" +
 	"\x0eRelaysResponse\x12'\n" +
@@ -1376,7 +5379,7 @@ const file_cotune_proto_rawDesc = "" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\"F\n" +
 	"\x14RelayRequestResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error2\x88\x06\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xef\x16\n" +
 	"\rCotuneService\x127\n" +
 	"\x06Status\x12\x15.cotune.StatusRequest\x1a\x16.cotune.StatusResponse\x12=\n" +
 	"\bPeerInfo\x12\x17.cotune.PeerInfoRequest\x1a\x18.cotune.PeerInfoResponse\x12?\n" +
@@ -1384,9 +5387,39 @@ const file_cotune_proto_rawDesc = "" +
 	"KnownPeers\x12\x15.cotune.StatusRequest\x1a\x1a.cotune.KnownPeersResponse\x12:\n" +
 	"\aConnect\x12\x16.cotune.ConnectRequest\x1a\x17.cotune.ConnectResponse\x127\n" +
 	"\x06Search\x12\x15.cotune.SearchRequest\x1a\x16.cotune.SearchResponse\x12R\n" +
-	"\x0fSearchProviders\x12\x1e.cotune.SearchProvidersRequest\x1a\x1f.cotune.SearchProvidersResponse\x124\n" +
-	"\x05Fetch\x12\x14.cotune.FetchRequest\x1a\x15.cotune.FetchResponse\x124\n" +
+	"\x0fSearchProviders\x12\x1e.cotune.SearchProvidersRequest\x1a\x1f.cotune.SearchProvidersResponse\x12F\n" +
+	"\vFindSimilar\x12\x1a.cotune.FindSimilarRequest\x1a\x1b.cotune.FindSimilarResponse\x12=\n" +
+	"\n" +
+	"GetArtwork\x12\x16.cotune.ArtworkRequest\x1a\x17.cotune.ArtworkResponse\x12@\n" +
+	"\vGetWaveform\x12\x17.cotune.WaveformRequest\x1a\x18.cotune.WaveformResponse\x12@\n" +
+	"\vGetLoudness\x12\x17.cotune.LoudnessRequest\x1a\x18.cotune.LoudnessResponse\x124\n" +
+	"\x05Fetch\x12\x14.cotune.FetchRequest\x1a\x15.cotune.FetchResponse\x12X\n" +
+	"\x11TranscodeProfiles\x12 .cotune.TranscodeProfilesRequest\x1a!.cotune.TranscodeProfilesResponse\x124\n" +
 	"\x05Share\x12\x14.cotune.ShareRequest\x1a\x15.cotune.ShareResponse\x12=\n" +
+	"\bListJobs\x12\x17.cotune.ListJobsRequest\x1a\x18.cotune.ListJobsResponse\x12@\n" +
+	"\tRetryJobs\x12\x18.cotune.RetryJobsRequest\x1a\x19.cotune.RetryJobsResponse\x12F\n" +
+	"\vDeleteTrack\x12\x1a.cotune.DeleteTrackRequest\x1a\x1b.cotune.DeleteTrackResponse\x12^\n" +
+	"\x13UpdateTrackMetadata\x12\".cotune.UpdateTrackMetadataRequest\x1a#.cotune.UpdateTrackMetadataResponse\x12I\n" +
+	"\fUnshareTrack\x12\x1b.cotune.UnshareTrackRequest\x1a\x1c.cotune.UnshareTrackResponse\x12I\n" +
+	"\x0eCreatePlaylist\x12\x1d.cotune.CreatePlaylistRequest\x1a\x18.cotune.PlaylistResponse\x12L\n" +
+	"\rListPlaylists\x12\x1c.cotune.ListPlaylistsRequest\x1a\x1d.cotune.ListPlaylistsResponse\x12I\n" +
+	"\x0eUpdatePlaylist\x12\x1d.cotune.UpdatePlaylistRequest\x1a\x18.cotune.PlaylistResponse\x12O\n" +
+	"\x0eDeletePlaylist\x12\x1d.cotune.DeletePlaylistRequest\x1a\x1e.cotune.DeletePlaylistResponse\x12L\n" +
+	"\rSharePlaylist\x12\x1c.cotune.SharePlaylistRequest\x1a\x1d.cotune.SharePlaylistResponse\x12R\n" +
+	"\x0fUnsharePlaylist\x12\x1e.cotune.UnsharePlaylistRequest\x1a\x1f.cotune.UnsharePlaylistResponse\x12I\n" +
+	"\fOpenPlaylist\x12\x1b.cotune.OpenPlaylistRequest\x1a\x1c.cotune.OpenPlaylistResponse\x127\n" +
+	"\x06Backup\x12\x15.cotune.BackupRequest\x1a\x16.cotune.BackupResponse\x12L\n" +
+	"\rExportLibrary\x12\x1c.cotune.ExportLibraryRequest\x1a\x1d.cotune.ExportLibraryResponse\x12L\n" +
+	"\rImportLibrary\x12\x1c.cotune.ImportLibraryRequest\x1a\x1d.cotune.ImportLibraryResponse\x12I\n" +
+	"\fWatchFolders\x12\x1b.cotune.WatchFoldersRequest\x1a\x1c.cotune.WatchFoldersResponse\x12M\n" +
+	"\x0eAddWatchFolder\x12\x1d.cotune.AddWatchFolderRequest\x1a\x1c.cotune.WatchFoldersResponse\x12S\n" +
+	"\x11RemoveWatchFolder\x12 .cotune.RemoveWatchFolderRequest\x1a\x1c.cotune.WatchFoldersResponse\x127\n" +
+	"\x06Unlock\x12\x15.cotune.UnlockRequest\x1a\x16.cotune.UnlockResponse\x121\n" +
+	"\x04Lock\x12\x13.cotune.LockRequest\x1a\x14.cotune.LockResponse\x12F\n" +
+	"\vVaultStatus\x12\x1a.cotune.VaultStatusRequest\x1a\x1b.cotune.VaultStatusResponse\x12P\n" +
+	"\x10EnableEncryption\x12\x1f.cotune.EnableEncryptionRequest\x1a\x1b.cotune.VaultStatusResponse\x12R\n" +
+	"\x11ChangeVaultSecret\x12 .cotune.ChangeVaultSecretRequest\x1a\x1b.cotune.VaultStatusResponse\x12L\n" +
+	"\x0eRotateVaultKey\x12\x1d.cotune.RotateVaultKeyRequest\x1a\x1b.cotune.VaultStatusResponse\x12=\n" +
 	"\bAnnounce\x12\x17.cotune.AnnounceRequest\x1a\x18.cotune.AnnounceResponse\x127\n" +
 	"\x06Relays\x12\x15.cotune.RelaysRequest\x1a\x16.cotune.RelaysResponse\x12F\n" +
 	"\vRelayEnable\x12\x1a.cotune.RelayEnableRequest\x1a\x1b.cotune.RelayEnableResponse\x12I\n" +
@@ -1404,68 +5437,200 @@ func file_cotune_proto_rawDescGZIP() []byte {
 	return file_cotune_proto_rawDescData
 }
 
-var file_cotune_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_cotune_proto_msgTypes = make([]protoimpl.MessageInfo, 84)
 var file_cotune_proto_goTypes = []any{
-	(*StatusRequest)(nil),           // 0: cotune.StatusRequest
-	(*PeerInfoRequest)(nil),         // 1: cotune.PeerInfoRequest
-	(*ConnectRequest)(nil),          // 2: cotune.ConnectRequest
-	(*SearchRequest)(nil),           // 3: cotune.SearchRequest
-	(*SearchProvidersRequest)(nil),  // 4: cotune.SearchProvidersRequest
-	(*FetchRequest)(nil),            // 5: cotune.FetchRequest
-	(*ShareRequest)(nil),            // 6: cotune.ShareRequest
-	(*AnnounceRequest)(nil),         // 7: cotune.AnnounceRequest
-	(*RelaysRequest)(nil),           // 8: cotune.RelaysRequest
-	(*RelayEnableRequest)(nil),      // 9: cotune.RelayEnableRequest
-	(*RelayRequestRequest)(nil),     // 10: cotune.RelayRequestRequest
-	(*StatusResponse)(nil),          // 11: cotune.StatusResponse
-	(*PeerInfo)(nil),                // 12: cotune.PeerInfo
-	(*PeerInfoResponse)(nil),        // 13: cotune.PeerInfoResponse
-	(*KnownPeersResponse)(nil),      // 14: cotune.KnownPeersResponse
-	(*ConnectResponse)(nil),         // 15: cotune.ConnectResponse
-	(*SearchResult)(nil),            // 16: cotune.SearchResult
-	(*SearchResponse)(nil),          // 17: cotune.SearchResponse
-	(*SearchProvidersResponse)(nil), // 18: cotune.SearchProvidersResponse
-	(*FetchResponse)(nil),           // 19: cotune.FetchResponse
-	(*ShareResponse)(nil),           // 20: cotune.ShareResponse
-	(*AnnounceResponse)(nil),        // 21: cotune.AnnounceResponse
-	(*RelaysResponse)(nil),          // 22: cotune.RelaysResponse
-	(*RelayEnableResponse)(nil),     // 23: cotune.RelayEnableResponse
-	(*RelayRequestResponse)(nil),    // 24: cotune.RelayRequestResponse
+	(*StatusRequest)(nil),               // 0: cotune.StatusRequest
+	(*PeerInfoRequest)(nil),             // 1: cotune.PeerInfoRequest
+	(*ConnectRequest)(nil),              // 2: cotune.ConnectRequest
+	(*SearchRequest)(nil),               // 3: cotune.SearchRequest
+	(*FindSimilarRequest)(nil),          // 4: cotune.FindSimilarRequest
+	(*SearchProvidersRequest)(nil),      // 5: cotune.SearchProvidersRequest
+	(*FetchRequest)(nil),                // 6: cotune.FetchRequest
+	(*TranscodeProfilesRequest)(nil),    // 7: cotune.TranscodeProfilesRequest
+	(*ShareRequest)(nil),                // 8: cotune.ShareRequest
+	(*ArtworkRequest)(nil),              // 9: cotune.ArtworkRequest
+	(*WaveformRequest)(nil),             // 10: cotune.WaveformRequest
+	(*LoudnessRequest)(nil),             // 11: cotune.LoudnessRequest
+	(*ListJobsRequest)(nil),             // 12: cotune.ListJobsRequest
+	(*RetryJobsRequest)(nil),            // 13: cotune.RetryJobsRequest
+	(*DeleteTrackRequest)(nil),          // 14: cotune.DeleteTrackRequest
+	(*UpdateTrackMetadataRequest)(nil),  // 15: cotune.UpdateTrackMetadataRequest
+	(*UnshareTrackRequest)(nil),         // 16: cotune.UnshareTrackRequest
+	(*CreatePlaylistRequest)(nil),       // 17: cotune.CreatePlaylistRequest
+	(*ListPlaylistsRequest)(nil),        // 18: cotune.ListPlaylistsRequest
+	(*UpdatePlaylistRequest)(nil),       // 19: cotune.UpdatePlaylistRequest
+	(*DeletePlaylistRequest)(nil),       // 20: cotune.DeletePlaylistRequest
+	(*SharePlaylistRequest)(nil),        // 21: cotune.SharePlaylistRequest
+	(*UnsharePlaylistRequest)(nil),      // 22: cotune.UnsharePlaylistRequest
+	(*OpenPlaylistRequest)(nil),         // 23: cotune.OpenPlaylistRequest
+	(*BackupRequest)(nil),               // 24: cotune.BackupRequest
+	(*ExportLibraryRequest)(nil),        // 25: cotune.ExportLibraryRequest
+	(*ImportLibraryRequest)(nil),        // 26: cotune.ImportLibraryRequest
+	(*WatchFoldersRequest)(nil),         // 27: cotune.WatchFoldersRequest
+	(*AddWatchFolderRequest)(nil),       // 28: cotune.AddWatchFolderRequest
+	(*RemoveWatchFolderRequest)(nil),    // 29: cotune.RemoveWatchFolderRequest
+	(*VaultSecret)(nil),                 // 30: cotune.VaultSecret
+	(*UnlockRequest)(nil),               // 31: cotune.UnlockRequest
+	(*LockRequest)(nil),                 // 32: cotune.LockRequest
+	(*VaultStatusRequest)(nil),          // 33: cotune.VaultStatusRequest
+	(*EnableEncryptionRequest)(nil),     // 34: cotune.EnableEncryptionRequest
+	(*ChangeVaultSecretRequest)(nil),    // 35: cotune.ChangeVaultSecretRequest
+	(*RotateVaultKeyRequest)(nil),       // 36: cotune.RotateVaultKeyRequest
+	(*AnnounceRequest)(nil),             // 37: cotune.AnnounceRequest
+	(*RelaysRequest)(nil),               // 38: cotune.RelaysRequest
+	(*RelayEnableRequest)(nil),          // 39: cotune.RelayEnableRequest
+	(*RelayRequestRequest)(nil),         // 40: cotune.RelayRequestRequest
+	(*StatusResponse)(nil),              // 41: cotune.StatusResponse
+	(*PeerInfo)(nil),                    // 42: cotune.PeerInfo
+	(*PeerInfoResponse)(nil),            // 43: cotune.PeerInfoResponse
+	(*KnownPeersResponse)(nil),          // 44: cotune.KnownPeersResponse
+	(*ConnectResponse)(nil),             // 45: cotune.ConnectResponse
+	(*SearchResult)(nil),                // 46: cotune.SearchResult
+	(*SearchResponse)(nil),              // 47: cotune.SearchResponse
+	(*SimilarTrack)(nil),                // 48: cotune.SimilarTrack
+	(*FindSimilarResponse)(nil),         // 49: cotune.FindSimilarResponse
+	(*SearchProvidersResponse)(nil),     // 50: cotune.SearchProvidersResponse
+	(*FetchResponse)(nil),               // 51: cotune.FetchResponse
+	(*TranscodeProfile)(nil),            // 52: cotune.TranscodeProfile
+	(*TranscodeProfilesResponse)(nil),   // 53: cotune.TranscodeProfilesResponse
+	(*ArtworkResponse)(nil),             // 54: cotune.ArtworkResponse
+	(*WaveformLevel)(nil),               // 55: cotune.WaveformLevel
+	(*WaveformResponse)(nil),            // 56: cotune.WaveformResponse
+	(*LoudnessResponse)(nil),            // 57: cotune.LoudnessResponse
+	(*ShareResponse)(nil),               // 58: cotune.ShareResponse
+	(*Job)(nil),                         // 59: cotune.Job
+	(*ListJobsResponse)(nil),            // 60: cotune.ListJobsResponse
+	(*RetryJobsResponse)(nil),           // 61: cotune.RetryJobsResponse
+	(*DeleteTrackResponse)(nil),         // 62: cotune.DeleteTrackResponse
+	(*UpdateTrackMetadataResponse)(nil), // 63: cotune.UpdateTrackMetadataResponse
+	(*UnshareTrackResponse)(nil),        // 64: cotune.UnshareTrackResponse
+	(*PlaylistEntry)(nil),               // 65: cotune.PlaylistEntry
+	(*Playlist)(nil),                    // 66: cotune.Playlist
+	(*PlaylistResponse)(nil),            // 67: cotune.PlaylistResponse
+	(*ListPlaylistsResponse)(nil),       // 68: cotune.ListPlaylistsResponse
+	(*DeletePlaylistResponse)(nil),      // 69: cotune.DeletePlaylistResponse
+	(*SharePlaylistResponse)(nil),       // 70: cotune.SharePlaylistResponse
+	(*UnsharePlaylistResponse)(nil),     // 71: cotune.UnsharePlaylistResponse
+	(*OpenPlaylistResponse)(nil),        // 72: cotune.OpenPlaylistResponse
+	(*BackupResponse)(nil),              // 73: cotune.BackupResponse
+	(*ExportLibraryResponse)(nil),       // 74: cotune.ExportLibraryResponse
+	(*ImportLibraryResponse)(nil),       // 75: cotune.ImportLibraryResponse
+	(*WatchFoldersResponse)(nil),        // 76: cotune.WatchFoldersResponse
+	(*UnlockResponse)(nil),              // 77: cotune.UnlockResponse
+	(*LockResponse)(nil),                // 78: cotune.LockResponse
+	(*VaultStatusResponse)(nil),         // 79: cotune.VaultStatusResponse
+	(*AnnounceResponse)(nil),            // 80: cotune.AnnounceResponse
+	(*RelaysResponse)(nil),              // 81: cotune.RelaysResponse
+	(*RelayEnableResponse)(nil),         // 82: cotune.RelayEnableResponse
+	(*RelayRequestResponse)(nil),        // 83: cotune.RelayRequestResponse
 }
 var file_cotune_proto_depIdxs = []int32{
-	12, // 0: cotune.ConnectRequest.peer_info:type_name -> cotune.PeerInfo
-	12, // 1: cotune.PeerInfoResponse.peer_info:type_name -> cotune.PeerInfo
-	12, // 2: cotune.KnownPeersResponse.peers:type_name -> cotune.PeerInfo
-	16, // 3: cotune.SearchResponse.results:type_name -> cotune.SearchResult
-	0,  // 4: cotune.CotuneService.Status:input_type -> cotune.StatusRequest
-	1,  // 5: cotune.CotuneService.PeerInfo:input_type -> cotune.PeerInfoRequest
-	0,  // 6: cotune.CotuneService.KnownPeers:input_type -> cotune.StatusRequest
-	2,  // 7: cotune.CotuneService.Connect:input_type -> cotune.ConnectRequest
-	3,  // 8: cotune.CotuneService.Search:input_type -> cotune.SearchRequest
-	4,  // 9: cotune.CotuneService.SearchProviders:input_type -> cotune.SearchProvidersRequest
-	5,  // 10: cotune.CotuneService.Fetch:input_type -> cotune.FetchRequest
-	6,  // 11: cotune.CotuneService.Share:input_type -> cotune.ShareRequest
-	7,  // 12: cotune.CotuneService.Announce:input_type -> cotune.AnnounceRequest
-	8,  // 13: cotune.CotuneService.Relays:input_type -> cotune.RelaysRequest
-	9,  // 14: cotune.CotuneService.RelayEnable:input_type -> cotune.RelayEnableRequest
-	10, // 15: cotune.CotuneService.RelayRequest:input_type -> cotune.RelayRequestRequest
-	11, // 16: cotune.CotuneService.Status:output_type -> cotune.StatusResponse
-	13, // 17: cotune.CotuneService.PeerInfo:output_type -> cotune.PeerInfoResponse
-	14, // 18: cotune.CotuneService.KnownPeers:output_type -> cotune.KnownPeersResponse
-	15, // 19: cotune.CotuneService.Connect:output_type -> cotune.ConnectResponse
-	17, // 20: cotune.CotuneService.Search:output_type -> cotune.SearchResponse
-	18, // 21: cotune.CotuneService.SearchProviders:output_type -> cotune.SearchProvidersResponse
-	19, // 22: cotune.CotuneService.Fetch:output_type -> cotune.FetchResponse
-	20, // 23: cotune.CotuneService.Share:output_type -> cotune.ShareResponse
-	21, // 24: cotune.CotuneService.Announce:output_type -> cotune.AnnounceResponse
-	22, // 25: cotune.CotuneService.Relays:output_type -> cotune.RelaysResponse
-	23, // 26: cotune.CotuneService.RelayEnable:output_type -> cotune.RelayEnableResponse
-	24, // 27: cotune.CotuneService.RelayRequest:output_type -> cotune.RelayRequestResponse
-	16, // [16:28] is the sub-list for method output_type
-	4,  // [4:16] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	42, // 0: cotune.ConnectRequest.peer_info:type_name -> cotune.PeerInfo
+	30, // 1: cotune.BackupRequest.secret:type_name -> cotune.VaultSecret
+	30, // 2: cotune.UnlockRequest.secret:type_name -> cotune.VaultSecret
+	30, // 3: cotune.EnableEncryptionRequest.secret:type_name -> cotune.VaultSecret
+	30, // 4: cotune.ChangeVaultSecretRequest.old_secret:type_name -> cotune.VaultSecret
+	30, // 5: cotune.ChangeVaultSecretRequest.new_secret:type_name -> cotune.VaultSecret
+	30, // 6: cotune.RotateVaultKeyRequest.secret:type_name -> cotune.VaultSecret
+	42, // 7: cotune.PeerInfoResponse.peer_info:type_name -> cotune.PeerInfo
+	42, // 8: cotune.KnownPeersResponse.peers:type_name -> cotune.PeerInfo
+	46, // 9: cotune.SearchResponse.results:type_name -> cotune.SearchResult
+	48, // 10: cotune.FindSimilarResponse.results:type_name -> cotune.SimilarTrack
+	52, // 11: cotune.TranscodeProfilesResponse.profiles:type_name -> cotune.TranscodeProfile
+	55, // 12: cotune.WaveformResponse.levels:type_name -> cotune.WaveformLevel
+	59, // 13: cotune.ListJobsResponse.jobs:type_name -> cotune.Job
+	65, // 14: cotune.Playlist.entries:type_name -> cotune.PlaylistEntry
+	66, // 15: cotune.PlaylistResponse.playlist:type_name -> cotune.Playlist
+	66, // 16: cotune.ListPlaylistsResponse.playlists:type_name -> cotune.Playlist
+	65, // 17: cotune.OpenPlaylistResponse.entries:type_name -> cotune.PlaylistEntry
+	66, // 18: cotune.OpenPlaylistResponse.saved:type_name -> cotune.Playlist
+	0,  // 19: cotune.CotuneService.Status:input_type -> cotune.StatusRequest
+	1,  // 20: cotune.CotuneService.PeerInfo:input_type -> cotune.PeerInfoRequest
+	0,  // 21: cotune.CotuneService.KnownPeers:input_type -> cotune.StatusRequest
+	2,  // 22: cotune.CotuneService.Connect:input_type -> cotune.ConnectRequest
+	3,  // 23: cotune.CotuneService.Search:input_type -> cotune.SearchRequest
+	5,  // 24: cotune.CotuneService.SearchProviders:input_type -> cotune.SearchProvidersRequest
+	4,  // 25: cotune.CotuneService.FindSimilar:input_type -> cotune.FindSimilarRequest
+	9,  // 26: cotune.CotuneService.GetArtwork:input_type -> cotune.ArtworkRequest
+	10, // 27: cotune.CotuneService.GetWaveform:input_type -> cotune.WaveformRequest
+	11, // 28: cotune.CotuneService.GetLoudness:input_type -> cotune.LoudnessRequest
+	6,  // 29: cotune.CotuneService.Fetch:input_type -> cotune.FetchRequest
+	7,  // 30: cotune.CotuneService.TranscodeProfiles:input_type -> cotune.TranscodeProfilesRequest
+	8,  // 31: cotune.CotuneService.Share:input_type -> cotune.ShareRequest
+	12, // 32: cotune.CotuneService.ListJobs:input_type -> cotune.ListJobsRequest
+	13, // 33: cotune.CotuneService.RetryJobs:input_type -> cotune.RetryJobsRequest
+	14, // 34: cotune.CotuneService.DeleteTrack:input_type -> cotune.DeleteTrackRequest
+	15, // 35: cotune.CotuneService.UpdateTrackMetadata:input_type -> cotune.UpdateTrackMetadataRequest
+	16, // 36: cotune.CotuneService.UnshareTrack:input_type -> cotune.UnshareTrackRequest
+	17, // 37: cotune.CotuneService.CreatePlaylist:input_type -> cotune.CreatePlaylistRequest
+	18, // 38: cotune.CotuneService.ListPlaylists:input_type -> cotune.ListPlaylistsRequest
+	19, // 39: cotune.CotuneService.UpdatePlaylist:input_type -> cotune.UpdatePlaylistRequest
+	20, // 40: cotune.CotuneService.DeletePlaylist:input_type -> cotune.DeletePlaylistRequest
+	21, // 41: cotune.CotuneService.SharePlaylist:input_type -> cotune.SharePlaylistRequest
+	22, // 42: cotune.CotuneService.UnsharePlaylist:input_type -> cotune.UnsharePlaylistRequest
+	23, // 43: cotune.CotuneService.OpenPlaylist:input_type -> cotune.OpenPlaylistRequest
+	24, // 44: cotune.CotuneService.Backup:input_type -> cotune.BackupRequest
+	25, // 45: cotune.CotuneService.ExportLibrary:input_type -> cotune.ExportLibraryRequest
+	26, // 46: cotune.CotuneService.ImportLibrary:input_type -> cotune.ImportLibraryRequest
+	27, // 47: cotune.CotuneService.WatchFolders:input_type -> cotune.WatchFoldersRequest
+	28, // 48: cotune.CotuneService.AddWatchFolder:input_type -> cotune.AddWatchFolderRequest
+	29, // 49: cotune.CotuneService.RemoveWatchFolder:input_type -> cotune.RemoveWatchFolderRequest
+	31, // 50: cotune.CotuneService.Unlock:input_type -> cotune.UnlockRequest
+	32, // 51: cotune.CotuneService.Lock:input_type -> cotune.LockRequest
+	33, // 52: cotune.CotuneService.VaultStatus:input_type -> cotune.VaultStatusRequest
+	34, // 53: cotune.CotuneService.EnableEncryption:input_type -> cotune.EnableEncryptionRequest
+	35, // 54: cotune.CotuneService.ChangeVaultSecret:input_type -> cotune.ChangeVaultSecretRequest
+	36, // 55: cotune.CotuneService.RotateVaultKey:input_type -> cotune.RotateVaultKeyRequest
+	37, // 56: cotune.CotuneService.Announce:input_type -> cotune.AnnounceRequest
+	38, // 57: cotune.CotuneService.Relays:input_type -> cotune.RelaysRequest
+	39, // 58: cotune.CotuneService.RelayEnable:input_type -> cotune.RelayEnableRequest
+	40, // 59: cotune.CotuneService.RelayRequest:input_type -> cotune.RelayRequestRequest
+	41, // 60: cotune.CotuneService.Status:output_type -> cotune.StatusResponse
+	43, // 61: cotune.CotuneService.PeerInfo:output_type -> cotune.PeerInfoResponse
+	44, // 62: cotune.CotuneService.KnownPeers:output_type -> cotune.KnownPeersResponse
+	45, // 63: cotune.CotuneService.Connect:output_type -> cotune.ConnectResponse
+	47, // 64: cotune.CotuneService.Search:output_type -> cotune.SearchResponse
+	50, // 65: cotune.CotuneService.SearchProviders:output_type -> cotune.SearchProvidersResponse
+	49, // 66: cotune.CotuneService.FindSimilar:output_type -> cotune.FindSimilarResponse
+	54, // 67: cotune.CotuneService.GetArtwork:output_type -> cotune.ArtworkResponse
+	56, // 68: cotune.CotuneService.GetWaveform:output_type -> cotune.WaveformResponse
+	57, // 69: cotune.CotuneService.GetLoudness:output_type -> cotune.LoudnessResponse
+	51, // 70: cotune.CotuneService.Fetch:output_type -> cotune.FetchResponse
+	53, // 71: cotune.CotuneService.TranscodeProfiles:output_type -> cotune.TranscodeProfilesResponse
+	58, // 72: cotune.CotuneService.Share:output_type -> cotune.ShareResponse
+	60, // 73: cotune.CotuneService.ListJobs:output_type -> cotune.ListJobsResponse
+	61, // 74: cotune.CotuneService.RetryJobs:output_type -> cotune.RetryJobsResponse
+	62, // 75: cotune.CotuneService.DeleteTrack:output_type -> cotune.DeleteTrackResponse
+	63, // 76: cotune.CotuneService.UpdateTrackMetadata:output_type -> cotune.UpdateTrackMetadataResponse
+	64, // 77: cotune.CotuneService.UnshareTrack:output_type -> cotune.UnshareTrackResponse
+	67, // 78: cotune.CotuneService.CreatePlaylist:output_type -> cotune.PlaylistResponse
+	68, // 79: cotune.CotuneService.ListPlaylists:output_type -> cotune.ListPlaylistsResponse
+	67, // 80: cotune.CotuneService.UpdatePlaylist:output_type -> cotune.PlaylistResponse
+	69, // 81: cotune.CotuneService.DeletePlaylist:output_type -> cotune.DeletePlaylistResponse
+	70, // 82: cotune.CotuneService.SharePlaylist:output_type -> cotune.SharePlaylistResponse
+	71, // 83: cotune.CotuneService.UnsharePlaylist:output_type -> cotune.UnsharePlaylistResponse
+	72, // 84: cotune.CotuneService.OpenPlaylist:output_type -> cotune.OpenPlaylistResponse
+	73, // 85: cotune.CotuneService.Backup:output_type -> cotune.BackupResponse
+	74, // 86: cotune.CotuneService.ExportLibrary:output_type -> cotune.ExportLibraryResponse
+	75, // 87: cotune.CotuneService.ImportLibrary:output_type -> cotune.ImportLibraryResponse
+	76, // 88: cotune.CotuneService.WatchFolders:output_type -> cotune.WatchFoldersResponse
+	76, // 89: cotune.CotuneService.AddWatchFolder:output_type -> cotune.WatchFoldersResponse
+	76, // 90: cotune.CotuneService.RemoveWatchFolder:output_type -> cotune.WatchFoldersResponse
+	77, // 91: cotune.CotuneService.Unlock:output_type -> cotune.UnlockResponse
+	78, // 92: cotune.CotuneService.Lock:output_type -> cotune.LockResponse
+	79, // 93: cotune.CotuneService.VaultStatus:output_type -> cotune.VaultStatusResponse
+	79, // 94: cotune.CotuneService.EnableEncryption:output_type -> cotune.VaultStatusResponse
+	79, // 95: cotune.CotuneService.ChangeVaultSecret:output_type -> cotune.VaultStatusResponse
+	79, // 96: cotune.CotuneService.RotateVaultKey:output_type -> cotune.VaultStatusResponse
+	80, // 97: cotune.CotuneService.Announce:output_type -> cotune.AnnounceResponse
+	81, // 98: cotune.CotuneService.Relays:output_type -> cotune.RelaysResponse
+	82, // 99: cotune.CotuneService.RelayEnable:output_type -> cotune.RelayEnableResponse
+	83, // 100: cotune.CotuneService.RelayRequest:output_type -> cotune.RelayRequestResponse
+	60, // [60:101] is the sub-list for method output_type
+	19, // [19:60] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_cotune_proto_init() }
@@ -1483,7 +5648,7 @@ func file_cotune_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cotune_proto_rawDesc), len(file_cotune_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   25,
+			NumMessages:   84,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,18 +19,47 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CotuneService_Status_FullMethodName          = "/cotune.CotuneService/Status"
-	CotuneService_PeerInfo_FullMethodName        = "/cotune.CotuneService/PeerInfo"
-	CotuneService_KnownPeers_FullMethodName      = "/cotune.CotuneService/KnownPeers"
-	CotuneService_Connect_FullMethodName         = "/cotune.CotuneService/Connect"
-	CotuneService_Search_FullMethodName          = "/cotune.CotuneService/Search"
-	CotuneService_SearchProviders_FullMethodName = "/cotune.CotuneService/SearchProviders"
-	CotuneService_Fetch_FullMethodName           = "/cotune.CotuneService/Fetch"
-	CotuneService_Share_FullMethodName           = "/cotune.CotuneService/Share"
-	CotuneService_Announce_FullMethodName        = "/cotune.CotuneService/Announce"
-	CotuneService_Relays_FullMethodName          = "/cotune.CotuneService/Relays"
-	CotuneService_RelayEnable_FullMethodName     = "/cotune.CotuneService/RelayEnable"
-	CotuneService_RelayRequest_FullMethodName    = "/cotune.CotuneService/RelayRequest"
+	CotuneService_Status_FullMethodName              = "/cotune.CotuneService/Status"
+	CotuneService_PeerInfo_FullMethodName            = "/cotune.CotuneService/PeerInfo"
+	CotuneService_KnownPeers_FullMethodName          = "/cotune.CotuneService/KnownPeers"
+	CotuneService_Connect_FullMethodName             = "/cotune.CotuneService/Connect"
+	CotuneService_Search_FullMethodName              = "/cotune.CotuneService/Search"
+	CotuneService_SearchProviders_FullMethodName     = "/cotune.CotuneService/SearchProviders"
+	CotuneService_FindSimilar_FullMethodName         = "/cotune.CotuneService/FindSimilar"
+	CotuneService_GetArtwork_FullMethodName          = "/cotune.CotuneService/GetArtwork"
+	CotuneService_GetWaveform_FullMethodName         = "/cotune.CotuneService/GetWaveform"
+	CotuneService_GetLoudness_FullMethodName         = "/cotune.CotuneService/GetLoudness"
+	CotuneService_Fetch_FullMethodName               = "/cotune.CotuneService/Fetch"
+	CotuneService_TranscodeProfiles_FullMethodName   = "/cotune.CotuneService/TranscodeProfiles"
+	CotuneService_Share_FullMethodName               = "/cotune.CotuneService/Share"
+	CotuneService_ListJobs_FullMethodName            = "/cotune.CotuneService/ListJobs"
+	CotuneService_RetryJobs_FullMethodName           = "/cotune.CotuneService/RetryJobs"
+	CotuneService_DeleteTrack_FullMethodName         = "/cotune.CotuneService/DeleteTrack"
+	CotuneService_UpdateTrackMetadata_FullMethodName = "/cotune.CotuneService/UpdateTrackMetadata"
+	CotuneService_UnshareTrack_FullMethodName        = "/cotune.CotuneService/UnshareTrack"
+	CotuneService_CreatePlaylist_FullMethodName      = "/cotune.CotuneService/CreatePlaylist"
+	CotuneService_ListPlaylists_FullMethodName       = "/cotune.CotuneService/ListPlaylists"
+	CotuneService_UpdatePlaylist_FullMethodName      = "/cotune.CotuneService/UpdatePlaylist"
+	CotuneService_DeletePlaylist_FullMethodName      = "/cotune.CotuneService/DeletePlaylist"
+	CotuneService_SharePlaylist_FullMethodName       = "/cotune.CotuneService/SharePlaylist"
+	CotuneService_UnsharePlaylist_FullMethodName     = "/cotune.CotuneService/UnsharePlaylist"
+	CotuneService_OpenPlaylist_FullMethodName        = "/cotune.CotuneService/OpenPlaylist"
+	CotuneService_Backup_FullMethodName              = "/cotune.CotuneService/Backup"
+	CotuneService_ExportLibrary_FullMethodName       = "/cotune.CotuneService/ExportLibrary"
+	CotuneService_ImportLibrary_FullMethodName       = "/cotune.CotuneService/ImportLibrary"
+	CotuneService_WatchFolders_FullMethodName        = "/cotune.CotuneService/WatchFolders"
+	CotuneService_AddWatchFolder_FullMethodName      = "/cotune.CotuneService/AddWatchFolder"
+	CotuneService_RemoveWatchFolder_FullMethodName   = "/cotune.CotuneService/RemoveWatchFolder"
+	CotuneService_Unlock_FullMethodName              = "/cotune.CotuneService/Unlock"
+	CotuneService_Lock_FullMethodName                = "/cotune.CotuneService/Lock"
+	CotuneService_VaultStatus_FullMethodName         = "/cotune.CotuneService/VaultStatus"
+	CotuneService_EnableEncryption_FullMethodName    = "/cotune.CotuneService/EnableEncryption"
+	CotuneService_ChangeVaultSecret_FullMethodName   = "/cotune.CotuneService/ChangeVaultSecret"
+	CotuneService_RotateVaultKey_FullMethodName      = "/cotune.CotuneService/RotateVaultKey"
+	CotuneService_Announce_FullMethodName            = "/cotune.CotuneService/Announce"
+	CotuneService_Relays_FullMethodName              = "/cotune.CotuneService/Relays"
+	CotuneService_RelayEnable_FullMethodName         = "/cotune.CotuneService/RelayEnable"
+	CotuneService_RelayRequest_FullMethodName        = "/cotune.CotuneService/RelayRequest"
 )
 
 // CotuneServiceClient is the client API for CotuneService service.
@@ -45,8 +74,37 @@ type CotuneServiceClient interface {
 	Connect(ctx context.Context, in *ConnectRequest, opts ...grpc.CallOption) (*ConnectResponse, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	SearchProviders(ctx context.Context, in *SearchProvidersRequest, opts ...grpc.CallOption) (*SearchProvidersResponse, error)
+	FindSimilar(ctx context.Context, in *FindSimilarRequest, opts ...grpc.CallOption) (*FindSimilarResponse, error)
+	GetArtwork(ctx context.Context, in *ArtworkRequest, opts ...grpc.CallOption) (*ArtworkResponse, error)
+	GetWaveform(ctx context.Context, in *WaveformRequest, opts ...grpc.CallOption) (*WaveformResponse, error)
+	GetLoudness(ctx context.Context, in *LoudnessRequest, opts ...grpc.CallOption) (*LoudnessResponse, error)
 	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
+	TranscodeProfiles(ctx context.Context, in *TranscodeProfilesRequest, opts ...grpc.CallOption) (*TranscodeProfilesResponse, error)
 	Share(ctx context.Context, in *ShareRequest, opts ...grpc.CallOption) (*ShareResponse, error)
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	RetryJobs(ctx context.Context, in *RetryJobsRequest, opts ...grpc.CallOption) (*RetryJobsResponse, error)
+	DeleteTrack(ctx context.Context, in *DeleteTrackRequest, opts ...grpc.CallOption) (*DeleteTrackResponse, error)
+	UpdateTrackMetadata(ctx context.Context, in *UpdateTrackMetadataRequest, opts ...grpc.CallOption) (*UpdateTrackMetadataResponse, error)
+	UnshareTrack(ctx context.Context, in *UnshareTrackRequest, opts ...grpc.CallOption) (*UnshareTrackResponse, error)
+	CreatePlaylist(ctx context.Context, in *CreatePlaylistRequest, opts ...grpc.CallOption) (*PlaylistResponse, error)
+	ListPlaylists(ctx context.Context, in *ListPlaylistsRequest, opts ...grpc.CallOption) (*ListPlaylistsResponse, error)
+	UpdatePlaylist(ctx context.Context, in *UpdatePlaylistRequest, opts ...grpc.CallOption) (*PlaylistResponse, error)
+	DeletePlaylist(ctx context.Context, in *DeletePlaylistRequest, opts ...grpc.CallOption) (*DeletePlaylistResponse, error)
+	SharePlaylist(ctx context.Context, in *SharePlaylistRequest, opts ...grpc.CallOption) (*SharePlaylistResponse, error)
+	UnsharePlaylist(ctx context.Context, in *UnsharePlaylistRequest, opts ...grpc.CallOption) (*UnsharePlaylistResponse, error)
+	OpenPlaylist(ctx context.Context, in *OpenPlaylistRequest, opts ...grpc.CallOption) (*OpenPlaylistResponse, error)
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	ExportLibrary(ctx context.Context, in *ExportLibraryRequest, opts ...grpc.CallOption) (*ExportLibraryResponse, error)
+	ImportLibrary(ctx context.Context, in *ImportLibraryRequest, opts ...grpc.CallOption) (*ImportLibraryResponse, error)
+	WatchFolders(ctx context.Context, in *WatchFoldersRequest, opts ...grpc.CallOption) (*WatchFoldersResponse, error)
+	AddWatchFolder(ctx context.Context, in *AddWatchFolderRequest, opts ...grpc.CallOption) (*WatchFoldersResponse, error)
+	RemoveWatchFolder(ctx context.Context, in *RemoveWatchFolderRequest, opts ...grpc.CallOption) (*WatchFoldersResponse, error)
+	Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error)
+	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	VaultStatus(ctx context.Context, in *VaultStatusRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error)
+	EnableEncryption(ctx context.Context, in *EnableEncryptionRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error)
+	ChangeVaultSecret(ctx context.Context, in *ChangeVaultSecretRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error)
+	RotateVaultKey(ctx context.Context, in *RotateVaultKeyRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error)
 	Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error)
 	Relays(ctx context.Context, in *RelaysRequest, opts ...grpc.CallOption) (*RelaysResponse, error)
 	RelayEnable(ctx context.Context, in *RelayEnableRequest, opts ...grpc.CallOption) (*RelayEnableResponse, error)
@@ -121,6 +179,46 @@ func (c *cotuneServiceClient) SearchProviders(ctx context.Context, in *SearchPro
 	return out, nil
 }
 
+func (c *cotuneServiceClient) FindSimilar(ctx context.Context, in *FindSimilarRequest, opts ...grpc.CallOption) (*FindSimilarResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindSimilarResponse)
+	err := c.cc.Invoke(ctx, CotuneService_FindSimilar_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) GetArtwork(ctx context.Context, in *ArtworkRequest, opts ...grpc.CallOption) (*ArtworkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArtworkResponse)
+	err := c.cc.Invoke(ctx, CotuneService_GetArtwork_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) GetWaveform(ctx context.Context, in *WaveformRequest, opts ...grpc.CallOption) (*WaveformResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaveformResponse)
+	err := c.cc.Invoke(ctx, CotuneService_GetWaveform_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) GetLoudness(ctx context.Context, in *LoudnessRequest, opts ...grpc.CallOption) (*LoudnessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoudnessResponse)
+	err := c.cc.Invoke(ctx, CotuneService_GetLoudness_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cotuneServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(FetchResponse)
@@ -131,6 +229,16 @@ func (c *cotuneServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts
 	return out, nil
 }
 
+func (c *cotuneServiceClient) TranscodeProfiles(ctx context.Context, in *TranscodeProfilesRequest, opts ...grpc.CallOption) (*TranscodeProfilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TranscodeProfilesResponse)
+	err := c.cc.Invoke(ctx, CotuneService_TranscodeProfiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cotuneServiceClient) Share(ctx context.Context, in *ShareRequest, opts ...grpc.CallOption) (*ShareResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ShareResponse)
@@ -141,6 +249,246 @@ func (c *cotuneServiceClient) Share(ctx context.Context, in *ShareRequest, opts
 	return out, nil
 }
 
+func (c *cotuneServiceClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, CotuneService_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) RetryJobs(ctx context.Context, in *RetryJobsRequest, opts ...grpc.CallOption) (*RetryJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RetryJobsResponse)
+	err := c.cc.Invoke(ctx, CotuneService_RetryJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) DeleteTrack(ctx context.Context, in *DeleteTrackRequest, opts ...grpc.CallOption) (*DeleteTrackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTrackResponse)
+	err := c.cc.Invoke(ctx, CotuneService_DeleteTrack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) UpdateTrackMetadata(ctx context.Context, in *UpdateTrackMetadataRequest, opts ...grpc.CallOption) (*UpdateTrackMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateTrackMetadataResponse)
+	err := c.cc.Invoke(ctx, CotuneService_UpdateTrackMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) UnshareTrack(ctx context.Context, in *UnshareTrackRequest, opts ...grpc.CallOption) (*UnshareTrackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnshareTrackResponse)
+	err := c.cc.Invoke(ctx, CotuneService_UnshareTrack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) CreatePlaylist(ctx context.Context, in *CreatePlaylistRequest, opts ...grpc.CallOption) (*PlaylistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaylistResponse)
+	err := c.cc.Invoke(ctx, CotuneService_CreatePlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) ListPlaylists(ctx context.Context, in *ListPlaylistsRequest, opts ...grpc.CallOption) (*ListPlaylistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlaylistsResponse)
+	err := c.cc.Invoke(ctx, CotuneService_ListPlaylists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) UpdatePlaylist(ctx context.Context, in *UpdatePlaylistRequest, opts ...grpc.CallOption) (*PlaylistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaylistResponse)
+	err := c.cc.Invoke(ctx, CotuneService_UpdatePlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) DeletePlaylist(ctx context.Context, in *DeletePlaylistRequest, opts ...grpc.CallOption) (*DeletePlaylistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePlaylistResponse)
+	err := c.cc.Invoke(ctx, CotuneService_DeletePlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) SharePlaylist(ctx context.Context, in *SharePlaylistRequest, opts ...grpc.CallOption) (*SharePlaylistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SharePlaylistResponse)
+	err := c.cc.Invoke(ctx, CotuneService_SharePlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) UnsharePlaylist(ctx context.Context, in *UnsharePlaylistRequest, opts ...grpc.CallOption) (*UnsharePlaylistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsharePlaylistResponse)
+	err := c.cc.Invoke(ctx, CotuneService_UnsharePlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) OpenPlaylist(ctx context.Context, in *OpenPlaylistRequest, opts ...grpc.CallOption) (*OpenPlaylistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OpenPlaylistResponse)
+	err := c.cc.Invoke(ctx, CotuneService_OpenPlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BackupResponse)
+	err := c.cc.Invoke(ctx, CotuneService_Backup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) ExportLibrary(ctx context.Context, in *ExportLibraryRequest, opts ...grpc.CallOption) (*ExportLibraryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportLibraryResponse)
+	err := c.cc.Invoke(ctx, CotuneService_ExportLibrary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) ImportLibrary(ctx context.Context, in *ImportLibraryRequest, opts ...grpc.CallOption) (*ImportLibraryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportLibraryResponse)
+	err := c.cc.Invoke(ctx, CotuneService_ImportLibrary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) WatchFolders(ctx context.Context, in *WatchFoldersRequest, opts ...grpc.CallOption) (*WatchFoldersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WatchFoldersResponse)
+	err := c.cc.Invoke(ctx, CotuneService_WatchFolders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) AddWatchFolder(ctx context.Context, in *AddWatchFolderRequest, opts ...grpc.CallOption) (*WatchFoldersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WatchFoldersResponse)
+	err := c.cc.Invoke(ctx, CotuneService_AddWatchFolder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) RemoveWatchFolder(ctx context.Context, in *RemoveWatchFolderRequest, opts ...grpc.CallOption) (*WatchFoldersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WatchFoldersResponse)
+	err := c.cc.Invoke(ctx, CotuneService_RemoveWatchFolder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnlockResponse)
+	err := c.cc.Invoke(ctx, CotuneService_Unlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockResponse)
+	err := c.cc.Invoke(ctx, CotuneService_Lock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) VaultStatus(ctx context.Context, in *VaultStatusRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VaultStatusResponse)
+	err := c.cc.Invoke(ctx, CotuneService_VaultStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) EnableEncryption(ctx context.Context, in *EnableEncryptionRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VaultStatusResponse)
+	err := c.cc.Invoke(ctx, CotuneService_EnableEncryption_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) ChangeVaultSecret(ctx context.Context, in *ChangeVaultSecretRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VaultStatusResponse)
+	err := c.cc.Invoke(ctx, CotuneService_ChangeVaultSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cotuneServiceClient) RotateVaultKey(ctx context.Context, in *RotateVaultKeyRequest, opts ...grpc.CallOption) (*VaultStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VaultStatusResponse)
+	err := c.cc.Invoke(ctx, CotuneService_RotateVaultKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cotuneServiceClient) Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AnnounceResponse)
@@ -193,8 +541,37 @@ type CotuneServiceServer interface {
 	Connect(context.Context, *ConnectRequest) (*ConnectResponse, error)
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	SearchProviders(context.Context, *SearchProvidersRequest) (*SearchProvidersResponse, error)
+	FindSimilar(context.Context, *FindSimilarRequest) (*FindSimilarResponse, error)
+	GetArtwork(context.Context, *ArtworkRequest) (*ArtworkResponse, error)
+	GetWaveform(context.Context, *WaveformRequest) (*WaveformResponse, error)
+	GetLoudness(context.Context, *LoudnessRequest) (*LoudnessResponse, error)
 	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
+	TranscodeProfiles(context.Context, *TranscodeProfilesRequest) (*TranscodeProfilesResponse, error)
 	Share(context.Context, *ShareRequest) (*ShareResponse, error)
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	RetryJobs(context.Context, *RetryJobsRequest) (*RetryJobsResponse, error)
+	DeleteTrack(context.Context, *DeleteTrackRequest) (*DeleteTrackResponse, error)
+	UpdateTrackMetadata(context.Context, *UpdateTrackMetadataRequest) (*UpdateTrackMetadataResponse, error)
+	UnshareTrack(context.Context, *UnshareTrackRequest) (*UnshareTrackResponse, error)
+	CreatePlaylist(context.Context, *CreatePlaylistRequest) (*PlaylistResponse, error)
+	ListPlaylists(context.Context, *ListPlaylistsRequest) (*ListPlaylistsResponse, error)
+	UpdatePlaylist(context.Context, *UpdatePlaylistRequest) (*PlaylistResponse, error)
+	DeletePlaylist(context.Context, *DeletePlaylistRequest) (*DeletePlaylistResponse, error)
+	SharePlaylist(context.Context, *SharePlaylistRequest) (*SharePlaylistResponse, error)
+	UnsharePlaylist(context.Context, *UnsharePlaylistRequest) (*UnsharePlaylistResponse, error)
+	OpenPlaylist(context.Context, *OpenPlaylistRequest) (*OpenPlaylistResponse, error)
+	Backup(context.Context, *BackupRequest) (*BackupResponse, error)
+	ExportLibrary(context.Context, *ExportLibraryRequest) (*ExportLibraryResponse, error)
+	ImportLibrary(context.Context, *ImportLibraryRequest) (*ImportLibraryResponse, error)
+	WatchFolders(context.Context, *WatchFoldersRequest) (*WatchFoldersResponse, error)
+	AddWatchFolder(context.Context, *AddWatchFolderRequest) (*WatchFoldersResponse, error)
+	RemoveWatchFolder(context.Context, *RemoveWatchFolderRequest) (*WatchFoldersResponse, error)
+	Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error)
+	Lock(context.Context, *LockRequest) (*LockResponse, error)
+	VaultStatus(context.Context, *VaultStatusRequest) (*VaultStatusResponse, error)
+	EnableEncryption(context.Context, *EnableEncryptionRequest) (*VaultStatusResponse, error)
+	ChangeVaultSecret(context.Context, *ChangeVaultSecretRequest) (*VaultStatusResponse, error)
+	RotateVaultKey(context.Context, *RotateVaultKeyRequest) (*VaultStatusResponse, error)
 	Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error)
 	Relays(context.Context, *RelaysRequest) (*RelaysResponse, error)
 	RelayEnable(context.Context, *RelayEnableRequest) (*RelayEnableResponse, error)
@@ -227,12 +604,99 @@ func (UnimplementedCotuneServiceServer) Search(context.Context, *SearchRequest)
 func (UnimplementedCotuneServiceServer) SearchProviders(context.Context, *SearchProvidersRequest) (*SearchProvidersResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SearchProviders not implemented")
 }
+func (UnimplementedCotuneServiceServer) FindSimilar(context.Context, *FindSimilarRequest) (*FindSimilarResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FindSimilar not implemented")
+}
+func (UnimplementedCotuneServiceServer) GetArtwork(context.Context, *ArtworkRequest) (*ArtworkResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetArtwork not implemented")
+}
+func (UnimplementedCotuneServiceServer) GetWaveform(context.Context, *WaveformRequest) (*WaveformResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetWaveform not implemented")
+}
+func (UnimplementedCotuneServiceServer) GetLoudness(context.Context, *LoudnessRequest) (*LoudnessResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLoudness not implemented")
+}
 func (UnimplementedCotuneServiceServer) Fetch(context.Context, *FetchRequest) (*FetchResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Fetch not implemented")
 }
+func (UnimplementedCotuneServiceServer) TranscodeProfiles(context.Context, *TranscodeProfilesRequest) (*TranscodeProfilesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TranscodeProfiles not implemented")
+}
 func (UnimplementedCotuneServiceServer) Share(context.Context, *ShareRequest) (*ShareResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Share not implemented")
 }
+func (UnimplementedCotuneServiceServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedCotuneServiceServer) RetryJobs(context.Context, *RetryJobsRequest) (*RetryJobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RetryJobs not implemented")
+}
+func (UnimplementedCotuneServiceServer) DeleteTrack(context.Context, *DeleteTrackRequest) (*DeleteTrackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTrack not implemented")
+}
+func (UnimplementedCotuneServiceServer) UpdateTrackMetadata(context.Context, *UpdateTrackMetadataRequest) (*UpdateTrackMetadataResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateTrackMetadata not implemented")
+}
+func (UnimplementedCotuneServiceServer) UnshareTrack(context.Context, *UnshareTrackRequest) (*UnshareTrackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnshareTrack not implemented")
+}
+func (UnimplementedCotuneServiceServer) CreatePlaylist(context.Context, *CreatePlaylistRequest) (*PlaylistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreatePlaylist not implemented")
+}
+func (UnimplementedCotuneServiceServer) ListPlaylists(context.Context, *ListPlaylistsRequest) (*ListPlaylistsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPlaylists not implemented")
+}
+func (UnimplementedCotuneServiceServer) UpdatePlaylist(context.Context, *UpdatePlaylistRequest) (*PlaylistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdatePlaylist not implemented")
+}
+func (UnimplementedCotuneServiceServer) DeletePlaylist(context.Context, *DeletePlaylistRequest) (*DeletePlaylistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeletePlaylist not implemented")
+}
+func (UnimplementedCotuneServiceServer) SharePlaylist(context.Context, *SharePlaylistRequest) (*SharePlaylistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SharePlaylist not implemented")
+}
+func (UnimplementedCotuneServiceServer) UnsharePlaylist(context.Context, *UnsharePlaylistRequest) (*UnsharePlaylistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnsharePlaylist not implemented")
+}
+func (UnimplementedCotuneServiceServer) OpenPlaylist(context.Context, *OpenPlaylistRequest) (*OpenPlaylistResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method OpenPlaylist not implemented")
+}
+func (UnimplementedCotuneServiceServer) Backup(context.Context, *BackupRequest) (*BackupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedCotuneServiceServer) ExportLibrary(context.Context, *ExportLibraryRequest) (*ExportLibraryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExportLibrary not implemented")
+}
+func (UnimplementedCotuneServiceServer) ImportLibrary(context.Context, *ImportLibraryRequest) (*ImportLibraryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ImportLibrary not implemented")
+}
+func (UnimplementedCotuneServiceServer) WatchFolders(context.Context, *WatchFoldersRequest) (*WatchFoldersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WatchFolders not implemented")
+}
+func (UnimplementedCotuneServiceServer) AddWatchFolder(context.Context, *AddWatchFolderRequest) (*WatchFoldersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddWatchFolder not implemented")
+}
+func (UnimplementedCotuneServiceServer) RemoveWatchFolder(context.Context, *RemoveWatchFolderRequest) (*WatchFoldersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveWatchFolder not implemented")
+}
+func (UnimplementedCotuneServiceServer) Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Unlock not implemented")
+}
+func (UnimplementedCotuneServiceServer) Lock(context.Context, *LockRequest) (*LockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Lock not implemented")
+}
+func (UnimplementedCotuneServiceServer) VaultStatus(context.Context, *VaultStatusRequest) (*VaultStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method VaultStatus not implemented")
+}
+func (UnimplementedCotuneServiceServer) EnableEncryption(context.Context, *EnableEncryptionRequest) (*VaultStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EnableEncryption not implemented")
+}
+func (UnimplementedCotuneServiceServer) ChangeVaultSecret(context.Context, *ChangeVaultSecretRequest) (*VaultStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ChangeVaultSecret not implemented")
+}
+func (UnimplementedCotuneServiceServer) RotateVaultKey(context.Context, *RotateVaultKeyRequest) (*VaultStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RotateVaultKey not implemented")
+}
 func (UnimplementedCotuneServiceServer) Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Announce not implemented")
 }
@@ -374,80 +838,602 @@ func _CotuneService_SearchProviders_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CotuneService_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FetchRequest)
+func _CotuneService_FindSimilar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSimilarRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CotuneServiceServer).Fetch(ctx, in)
+		return srv.(CotuneServiceServer).FindSimilar(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CotuneService_Fetch_FullMethodName,
+		FullMethod: CotuneService_FindSimilar_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CotuneServiceServer).Fetch(ctx, req.(*FetchRequest))
+		return srv.(CotuneServiceServer).FindSimilar(ctx, req.(*FindSimilarRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CotuneService_Share_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ShareRequest)
+func _CotuneService_GetArtwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArtworkRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CotuneServiceServer).Share(ctx, in)
+		return srv.(CotuneServiceServer).GetArtwork(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CotuneService_Share_FullMethodName,
+		FullMethod: CotuneService_GetArtwork_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CotuneServiceServer).Share(ctx, req.(*ShareRequest))
+		return srv.(CotuneServiceServer).GetArtwork(ctx, req.(*ArtworkRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CotuneService_Announce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AnnounceRequest)
+func _CotuneService_GetWaveform_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaveformRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CotuneServiceServer).Announce(ctx, in)
+		return srv.(CotuneServiceServer).GetWaveform(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CotuneService_Announce_FullMethodName,
+		FullMethod: CotuneService_GetWaveform_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CotuneServiceServer).Announce(ctx, req.(*AnnounceRequest))
+		return srv.(CotuneServiceServer).GetWaveform(ctx, req.(*WaveformRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CotuneService_Relays_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RelaysRequest)
+func _CotuneService_GetLoudness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoudnessRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(CotuneServiceServer).Relays(ctx, in)
+		return srv.(CotuneServiceServer).GetLoudness(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: CotuneService_Relays_FullMethodName,
+		FullMethod: CotuneService_GetLoudness_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CotuneServiceServer).Relays(ctx, req.(*RelaysRequest))
+		return srv.(CotuneServiceServer).GetLoudness(ctx, req.(*LoudnessRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _CotuneService_RelayEnable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RelayEnableRequest)
+func _CotuneService_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Fetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Fetch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Fetch(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_TranscodeProfiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranscodeProfilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).TranscodeProfiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_TranscodeProfiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).TranscodeProfiles(ctx, req.(*TranscodeProfilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_Share_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Share(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Share_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Share(ctx, req.(*ShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_RetryJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).RetryJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_RetryJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).RetryJobs(ctx, req.(*RetryJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_DeleteTrack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).DeleteTrack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_DeleteTrack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).DeleteTrack(ctx, req.(*DeleteTrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_UpdateTrackMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTrackMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).UpdateTrackMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_UpdateTrackMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).UpdateTrackMetadata(ctx, req.(*UpdateTrackMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_UnshareTrack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnshareTrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).UnshareTrack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_UnshareTrack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).UnshareTrack(ctx, req.(*UnshareTrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_CreatePlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).CreatePlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_CreatePlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).CreatePlaylist(ctx, req.(*CreatePlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_ListPlaylists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlaylistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).ListPlaylists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_ListPlaylists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).ListPlaylists(ctx, req.(*ListPlaylistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_UpdatePlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).UpdatePlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_UpdatePlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).UpdatePlaylist(ctx, req.(*UpdatePlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_DeletePlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).DeletePlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_DeletePlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).DeletePlaylist(ctx, req.(*DeletePlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_SharePlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SharePlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).SharePlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_SharePlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).SharePlaylist(ctx, req.(*SharePlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_UnsharePlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsharePlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).UnsharePlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_UnsharePlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).UnsharePlaylist(ctx, req.(*UnsharePlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_OpenPlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenPlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).OpenPlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_OpenPlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).OpenPlaylist(ctx, req.(*OpenPlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Backup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Backup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Backup(ctx, req.(*BackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_ExportLibrary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportLibraryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).ExportLibrary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_ExportLibrary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).ExportLibrary(ctx, req.(*ExportLibraryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_ImportLibrary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportLibraryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).ImportLibrary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_ImportLibrary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).ImportLibrary(ctx, req.(*ImportLibraryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_WatchFolders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WatchFoldersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).WatchFolders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_WatchFolders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).WatchFolders(ctx, req.(*WatchFoldersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_AddWatchFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddWatchFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).AddWatchFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_AddWatchFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).AddWatchFolder(ctx, req.(*AddWatchFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_RemoveWatchFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveWatchFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).RemoveWatchFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_RemoveWatchFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).RemoveWatchFolder(ctx, req.(*RemoveWatchFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_Unlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Unlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Unlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Unlock(ctx, req.(*UnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Lock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Lock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Lock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_VaultStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).VaultStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_VaultStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).VaultStatus(ctx, req.(*VaultStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_EnableEncryption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableEncryptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).EnableEncryption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_EnableEncryption_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).EnableEncryption(ctx, req.(*EnableEncryptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_ChangeVaultSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeVaultSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).ChangeVaultSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_ChangeVaultSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).ChangeVaultSecret(ctx, req.(*ChangeVaultSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_RotateVaultKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateVaultKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).RotateVaultKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_RotateVaultKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).RotateVaultKey(ctx, req.(*RotateVaultKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_Announce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnounceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Announce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Announce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Announce(ctx, req.(*AnnounceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_Relays_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelaysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CotuneServiceServer).Relays(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CotuneService_Relays_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CotuneServiceServer).Relays(ctx, req.(*RelaysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CotuneService_RelayEnable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelayEnableRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -513,14 +1499,130 @@ var CotuneService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SearchProviders",
 			Handler:    _CotuneService_SearchProviders_Handler,
 		},
+		{
+			MethodName: "FindSimilar",
+			Handler:    _CotuneService_FindSimilar_Handler,
+		},
+		{
+			MethodName: "GetArtwork",
+			Handler:    _CotuneService_GetArtwork_Handler,
+		},
+		{
+			MethodName: "GetWaveform",
+			Handler:    _CotuneService_GetWaveform_Handler,
+		},
+		{
+			MethodName: "GetLoudness",
+			Handler:    _CotuneService_GetLoudness_Handler,
+		},
 		{
 			MethodName: "Fetch",
 			Handler:    _CotuneService_Fetch_Handler,
 		},
+		{
+			MethodName: "TranscodeProfiles",
+			Handler:    _CotuneService_TranscodeProfiles_Handler,
+		},
 		{
 			MethodName: "Share",
 			Handler:    _CotuneService_Share_Handler,
 		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _CotuneService_ListJobs_Handler,
+		},
+		{
+			MethodName: "RetryJobs",
+			Handler:    _CotuneService_RetryJobs_Handler,
+		},
+		{
+			MethodName: "DeleteTrack",
+			Handler:    _CotuneService_DeleteTrack_Handler,
+		},
+		{
+			MethodName: "UpdateTrackMetadata",
+			Handler:    _CotuneService_UpdateTrackMetadata_Handler,
+		},
+		{
+			MethodName: "UnshareTrack",
+			Handler:    _CotuneService_UnshareTrack_Handler,
+		},
+		{
+			MethodName: "CreatePlaylist",
+			Handler:    _CotuneService_CreatePlaylist_Handler,
+		},
+		{
+			MethodName: "ListPlaylists",
+			Handler:    _CotuneService_ListPlaylists_Handler,
+		},
+		{
+			MethodName: "UpdatePlaylist",
+			Handler:    _CotuneService_UpdatePlaylist_Handler,
+		},
+		{
+			MethodName: "DeletePlaylist",
+			Handler:    _CotuneService_DeletePlaylist_Handler,
+		},
+		{
+			MethodName: "SharePlaylist",
+			Handler:    _CotuneService_SharePlaylist_Handler,
+		},
+		{
+			MethodName: "UnsharePlaylist",
+			Handler:    _CotuneService_UnsharePlaylist_Handler,
+		},
+		{
+			MethodName: "OpenPlaylist",
+			Handler:    _CotuneService_OpenPlaylist_Handler,
+		},
+		{
+			MethodName: "Backup",
+			Handler:    _CotuneService_Backup_Handler,
+		},
+		{
+			MethodName: "ExportLibrary",
+			Handler:    _CotuneService_ExportLibrary_Handler,
+		},
+		{
+			MethodName: "ImportLibrary",
+			Handler:    _CotuneService_ImportLibrary_Handler,
+		},
+		{
+			MethodName: "WatchFolders",
+			Handler:    _CotuneService_WatchFolders_Handler,
+		},
+		{
+			MethodName: "AddWatchFolder",
+			Handler:    _CotuneService_AddWatchFolder_Handler,
+		},
+		{
+			MethodName: "RemoveWatchFolder",
+			Handler:    _CotuneService_RemoveWatchFolder_Handler,
+		},
+		{
+			MethodName: "Unlock",
+			Handler:    _CotuneService_Unlock_Handler,
+		},
+		{
+			MethodName: "Lock",
+			Handler:    _CotuneService_Lock_Handler,
+		},
+		{
+			MethodName: "VaultStatus",
+			Handler:    _CotuneService_VaultStatus_Handler,
+		},
+		{
+			MethodName: "EnableEncryption",
+			Handler:    _CotuneService_EnableEncryption_Handler,
+		},
+		{
+			MethodName: "ChangeVaultSecret",
+			Handler:    _CotuneService_ChangeVaultSecret_Handler,
+		},
+		{
+			MethodName: "RotateVaultKey",
+			Handler:    _CotuneService_RotateVaultKey_Handler,
+		},
 		{
 			MethodName: "Announce",
 			Handler:    _CotuneService_Announce_Handler,
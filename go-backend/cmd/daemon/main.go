@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -13,13 +18,21 @@ import (
 
 	controlapi "github.com/cotune/go-backend/internal/api/control"
 	protoapi "github.com/cotune/go-backend/internal/api/proto"
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/backup"
 	"github.com/cotune/go-backend/internal/ctr"
 	"github.com/cotune/go-backend/internal/daemon"
 	"github.com/cotune/go-backend/internal/dht"
+	"github.com/cotune/go-backend/internal/fsck"
 	"github.com/cotune/go-backend/internal/host"
+	"github.com/cotune/go-backend/internal/media"
+	"github.com/cotune/go-backend/internal/quota"
 	"github.com/cotune/go-backend/internal/search"
 	"github.com/cotune/go-backend/internal/storage"
 	"github.com/cotune/go-backend/internal/streaming"
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/cotune/go-backend/internal/watch"
 )
 
 var (
@@ -30,16 +43,35 @@ var (
 	listenAddr  = flag.String("listen", "/ip4/0.0.0.0/tcp/0", "libp2p listen address")
 	dataDir     = flag.String("data", "", "Data directory")
 	enableRelay = flag.Bool("relay", false, "Enable relay service")
-	bootstrap   bootstrapAddrs
+	ctrWorkers  = flag.Int("ctr-workers", ctr.DefaultWorkers, "Number of tracks processed concurrently for CTID")
+	trustTags   = flag.Bool("trust-tags", false, "Treat title/artist read from file tags as recognized")
+	rebuildIdx  = flag.Bool("rebuild-indexes", false, "Rebuild the storage indexes from the stored tracks and exit")
+	budget      = flag.String("storage-budget", "", "Media store size before cached replicas are evicted, e.g. 20GB (0 is unlimited); overrides and replaces the saved budget")
+	migrateDry  = flag.Bool("migrate-dry-run", false, "Report the pending datastore migrations without applying them and exit")
+	backupPath  = flag.String("backup", "", "Write a backup archive of the node to this path and exit")
+	backupMedia = flag.Bool("backup-media", false, "Include the audio files in the -backup archive")
+	backupPlain = flag.Bool("backup-plaintext", false, "Write the -backup archive unsealed, even of an encrypted node")
+	exportDir   = flag.String("export-dir", "", "Directory API clients write backups and library exports to and import library files from (default <data>/exports)")
+	restorePath = flag.String("restore", "", "Restore the node from a backup archive into the data directory and exit; a sealed archive needs -passphrase-file or -vault-key-file")
+	memoryStore = flag.Bool("memory-store", false, "Keep the library in memory instead of the data directory, losing it on exit (test and ephemeral nodes)")
+	fsckRun     = flag.Bool("fsck", false, "Verify the file of every track, mark broken tracks, report them and exit (status 1 if any is broken)")
+	fsckDeep    = flag.Bool("fsck-deep", false, "With -fsck, also read every file to check its content hash or CTID")
+	ffmpegPath  = flag.String("ffmpeg", audio.DefaultFFmpegPath, "ffmpeg binary used to decode formats without a built-in decoder (path or name in PATH)")
+	passFile    = flag.String("passphrase-file", "", "File holding the passphrase of an encrypted node, which also seals -backup archives; without it or -vault-key-file an encrypted node starts locked")
+	keyFile     = flag.String("vault-key-file", "", "File holding the 32-byte keystore key (hex) of an encrypted node")
+	encrypt     = flag.Bool("encrypt", false, "Encrypt the identity key and library with the -passphrase-file or -vault-key-file secret, if not already")
+	bootstrap   stringList
+	watchDirs   stringList
 )
 
-type bootstrapAddrs []string
+// stringList collects a flag given repeatedly or as a comma-separated list
+type stringList []string
 
-func (b *bootstrapAddrs) String() string {
+func (b *stringList) String() string {
 	return strings.Join(*b, ",")
 }
 
-func (b *bootstrapAddrs) Set(value string) error {
+func (b *stringList) Set(value string) error {
 	for _, part := range strings.Split(value, ",") {
 		addr := strings.TrimSpace(part)
 		if addr != "" {
@@ -51,6 +83,7 @@ func (b *bootstrapAddrs) Set(value string) error {
 
 func main() {
 	flag.Var(&bootstrap, "bootstrap", "Bootstrap peer multiaddr (repeatable or comma-separated)")
+	flag.Var(&watchDirs, "watch", "Music folder to import from and keep watching, added to the saved ones (repeatable or comma-separated)")
 	flag.Parse()
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	if *mode != "android" && *mode != "server" {
@@ -65,7 +98,11 @@ func main() {
 		"listen", *listenAddr,
 		"data", *dataDir,
 		"relay", *enableRelay,
+		"ctr_workers", *ctrWorkers,
+		"trust_tags", *trustTags,
+		"storage_budget", *budget,
 		"bootstrap", bootstrap.String(),
+		"watch", watchDirs.String(),
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -81,6 +118,15 @@ func main() {
 		}
 	}
 
+	var budgetBytes int64
+	if *budget != "" {
+		var err error
+		if budgetBytes, err = quota.ParseSize(*budget); err != nil {
+			logger.Error("invalid-storage-budget", "error", err)
+			os.Exit(2)
+		}
+	}
+
 	logger.Info("using-data-directory", "path", *dataDir)
 	if err := os.MkdirAll(*dataDir, 0755); err != nil {
 		logger.Error("failed-create-data-directory", "error", err)
@@ -88,19 +134,202 @@ func main() {
 	}
 	logger.Info("data-directory-ready")
 
+	if *exportDir == "" {
+		*exportDir = filepath.Join(*dataDir, "exports")
+	}
+	if err := os.MkdirAll(*exportDir, 0755); err != nil {
+		logger.Error("failed-create-export-directory", "error", err)
+		os.Exit(1)
+	}
+
+	if *restorePath != "" {
+		report, err := restoreBackup(*restorePath, *dataDir)
+		if err != nil {
+			logger.Error("failed-restore", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("node-restored",
+			"peer_id", report.PeerID,
+			"backup_created_at", report.CreatedAt,
+			"tracks", report.Tracks,
+			"playlists", report.Playlists,
+			"media_files", report.MediaFiles,
+			"artwork", report.Artwork,
+			"missing", report.Missing,
+			"reprocess", report.Reprocess,
+		)
+		return
+	}
+
+	// An encrypted node needs its secret before the library is opened
+	v, keyring, err := openVault(*dataDir, logger)
+	if err != nil {
+		logger.Error("failed-open-vault", "error", err)
+		os.Exit(1)
+	}
+	if v != nil && keyring == nil && (*migrateDry || *rebuildIdx || *fsckRun || *backupPath != "") {
+		logger.Error("node-locked", "error", "encrypted node needs -passphrase-file or -vault-key-file")
+		os.Exit(2)
+	}
+
+	if *migrateDry {
+		report, err := storage.DryRunMigrations(*dataDir, keyring)
+		if err != nil {
+			logger.Error("failed-migration-dry-run", "error", err)
+			os.Exit(1)
+		}
+		for _, m := range report.Migrations {
+			logger.Info("pending-migration", "version", m.Version, "description", m.Description, "changes", m.Changed)
+		}
+		logger.Info("migration-dry-run", "from_version", report.FromVersion, "to_version", report.ToVersion)
+		return
+	}
+
+	// Backward compatibility: if http is set, use it as proto address
+	if *httpAddr != "" {
+		*protoAddr = *httpAddr
+		logger.Warn("deprecated-http-flag", "http", *httpAddr)
+	}
+	// In Android mode expose control API only on localhost for in-app diagnostics.
+	if *mode == "android" && *controlAddr == "0.0.0.0:8080" {
+		*controlAddr = "127.0.0.1:8080"
+	}
+
+	for {
+		if v != nil && keyring == nil {
+			if keyring = waitForUnlock(v, logger); keyring == nil {
+				return
+			}
+		}
+		if !run(ctx, logger, v, keyring, budgetBytes) {
+			return
+		}
+		// Locked: forget the keyring until the secret is given again. The
+		// node may have been encrypted while it ran.
+		keyring = nil
+		if v, err = vault.Load(*dataDir); err != nil {
+			logger.Error("failed-open-vault", "error", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// run starts the node and serves it until it is stopped. It reports
+// whether the node was locked rather than shut down; one-shot flags such as
+// -backup run here and stop it.
+func run(ctx context.Context, logger *slog.Logger, v *vault.Vault, keyring *vault.Keyring, budgetBytes int64) bool {
 	// Initialize storage
 	logger.Info("initializing-storage")
-	store, err := storage.New(*dataDir)
+	var store storage.Store
+	if *memoryStore {
+		store = storage.NewMemory()
+		logger.Info("storage-initialized", "backend", "memory")
+	} else {
+		diskStore, err := storage.Open(*dataDir, keyring)
+		if err != nil {
+			logger.Error("failed-initialize-storage", "error", err)
+			os.Exit(1)
+		}
+		defer diskStore.Close()
+		if report := diskStore.Migrations(); len(report.Migrations) > 0 {
+			for _, m := range report.Migrations {
+				logger.Info("migration-applied", "version", m.Version, "description", m.Description, "changes", m.Changed)
+			}
+			logger.Info("datastore-migrated", "from_version", report.FromVersion, "to_version", report.ToVersion, "backup", report.Backup)
+		}
+		logger.Info("storage-initialized", "schema_version", storage.SchemaVersion(), "encrypted", keyring != nil)
+
+		if *rebuildIdx {
+			n, err := diskStore.RebuildIndexes()
+			if err != nil {
+				logger.Error("failed-rebuild-indexes", "error", err)
+				os.Exit(1)
+			}
+			logger.Info("indexes-rebuilt", "tracks", n)
+			return false
+		}
+		store = diskStore
+	}
+
+	if *fsckRun {
+		// Deep checks only decode audio; CTR is not started
+		audio.SetFFmpegPath(*ffmpegPath)
+		verifier := fsck.New(store, ctr.New(store, nil))
+		report, err := verifier.Run(ctx, fsck.Options{Deep: *fsckDeep})
+		if err != nil {
+			logger.Error("failed-fsck", "error", err)
+			os.Exit(1)
+		}
+		for _, issue := range report.Issues {
+			logger.Warn("track-broken", "track_id", issue.TrackID, "path", issue.Path, "problem", issue.Problem, "detail", issue.Detail)
+		}
+		logger.Info("fsck-finished",
+			"tracks", report.Total,
+			"ok", report.OK,
+			"missing", report.Missing,
+			"modified", report.Modified,
+			"mismatched", report.Mismatched,
+			"corrupt", report.Corrupt,
+			"recovered", report.Recovered,
+			"repaired", report.Repaired,
+		)
+		if len(report.Issues) > 0 {
+			store.Close() // os.Exit skips the deferred close
+			os.Exit(1)
+		}
+		return false
+	}
+
+	artworkStore, err := artwork.New(*dataDir)
 	if err != nil {
-		logger.Error("failed-initialize-storage", "error", err)
+		logger.Error("failed-initialize-artwork-store", "error", err)
 		os.Exit(1)
 	}
-	defer store.Close()
-	logger.Info("storage-initialized")
+
+	mediaStore, err := media.New(*dataDir)
+	if err != nil {
+		logger.Error("failed-initialize-media-store", "error", err)
+		os.Exit(1)
+	}
+
+	backupSource := backup.Source{DataDir: *dataDir, Store: store, Media: mediaStore, Artwork: artworkStore, Keyring: keyring}
+	if *backupPath != "" {
+		opts := backup.Options{IncludeMedia: *backupMedia, Plaintext: *backupPlain}
+		if !*backupPlain {
+			if opts.Secret, err = readSecret(*passFile, *keyFile); err != nil {
+				logger.Error("failed-backup", "error", err)
+				os.Exit(1)
+			}
+		}
+		manifest, err := backup.CreateFile(*backupPath, backupSource, opts)
+		if err != nil {
+			logger.Error("failed-backup", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("backup-written", "path", *backupPath, "peer_id", manifest.PeerID, "tracks", manifest.Tracks, "playlists", manifest.Playlists, "media_files", manifest.MediaFiles, "sealed", manifest.Vault != nil)
+		return false
+	}
+
+	if *budget != "" {
+		settings, err := store.GetSettings()
+		if err == nil {
+			settings.StorageBudget = budgetBytes
+			err = store.SaveSettings(settings)
+		}
+		if err != nil {
+			logger.Error("failed-save-storage-budget", "error", err)
+			os.Exit(1)
+		}
+	} else if settings, err := store.GetSettings(); err != nil {
+		logger.Error("failed-read-settings", "error", err)
+		os.Exit(1)
+	} else {
+		budgetBytes = settings.StorageBudget
+	}
 
 	// Initialize libp2p host
 	logger.Info("initializing-libp2p-host")
-	h, err := host.New(ctx, *listenAddr, *dataDir, *enableRelay)
+	h, err := host.New(ctx, *listenAddr, *dataDir, keyring, *enableRelay)
 	if err != nil {
 		logger.Error("failed-create-libp2p-host", "error", err)
 		os.Exit(1)
@@ -110,6 +339,16 @@ func main() {
 
 	peerLogger.Info("host-ready", "addresses", h.Addrs())
 
+	if keyring != nil && keyring.Rotating() {
+		// Opening the storage and host resealed what an interrupted
+		// rotation left under the previous key
+		if err := v.FinishRotation(keyring); err != nil {
+			peerLogger.Error("failed-finish-key-rotation", "error", err)
+			os.Exit(1)
+		}
+		peerLogger.Info("vault-key-rotated", "key_id", keyring.KeyID())
+	}
+
 	// Initialize DHT
 	dhtService, err := dht.New(ctx, h, bootstrap)
 	if err != nil {
@@ -121,7 +360,11 @@ func main() {
 
 	// Initialize CTR pipeline
 	peerLogger.Info("initializing-ctr-service")
+	audio.SetFFmpegPath(*ffmpegPath)
 	ctrService := ctr.New(store, dhtService)
+	ctrService.SetWorkers(*ctrWorkers)
+	ctrService.SetTrustTags(*trustTags)
+	ctrService.SetArtworkStore(artworkStore)
 	peerLogger.Info("ctr-service-initialized")
 
 	// Initialize search service
@@ -131,14 +374,25 @@ func main() {
 
 	// Initialize streaming service
 	peerLogger.Info("initializing-streaming-service")
-	streamingService := streaming.New(h, store)
+	streamingService := streaming.New(h, store, artworkStore)
 	peerLogger.Info("streaming-service-initialized")
 
 	// Initialize daemon
 	peerLogger.Info("initializing-daemon")
 	dm := daemon.New(h, dhtService, ctrService, searchService, streamingService, store, peerLogger)
+	dm.SetMediaStore(mediaStore)
+	dm.SetStorageBudget(budgetBytes)
+	dm.SetBackupSource(backupSource)
+	dm.SetExportDir(*exportDir)
+	dm.SetWatcher(watch.New(store, dm, peerLogger))
+	dm.SetVault(*dataDir, v, keyring)
 	peerLogger.Info("daemon-initialized")
 
+	if err := addWatchFolders(store, watchDirs); err != nil {
+		peerLogger.Error("failed-save-watch-folders", "error", err)
+		os.Exit(1)
+	}
+
 	// Start daemon
 	peerLogger.Info("starting-daemon")
 	if err := dm.Start(ctx); err != nil {
@@ -147,40 +401,29 @@ func main() {
 	}
 	peerLogger.Info("daemon-started")
 
-	// Start Protobuf IPC server
-	protoAddr := *protoAddr
-	if *httpAddr != "" {
-		// Backward compatibility: if http is set, use it as proto address
-		protoAddr = *httpAddr
-		peerLogger.Warn("deprecated-http-flag", "http", *httpAddr)
-	}
-
-	apiServer := protoapi.New(protoAddr, dm)
-	if err := apiServer.Start(); err != nil {
-		peerLogger.Error("protobuf-ipc-server-error", "error", err)
-		os.Exit(1)
-	}
-	peerLogger.Info("protobuf-ipc-started", "addr", protoAddr)
-
-	// In Android mode expose control API only on localhost for in-app diagnostics.
-	if *mode == "android" && *controlAddr == "0.0.0.0:8080" {
-		*controlAddr = "127.0.0.1:8080"
-	}
-
-	var controlServer *controlapi.Server
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	stopChan := make(chan struct{}, 1)
+	defer signal.Stop(sigChan)
+	stopChan := make(chan bool, 1) // true to lock rather than shut down
 	var shutdownOnce sync.Once
-	shutdown := func(reason string) {
+	shutdown := func(reason string, lock bool) {
 		shutdownOnce.Do(func() {
 			peerLogger.Info("shutdown-requested", "reason", reason)
-			stopChan <- struct{}{}
+			stopChan <- lock
 		})
 	}
+	dm.SetOnLock(func() { shutdown("lock", true) })
+
+	// Start Protobuf IPC server
+	apiServer := protoapi.New(*protoAddr, dm)
+	if err := apiServer.Start(); err != nil {
+		peerLogger.Error("protobuf-ipc-server-error", "error", err)
+		os.Exit(1)
+	}
+	peerLogger.Info("protobuf-ipc-started", "addr", *protoAddr)
 
-	controlServer = controlapi.New(*controlAddr, dm, peerLogger, func(context.Context) error {
-		shutdown("control-api")
+	controlServer := controlapi.New(*controlAddr, dm, peerLogger, func(context.Context) error {
+		shutdown("control-api", false)
 		return nil
 	})
 	if err := controlServer.Start(); err != nil {
@@ -190,20 +433,19 @@ func main() {
 	peerLogger.Info("control-api-started", "addr", *controlAddr)
 	peerLogger.Info("cotune-daemon-ready", "mode", *mode)
 
-	go func() {
-		sig := <-sigChan
-		shutdown(sig.String())
-	}()
-	<-stopChan
+	var relock bool
+	select {
+	case sig := <-sigChan:
+		peerLogger.Info("shutdown-requested", "reason", sig.String())
+	case relock = <-stopChan:
+	}
 
 	// Graceful shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 
-	if controlServer != nil {
-		if err := controlServer.Shutdown(shutdownCtx); err != nil {
-			peerLogger.Warn("error-shutting-down-control-server", "error", err)
-		}
+	if err := controlServer.Shutdown(shutdownCtx); err != nil {
+		peerLogger.Warn("error-shutting-down-control-server", "error", err)
 	}
 
 	if err := apiServer.Shutdown(shutdownCtx); err != nil {
@@ -212,7 +454,169 @@ func main() {
 
 	if err := dm.Stop(shutdownCtx); err != nil {
 		peerLogger.Warn("error-stopping-daemon", "error", err)
+		if relock {
+			// Work still running may use the store; it cannot be closed
+			// and opened again for the next unlock
+			peerLogger.Error("failed-lock", "error", err)
+			os.Exit(1)
+		}
 	}
 
 	peerLogger.Info("shutdown-complete")
+	return relock
+}
+
+// openVault loads the vault of an encrypted node and unlocks it with the
+// secret the flags give, if any; the keyring is nil while the node stays
+// locked. With -encrypt a node not yet encrypted gets a vault, and its
+// library and identity key are sealed as they are opened.
+func openVault(dataDir string, logger *slog.Logger) (*vault.Vault, *vault.Keyring, error) {
+	secret, err := readSecret(*passFile, *keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := vault.Load(dataDir)
+	if errors.Is(err, os.ErrNotExist) {
+		if !*encrypt {
+			return nil, nil, nil
+		}
+		v, keyring, err := vault.Create(dataDir, secret)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Info("vault-created", "kdf", v.Info().KDF, "key_id", keyring.KeyID())
+		return v, keyring, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret.IsZero() {
+		return v, nil, nil
+	}
+	keyring, err := v.Unlock(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, keyring, nil
+}
+
+// readSecret reads the vault secret from a passphrase file or a keystore
+// key file
+func readSecret(passphraseFile string, keyFile string) (vault.Secret, error) {
+	var secret vault.Secret
+	if passphraseFile != "" {
+		data, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return secret, err
+		}
+		secret.Passphrase = strings.TrimRight(string(data), "\r\n")
+	}
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return secret, err
+		}
+		if secret.Key, err = hex.DecodeString(strings.TrimSpace(string(data))); err != nil {
+			return secret, fmt.Errorf("invalid vault key file: %w", err)
+		}
+	}
+	return secret, nil
+}
+
+// waitForUnlock serves the locked control and protobuf APIs until either
+// is given the vault secret, and returns the keyring it unlocks; nil if
+// the daemon is shut down instead
+func waitForUnlock(v *vault.Vault, logger *slog.Logger) *vault.Keyring {
+	unlocked := make(chan *vault.Keyring, 1)
+	unlock := func(secret vault.Secret) error {
+		keyring, err := v.Unlock(secret)
+		if err != nil {
+			logger.Warn("unlock-failed", "error", err)
+			return err
+		}
+		select {
+		case unlocked <- keyring:
+		default: // Already unlocked by another request
+		}
+		return nil
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	stopChan := make(chan struct{}, 1)
+
+	apiServer := protoapi.NewLocked(*protoAddr, unlock)
+	if err := apiServer.Start(); err != nil {
+		logger.Error("protobuf-ipc-server-error", "error", err)
+		os.Exit(1)
+	}
+	controlServer := controlapi.NewLocked(*controlAddr, unlock, logger, func(context.Context) error {
+		select {
+		case stopChan <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	if err := controlServer.Start(); err != nil {
+		logger.Error("control-api-start-error", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("daemon-locked", "kdf", v.Info().KDF, "proto", *protoAddr, "control", *controlAddr)
+
+	var keyring *vault.Keyring
+	select {
+	case keyring = <-unlocked:
+		logger.Info("daemon-unlocked", "key_id", keyring.KeyID())
+	case sig := <-sigChan:
+		logger.Info("shutdown-requested", "reason", sig.String())
+	case <-stopChan:
+		logger.Info("shutdown-requested", "reason", "control-api")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := controlServer.Shutdown(ctx); err != nil {
+		logger.Warn("error-shutting-down-control-server", "error", err)
+	}
+	if err := apiServer.Shutdown(ctx); err != nil {
+		logger.Warn("error-shutting-down-protobuf-server", "error", err)
+	}
+	return keyring
+}
+
+// restoreBackup restores a backup archive into dataDir, opening a sealed
+// one with the secret the flags give
+func restoreBackup(archivePath string, dataDir string) (*backup.RestoreReport, error) {
+	secret, err := readSecret(*passFile, *keyFile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return backup.Restore(file, dataDir, secret)
+}
+
+// addWatchFolders adds folders to the saved watch folders
+func addWatchFolders(store storage.Store, folders []string) error {
+	if len(folders) == 0 {
+		return nil
+	}
+	settings, err := store.GetSettings()
+	if err != nil {
+		return err
+	}
+	for _, folder := range folders {
+		folder, err := watch.CleanFolder(folder)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(settings.WatchFolders, folder) {
+			settings.WatchFolders = append(settings.WatchFolders, folder)
+		}
+	}
+	return store.SaveSettings(settings)
 }
@@ -0,0 +1,151 @@
+package control
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/cotune/go-backend/internal/backup"
+	"github.com/cotune/go-backend/internal/daemon"
+	"github.com/cotune/go-backend/internal/vault"
+)
+
+// handleBackup writes a backup archive of the node to a new file in the
+// export dir
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Path         string         `json:"path"`
+		IncludeMedia bool           `json:"include_media"`
+		Secret       *secretRequest `json:"secret"`    // seals the archive; required on an encrypted node
+		Plaintext    bool           `json:"plaintext"` // back up an encrypted node unsealed
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if req.Path == "" {
+		writeError(w, http.StatusBadRequest, "path is required")
+		return
+	}
+
+	opts := backup.Options{IncludeMedia: req.IncludeMedia, Plaintext: req.Plaintext}
+	if req.Secret != nil {
+		secret, err := req.Secret.secret()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		opts.Secret = secret
+	}
+	path, err := s.dm.ExportPath(req.Path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	manifest, err := s.dm.BackupToFile(path, opts)
+	switch {
+	case errors.Is(err, os.ErrExist):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, backup.ErrNeedsSecret):
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, vault.ErrWrongSecret):
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"path":        path,
+		"peer_id":     manifest.PeerID,
+		"tracks":      manifest.Tracks,
+		"playlists":   manifest.Playlists,
+		"media_files": manifest.MediaFiles,
+		"artwork":     manifest.Artwork,
+		"sealed":      manifest.Vault != nil,
+	})
+}
+
+// handleLibraryExport returns the library, or one playlist, as JSON or M3U8
+func (s *Server) handleLibraryExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	format, err := daemon.LibraryFormatFor(r.URL.Query().Get("format"), "")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var buf bytes.Buffer
+	if err := s.dm.ExportLibrary(&buf, format, r.URL.Query().Get("playlist_id")); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	contentType := "application/json"
+	if format == daemon.LibraryM3U8 {
+		contentType = "audio/x-mpegurl; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleLibraryImport imports a library export or playlist file from the
+// export dir
+func (s *Server) handleLibraryImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Path   string `json:"path"`
+		Format string `json:"format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if req.Path == "" {
+		writeError(w, http.StatusBadRequest, "path is required")
+		return
+	}
+	if _, err := daemon.LibraryFormatFor(req.Format, req.Path); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path, err := s.dm.ExportPath(req.Path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.dm.ImportLibraryFile(r.Context(), path, req.Format)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"tracks":    result.Tracks,
+		"playlists": result.Playlists,
+		"existing":  result.Existing,
+		"missing":   result.Missing,
+	})
+}
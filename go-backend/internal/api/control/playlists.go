@@ -0,0 +1,168 @@
+package control
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cotune/go-backend/internal/daemon"
+)
+
+// handlePlaylists lists local playlists on GET and creates one on POST
+func (s *Server) handlePlaylists(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		playlists, err := s.dm.ListPlaylists()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"playlists": playlists})
+	case http.MethodPost:
+		var req struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			CTIDs       []string `json:"ctids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json body")
+			return
+		}
+		pl, err := s.dm.CreatePlaylist(req.Name, req.Description, req.CTIDs)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, pl)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleUpdatePlaylist changes the playlist fields present in the body
+func (s *Server) handleUpdatePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		PlaylistID string `json:"playlist_id"`
+		daemon.PlaylistUpdate
+	}
+	if !decodePlaylistRequest(w, r, &req, &req.PlaylistID) {
+		return
+	}
+
+	pl, err := s.dm.UpdatePlaylist(r.Context(), req.PlaylistID, req.PlaylistUpdate)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, pl)
+}
+
+func (s *Server) handleDeletePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		PlaylistID string `json:"playlist_id"`
+	}
+	if !decodePlaylistRequest(w, r, &req, &req.PlaylistID) {
+		return
+	}
+
+	if err := s.dm.DeletePlaylist(req.PlaylistID); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"playlist_id": req.PlaylistID, "deleted": true})
+}
+
+// handleSharePlaylist publishes a playlist and returns the record ID peers
+// open it by
+func (s *Server) handleSharePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		PlaylistID string `json:"playlist_id"`
+	}
+	if !decodePlaylistRequest(w, r, &req, &req.PlaylistID) {
+		return
+	}
+
+	recordID, err := s.dm.SharePlaylist(r.Context(), req.PlaylistID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"playlist_id": req.PlaylistID, "record_id": recordID})
+}
+
+func (s *Server) handleUnsharePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		PlaylistID string `json:"playlist_id"`
+	}
+	if !decodePlaylistRequest(w, r, &req, &req.PlaylistID) {
+		return
+	}
+
+	if err := s.dm.UnsharePlaylist(req.PlaylistID); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"playlist_id": req.PlaylistID, "shared": false})
+}
+
+// handleOpenPlaylist fetches a playlist record by ID and resolves its
+// tracks to providers, saving a local copy with "save"
+func (s *Server) handleOpenPlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		RecordID string `json:"record_id"`
+		Save     bool   `json:"save"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if req.RecordID == "" {
+		writeError(w, http.StatusBadRequest, "record_id is required")
+		return
+	}
+
+	opened, err := s.dm.OpenPlaylist(r.Context(), req.RecordID, req.Save)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, opened)
+}
+
+// decodePlaylistRequest decodes a body that must name a playlist, writing
+// the error response if it does not
+func decodePlaylistRequest(w http.ResponseWriter, r *http.Request, req interface{}, playlistID *string) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return false
+	}
+	if *playlistID == "" {
+		writeError(w, http.StatusBadRequest, "playlist_id is required")
+		return false
+	}
+	return true
+}
@@ -5,13 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/ctr"
 	"github.com/cotune/go-backend/internal/daemon"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/quota"
+	"github.com/cotune/go-backend/internal/streaming"
+	"github.com/cotune/go-backend/internal/vault"
 )
 
 type Server struct {
@@ -19,6 +26,7 @@ type Server struct {
 	dm         *daemon.Daemon
 	logger     *slog.Logger
 	shutdownFn func(context.Context) error
+	unlock     func(vault.Secret) error // set while the daemon is locked
 	server     *http.Server
 }
 
@@ -36,17 +44,60 @@ func New(addr string, dm *daemon.Daemon, logger *slog.Logger, shutdownFn func(co
 
 func (s *Server) Start() error {
 	mux := http.NewServeMux()
+	if s.unlock != nil {
+		// A locked daemon serves only what unlocks or stops it
+		mux.HandleFunc("/status", s.handleLockedStatus)
+		mux.HandleFunc("/unlock", s.handleUnlock)
+		mux.HandleFunc("/shutdown", s.handleShutdown)
+		return s.serve(mux)
+	}
+
 	mux.HandleFunc("/status", s.handleStatus)
 	mux.HandleFunc("/peers", s.handlePeers)
 	mux.HandleFunc("/providers", s.handleProviders)
 	mux.HandleFunc("/addTrack", s.handleAddTrack)
+	mux.HandleFunc("/deleteTrack", s.handleDeleteTrack)
+	mux.HandleFunc("/updateTrack", s.handleUpdateTrack)
+	mux.HandleFunc("/unshareTrack", s.handleUnshareTrack)
+	mux.HandleFunc("/playlists", s.handlePlaylists)
+	mux.HandleFunc("/playlists/update", s.handleUpdatePlaylist)
+	mux.HandleFunc("/playlists/delete", s.handleDeletePlaylist)
+	mux.HandleFunc("/playlists/share", s.handleSharePlaylist)
+	mux.HandleFunc("/playlists/unshare", s.handleUnsharePlaylist)
+	mux.HandleFunc("/playlists/open", s.handleOpenPlaylist)
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/similar", s.handleSimilar)
+	mux.HandleFunc("/artwork", s.handleArtwork)
+	mux.HandleFunc("/waveform", s.handleWaveform)
 	mux.HandleFunc("/replicate", s.handleReplicate)
 	mux.HandleFunc("/disconnect", s.handleDisconnect)
 	mux.HandleFunc("/shutdown", s.handleShutdown)
 	mux.HandleFunc("/connect", s.handleConnect)
 	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/migration", s.handleMigration)
+	mux.HandleFunc("/jobs", s.handleJobs)
+	mux.HandleFunc("/jobs/retry", s.handleRetryJobs)
+	mux.HandleFunc("/media/gc", s.handleMediaGC)
+	mux.HandleFunc("/storage/usage", s.handleStorageUsage)
+	mux.HandleFunc("/storage/budget", s.handleStorageBudget)
+	mux.HandleFunc("/storage/evict", s.handleStorageEvict)
+	mux.HandleFunc("/backup", s.handleBackup)
+	mux.HandleFunc("/library/export", s.handleLibraryExport)
+	mux.HandleFunc("/library/import", s.handleLibraryImport)
+	mux.HandleFunc("/watch", s.handleWatch)
+	mux.HandleFunc("/watch/add", s.handleAddWatchFolder)
+	mux.HandleFunc("/watch/remove", s.handleRemoveWatchFolder)
+	mux.HandleFunc("/watch/rescan", s.handleRescanWatchFolders)
+	mux.HandleFunc("/verify", s.handleVerify)
+	mux.HandleFunc("/vault", s.handleVault)
+	mux.HandleFunc("/vault/enable", s.handleEnableEncryption)
+	mux.HandleFunc("/vault/secret", s.handleChangeVaultSecret)
+	mux.HandleFunc("/vault/rotate", s.handleRotateVaultKey)
+	mux.HandleFunc("/lock", s.handleLock)
+	return s.serve(mux)
+}
 
+func (s *Server) serve(mux *http.ServeMux) error {
 	s.server = &http.Server{
 		Addr:              s.addr,
 		Handler:           mux,
@@ -130,9 +181,10 @@ func (s *Server) handleAddTrack(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Path   string `json:"path"`
-		Title  string `json:"title"`
-		Artist string `json:"artist"`
+		Path    string `json:"path"`
+		Title   string `json:"title"`
+		Artist  string `json:"artist"`
+		InPlace bool   `json:"in_place"` // reference the file instead of importing it
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid json body")
@@ -143,7 +195,7 @@ func (s *Server) handleAddTrack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	track, err := s.dm.AddTrack(r.Context(), req.Path, req.Title, req.Artist)
+	track, err := s.dm.AddTrack(r.Context(), req.Path, req.Title, req.Artist, req.InPlace)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -151,6 +203,85 @@ func (s *Server) handleAddTrack(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, track)
 }
 
+func (s *Server) handleDeleteTrack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		TrackID    string `json:"track_id"`
+		DeleteFile bool   `json:"delete_file"` // also delete the audio file
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if req.TrackID == "" {
+		writeError(w, http.StatusBadRequest, "track_id is required")
+		return
+	}
+
+	if err := s.dm.DeleteTrack(req.TrackID, req.DeleteFile); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"track_id": req.TrackID, "deleted": true})
+}
+
+// handleUpdateTrack changes the metadata fields present in the body
+func (s *Server) handleUpdateTrack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		TrackID string `json:"track_id"`
+		daemon.MetadataUpdate
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if req.TrackID == "" {
+		writeError(w, http.StatusBadRequest, "track_id is required")
+		return
+	}
+
+	track, err := s.dm.UpdateTrackMetadata(r.Context(), req.TrackID, req.MetadataUpdate)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, track)
+}
+
+func (s *Server) handleUnshareTrack(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		TrackID string `json:"track_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if req.TrackID == "" {
+		writeError(w, http.StatusBadRequest, "track_id is required")
+		return
+	}
+
+	if err := s.dm.UnshareTrack(req.TrackID); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"track_id": req.TrackID, "shared": false})
+}
+
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -180,6 +311,96 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
 }
 
+func (s *Server) handleSimilar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	ctid := r.URL.Query().Get("ctid")
+	if ctid == "" {
+		writeError(w, http.StatusBadRequest, "ctid is required")
+		return
+	}
+	max := 10
+	if raw := r.URL.Query().Get("max"); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			max = v
+		}
+	}
+
+	results, err := s.dm.FindSimilar(r.Context(), ctid, max)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ctid": ctid, "results": results})
+}
+
+func (s *Server) handleArtwork(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	ctid := r.URL.Query().Get("ctid")
+	if ctid == "" {
+		writeError(w, http.StatusBadRequest, "ctid is required")
+		return
+	}
+	size, err := artwork.ParseSize(r.URL.Query().Get("size"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, mime, err := s.dm.GetArtwork(r.Context(), ctid, size)
+	if errors.Is(err, streaming.ErrNoArtwork) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", mime)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleWaveform(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	ctid := r.URL.Query().Get("ctid")
+	if ctid == "" {
+		writeError(w, http.StatusBadRequest, "ctid is required")
+		return
+	}
+	maxPeaks := 0
+	if raw := r.URL.Query().Get("max_peaks"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "max_peaks must be a non-negative integer")
+			return
+		}
+		maxPeaks = n
+	}
+
+	peaks, err := s.dm.GetWaveform(r.Context(), ctid, maxPeaks)
+	if errors.Is(err, streaming.ErrNoWaveform) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ctid": ctid, "sample_rate": peaks.SampleRate, "levels": peaks.Levels})
+}
+
 func (s *Server) handleReplicate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -205,7 +426,20 @@ func (s *Server) handleReplicate(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, map[string]interface{}{"replicated_track_id": req.TrackID})
 	case req.CTID != "":
 		if req.OutputPath == "" {
-			req.OutputPath = fmt.Sprintf("/data/replicated_%d.bin", time.Now().UnixNano())
+			// Without an output path the daemon keeps the file in its cache
+			track, fetched, err := s.dm.CacheTrack(r.Context(), req.CTID)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"ctid":         req.CTID,
+				"path":         track.Path,
+				"track_id":     track.ID,
+				"fetched_ctid": fetched,
+				"substituted":  fetched != req.CTID,
+			})
+			return
 		}
 		if err := s.dm.FetchTrack(r.Context(), req.CTID, req.OutputPath); err != nil {
 			writeError(w, http.StatusBadRequest, err.Error())
@@ -339,6 +573,151 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(sb.String()))
 }
 
+// handleMigration reports CTID migration progress (GET) or starts a new
+// migration (POST)
+func (s *Server) handleMigration(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.dm.CTIDMigrationProgress())
+	case http.MethodPost:
+		if err := s.dm.StartCTIDMigration(); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ctr.ErrMigrationRunning) {
+				status = http.StatusConflict
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, s.dm.CTIDMigrationProgress())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	state := models.JobState(r.URL.Query().Get("state"))
+	switch state {
+	case "", models.JobQueued, models.JobDecoding, models.JobHashing, models.JobFailed:
+	default:
+		writeError(w, http.StatusBadRequest, "unknown job state")
+		return
+	}
+
+	jobs, err := s.dm.ListJobs(state)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": jobs, "count": len(jobs)})
+}
+
+// handleRetryJobs queues failed jobs again; an empty job_ids retries all
+func (s *Server) handleRetryJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		JobIDs []string `json:"job_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	retried, err := s.dm.RetryJobs(req.JobIDs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"retried": retried})
+}
+
+// handleMediaGC removes the files in the media store no track references
+func (s *Server) handleMediaGC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	result, err := s.dm.GCMedia()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleStorageUsage reports media store usage by category against the
+// budget
+func (s *Server) handleStorageUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	usage, err := s.dm.StorageUsage()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// handleStorageBudget changes and saves the storage budget and evicts down
+// to it
+func (s *Server) handleStorageBudget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Budget string `json:"budget"` // e.g. "20GB"; "0" is unlimited
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	budget, err := quota.ParseSize(req.Budget)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.dm.UpdateStorageBudget(budget); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	eviction, err := s.dm.EnforceBudget()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"budget_bytes": budget, "eviction": eviction})
+}
+
+// handleStorageEvict evicts cached replicas until the budget is met
+func (s *Server) handleStorageEvict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	eviction, err := s.dm.EnforceBudget()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, eviction)
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
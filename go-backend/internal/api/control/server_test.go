@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/cotune/go-backend/internal/vault"
 )
 
 func TestHandlersRejectWrongMethodsBeforeDaemonUse(t *testing.T) {
@@ -20,8 +22,42 @@ func TestHandlersRejectWrongMethodsBeforeDaemonUse(t *testing.T) {
 		{name: "status", handler: s.handleStatus, method: http.MethodPost, path: "/status"},
 		{name: "peers", handler: s.handlePeers, method: http.MethodPost, path: "/peers"},
 		{name: "addTrack", handler: s.handleAddTrack, method: http.MethodGet, path: "/addTrack"},
+		{name: "deleteTrack", handler: s.handleDeleteTrack, method: http.MethodGet, path: "/deleteTrack"},
+		{name: "updateTrack", handler: s.handleUpdateTrack, method: http.MethodGet, path: "/updateTrack"},
+		{name: "unshareTrack", handler: s.handleUnshareTrack, method: http.MethodGet, path: "/unshareTrack"},
+		{name: "playlists", handler: s.handlePlaylists, method: http.MethodDelete, path: "/playlists"},
+		{name: "updatePlaylist", handler: s.handleUpdatePlaylist, method: http.MethodGet, path: "/playlists/update"},
+		{name: "deletePlaylist", handler: s.handleDeletePlaylist, method: http.MethodGet, path: "/playlists/delete"},
+		{name: "sharePlaylist", handler: s.handleSharePlaylist, method: http.MethodGet, path: "/playlists/share"},
+		{name: "unsharePlaylist", handler: s.handleUnsharePlaylist, method: http.MethodGet, path: "/playlists/unshare"},
+		{name: "openPlaylist", handler: s.handleOpenPlaylist, method: http.MethodGet, path: "/playlists/open"},
 		{name: "search", handler: s.handleSearch, method: http.MethodGet, path: "/search"},
+		{name: "similar", handler: s.handleSimilar, method: http.MethodPost, path: "/similar"},
+		{name: "artwork", handler: s.handleArtwork, method: http.MethodPost, path: "/artwork"},
+		{name: "waveform", handler: s.handleWaveform, method: http.MethodPost, path: "/waveform"},
 		{name: "connect", handler: s.handleConnect, method: http.MethodGet, path: "/connect"},
+		{name: "migration", handler: s.handleMigration, method: http.MethodDelete, path: "/migration"},
+		{name: "jobs", handler: s.handleJobs, method: http.MethodPost, path: "/jobs"},
+		{name: "retryJobs", handler: s.handleRetryJobs, method: http.MethodGet, path: "/jobs/retry"},
+		{name: "mediaGC", handler: s.handleMediaGC, method: http.MethodGet, path: "/media/gc"},
+		{name: "storageUsage", handler: s.handleStorageUsage, method: http.MethodPost, path: "/storage/usage"},
+		{name: "storageBudget", handler: s.handleStorageBudget, method: http.MethodGet, path: "/storage/budget"},
+		{name: "storageEvict", handler: s.handleStorageEvict, method: http.MethodGet, path: "/storage/evict"},
+		{name: "backup", handler: s.handleBackup, method: http.MethodGet, path: "/backup"},
+		{name: "libraryExport", handler: s.handleLibraryExport, method: http.MethodPost, path: "/library/export"},
+		{name: "libraryImport", handler: s.handleLibraryImport, method: http.MethodGet, path: "/library/import"},
+		{name: "watch", handler: s.handleWatch, method: http.MethodPost, path: "/watch"},
+		{name: "addWatchFolder", handler: s.handleAddWatchFolder, method: http.MethodGet, path: "/watch/add"},
+		{name: "removeWatchFolder", handler: s.handleRemoveWatchFolder, method: http.MethodGet, path: "/watch/remove"},
+		{name: "rescanWatchFolders", handler: s.handleRescanWatchFolders, method: http.MethodGet, path: "/watch/rescan"},
+		{name: "verify", handler: s.handleVerify, method: http.MethodDelete, path: "/verify"},
+		{name: "vault", handler: s.handleVault, method: http.MethodPost, path: "/vault"},
+		{name: "enableEncryption", handler: s.handleEnableEncryption, method: http.MethodGet, path: "/vault/enable"},
+		{name: "changeVaultSecret", handler: s.handleChangeVaultSecret, method: http.MethodGet, path: "/vault/secret"},
+		{name: "rotateVaultKey", handler: s.handleRotateVaultKey, method: http.MethodGet, path: "/vault/rotate"},
+		{name: "lock", handler: s.handleLock, method: http.MethodGet, path: "/lock"},
+		{name: "lockedStatus", handler: s.handleLockedStatus, method: http.MethodPost, path: "/status"},
+		{name: "unlock", handler: s.handleUnlock, method: http.MethodGet, path: "/unlock"},
 	}
 
 	for _, tc := range tests {
@@ -53,6 +89,87 @@ func TestAddTrackRejectsInvalidBodyBeforeDaemonUse(t *testing.T) {
 	assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
 }
 
+func TestTrackLifecycleRequiresTrackIDBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	handlers := map[string]http.HandlerFunc{
+		"/deleteTrack":  s.handleDeleteTrack,
+		"/updateTrack":  s.handleUpdateTrack,
+		"/unshareTrack": s.handleUnshareTrack,
+	}
+	for path, handler := range handlers {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"title":"New title"}`))
+		rr := httptest.NewRecorder()
+
+		handler(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d; body=%s", path, rr.Code, http.StatusBadRequest, rr.Body.String())
+		}
+		assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+	}
+}
+
+func TestPlaylistHandlersRequireIDsBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	handlers := map[string]http.HandlerFunc{
+		"/playlists/update":  s.handleUpdatePlaylist,
+		"/playlists/delete":  s.handleDeletePlaylist,
+		"/playlists/share":   s.handleSharePlaylist,
+		"/playlists/unshare": s.handleUnsharePlaylist,
+		"/playlists/open":    s.handleOpenPlaylist,
+	}
+	for path, handler := range handlers {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"Road Trip"}`))
+		rr := httptest.NewRecorder()
+
+		handler(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d; body=%s", path, rr.Code, http.StatusBadRequest, rr.Body.String())
+		}
+		assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+	}
+}
+
+func TestLibraryAndWatchHandlersValidateRequestsBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		body    string
+	}{
+		{name: "backupWithoutPath", handler: s.handleBackup, method: http.MethodPost, target: "/backup", body: `{"include_media":true}`},
+		{name: "exportUnknownFormat", handler: s.handleLibraryExport, method: http.MethodGet, target: "/library/export?format=xspf"},
+		{name: "importWithoutPath", handler: s.handleLibraryImport, method: http.MethodPost, target: "/library/import", body: `{"format":"json"}`},
+		{name: "watchAddWithoutPath", handler: s.handleAddWatchFolder, method: http.MethodPost, target: "/watch/add", body: `{}`},
+		{name: "watchRemoveWithoutPath", handler: s.handleRemoveWatchFolder, method: http.MethodPost, target: "/watch/remove", body: `{"path":""}`},
+		{name: "importUnknownFormat", handler: s.handleLibraryImport, method: http.MethodPost, target: "/library/import", body: `{"path":"/tmp/library.pls","format":"pls"}`},
+		{name: "verifyInvalidBody", handler: s.handleVerify, method: http.MethodPost, target: "/verify", body: `{"deep":`},
+		{name: "enableWithoutSecret", handler: s.handleEnableEncryption, method: http.MethodPost, target: "/vault/enable", body: `{}`},
+		{name: "rotateWithKeyNotHex", handler: s.handleRotateVaultKey, method: http.MethodPost, target: "/vault/rotate", body: `{"key":"not hex"}`},
+		{name: "changeSecretWithoutNew", handler: s.handleChangeVaultSecret, method: http.MethodPost, target: "/vault/secret", body: `{"old":{"passphrase":"old"}}`},
+		{name: "unlockWithoutSecret", handler: s.handleUnlock, method: http.MethodPost, target: "/unlock", body: `{"passphrase":""}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			tc.handler(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d; body=%s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+			assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+		})
+	}
+}
+
 func TestSearchRejectsEmptyQueryBeforeDaemonUse(t *testing.T) {
 	s := New("127.0.0.1:0", nil, nil, nil)
 
@@ -67,6 +184,52 @@ func TestSearchRejectsEmptyQueryBeforeDaemonUse(t *testing.T) {
 	assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
 }
 
+func TestSimilarRequiresCTIDBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/similar", nil)
+	rr := httptest.NewRecorder()
+
+	s.handleSimilar(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+}
+
+func TestArtworkValidatesQueryBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	for _, path := range []string{"/artwork", "/artwork?ctid=abc&size=huge"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+
+		s.handleArtwork(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d; body=%s", path, rr.Code, http.StatusBadRequest, rr.Body.String())
+		}
+		assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+	}
+}
+
+func TestWaveformValidatesQueryBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	for _, path := range []string{"/waveform", "/waveform?ctid=abc&max_peaks=-1", "/waveform?ctid=abc&max_peaks=many"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+
+		s.handleWaveform(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want %d; body=%s", path, rr.Code, http.StatusBadRequest, rr.Body.String())
+		}
+		assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+	}
+}
+
 func TestConnectRejectsMissingPeerDataBeforeDaemonUse(t *testing.T) {
 	s := New("127.0.0.1:0", nil, nil, nil)
 
@@ -81,6 +244,50 @@ func TestConnectRejectsMissingPeerDataBeforeDaemonUse(t *testing.T) {
 	assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
 }
 
+func TestJobsRejectsUnknownStateBeforeDaemonUse(t *testing.T) {
+	s := New("127.0.0.1:0", nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs?state=stuck", nil)
+	rr := httptest.NewRecorder()
+
+	s.handleJobs(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body=%s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	assertJSONError(t, rr.Body.String(), http.StatusBadRequest)
+}
+
+func TestLockedServerUnlocksWithSecret(t *testing.T) {
+	var unlocked vault.Secret
+	s := NewLocked("127.0.0.1:0", func(secret vault.Secret) error {
+		if secret.Passphrase != "open sesame" {
+			return vault.ErrWrongSecret
+		}
+		unlocked = secret
+		return nil
+	}, nil, nil)
+
+	rr := httptest.NewRecorder()
+	s.handleLockedStatus(rr, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"locked":true`) {
+		t.Fatalf("locked status = %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	s.handleUnlock(rr, httptest.NewRequest(http.MethodPost, "/unlock", strings.NewReader(`{"passphrase":"guess"}`)))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("unlock with wrong secret status = %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	assertJSONError(t, rr.Body.String(), http.StatusForbidden)
+
+	rr = httptest.NewRecorder()
+	s.handleUnlock(rr, httptest.NewRequest(http.MethodPost, "/unlock", strings.NewReader(`{"passphrase":"open sesame"}`)))
+	if rr.Code != http.StatusAccepted || unlocked.Passphrase != "open sesame" {
+		t.Fatalf("unlock status = %d, secret = %+v; body=%s", rr.Code, unlocked, rr.Body.String())
+	}
+}
+
 func assertJSONError(t *testing.T, body string, status int) {
 	t.Helper()
 	var payload struct {
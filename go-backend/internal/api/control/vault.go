@@ -0,0 +1,199 @@
+package control
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/cotune/go-backend/internal/daemon"
+	"github.com/cotune/go-backend/internal/vault"
+)
+
+// NewLocked creates the control API of a daemon started locked: it serves
+// only status, unlock and shutdown until unlock accepts a secret
+func NewLocked(addr string, unlock func(vault.Secret) error, logger *slog.Logger, shutdownFn func(context.Context) error) *Server {
+	s := New(addr, nil, logger, shutdownFn)
+	s.unlock = unlock
+	return s
+}
+
+// secretRequest gives a vault secret: a passphrase, or a keystore key in
+// hex
+type secretRequest struct {
+	Passphrase string `json:"passphrase"`
+	Key        string `json:"key"`
+}
+
+func (r secretRequest) secret() (vault.Secret, error) {
+	secret := vault.Secret{Passphrase: r.Passphrase}
+	if r.Key != "" {
+		key, err := hex.DecodeString(r.Key)
+		if err != nil {
+			return secret, fmt.Errorf("key must be hex")
+		}
+		secret.Key = key
+	}
+	if secret.IsZero() {
+		return secret, vault.ErrNoSecret
+	}
+	return secret, nil
+}
+
+// decodeSecret reads a secretRequest body, answering 400 if it holds none
+func decodeSecret(w http.ResponseWriter, r *http.Request) (vault.Secret, bool) {
+	var req secretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return vault.Secret{}, false
+	}
+	secret, err := req.secret()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return vault.Secret{}, false
+	}
+	return secret, true
+}
+
+// writeVaultError answers a failed vault operation
+func writeVaultError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, vault.ErrWrongSecret):
+		status = http.StatusForbidden
+	case errors.Is(err, daemon.ErrEncrypted), errors.Is(err, daemon.ErrNotEncrypted):
+		status = http.StatusConflict
+	}
+	writeError(w, status, err.Error())
+}
+
+// handleLockedStatus reports a daemon waiting to be unlocked
+func (s *Server) handleLockedStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"running":   false,
+		"locked":    true,
+		"encrypted": true,
+	})
+}
+
+// handleUnlock unlocks a daemon started locked with its secret
+func (s *Server) handleUnlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	secret, ok := decodeSecret(w, r)
+	if !ok {
+		return
+	}
+	if err := s.unlock(secret); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, vault.ErrWrongSecret) {
+			status = http.StatusForbidden
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"status": "unlocking"})
+}
+
+// handleVault reports whether and how the node is encrypted
+func (s *Server) handleVault(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.dm.VaultStatus())
+}
+
+// handleEnableEncryption encrypts the identity key and library with a new
+// vault unlocked by the secret in the body
+func (s *Server) handleEnableEncryption(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	secret, ok := decodeSecret(w, r)
+	if !ok {
+		return
+	}
+	status, err := s.dm.EnableEncryption(secret)
+	if err != nil {
+		writeVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleChangeVaultSecret replaces the vault secret:
+// {"old": {"passphrase": ...}, "new": {"key": ...}}
+func (s *Server) handleChangeVaultSecret(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Old secretRequest `json:"old"`
+		New secretRequest `json:"new"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	old, err := req.Old.secret()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "old: "+err.Error())
+		return
+	}
+	secret, err := req.New.secret()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "new: "+err.Error())
+		return
+	}
+
+	status, err := s.dm.ChangeVaultSecret(old, secret)
+	if err != nil {
+		writeVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleRotateVaultKey reseals the identity key and library with a new
+// data key
+func (s *Server) handleRotateVaultKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	secret, ok := decodeSecret(w, r)
+	if !ok {
+		return
+	}
+	status, err := s.dm.RotateVaultKey(secret)
+	if err != nil {
+		writeVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleLock stops an encrypted daemon until it is unlocked again
+func (s *Server) handleLock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := s.dm.Lock(); err != nil {
+		writeVaultError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"status": "locking"})
+}
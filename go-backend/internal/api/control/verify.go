@@ -0,0 +1,38 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cotune/go-backend/internal/fsck"
+)
+
+// handleVerify reports library verification progress (GET) or starts a
+// verification of every track's file (POST, optionally {"deep": true})
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.dm.VerifyReport())
+	case http.MethodPost:
+		var req struct {
+			Deep bool `json:"deep"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid json body")
+			return
+		}
+		if err := s.dm.StartVerify(req.Deep); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, fsck.ErrRunning) {
+				status = http.StatusConflict
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, s.dm.VerifyReport())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
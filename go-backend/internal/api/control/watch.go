@@ -0,0 +1,84 @@
+package control
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleWatch returns the watched folders and scan progress
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.dm.WatchStatus())
+}
+
+// handleAddWatchFolder starts watching a folder
+func (s *Server) handleAddWatchFolder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	path, ok := decodeWatchPath(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := s.dm.AddWatchFolder(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleRemoveWatchFolder stops watching a folder
+func (s *Server) handleRemoveWatchFolder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	path, ok := decodeWatchPath(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := s.dm.RemoveWatchFolder(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleRescanWatchFolders scans every watched folder again
+func (s *Server) handleRescanWatchFolders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	status, err := s.dm.RescanWatchFolders()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// decodeWatchPath reads {"path": "..."}, answering bad requests itself
+func decodeWatchPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return "", false
+	}
+	if req.Path == "" {
+		writeError(w, http.StatusBadRequest, "path is required")
+		return "", false
+	}
+	return req.Path, true
+}
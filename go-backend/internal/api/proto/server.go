@@ -2,6 +2,7 @@ package proto
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -11,7 +12,13 @@ import (
 	"google.golang.org/grpc"
 
 	protoapi "github.com/cotune/go-backend/api/proto"
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/backup"
 	"github.com/cotune/go-backend/internal/daemon"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/streaming"
+	"github.com/cotune/go-backend/internal/vault"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
@@ -22,6 +29,7 @@ type Server struct {
 	protoapi.UnimplementedCotuneServiceServer
 	addr   string
 	daemon *daemon.Daemon
+	unlock func(vault.Secret) error // set while the daemon is locked
 	server *grpc.Server
 }
 
@@ -33,6 +41,15 @@ func New(addr string, dm *daemon.Daemon) *Server {
 	}
 }
 
+// NewLocked creates the protobuf server of a daemon started locked: it
+// answers only Status and Unlock until unlock accepts a secret
+func NewLocked(addr string, unlock func(vault.Secret) error) *Server {
+	return &Server{
+		addr:   addr,
+		unlock: unlock,
+	}
+}
+
 // Start starts the protobuf server
 func (s *Server) Start() error {
 	// Determine if Unix socket or TCP
@@ -57,7 +74,11 @@ func (s *Server) Start() error {
 	}
 
 	s.server = grpc.NewServer()
-	protoapi.RegisterCotuneServiceServer(s.server, s)
+	if s.unlock != nil {
+		protoapi.RegisterCotuneServiceServer(s.server, &lockedService{unlock: s.unlock})
+	} else {
+		protoapi.RegisterCotuneServiceServer(s.server, s)
+	}
 
 	go func() {
 		if err := s.server.Serve(listener); err != nil {
@@ -78,9 +99,13 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // Status implements CotuneService.Status
 func (s *Server) Status(ctx context.Context, req *protoapi.StatusRequest) (*protoapi.StatusResponse, error) {
+	ffmpeg := s.daemon.FFmpeg()
 	return &protoapi.StatusResponse{
-		Running: true,
-		Version: "1.0.0",
+		Running:         true,
+		Version:         "1.0.0",
+		FfmpegAvailable: ffmpeg.Available,
+		FfmpegVersion:   ffmpeg.Version,
+		FfmpegError:     ffmpeg.Error,
 	}, nil
 }
 
@@ -181,13 +206,30 @@ func (s *Server) Search(ctx context.Context, req *protoapi.SearchRequest) (*prot
 
 	protoResults := make([]*protoapi.SearchResult, 0, len(results))
 	for _, r := range results {
-		protoResults = append(protoResults, &protoapi.SearchResult{
-			Ctid:       r.CTID,
-			Title:      r.Title,
-			Artist:     r.Artist,
-			Recognized: r.Recognized,
-			Providers:  r.Providers,
-		})
+		result := &protoapi.SearchResult{
+			Ctid:           r.CTID,
+			Title:          r.Title,
+			Artist:         r.Artist,
+			Recognized:     r.Recognized,
+			Providers:      r.Providers,
+			FingerprintKey: r.FingerprintKey,
+			Alternates:     r.Alternates,
+			Codec:          r.Codec,
+			SampleRate:     int32(r.SampleRate),
+			Channels:       int32(r.Channels),
+			Bitrate:        int32(r.Bitrate),
+			DurationMs:     r.DurationMs,
+			FileSize:       r.FileSize,
+			Artwork:        r.Artwork,
+		}
+		if l := r.Loudness; l != nil {
+			result.HasLoudness = true
+			result.IntegratedLufs = l.IntegratedLUFS
+			result.RangeLu = l.RangeLU
+			result.TruePeakDbtp = l.TruePeakDBTP
+			result.GainDb = l.GainDB
+		}
+		protoResults = append(protoResults, result)
 	}
 	log.Printf("grpc-search-response query=%q results=%d", req.GetQuery(), len(protoResults))
 
@@ -218,9 +260,117 @@ func (s *Server) SearchProviders(ctx context.Context, req *protoapi.SearchProvid
 	}, nil
 }
 
+// FindSimilar implements CotuneService.FindSimilar
+func (s *Server) FindSimilar(ctx context.Context, req *protoapi.FindSimilarRequest) (*protoapi.FindSimilarResponse, error) {
+	maxResults := int(req.GetMaxResults())
+	if maxResults == 0 {
+		maxResults = 10
+	}
+
+	results, err := s.daemon.FindSimilar(ctx, req.GetCtid(), maxResults)
+	if err != nil {
+		return &protoapi.FindSimilarResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	protoResults := make([]*protoapi.SimilarTrack, 0, len(results))
+	for _, r := range results {
+		protoResults = append(protoResults, &protoapi.SimilarTrack{
+			Ctid:      r.CTID,
+			Title:     r.Title,
+			Artist:    r.Artist,
+			Score:     r.Score,
+			Providers: r.Providers,
+		})
+	}
+
+	return &protoapi.FindSimilarResponse{
+		Results: protoResults,
+	}, nil
+}
+
+// GetArtwork implements CotuneService.GetArtwork
+func (s *Server) GetArtwork(ctx context.Context, req *protoapi.ArtworkRequest) (*protoapi.ArtworkResponse, error) {
+	size, err := artwork.ParseSize(req.GetSize())
+	if err != nil {
+		return &protoapi.ArtworkResponse{Error: err.Error()}, nil
+	}
+
+	data, mime, err := s.daemon.GetArtwork(ctx, req.GetCtid(), size)
+	if err != nil {
+		return &protoapi.ArtworkResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.ArtworkResponse{Data: data, MimeType: mime}, nil
+}
+
+// GetWaveform implements CotuneService.GetWaveform
+func (s *Server) GetWaveform(ctx context.Context, req *protoapi.WaveformRequest) (*protoapi.WaveformResponse, error) {
+	peaks, err := s.daemon.GetWaveform(ctx, req.GetCtid(), int(req.GetMaxPeaks()))
+	if err != nil {
+		return &protoapi.WaveformResponse{Error: err.Error()}, nil
+	}
+
+	resp := &protoapi.WaveformResponse{SampleRate: int32(peaks.SampleRate)}
+	for _, level := range peaks.Levels {
+		data := make([]byte, len(level.Peaks))
+		for i, v := range level.Peaks {
+			data[i] = byte(v)
+		}
+		resp.Levels = append(resp.Levels, &protoapi.WaveformLevel{
+			SamplesPerPeak: int32(level.SamplesPerPeak),
+			Peaks:          data,
+		})
+	}
+	return resp, nil
+}
+
+// GetLoudness implements CotuneService.GetLoudness
+func (s *Server) GetLoudness(ctx context.Context, req *protoapi.LoudnessRequest) (*protoapi.LoudnessResponse, error) {
+	if req.GetTrackId() == "" && req.GetCtid() == "" {
+		return &protoapi.LoudnessResponse{Error: "track_id or ctid is required"}, nil
+	}
+
+	l, err := s.daemon.GetLoudness(req.GetTrackId(), req.GetCtid())
+	if err != nil {
+		return &protoapi.LoudnessResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.LoudnessResponse{
+		IntegratedLufs: l.IntegratedLUFS,
+		RangeLu:        l.RangeLU,
+		TruePeakDbtp:   l.TruePeakDBTP,
+		GainDb:         l.GainDB,
+	}, nil
+}
+
 // Fetch implements CotuneService.Fetch
 func (s *Server) Fetch(ctx context.Context, req *protoapi.FetchRequest) (*protoapi.FetchResponse, error) {
+	var header *streaming.StreamHeader
 	var err error
+	fetched := req.GetCtid()
+	opts := streaming.StreamRequest{Format: req.GetFormat(), Bitrate: int(req.GetBitrate())}
+
+	if req.GetOutputPath() == "" && req.GetPeerId() == "" && opts.Format == "" {
+		// Without an output path the daemon keeps the file in its cache
+		track, fetched, err := s.daemon.CacheTrack(ctx, req.GetCtid())
+		if err != nil {
+			return &protoapi.FetchResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+		return &protoapi.FetchResponse{
+			Success:     true,
+			Path:        track.Path,
+			FetchedCtid: fetched,
+		}, nil
+	}
+	if req.GetOutputPath() == "" {
+		return &protoapi.FetchResponse{
+			Success: false,
+			Error:   "output_path is required when fetching from a peer or transcoding",
+		}, nil
+	}
 
 	if req.GetPeerId() != "" {
 		// Fetch from specific peer
@@ -231,10 +381,10 @@ func (s *Server) Fetch(ctx context.Context, req *protoapi.FetchRequest) (*protoa
 				Error:   fmt.Sprintf("invalid peer ID: %v", err2),
 			}, nil
 		}
-		err = s.daemon.FetchTrackFromPeer(ctx, pid, req.GetCtid(), req.GetOutputPath())
+		header, err = s.daemon.FetchTrackFromPeerTranscoded(ctx, pid, req.GetCtid(), req.GetOutputPath(), opts)
 	} else {
 		// Fetch from network
-		err = s.daemon.FetchTrack(ctx, req.GetCtid(), req.GetOutputPath())
+		header, fetched, err = s.daemon.FetchTrackTranscoded(ctx, req.GetCtid(), req.GetOutputPath(), opts)
 	}
 
 	if err != nil {
@@ -244,10 +394,38 @@ func (s *Server) Fetch(ctx context.Context, req *protoapi.FetchRequest) (*protoa
 		}, nil
 	}
 
-	return &protoapi.FetchResponse{
-		Success: true,
-		Path:    req.GetOutputPath(),
-	}, nil
+	resp := &protoapi.FetchResponse{
+		Success:     true,
+		Path:        req.GetOutputPath(),
+		FetchedCtid: fetched,
+	}
+	if header != nil {
+		resp.Transcoded = header.Transcoded
+		resp.Format = header.Format
+		resp.Bitrate = int32(header.Bitrate)
+		resp.MimeType = header.MIMEType
+	}
+	return resp, nil
+}
+
+// TranscodeProfiles implements CotuneService.TranscodeProfiles
+func (s *Server) TranscodeProfiles(ctx context.Context, req *protoapi.TranscodeProfilesRequest) (*protoapi.TranscodeProfilesResponse, error) {
+	profiles, err := s.daemon.TranscodeProfiles(ctx, req.GetPeerId())
+	if err != nil {
+		return &protoapi.TranscodeProfilesResponse{Error: err.Error()}, nil
+	}
+
+	resp := &protoapi.TranscodeProfilesResponse{}
+	for _, p := range profiles {
+		resp.Profiles = append(resp.Profiles, &protoapi.TranscodeProfile{
+			Format:         p.Format,
+			MimeType:       p.MIMEType,
+			MinBitrate:     int32(p.MinBitrate),
+			MaxBitrate:     int32(p.MaxBitrate),
+			DefaultBitrate: int32(p.DefaultBitrate),
+		})
+	}
+	return resp, nil
 }
 
 // Share implements CotuneService.Share
@@ -278,7 +456,7 @@ func (s *Server) Share(ctx context.Context, req *protoapi.ShareRequest) (*protoa
 		title = ""
 		artist = ""
 	}
-	track, addErr := s.daemon.AddTrack(ctx, path, title, artist)
+	track, addErr := s.daemon.AddTrack(ctx, path, title, artist, req.GetInPlace())
 	if addErr != nil {
 		return &protoapi.ShareResponse{
 			Success: false,
@@ -289,8 +467,8 @@ func (s *Server) Share(ctx context.Context, req *protoapi.ShareRequest) (*protoa
 	shareErr := s.daemon.ShareTrack(ctx, track.ID)
 	if shareErr != nil {
 		shareMsg := shareErr.Error()
-		if strings.Contains(shareMsg, "ffmpeg not found") {
-			shareMsg = "CTID calculation failed: this file format requires ffmpeg. On Android use MP3/WAV or install build with bundled ffmpeg."
+		if errors.Is(shareErr, audio.ErrFFmpegNotFound) {
+			shareMsg = "CTID calculation failed: this file could not be decoded natively and ffmpeg is not available. Built-in decoders cover MP3, WAV, FLAC, Ogg Vorbis and Opus."
 		}
 		return &protoapi.ShareResponse{
 			Success: false,
@@ -304,6 +482,452 @@ func (s *Server) Share(ctx context.Context, req *protoapi.ShareRequest) (*protoa
 	}, nil
 }
 
+// DeleteTrack implements CotuneService.DeleteTrack
+func (s *Server) DeleteTrack(ctx context.Context, req *protoapi.DeleteTrackRequest) (*protoapi.DeleteTrackResponse, error) {
+	if err := s.daemon.DeleteTrack(req.GetTrackId(), req.GetDeleteFile()); err != nil {
+		return &protoapi.DeleteTrackResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.DeleteTrackResponse{Success: true}, nil
+}
+
+// UpdateTrackMetadata implements CotuneService.UpdateTrackMetadata
+func (s *Server) UpdateTrackMetadata(ctx context.Context, req *protoapi.UpdateTrackMetadataRequest) (*protoapi.UpdateTrackMetadataResponse, error) {
+	update, err := metadataUpdate(req)
+	if err != nil {
+		return &protoapi.UpdateTrackMetadataResponse{Error: err.Error()}, nil
+	}
+	track, err := s.daemon.UpdateTrackMetadata(ctx, req.GetTrackId(), update)
+	if err != nil {
+		return &protoapi.UpdateTrackMetadataResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.UpdateTrackMetadataResponse{Success: true, Recognized: track.Recognized}, nil
+}
+
+// metadataUpdate picks the fields a request sets: those listed in fields,
+// or every non-empty one when it lists none
+func metadataUpdate(req *protoapi.UpdateTrackMetadataRequest) (daemon.MetadataUpdate, error) {
+	title, artist, album, genre := req.GetTitle(), req.GetArtist(), req.GetAlbum(), req.GetGenre()
+	trackNumber, year := int(req.GetTrackNumber()), int(req.GetYear())
+
+	var update daemon.MetadataUpdate
+	if len(req.GetFields()) == 0 {
+		if title != "" {
+			update.Title = &title
+		}
+		if artist != "" {
+			update.Artist = &artist
+		}
+		if album != "" {
+			update.Album = &album
+		}
+		if trackNumber != 0 {
+			update.TrackNumber = &trackNumber
+		}
+		if year != 0 {
+			update.Year = &year
+		}
+		if genre != "" {
+			update.Genre = &genre
+		}
+		return update, nil
+	}
+
+	for _, field := range req.GetFields() {
+		switch field {
+		case "title":
+			update.Title = &title
+		case "artist":
+			update.Artist = &artist
+		case "album":
+			update.Album = &album
+		case "track_number":
+			update.TrackNumber = &trackNumber
+		case "year":
+			update.Year = &year
+		case "genre":
+			update.Genre = &genre
+		default:
+			return update, fmt.Errorf("unknown field: %s", field)
+		}
+	}
+	return update, nil
+}
+
+// UnshareTrack implements CotuneService.UnshareTrack
+func (s *Server) UnshareTrack(ctx context.Context, req *protoapi.UnshareTrackRequest) (*protoapi.UnshareTrackResponse, error) {
+	if err := s.daemon.UnshareTrack(req.GetTrackId()); err != nil {
+		return &protoapi.UnshareTrackResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.UnshareTrackResponse{Success: true}, nil
+}
+
+// CreatePlaylist implements CotuneService.CreatePlaylist
+func (s *Server) CreatePlaylist(ctx context.Context, req *protoapi.CreatePlaylistRequest) (*protoapi.PlaylistResponse, error) {
+	pl, err := s.daemon.CreatePlaylist(req.GetName(), req.GetDescription(), req.GetCtids())
+	if err != nil {
+		return &protoapi.PlaylistResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.PlaylistResponse{Playlist: toProtoPlaylist(pl)}, nil
+}
+
+// ListPlaylists implements CotuneService.ListPlaylists
+func (s *Server) ListPlaylists(ctx context.Context, req *protoapi.ListPlaylistsRequest) (*protoapi.ListPlaylistsResponse, error) {
+	playlists, err := s.daemon.ListPlaylists()
+	if err != nil {
+		return &protoapi.ListPlaylistsResponse{Error: err.Error()}, nil
+	}
+	resp := &protoapi.ListPlaylistsResponse{Playlists: make([]*protoapi.Playlist, 0, len(playlists))}
+	for _, pl := range playlists {
+		resp.Playlists = append(resp.Playlists, toProtoPlaylist(pl))
+	}
+	return resp, nil
+}
+
+// UpdatePlaylist implements CotuneService.UpdatePlaylist
+func (s *Server) UpdatePlaylist(ctx context.Context, req *protoapi.UpdatePlaylistRequest) (*protoapi.PlaylistResponse, error) {
+	name, description, ctids := req.GetName(), req.GetDescription(), req.GetCtids()
+
+	var update daemon.PlaylistUpdate
+	if len(req.GetFields()) == 0 {
+		if name != "" {
+			update.Name = &name
+		}
+		if description != "" {
+			update.Description = &description
+		}
+		if len(ctids) > 0 {
+			update.CTIDs = &ctids
+		}
+	}
+	for _, field := range req.GetFields() {
+		switch field {
+		case "name":
+			update.Name = &name
+		case "description":
+			update.Description = &description
+		case "ctids":
+			update.CTIDs = &ctids
+		default:
+			return &protoapi.PlaylistResponse{Error: fmt.Sprintf("unknown field: %s", field)}, nil
+		}
+	}
+
+	pl, err := s.daemon.UpdatePlaylist(ctx, req.GetPlaylistId(), update)
+	if err != nil {
+		return &protoapi.PlaylistResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.PlaylistResponse{Playlist: toProtoPlaylist(pl)}, nil
+}
+
+// DeletePlaylist implements CotuneService.DeletePlaylist
+func (s *Server) DeletePlaylist(ctx context.Context, req *protoapi.DeletePlaylistRequest) (*protoapi.DeletePlaylistResponse, error) {
+	if err := s.daemon.DeletePlaylist(req.GetPlaylistId()); err != nil {
+		return &protoapi.DeletePlaylistResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.DeletePlaylistResponse{Success: true}, nil
+}
+
+// SharePlaylist implements CotuneService.SharePlaylist
+func (s *Server) SharePlaylist(ctx context.Context, req *protoapi.SharePlaylistRequest) (*protoapi.SharePlaylistResponse, error) {
+	recordID, err := s.daemon.SharePlaylist(ctx, req.GetPlaylistId())
+	if err != nil {
+		return &protoapi.SharePlaylistResponse{RecordId: recordID, Error: err.Error()}, nil
+	}
+	return &protoapi.SharePlaylistResponse{Success: true, RecordId: recordID}, nil
+}
+
+// UnsharePlaylist implements CotuneService.UnsharePlaylist
+func (s *Server) UnsharePlaylist(ctx context.Context, req *protoapi.UnsharePlaylistRequest) (*protoapi.UnsharePlaylistResponse, error) {
+	if err := s.daemon.UnsharePlaylist(req.GetPlaylistId()); err != nil {
+		return &protoapi.UnsharePlaylistResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.UnsharePlaylistResponse{Success: true}, nil
+}
+
+// OpenPlaylist implements CotuneService.OpenPlaylist
+func (s *Server) OpenPlaylist(ctx context.Context, req *protoapi.OpenPlaylistRequest) (*protoapi.OpenPlaylistResponse, error) {
+	opened, err := s.daemon.OpenPlaylist(ctx, req.GetRecordId(), req.GetSave())
+	if err != nil {
+		return &protoapi.OpenPlaylistResponse{RecordId: req.GetRecordId(), Error: err.Error()}, nil
+	}
+
+	resp := &protoapi.OpenPlaylistResponse{
+		RecordId:    opened.RecordID,
+		Name:        opened.Name,
+		Description: opened.Description,
+		Author:      opened.Author,
+		Entries:     make([]*protoapi.PlaylistEntry, 0, len(opened.Entries)),
+	}
+	for _, e := range opened.Entries {
+		entry := toProtoPlaylistEntry(e.PlaylistEntry)
+		entry.Local = e.Local
+		entry.Providers = e.Providers
+		resp.Entries = append(resp.Entries, entry)
+	}
+	if opened.Saved != nil {
+		resp.Saved = toProtoPlaylist(opened.Saved)
+	}
+	return resp, nil
+}
+
+// Backup implements CotuneService.Backup
+func (s *Server) Backup(ctx context.Context, req *protoapi.BackupRequest) (*protoapi.BackupResponse, error) {
+	if req.GetOutputPath() == "" {
+		return &protoapi.BackupResponse{Error: "output_path is required"}, nil
+	}
+	path, err := s.daemon.ExportPath(req.GetOutputPath())
+	if err != nil {
+		return &protoapi.BackupResponse{Error: err.Error()}, nil
+	}
+	manifest, err := s.daemon.BackupToFile(path, backup.Options{
+		IncludeMedia: req.GetIncludeMedia(),
+		Secret:       fromProtoSecret(req.GetSecret()),
+		Plaintext:    req.GetPlaintext(),
+	})
+	if err != nil {
+		return &protoapi.BackupResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.BackupResponse{
+		Success:    true,
+		Path:       path,
+		Tracks:     int32(manifest.Tracks),
+		Playlists:  int32(manifest.Playlists),
+		MediaFiles: int32(manifest.MediaFiles),
+		Sealed:     manifest.Vault != nil,
+	}, nil
+}
+
+// ExportLibrary implements CotuneService.ExportLibrary
+func (s *Server) ExportLibrary(ctx context.Context, req *protoapi.ExportLibraryRequest) (*protoapi.ExportLibraryResponse, error) {
+	if req.GetOutputPath() == "" {
+		return &protoapi.ExportLibraryResponse{Error: "output_path is required"}, nil
+	}
+	path, err := s.daemon.ExportPath(req.GetOutputPath())
+	if err != nil {
+		return &protoapi.ExportLibraryResponse{Error: err.Error()}, nil
+	}
+	if err := s.daemon.ExportLibraryFile(path, req.GetFormat(), req.GetPlaylistId()); err != nil {
+		return &protoapi.ExportLibraryResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.ExportLibraryResponse{Success: true, Path: path}, nil
+}
+
+// ImportLibrary implements CotuneService.ImportLibrary
+func (s *Server) ImportLibrary(ctx context.Context, req *protoapi.ImportLibraryRequest) (*protoapi.ImportLibraryResponse, error) {
+	if req.GetPath() == "" {
+		return &protoapi.ImportLibraryResponse{Error: "path is required"}, nil
+	}
+	path, err := s.daemon.ExportPath(req.GetPath())
+	if err != nil {
+		return &protoapi.ImportLibraryResponse{Error: err.Error()}, nil
+	}
+	result, err := s.daemon.ImportLibraryFile(ctx, path, req.GetFormat())
+	if err != nil {
+		return &protoapi.ImportLibraryResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.ImportLibraryResponse{
+		Success:   true,
+		Tracks:    int32(result.Tracks),
+		Playlists: int32(result.Playlists),
+		Existing:  int32(result.Existing),
+		Missing:   int32(result.Missing),
+	}, nil
+}
+
+// WatchFolders implements CotuneService.WatchFolders
+func (s *Server) WatchFolders(ctx context.Context, req *protoapi.WatchFoldersRequest) (*protoapi.WatchFoldersResponse, error) {
+	return toProtoWatchStatus(s.daemon.WatchStatus()), nil
+}
+
+// AddWatchFolder implements CotuneService.AddWatchFolder
+func (s *Server) AddWatchFolder(ctx context.Context, req *protoapi.AddWatchFolderRequest) (*protoapi.WatchFoldersResponse, error) {
+	status, err := s.daemon.AddWatchFolder(req.GetPath())
+	if err != nil {
+		resp := toProtoWatchStatus(s.daemon.WatchStatus())
+		resp.Success, resp.Error = false, err.Error()
+		return resp, nil
+	}
+	return toProtoWatchStatus(status), nil
+}
+
+// RemoveWatchFolder implements CotuneService.RemoveWatchFolder
+func (s *Server) RemoveWatchFolder(ctx context.Context, req *protoapi.RemoveWatchFolderRequest) (*protoapi.WatchFoldersResponse, error) {
+	status, err := s.daemon.RemoveWatchFolder(req.GetPath())
+	if err != nil {
+		resp := toProtoWatchStatus(s.daemon.WatchStatus())
+		resp.Success, resp.Error = false, err.Error()
+		return resp, nil
+	}
+	return toProtoWatchStatus(status), nil
+}
+
+func toProtoWatchStatus(status *daemon.WatchStatus) *protoapi.WatchFoldersResponse {
+	return &protoapi.WatchFoldersResponse{
+		Success:    true,
+		Folders:    status.Folders,
+		Scanning:   status.Scanning,
+		Found:      int32(status.Found),
+		Scanned:    int32(status.Scanned),
+		Imported:   int32(status.Imported),
+		Moved:      int32(status.Moved),
+		Removed:    int32(status.Removed),
+		Duplicates: int32(status.Duplicates),
+		Failed:     int32(status.Failed),
+		LastScan:   status.LastScan,
+	}
+}
+
+// Unlock implements CotuneService.Unlock; the daemon is already unlocked
+func (s *Server) Unlock(ctx context.Context, req *protoapi.UnlockRequest) (*protoapi.UnlockResponse, error) {
+	return &protoapi.UnlockResponse{Error: "daemon is not locked"}, nil
+}
+
+// Lock implements CotuneService.Lock
+func (s *Server) Lock(ctx context.Context, req *protoapi.LockRequest) (*protoapi.LockResponse, error) {
+	if err := s.daemon.Lock(); err != nil {
+		return &protoapi.LockResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.LockResponse{Success: true}, nil
+}
+
+// VaultStatus implements CotuneService.VaultStatus
+func (s *Server) VaultStatus(ctx context.Context, req *protoapi.VaultStatusRequest) (*protoapi.VaultStatusResponse, error) {
+	return toProtoVaultStatus(s.daemon.VaultStatus(), nil), nil
+}
+
+// EnableEncryption implements CotuneService.EnableEncryption
+func (s *Server) EnableEncryption(ctx context.Context, req *protoapi.EnableEncryptionRequest) (*protoapi.VaultStatusResponse, error) {
+	status, err := s.daemon.EnableEncryption(fromProtoSecret(req.GetSecret()))
+	return toProtoVaultStatus(status, err), nil
+}
+
+// ChangeVaultSecret implements CotuneService.ChangeVaultSecret
+func (s *Server) ChangeVaultSecret(ctx context.Context, req *protoapi.ChangeVaultSecretRequest) (*protoapi.VaultStatusResponse, error) {
+	status, err := s.daemon.ChangeVaultSecret(fromProtoSecret(req.GetOldSecret()), fromProtoSecret(req.GetNewSecret()))
+	return toProtoVaultStatus(status, err), nil
+}
+
+// RotateVaultKey implements CotuneService.RotateVaultKey
+func (s *Server) RotateVaultKey(ctx context.Context, req *protoapi.RotateVaultKeyRequest) (*protoapi.VaultStatusResponse, error) {
+	status, err := s.daemon.RotateVaultKey(fromProtoSecret(req.GetSecret()))
+	return toProtoVaultStatus(status, err), nil
+}
+
+// lockedService answers for a daemon started locked; every RPC but Status
+// and Unlock is unimplemented until it is unlocked
+type lockedService struct {
+	protoapi.UnimplementedCotuneServiceServer
+	unlock func(vault.Secret) error
+}
+
+// Status implements CotuneService.Status
+func (l *lockedService) Status(ctx context.Context, req *protoapi.StatusRequest) (*protoapi.StatusResponse, error) {
+	return &protoapi.StatusResponse{
+		Version: "1.0.0",
+		Locked:  true,
+	}, nil
+}
+
+// Unlock implements CotuneService.Unlock
+func (l *lockedService) Unlock(ctx context.Context, req *protoapi.UnlockRequest) (*protoapi.UnlockResponse, error) {
+	if err := l.unlock(fromProtoSecret(req.GetSecret())); err != nil {
+		return &protoapi.UnlockResponse{Error: err.Error()}, nil
+	}
+	return &protoapi.UnlockResponse{Success: true}, nil
+}
+
+func fromProtoSecret(secret *protoapi.VaultSecret) vault.Secret {
+	return vault.Secret{Passphrase: secret.GetPassphrase(), Key: secret.GetKey()}
+}
+
+func toProtoVaultStatus(status daemon.VaultStatus, err error) *protoapi.VaultStatusResponse {
+	out := &protoapi.VaultStatusResponse{
+		Success:   err == nil,
+		Encrypted: status.Encrypted,
+	}
+	if status.Info != nil {
+		out.Kdf = status.KDF
+		out.KeyId = status.KeyID
+		out.Rotating = status.Rotating
+		out.CreatedAt = status.CreatedAt
+		out.RotatedAt = status.RotatedAt
+	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out
+}
+
+func toProtoPlaylist(pl *models.Playlist) *protoapi.Playlist {
+	out := &protoapi.Playlist{
+		Id:          pl.ID,
+		Name:        pl.Name,
+		Description: pl.Description,
+		Entries:     make([]*protoapi.PlaylistEntry, 0, len(pl.Entries)),
+		Author:      pl.Author,
+		Shared:      pl.Shared,
+		RecordId:    pl.RecordID,
+		CreatedAt:   pl.CreatedAt,
+		UpdatedAt:   pl.UpdatedAt,
+	}
+	for _, entry := range pl.Entries {
+		out.Entries = append(out.Entries, toProtoPlaylistEntry(entry))
+	}
+	return out
+}
+
+func toProtoPlaylistEntry(entry models.PlaylistEntry) *protoapi.PlaylistEntry {
+	return &protoapi.PlaylistEntry{
+		Ctid:       entry.CTID,
+		Title:      entry.Title,
+		Artist:     entry.Artist,
+		DurationMs: entry.DurationMs,
+	}
+}
+
+// ListJobs implements CotuneService.ListJobs
+func (s *Server) ListJobs(ctx context.Context, req *protoapi.ListJobsRequest) (*protoapi.ListJobsResponse, error) {
+	jobs, err := s.daemon.ListJobs(models.JobState(req.GetState()))
+	if err != nil {
+		return &protoapi.ListJobsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	protoJobs := make([]*protoapi.Job, 0, len(jobs))
+	for _, job := range jobs {
+		protoJobs = append(protoJobs, &protoapi.Job{
+			Id:          job.ID,
+			TrackId:     job.TrackID,
+			State:       string(job.State),
+			Attempts:    int32(job.Attempts),
+			LastError:   job.LastError,
+			NextAttempt: job.NextAttempt,
+			CreatedAt:   job.CreatedAt,
+			UpdatedAt:   job.UpdatedAt,
+		})
+	}
+
+	return &protoapi.ListJobsResponse{
+		Jobs: protoJobs,
+	}, nil
+}
+
+// RetryJobs implements CotuneService.RetryJobs
+func (s *Server) RetryJobs(ctx context.Context, req *protoapi.RetryJobsRequest) (*protoapi.RetryJobsResponse, error) {
+	retried, err := s.daemon.RetryJobs(req.GetJobIds())
+	if err != nil {
+		return &protoapi.RetryJobsResponse{
+			Retried: int32(retried),
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &protoapi.RetryJobsResponse{
+		Retried: int32(retried),
+	}, nil
+}
+
 // Announce implements CotuneService.Announce
 func (s *Server) Announce(ctx context.Context, req *protoapi.AnnounceRequest) (*protoapi.AnnounceResponse, error) {
 	// Trigger manual announce (daemon has announceLoop that does this automatically)
@@ -0,0 +1,217 @@
+package artwork
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // registered for image.Decode
+	"image/jpeg"
+	_ "image/png" // registered for image.Decode
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Size selects a rendition of a cover
+type Size string
+
+const (
+	SizeOriginal Size = "original"
+	SizeSmall    Size = "small"  // list rows
+	SizeMedium   Size = "medium" // player and search result cards
+)
+
+// thumbnailEdges is the longest edge in pixels of each generated thumbnail
+var thumbnailEdges = map[Size]int{
+	SizeSmall:  96,
+	SizeMedium: 300,
+}
+
+// MaxBytes bounds an original cover; larger embedded pictures are not stored
+const MaxBytes = 8 << 20
+
+// ErrNotFound is returned for covers the store does not hold
+var ErrNotFound = errors.New("artwork not found")
+
+// Store keeps cover art content-addressed by the SHA256 of the original
+// image under <data dir>/artwork, alongside JPEG thumbnails generated when
+// the cover is added. Identical covers shared by many tracks are stored once.
+type Store struct {
+	dir string
+}
+
+// New creates an artwork store under dataDir
+func New(dataDir string) (*Store, error) {
+	dir := filepath.Join(dataDir, "artwork")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create artwork dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// ParseSize validates a size name; empty means SizeMedium
+func ParseSize(name string) (Size, error) {
+	size := Size(name)
+	if name == "" {
+		return SizeMedium, nil
+	}
+	if _, ok := thumbnailEdges[size]; ok || size == SizeOriginal {
+		return size, nil
+	}
+	return "", fmt.Errorf("unknown artwork size: %s", name)
+}
+
+// Put stores a cover and its thumbnails and returns its hash. Storing a
+// cover that is already present is a no-op. Images that cannot be decoded
+// are kept without thumbnails; Get then serves the original for every size.
+func (s *Store) Put(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("empty artwork")
+	}
+	if len(data) > MaxBytes {
+		return "", fmt.Errorf("artwork too large: %d bytes", len(data))
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	original := s.path(hash, SizeOriginal)
+	if _, err := os.Stat(original); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(original), 0755); err != nil {
+		return "", fmt.Errorf("failed to create artwork dir: %w", err)
+	}
+
+	// Thumbnails first: a present original means the set is complete
+	if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+		for size, edge := range thumbnailEdges {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, thumbnail(img, edge), &jpeg.Options{Quality: 85}); err != nil {
+				return "", fmt.Errorf("failed to encode thumbnail: %w", err)
+			}
+			if err := writeFile(s.path(hash, size), buf.Bytes()); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := writeFile(original, data); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// Get returns a rendition of a cover and its MIME type
+func (s *Store) Get(hash string, size Size) ([]byte, string, error) {
+	if !validHash(hash) {
+		return nil, "", ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(hash, size))
+	if errors.Is(err, os.ErrNotExist) && size != SizeOriginal {
+		// Undecodable originals have no thumbnails
+		data, err = os.ReadFile(s.path(hash, SizeOriginal))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read artwork: %w", err)
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+// Has reports whether the store holds a cover
+func (s *Store) Has(hash string) bool {
+	if !validHash(hash) {
+		return false
+	}
+	_, err := os.Stat(s.path(hash, SizeOriginal))
+	return err == nil
+}
+
+// path lays covers out as <dir>/<first two hex digits>/<hash>[_<size>.jpg]
+func (s *Store) path(hash string, size Size) string {
+	name := hash
+	if size != SizeOriginal {
+		name = fmt.Sprintf("%s_%s.jpg", hash, size)
+	}
+	return filepath.Join(s.dir, hash[:2], name)
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// writeFile writes through a temporary file so readers never see a partial
+// image
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".artwork-*")
+	if err != nil {
+		return fmt.Errorf("failed to write artwork: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write artwork: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write artwork: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write artwork: %w", err)
+	}
+	return nil
+}
+
+// thumbnail scales img to fit within edge x edge by averaging the source
+// pixels under each target pixel. Images are never enlarged. Transparent
+// areas are flattened onto white since JPEG has no alpha.
+func thumbnail(img image.Image, edge int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > edge || srcH > edge {
+		if srcW >= srcH {
+			dstW, dstH = edge, max(1, srcH*edge/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*edge/srcH), edge
+		}
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					r += int(row[sx*4])
+					g += int(row[sx*4+1])
+					b += int(row[sx*4+2])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,128 @@
+package artwork
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 200, G: 40, B: 40, A: 0xff}
+			if x >= w/2 {
+				c = color.NRGBA{A: 0} // transparent half
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPutStoresOriginalAndThumbnails(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	data := testPNG(t, 600, 400)
+
+	hash, err := store.Put(data)
+	if err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	if again, err := store.Put(data); err != nil || again != hash {
+		t.Fatalf("Put() again = %s, %v; want %s", again, err, hash)
+	}
+	if !store.Has(hash) {
+		t.Fatal("Has() = false after Put()")
+	}
+
+	original, mime, err := store.Get(hash, SizeOriginal)
+	if err != nil || mime != "image/png" || !bytes.Equal(original, data) {
+		t.Fatalf("Get(original) = %d bytes %s, %v; want the PNG", len(original), mime, err)
+	}
+
+	for size, edge := range thumbnailEdges {
+		thumb, mime, err := store.Get(hash, size)
+		if err != nil || mime != "image/jpeg" {
+			t.Fatalf("Get(%s) = %s, %v; want JPEG", size, mime, err)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(thumb))
+		if err != nil {
+			t.Fatalf("jpeg.Decode(%s) error: %v", size, err)
+		}
+		if b := img.Bounds(); b.Dx() != edge || b.Dy() != edge*2/3 {
+			t.Fatalf("%s thumbnail = %dx%d, want %dx%d", size, b.Dx(), b.Dy(), edge, edge*2/3)
+		}
+		// Transparent pixels are flattened onto white
+		if r, g, b, _ := img.At(img.Bounds().Dx()-1, 0).RGBA(); r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+			t.Fatalf("%s thumbnail transparent corner = %d,%d,%d, want white", size, r>>8, g>>8, b>>8)
+		}
+	}
+}
+
+func TestSmallCoversAreNotEnlarged(t *testing.T) {
+	img := thumbnail(image.NewRGBA(image.Rect(0, 0, 50, 20)), 300)
+	if b := img.Bounds(); b.Dx() != 50 || b.Dy() != 20 {
+		t.Fatalf("thumbnail() = %dx%d, want 50x20", b.Dx(), b.Dy())
+	}
+}
+
+func TestUndecodableCoverServesOriginal(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	data := []byte("RIFF\x00\x00\x00\x00WEBPVP8 not decodable here")
+	hash, err := store.Put(data)
+	if err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	got, _, err := store.Get(hash, SizeSmall)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Get(small) = %q, %v; want the original", got, err)
+	}
+}
+
+func TestGetRejectsUnknownAndMalformedHashes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret"), []byte("x"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	for _, hash := range []string{"", "../secret", "00" + string(make([]byte, 62)), "ab"} {
+		if _, _, err := store.Get(hash, SizeOriginal); err != ErrNotFound {
+			t.Fatalf("Get(%q) error = %v, want ErrNotFound", hash, err)
+		}
+	}
+	missing := "0000000000000000000000000000000000000000000000000000000000000000"
+	if _, _, err := store.Get(missing, SizeMedium); err != ErrNotFound {
+		t.Fatalf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for name, want := range map[string]Size{"": SizeMedium, "small": SizeSmall, "original": SizeOriginal} {
+		if got, err := ParseSize(name); err != nil || got != want {
+			t.Fatalf("ParseSize(%q) = %s, %v; want %s", name, got, err, want)
+		}
+	}
+	if _, err := ParseSize("huge"); err == nil {
+		t.Fatal("ParseSize(huge) error = nil, want error")
+	}
+}
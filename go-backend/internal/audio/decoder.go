@@ -3,10 +3,8 @@ package audio
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
 
 	"github.com/go-audio/wav"
 	mp3 "github.com/hajimehoshi/go-mp3"
@@ -21,39 +19,122 @@ const (
 	TargetBitDepth = 16
 )
 
-// DecodeAudioToPCM decodes an audio file to normalized PCM
+// Version identifies a PCM normalization algorithm. CTIDs hash normalized
+// PCM, so any change to decoding or resampling needs a new version.
+type Version int
+
+const (
+	// V1 treats every MP3 as 44.1kHz and resamples by linear interpolation;
+	// FLAC and Ogg are decoded and converted by ffmpeg
+	V1 Version = 1
+	// V2 uses the real stream parameters and a windowed-sinc resampler with
+	// integer arithmetic, giving identical output on every platform
+	V2 Version = 2
+	// LatestVersion is used for newly computed CTIDs
+	LatestVersion = V2
+)
+
+// DecodeAudioToPCM decodes an audio file to PCM normalized with LatestVersion
 func DecodeAudioToPCM(ctx context.Context, filePath string) ([]int16, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
-
-	switch ext {
-	case ".mp3":
-		return decodeMP3(ctx, filePath)
-	case ".wav":
-		return decodeWAV(ctx, filePath)
-	case ".flac", ".aac", ".ogg", ".m4a":
-		// Use ffmpeg for formats not directly supported
-		return decodeWithFFmpeg(ctx, filePath)
+	return DecodeAudioToPCMVersion(ctx, filePath, LatestVersion)
+}
+
+// DecodeAudioToPCMVersion decodes an audio file to PCM normalized with the
+// given algorithm version. The decoder is chosen by probing the file content,
+// so the file name does not matter.
+func DecodeAudioToPCMVersion(ctx context.Context, filePath string, version Version) ([]int16, error) {
+	format, err := ProbeFile(filePath)
+	if err != nil {
+		// Final fallback: ffmpeg may know formats the probe does not
+		return withFFmpegFallback(ctx, filePath, nil, version, func(context.Context, string, Version) ([]int16, error) {
+			return nil, err
+		})
+	}
+
+	pcm, err := decodeFormat(ctx, filePath, format, version)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", format, err)
+	}
+	return pcm, nil
+}
+
+// decodeFormat runs the decoder for a probed format
+func decodeFormat(ctx context.Context, filePath string, format *Format, version Version) ([]int16, error) {
+	switch {
+	case format.Container == ContainerWAV && format.Codec == CodecPCM:
+		return decodeWAV(ctx, filePath, version)
+	case format.Container == ContainerMPEG && format.Codec == CodecMP3:
+		return decodeMP3(ctx, filePath, version)
+	case version == V1 && (format.Container == ContainerFLAC || format.Container == ContainerOgg):
+		// V1 hashed what ffmpeg made of these, resampling and downmix included
+		return decodeWithFFmpeg(ctx, filePath, format, version)
+	case format.Container == ContainerFLAC:
+		return withFFmpegFallback(ctx, filePath, format, version, decodeFLAC)
+	case format.Container == ContainerOgg && format.Codec == CodecVorbis:
+		return withFFmpegFallback(ctx, filePath, format, version, decodeVorbis)
+	case format.Container == ContainerOgg && format.Codec == CodecOpus:
+		return withFFmpegFallback(ctx, filePath, format, version, decodeOpus)
 	default:
-		// Unknown extension: try built-in decoders first (important on Android
-		// where picker-provided filenames may miss extension).
-		if pcm, err := decodeWAV(ctx, filePath); err == nil {
-			return pcm, nil
+		// No pure Go decoder (AAC, ALAC, MP4 audio, MPEG layer I/II, ...)
+		return decodeWithFFmpeg(ctx, filePath, format, version)
+	}
+}
+
+// withFFmpegFallback runs a native decoder and falls back to ffmpeg if it fails
+func withFFmpegFallback(ctx context.Context, filePath string, format *Format, version Version, decode func(context.Context, string, Version) ([]int16, error)) ([]int16, error) {
+	pcm, err := decode(ctx, filePath, version)
+	if err == nil {
+		return pcm, nil
+	}
+	pcm, ffErr := decodeWithFFmpeg(ctx, filePath, format, version)
+	if ffErr != nil {
+		return nil, fmt.Errorf("%v; fallback failed: %w", err, ffErr)
+	}
+	return pcm, nil
+}
+
+// decodeSource reads a sample source to the end and normalizes the result
+func decodeSource(ctx context.Context, src sampleSource, version Version) ([]int16, error) {
+	defer src.Close()
+
+	var pcm []int16
+	buf := make([]int16, streamChunkSamples)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		n, err := src.readSamples(buf)
+		pcm = append(pcm, buf[:n]...)
+		if err == io.EOF {
+			break
 		}
-		if pcm, err := decodeMP3(ctx, filePath); err == nil {
-			return pcm, nil
+		if err != nil {
+			return nil, err
 		}
-		// Final fallback: ffmpeg for other codecs.
-		return decodeWithFFmpeg(ctx, filePath)
 	}
+
+	return normalize(pcm, src.sampleRate(), src.channels(), version)
+}
+
+// normalize converts fully decoded interleaved PCM to the target format
+func normalize(pcm []int16, srcRate, srcChannels int, version Version) ([]int16, error) {
+	if version == V1 {
+		return resampleToTarget(pcm, srcRate, TargetSampleRate, srcChannels, TargetChannels)
+	}
+	n := newNormalizer(version, srcRate, TargetSampleRate, srcChannels, TargetChannels)
+	return n.finish(n.push(pcm, nil)), nil
 }
 
 // decodeMP3 decodes MP3 file to PCM
-func decodeMP3(ctx context.Context, filePath string) ([]int16, error) {
-	return decodeMP3Hajimehoshi(ctx, filePath)
+func decodeMP3(ctx context.Context, filePath string, version Version) ([]int16, error) {
+	return decodeMP3Hajimehoshi(ctx, filePath, version)
 }
 
 // decodeMP3Hajimehoshi uses hajimehoshi/go-mp3 (pure Go)
-func decodeMP3Hajimehoshi(ctx context.Context, filePath string) ([]int16, error) {
+func decodeMP3Hajimehoshi(ctx context.Context, filePath string, version Version) ([]int16, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -92,13 +173,12 @@ func decodeMP3Hajimehoshi(ctx context.Context, filePath string) ([]int16, error)
 		}
 	}
 
-	// MP3 decoder doesn't provide sample rate/channels info easily
-	// Assume 44.1kHz stereo and resample
-	return resampleToTarget(pcm, 44100, TargetSampleRate, 2, TargetChannels)
+	// go-mp3 always outputs stereo. V1 assumed every stream was 44.1kHz.
+	return normalize(pcm, mp3SampleRate(dec, version), 2, version)
 }
 
 // decodeWAV decodes WAV file to PCM
-func decodeWAV(ctx context.Context, filePath string) ([]int16, error) {
+func decodeWAV(ctx context.Context, filePath string, version Version) ([]int16, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -133,49 +213,24 @@ func decodeWAV(ctx context.Context, filePath string) ([]int16, error) {
 		pcm[i] = int16(sample)
 	}
 
-	return resampleToTarget(pcm, int(format.SampleRate), TargetSampleRate, int(format.NumChannels), TargetChannels)
+	return normalize(pcm, int(format.SampleRate), int(format.NumChannels), version)
 }
 
 // decodeWithFFmpeg uses ffmpeg to decode audio (fallback for unsupported formats)
-func decodeWithFFmpeg(ctx context.Context, filePath string) ([]int16, error) {
-	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return nil, fmt.Errorf("ffmpeg not found: %w", err)
-	}
-
-	// Create temporary output file
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("cotune_pcm_%d.raw", os.Getpid()))
-	defer os.Remove(tmpFile)
-
-	// Use ffmpeg to convert to raw PCM
-	cmd := exec.CommandContext(ctx, "ffmpeg",
-		"-i", filePath,
-		"-f", "s16le", // 16-bit signed little-endian
-		"-ar", "44100", // Sample rate 44.1kHz
-		"-ac", "1", // Mono
-		"-y", // Overwrite output
-		tmpFile,
-	)
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("ffmpeg conversion failed: %w", err)
-	}
-
-	// Read PCM data
-	data, err := os.ReadFile(tmpFile)
+func decodeWithFFmpeg(ctx context.Context, filePath string, format *Format, version Version) ([]int16, error) {
+	src, err := openFFmpegSource(ctx, filePath, format, version)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read PCM file: %w", err)
+		return nil, err
 	}
+	return decodeSource(ctx, src, version)
+}
 
-	// Convert bytes to int16
-	pcm := make([]int16, len(data)/2)
-	for i := 0; i < len(data); i += 2 {
-		if i+1 < len(data) {
-			pcm[i/2] = int16(data[i]) | int16(data[i+1])<<8
-		}
+// mp3SampleRate returns the rate to assume for go-mp3 output
+func mp3SampleRate(dec *mp3.Decoder, version Version) int {
+	if version == V1 {
+		return 44100
 	}
-
-	return pcm, nil
+	return dec.SampleRate()
 }
 
 // resampleToTarget resamples PCM data to target sample rate and channels
@@ -0,0 +1,264 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultFFmpegPath is looked up in PATH unless SetFFmpegPath says otherwise
+const DefaultFFmpegPath = "ffmpeg"
+
+// ffmpegStderrLimit bounds how much of ffmpeg's stderr is kept for errors
+const ffmpegStderrLimit = 8 << 10
+
+var (
+	ffmpegMu   sync.RWMutex
+	ffmpegPath = DefaultFFmpegPath
+)
+
+// ErrFFmpegNotFound is returned when the configured ffmpeg binary cannot be
+// run, which leaves formats without a native decoder undecodable
+var ErrFFmpegNotFound = errors.New("ffmpeg not found")
+
+// SetFFmpegPath sets the ffmpeg binary used as the decoding fallback: a path,
+// or a name looked up in PATH. Empty restores DefaultFFmpegPath.
+func SetFFmpegPath(path string) {
+	if path == "" {
+		path = DefaultFFmpegPath
+	}
+	ffmpegMu.Lock()
+	defer ffmpegMu.Unlock()
+	ffmpegPath = path
+}
+
+// FFmpegPath returns the configured ffmpeg binary
+func FFmpegPath() string {
+	ffmpegMu.RLock()
+	defer ffmpegMu.RUnlock()
+	return ffmpegPath
+}
+
+// lookFFmpeg resolves the configured binary to an executable
+func lookFFmpeg() (string, error) {
+	path, err := exec.LookPath(FFmpegPath())
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFFmpegNotFound, err)
+	}
+	return path, nil
+}
+
+// FFmpegError is a failed ffmpeg run, carrying what ffmpeg reported
+type FFmpegError struct {
+	ExitCode int    // -1 if ffmpeg was killed by a signal
+	Message  string // last line ffmpeg wrote to stderr; empty if none
+	Err      error
+}
+
+func (e *FFmpegError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ffmpeg conversion failed: %v", e.Err)
+	}
+	return fmt.Sprintf("ffmpeg conversion failed (exit %d): %s", e.ExitCode, e.Message)
+}
+
+func (e *FFmpegError) Unwrap() error {
+	return e.Err
+}
+
+// newFFmpegError describes a failed ffmpeg process from its wait error and
+// stderr
+func newFFmpegError(err error, stderr *tailBuffer) *FFmpegError {
+	ffErr := &FFmpegError{ExitCode: -1, Message: stderr.lastLine(), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ffErr.ExitCode = exitErr.ExitCode()
+	}
+	return ffErr
+}
+
+// tailBuffer keeps the last ffmpegStderrLimit bytes written to it. It is
+// written by the goroutine exec starts to copy stderr.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - ffmpegStderrLimit; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last non-empty line, which is where ffmpeg states
+// why it gave up
+func (b *tailBuffer) lastLine() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(string(b.buf)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// FFmpegInfo reports whether the ffmpeg fallback can be used
+type FFmpegInfo struct {
+	Path      string `json:"path"`
+	Available bool   `json:"available"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ProbeFFmpeg runs the configured ffmpeg with -version
+func ProbeFFmpeg(ctx context.Context) FFmpegInfo {
+	info := FFmpegInfo{Path: FFmpegPath()}
+	path, err := lookFFmpeg()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Path = path
+
+	var stderr tailBuffer
+	cmd := exec.CommandContext(ctx, path, "-hide_banner", "-version")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		info.Error = newFFmpegError(err, &stderr).Error()
+		return info
+	}
+	info.Available = true
+	// The first line reads "ffmpeg version <version> Copyright ..."
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	if fields := strings.Fields(string(line)); len(fields) >= 3 && fields[1] == "version" {
+		info.Version = fields[2]
+	}
+	return info
+}
+
+// ffmpegOutput picks the PCM layout to request from ffmpeg. V2 keeps the
+// probed stream parameters so resampling happens in our deterministic
+// resampler; V1 and unprobed files let ffmpeg produce the target directly.
+func ffmpegOutput(format *Format, version Version) (rate, channels int) {
+	if version == V1 || format == nil || format.SampleRate <= 0 || format.Channels <= 0 {
+		return TargetSampleRate, TargetChannels
+	}
+	return format.SampleRate, format.Channels
+}
+
+// ffmpegProcess is a running ffmpeg writing its output to stdout. Reads
+// return the output; once it ends, Read reports how ffmpeg exited. Nothing is
+// written to disk, so concurrent runs cannot see each other's output.
+type ffmpegProcess struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr *tailBuffer
+	waited bool
+}
+
+// startFFmpeg runs the configured ffmpeg with args, which must direct the
+// output to pipe:1
+func startFFmpeg(ctx context.Context, args ...string) (*ffmpegProcess, error) {
+	path, err := lookFFmpeg()
+	if err != nil {
+		return nil, err
+	}
+
+	stderr := &tailBuffer{}
+	cmd := exec.CommandContext(ctx, path, append([]string{"-nostdin", "-hide_banner", "-loglevel", "error"}, args...)...)
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg conversion failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("ffmpeg conversion failed: %w", err)
+	}
+	return &ffmpegProcess{cmd: cmd, stdout: stdout, stderr: stderr}, nil
+}
+
+func (p *ffmpegProcess) Read(buf []byte) (int, error) {
+	n, err := p.stdout.Read(buf)
+	if err == io.EOF && !p.waited {
+		p.waited = true
+		if waitErr := p.cmd.Wait(); waitErr != nil {
+			return n, newFFmpegError(waitErr, p.stderr)
+		}
+	}
+	return n, err
+}
+
+// Close stops ffmpeg if its output was not read to the end
+func (p *ffmpegProcess) Close() error {
+	if p.waited {
+		return nil
+	}
+	p.waited = true
+	p.stdout.Close()
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+	p.cmd.Wait()
+	return nil
+}
+
+// ffmpegSource streams raw PCM decoded by ffmpeg
+type ffmpegSource struct {
+	proc        *ffmpegProcess
+	r           *bufio.Reader
+	raw         []byte
+	rate        int
+	numChannels int
+}
+
+func openFFmpegSource(ctx context.Context, filePath string, format *Format, version Version) (*ffmpegSource, error) {
+	rate, channels := ffmpegOutput(format, version)
+	proc, err := startFFmpeg(ctx,
+		"-i", filePath,
+		"-f", "s16le", // 16-bit signed little-endian
+		"-ar", strconv.Itoa(rate),
+		"-ac", strconv.Itoa(channels),
+		"pipe:1",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ffmpegSource{
+		proc:        proc,
+		r:           bufio.NewReaderSize(proc, 64*1024),
+		rate:        rate,
+		numChannels: channels,
+	}, nil
+}
+
+func (f *ffmpegSource) readSamples(buf []int16) (int, error) {
+	if cap(f.raw) < len(buf)*2 {
+		f.raw = make([]byte, len(buf)*2)
+	}
+	raw := f.raw[:len(buf)*2]
+
+	m, err := io.ReadFull(f.r, raw)
+	n := m / 2
+	for i := 0; i < n; i++ {
+		buf[i] = int16(raw[i*2]) | int16(raw[i*2+1])<<8
+	}
+
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (f *ffmpegSource) sampleRate() int { return f.rate }
+func (f *ffmpegSource) channels() int   { return f.numChannels }
+func (f *ffmpegSource) Close() error    { return f.proc.Close() }
@@ -0,0 +1,131 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fakeFFmpeg installs a shell script as the ffmpeg binary for one test
+func fakeFFmpeg(t *testing.T, script string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	SetFFmpegPath(path)
+	t.Cleanup(func() { SetFFmpegPath("") })
+}
+
+func TestConcurrentFFmpegDecodesDoNotMix(t *testing.T) {
+	// Emits the PCM stored beside the input, the argument after -i
+	fakeFFmpeg(t, `while [ "$1" != "-i" ]; do shift; done
+cat "$2.pcm"
+`)
+
+	dir := t.TempDir()
+	const decodes = 8
+	want := make([][]int16, decodes)
+	for i := range want {
+		want[i] = make([]int16, 20000+i)
+		for j := range want[i] {
+			want[i][j] = int16(i*1000 + j%1000)
+		}
+		raw := make([]byte, len(want[i])*2)
+		for j, v := range want[i] {
+			binary.LittleEndian.PutUint16(raw[j*2:], uint16(v))
+		}
+		if err := os.WriteFile(filepath.Join(dir, string(rune('a'+i))+".pcm"), raw, 0644); err != nil {
+			t.Fatalf("os.WriteFile() error: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, decodes)
+	for i := 0; i < decodes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pcm, err := decodeWithFFmpeg(context.Background(), filepath.Join(dir, string(rune('a'+i))), nil, V2)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if len(pcm) != len(want[i]) || pcm[0] != want[i][0] || pcm[len(pcm)-1] != want[i][len(want[i])-1] {
+				errs[i] = errors.New("decoded another file's samples")
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("decode %d error: %v", i, err)
+		}
+	}
+}
+
+func TestFFmpegFailureCarriesStderr(t *testing.T) {
+	fakeFFmpeg(t, `echo "[mov,mp4] something" >&2
+echo "broken.m4a: Invalid data found when processing input" >&2
+exit 183
+`)
+
+	_, err := decodeWithFFmpeg(context.Background(), "broken.m4a", nil, V2)
+	var ffErr *FFmpegError
+	if !errors.As(err, &ffErr) {
+		t.Fatalf("decodeWithFFmpeg() error = %v, want *FFmpegError", err)
+	}
+	if ffErr.ExitCode != 183 || ffErr.Message != "broken.m4a: Invalid data found when processing input" {
+		t.Fatalf("FFmpegError = exit %d %q, want exit 183 and the last stderr line", ffErr.ExitCode, ffErr.Message)
+	}
+}
+
+func TestMissingFFmpeg(t *testing.T) {
+	SetFFmpegPath(filepath.Join(t.TempDir(), "no-ffmpeg"))
+	t.Cleanup(func() { SetFFmpegPath("") })
+
+	if _, err := decodeWithFFmpeg(context.Background(), "track.m4a", nil, V2); !errors.Is(err, ErrFFmpegNotFound) {
+		t.Fatalf("decodeWithFFmpeg() error = %v, want ErrFFmpegNotFound", err)
+	}
+	if info := ProbeFFmpeg(context.Background()); info.Available || info.Error == "" {
+		t.Fatalf("ProbeFFmpeg() = %+v, want unavailable with an error", info)
+	}
+}
+
+func TestProbeFFmpegReportsVersion(t *testing.T) {
+	fakeFFmpeg(t, `echo "ffmpeg version 6.1.1-test Copyright (c) 2000-2023 the FFmpeg developers"
+echo "built with gcc"
+`)
+
+	info := ProbeFFmpeg(context.Background())
+	if !info.Available || info.Version != "6.1.1-test" || info.Error != "" {
+		t.Fatalf("ProbeFFmpeg() = %+v, want available version 6.1.1-test", info)
+	}
+}
+
+func TestProbeTranscodeProfiles(t *testing.T) {
+	fakeFFmpeg(t, `cat <<'LIST'
+Encoders:
+ V..... = Video
+ ------
+ V....D libx264              libx264 H.264
+ A....D libopus              libopus Opus
+ A....D aac                  AAC (Advanced Audio Coding)
+LIST
+`)
+
+	profiles, err := ProbeTranscodeProfiles(context.Background())
+	if err != nil || len(profiles) != 1 || profiles[0].Format != TranscodeOpus {
+		t.Fatalf("ProbeTranscodeProfiles() = %+v, %v; want only opus", profiles, err)
+	}
+	if bitrate, err := profiles[0].ResolveBitrate(0); err != nil || bitrate != profiles[0].DefaultBitrate {
+		t.Fatalf("ResolveBitrate(0) = %d, %v; want the default", bitrate, err)
+	}
+	if _, err := profiles[0].ResolveBitrate(1000); err == nil {
+		t.Fatal("ResolveBitrate(1000) error = nil, want out of range")
+	}
+}
@@ -0,0 +1,115 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mewkiz/flac"
+)
+
+// decodeFLAC decodes a FLAC file to PCM using the pure Go decoder
+func decodeFLAC(ctx context.Context, filePath string, version Version) ([]int16, error) {
+	src, err := openFLACSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSource(ctx, src, version)
+}
+
+// flacSource streams samples from a FLAC file one frame at a time
+type flacSource struct {
+	file        *os.File
+	stream      *flac.Stream
+	rate        int
+	numChannels int
+	shift       int // bits to drop (positive) or add (negative) to reach 16-bit
+	pending     []int16
+}
+
+func openFLACSource(filePath string) (*flacSource, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	stream, err := flac.New(bufio.NewReaderSize(file, 64*1024))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create FLAC decoder: %w", err)
+	}
+
+	info := stream.Info
+	if info.SampleRate == 0 || info.NChannels == 0 {
+		file.Close()
+		return nil, fmt.Errorf("invalid FLAC stream info")
+	}
+
+	return &flacSource{
+		file:        file,
+		stream:      stream,
+		rate:        int(info.SampleRate),
+		numChannels: int(info.NChannels),
+		shift:       int(info.BitsPerSample) - TargetBitDepth,
+	}, nil
+}
+
+func (f *flacSource) readSamples(buf []int16) (int, error) {
+	n := 0
+	for n < len(buf) {
+		if len(f.pending) == 0 {
+			if err := f.nextFrame(); err != nil {
+				if err == io.EOF {
+					return n, io.EOF
+				}
+				return n, fmt.Errorf("failed to decode FLAC frame: %w", err)
+			}
+			continue
+		}
+		k := copy(buf[n:], f.pending)
+		f.pending = f.pending[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// nextFrame decodes one frame and interleaves its subframes into pending
+func (f *flacSource) nextFrame() error {
+	frame, err := f.stream.ParseNext()
+	if err != nil {
+		return err
+	}
+	if len(frame.Subframes) != f.numChannels {
+		return fmt.Errorf("frame has %d channels, stream has %d", len(frame.Subframes), f.numChannels)
+	}
+
+	frames := len(frame.Subframes[0].Samples)
+	need := frames * f.numChannels
+	if cap(f.pending) < need {
+		f.pending = make([]int16, need)
+	}
+	f.pending = f.pending[:need]
+	for ch, sub := range frame.Subframes {
+		for i, sample := range sub.Samples[:frames] {
+			f.pending[i*f.numChannels+ch] = f.scale(sample)
+		}
+	}
+	return nil
+}
+
+// scale converts a sample of the stream's bit depth to 16-bit
+func (f *flacSource) scale(sample int32) int16 {
+	switch {
+	case f.shift > 0:
+		sample >>= uint(f.shift)
+	case f.shift < 0:
+		sample <<= uint(-f.shift)
+	}
+	return clampInt16(int(sample))
+}
+
+func (f *flacSource) sampleRate() int { return f.rate }
+func (f *flacSource) channels() int   { return f.numChannels }
+func (f *flacSource) Close() error    { return f.file.Close() }
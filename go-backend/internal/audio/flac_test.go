@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+// writeTestFLAC encodes testSignal as a verbatim FLAC file and returns its path
+func writeTestFLAC(t *testing.T, sampleRate, bitDepth, channels int, seconds float64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.flac")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("os.Create() error: %v", err)
+	}
+	defer file.Close()
+
+	const blockSize = 4096
+	data := testSignal(sampleRate, bitDepth, channels, seconds)
+	frames := len(data) / channels
+
+	info := &meta.StreamInfo{
+		BlockSizeMin:  blockSize,
+		BlockSizeMax:  blockSize,
+		SampleRate:    uint32(sampleRate),
+		NChannels:     uint8(channels),
+		BitsPerSample: uint8(bitDepth),
+		NSamples:      uint64(frames),
+	}
+	enc, err := flac.NewEncoder(file, info)
+	if err != nil {
+		t.Fatalf("flac.NewEncoder() error: %v", err)
+	}
+
+	layout := frame.ChannelsMono
+	if channels == 2 {
+		layout = frame.ChannelsLR
+	}
+	for start := 0; start < frames; start += blockSize {
+		n := min(blockSize, frames-start)
+		f := &frame.Frame{
+			Header: frame.Header{
+				HasFixedBlockSize: true,
+				BlockSize:         uint16(n),
+				SampleRate:        uint32(sampleRate),
+				Channels:          layout,
+				BitsPerSample:     uint8(bitDepth),
+			},
+		}
+		for ch := 0; ch < channels; ch++ {
+			samples := make([]int32, n)
+			for i := range samples {
+				samples[i] = int32(data[(start+i)*channels+ch])
+			}
+			f.Subframes = append(f.Subframes, &frame.Subframe{
+				SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+				Samples:   samples,
+				NSamples:  n,
+			})
+		}
+		if err := enc.WriteFrame(f); err != nil {
+			t.Fatalf("flac Encoder.WriteFrame() error: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("flac Encoder.Close() error: %v", err)
+	}
+	return path
+}
+
+func TestDecodeFLACMatchesWAV(t *testing.T) {
+	wavPCM, err := DecodeAudioToPCM(context.Background(), writeTestWAV(t, 48000, 16, 2, 1))
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCM(wav) error: %v", err)
+	}
+	flacPCM, err := DecodeAudioToPCM(context.Background(), writeTestFLAC(t, 48000, 16, 2, 1))
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCM(flac) error: %v", err)
+	}
+
+	if len(flacPCM) != len(wavPCM) {
+		t.Fatalf("FLAC decoded %d samples, WAV %d", len(flacPCM), len(wavPCM))
+	}
+	for i := range wavPCM {
+		if flacPCM[i] != wavPCM[i] {
+			t.Fatalf("sample %d: FLAC %d, WAV %d", i, flacPCM[i], wavPCM[i])
+		}
+	}
+}
+
+func TestDecodeFLACScalesHighBitDepth(t *testing.T) {
+	pcm, err := DecodeAudioToPCM(context.Background(), writeTestFLAC(t, 44100, 24, 1, 0.5))
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCM() error: %v", err)
+	}
+
+	want := testSignal(44100, 24, 1, 0.5)
+	if len(pcm) != len(want) {
+		t.Fatalf("decoded %d samples, want %d", len(pcm), len(want))
+	}
+	for i := range want {
+		if int(pcm[i]) != want[i]>>8 {
+			t.Fatalf("sample %d = %d, want %d", i, pcm[i], want[i]>>8)
+		}
+	}
+}
+
+func TestDecodeWithoutExtensionSniffsNativeFormats(t *testing.T) {
+	for _, src := range []string{
+		writeTestFLAC(t, 44100, 16, 2, 0.5),
+		filepath.Join("testdata", "tone_vorbis.ogg"),
+		filepath.Join("testdata", "tiny_opus.ogg"),
+	} {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatalf("os.ReadFile() error: %v", err)
+		}
+		bare := filepath.Join(t.TempDir(), "picked_file")
+		if err := os.WriteFile(bare, data, 0o644); err != nil {
+			t.Fatalf("os.WriteFile() error: %v", err)
+		}
+
+		want, err := DecodeAudioToPCM(context.Background(), src)
+		if err != nil {
+			t.Fatalf("DecodeAudioToPCM(%s) error: %v", src, err)
+		}
+		got, err := DecodeAudioToPCM(context.Background(), bare)
+		if err != nil {
+			t.Fatalf("DecodeAudioToPCM(no extension) for %s error: %v", src, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s without extension decoded %d samples, want %d", src, len(got), len(want))
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/jfreymuth/oggvorbis"
+	"github.com/pion/opus"
+	"github.com/pion/opus/pkg/oggreader"
+)
+
+const (
+	// opusSampleRate is the rate Opus always decodes at
+	opusSampleRate = 48000
+	// opusMaxFrameSamples is 120ms at 48kHz, the longest Opus packet
+	opusMaxFrameSamples = 5760
+)
+
+// decodeVorbis decodes an Ogg Vorbis file to PCM using the pure Go decoder
+func decodeVorbis(ctx context.Context, filePath string, version Version) ([]int16, error) {
+	src, err := openVorbisSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSource(ctx, src, version)
+}
+
+// decodeOpus decodes an Ogg Opus file to PCM using the pure Go decoder
+func decodeOpus(ctx context.Context, filePath string, version Version) ([]int16, error) {
+	src, err := openOpusSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSource(ctx, src, version)
+}
+
+// vorbisSource streams samples from an Ogg Vorbis file
+type vorbisSource struct {
+	file   *os.File
+	reader *oggvorbis.Reader
+	floats []float32
+}
+
+func openVorbisSource(filePath string) (*vorbisSource, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	reader, err := oggvorbis.NewReader(bufio.NewReaderSize(file, 64*1024))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create Vorbis decoder: %w", err)
+	}
+	return &vorbisSource{file: file, reader: reader}, nil
+}
+
+func (v *vorbisSource) readSamples(buf []int16) (int, error) {
+	// Vorbis yields whole frames, so keep the request a multiple of channels
+	want := len(buf) / v.reader.Channels() * v.reader.Channels()
+	if cap(v.floats) < want {
+		v.floats = make([]float32, want)
+	}
+
+	n := 0
+	for n < want {
+		k, err := v.reader.Read(v.floats[:want-n])
+		for i := 0; i < k; i++ {
+			buf[n+i] = floatToInt16(v.floats[i])
+		}
+		n += k
+		if err == io.EOF {
+			return n, io.EOF
+		}
+		if err != nil {
+			return n, fmt.Errorf("failed to decode Vorbis packet: %w", err)
+		}
+	}
+	return n, nil
+}
+
+func (v *vorbisSource) sampleRate() int { return v.reader.SampleRate() }
+func (v *vorbisSource) channels() int   { return v.reader.Channels() }
+func (v *vorbisSource) Close() error    { return v.file.Close() }
+
+// opusSource streams samples from an Ogg Opus file. Pre-skip samples are
+// dropped and the final packet is trimmed to the page granule position.
+type opusSource struct {
+	file        *os.File
+	ogg         *oggreader.OggReader
+	dec         opus.Decoder
+	numChannels int
+	preSkip     int
+	decoded     uint64 // 48kHz samples per channel decoded so far
+	frame       []int16
+	pending     []int16
+}
+
+func openOpusSource(filePath string) (*opusSource, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	ogg, header, err := oggreader.NewWith(bufio.NewReaderSize(file, 64*1024))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read Opus header: %w", err)
+	}
+	if header.Channels < 1 || header.Channels > 2 {
+		file.Close()
+		return nil, fmt.Errorf("unsupported Opus channel count: %d", header.Channels)
+	}
+
+	numChannels := int(header.Channels)
+	dec, err := opus.NewDecoderWithOutput(opusSampleRate, numChannels)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create Opus decoder: %w", err)
+	}
+
+	return &opusSource{
+		file:        file,
+		ogg:         ogg,
+		dec:         dec,
+		numChannels: numChannels,
+		preSkip:     int(header.PreSkip),
+		frame:       make([]int16, opusMaxFrameSamples*numChannels),
+	}, nil
+}
+
+func (o *opusSource) readSamples(buf []int16) (int, error) {
+	n := 0
+	for n < len(buf) {
+		if len(o.pending) == 0 {
+			if err := o.nextPacket(); err != nil {
+				if err == io.EOF {
+					return n, io.EOF
+				}
+				return n, err
+			}
+			continue
+		}
+		k := copy(buf[n:], o.pending)
+		o.pending = o.pending[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// nextPacket decodes the next audio packet into pending
+func (o *opusSource) nextPacket() error {
+	for {
+		packet, page, err := o.ogg.ParseNextPacket()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return io.EOF
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read Ogg page: %w", err)
+		}
+		if bytes.HasPrefix(packet, []byte("OpusTags")) {
+			continue
+		}
+
+		frames, err := o.dec.DecodeToInt16(packet, o.frame)
+		if err != nil {
+			return fmt.Errorf("failed to decode Opus packet: %w", err)
+		}
+
+		start, end := 0, frames
+		if end > 0 && page.GranulePosition > 0 && o.decoded+uint64(end) > page.GranulePosition {
+			// End trimming: the last page's granule marks the real length
+			if page.GranulePosition > o.decoded {
+				end = int(page.GranulePosition - o.decoded)
+			} else {
+				end = 0
+			}
+		}
+		o.decoded += uint64(frames)
+		if o.preSkip > 0 {
+			start = min(o.preSkip, end)
+			o.preSkip -= start
+		}
+
+		o.pending = o.frame[start*o.numChannels : end*o.numChannels]
+		if len(o.pending) > 0 {
+			return nil
+		}
+	}
+}
+
+func (o *opusSource) sampleRate() int { return opusSampleRate }
+func (o *opusSource) channels() int   { return o.numChannels }
+func (o *opusSource) Close() error    { return o.file.Close() }
+
+// floatToInt16 converts a sample in [-1, 1] to 16-bit
+func floatToInt16(f float32) int16 {
+	return clampInt16(int(math.Round(float64(f) * 32767)))
+}
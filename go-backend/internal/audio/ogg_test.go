@@ -0,0 +1,28 @@
+package audio
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVorbisDecodesFullLength(t *testing.T) {
+	src, err := openVorbisSource(filepath.Join("testdata", "tone_vorbis.ogg"))
+	if err != nil {
+		t.Fatalf("openVorbisSource() error: %v", err)
+	}
+
+	pcm, err := decodeSource(t.Context(), src, LatestVersion)
+	if err != nil {
+		t.Fatalf("decodeSource() error: %v", err)
+	}
+	// The fixture is exactly one second of 44.1kHz mono
+	if len(pcm) != 44100 {
+		t.Fatalf("decoded %d samples, want 44100", len(pcm))
+	}
+}
+
+func TestOpenOpusSourceRejectsVorbis(t *testing.T) {
+	if _, err := openOpusSource(filepath.Join("testdata", "tone_vorbis.ogg")); err == nil {
+		t.Fatal("openOpusSource(vorbis) returned nil error")
+	}
+}
@@ -0,0 +1,455 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Container identifies the file format wrapping the audio stream
+type Container string
+
+// Codec identifies the encoding of the audio stream
+type Codec string
+
+const (
+	ContainerWAV  Container = "wav"
+	ContainerMPEG Container = "mpeg" // Bare MPEG audio frames, optionally ID3-tagged
+	ContainerADTS Container = "adts"
+	ContainerFLAC Container = "flac"
+	ContainerOgg  Container = "ogg"
+	ContainerMP4  Container = "mp4"
+
+	CodecPCM     Codec = "pcm"
+	CodecMP3     Codec = "mp3"
+	CodecMP2     Codec = "mp2" // MPEG layer I and II
+	CodecFLAC    Codec = "flac"
+	CodecVorbis  Codec = "vorbis"
+	CodecOpus    Codec = "opus"
+	CodecAAC     Codec = "aac"
+	CodecALAC    Codec = "alac"
+	CodecUnknown Codec = "unknown"
+)
+
+// ErrUnknownFormat is returned when no known container signature matches
+var ErrUnknownFormat = errors.New("unrecognized audio format")
+
+// Format describes an audio file as identified from its content
+type Format struct {
+	Container  Container `json:"container"`
+	Codec      Codec     `json:"codec"`
+	SampleRate int       `json:"sample_rate"` // Hz; 0 if the header does not say
+	Channels   int       `json:"channels"`    // 0 if the header does not say
+}
+
+// String describes the format for logs and error messages
+func (f *Format) String() string {
+	if f == nil {
+		return "unknown format"
+	}
+	s := string(f.Codec)
+	if string(f.Container) != s {
+		s = fmt.Sprintf("%s in %s", f.Codec, f.Container)
+	}
+	if f.SampleRate > 0 && f.Channels > 0 {
+		s = fmt.Sprintf("%s (%dHz, %dch)", s, f.SampleRate, f.Channels)
+	}
+	return s
+}
+
+// containerExts are the usual file extensions of each container
+var containerExts = map[Container]string{
+	ContainerWAV:  ".wav",
+	ContainerMPEG: ".mp3",
+	ContainerADTS: ".aac",
+	ContainerFLAC: ".flac",
+	ContainerOgg:  ".ogg",
+	ContainerMP4:  ".m4a",
+}
+
+// Ext returns the usual file extension for the format, for files whose
+// name was lost, or "" if unknown
+func (f *Format) Ext() string {
+	if f == nil {
+		return ""
+	}
+	if f.Container == ContainerOgg && f.Codec == CodecOpus {
+		return ".opus"
+	}
+	return containerExts[f.Container]
+}
+
+// probeScanLimit bounds how far past tags the MPEG frame sync is searched for
+const probeScanLimit = 64 * 1024
+
+// ProbeFile identifies an audio file from its magic bytes and headers,
+// ignoring the file name
+func ProbeFile(filePath string) (*Format, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return Probe(file, info.Size())
+}
+
+// Probe identifies the audio format of size bytes readable from r
+func Probe(r io.ReaderAt, size int64) (*Format, error) {
+	head := make([]byte, 64)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	head = head[:n]
+
+	switch {
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return probeWAV(r, size)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return probeOgg(r)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return probeMP4(r, size)
+	}
+
+	// FLAC and MPEG audio may both be preceded by an ID3v2 tag
+	offset := id3v2Size(head)
+	sig := make([]byte, 4)
+	if _, err := r.ReadAt(sig, offset); err == nil && string(sig) == "fLaC" {
+		return probeFLAC(r, offset)
+	}
+	if format := probeMPEG(r, offset, size); format != nil {
+		return format, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// id3v2Size returns the length of a leading ID3v2 tag, or 0 if there is none
+func id3v2Size(head []byte) int64 {
+	if len(head) < 10 || string(head[0:3]) != "ID3" {
+		return 0
+	}
+	// Tag size is a 28-bit synchsafe integer excluding the 10-byte header
+	size := int64(head[6]&0x7f)<<21 | int64(head[7]&0x7f)<<14 | int64(head[8]&0x7f)<<7 | int64(head[9]&0x7f)
+	size += 10
+	if head[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	return size
+}
+
+// probeWAV reads the fmt chunk of a RIFF/WAVE file
+func probeWAV(r io.ReaderAt, size int64) (*Format, error) {
+	offset := int64(12)
+	header := make([]byte, 8)
+	for offset+8 <= size {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			break
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+		if string(header[0:4]) == "fmt " {
+			if chunkSize < 16 {
+				break
+			}
+			body := make([]byte, 16)
+			if _, err := r.ReadAt(body, offset+8); err != nil {
+				break
+			}
+			codec := CodecUnknown
+			switch binary.LittleEndian.Uint16(body[0:2]) {
+			case 1, 0xfffe: // PCM and WAVE_FORMAT_EXTENSIBLE
+				codec = CodecPCM
+			}
+			return &Format{
+				Container:  ContainerWAV,
+				Codec:      codec,
+				SampleRate: int(binary.LittleEndian.Uint32(body[4:8])),
+				Channels:   int(binary.LittleEndian.Uint16(body[2:4])),
+			}, nil
+		}
+		// Chunks are padded to an even length
+		offset += 8 + chunkSize + chunkSize%2
+	}
+	return nil, fmt.Errorf("invalid WAV file: fmt chunk not found")
+}
+
+// probeFLAC reads the STREAMINFO block following the fLaC marker
+func probeFLAC(r io.ReaderAt, offset int64) (*Format, error) {
+	block := make([]byte, 4+18)
+	if _, err := r.ReadAt(block, offset+4); err != nil {
+		return nil, fmt.Errorf("invalid FLAC file: %w", err)
+	}
+	if block[0]&0x7f != 0 {
+		return nil, fmt.Errorf("invalid FLAC file: STREAMINFO block missing")
+	}
+	info := block[4:]
+	return &Format{
+		Container:  ContainerFLAC,
+		Codec:      CodecFLAC,
+		SampleRate: int(info[10])<<12 | int(info[11])<<4 | int(info[12])>>4,
+		Channels:   int(info[12]>>1&0x07) + 1,
+	}, nil
+}
+
+// probeOgg identifies the codec from the first packet of the first page
+func probeOgg(r io.ReaderAt) (*Format, error) {
+	page := make([]byte, 27+255)
+	n, err := r.ReadAt(page, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid Ogg file: %w", err)
+	}
+	page = page[:n]
+	if len(page) < 27 || len(page) < 27+int(page[26]) {
+		return nil, fmt.Errorf("invalid Ogg file: truncated page header")
+	}
+	segments := int(page[26])
+
+	packet := make([]byte, 32)
+	n, err = r.ReadAt(packet, int64(27+segments))
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid Ogg file: %w", err)
+	}
+	packet = packet[:n]
+
+	switch {
+	case len(packet) >= 19 && string(packet[0:8]) == "OpusHead":
+		// Opus always decodes at 48kHz whatever input rate the header records
+		return &Format{Container: ContainerOgg, Codec: CodecOpus, SampleRate: opusSampleRate, Channels: int(packet[9])}, nil
+	case len(packet) >= 16 && string(packet[0:7]) == "\x01vorbis":
+		return &Format{
+			Container:  ContainerOgg,
+			Codec:      CodecVorbis,
+			SampleRate: int(binary.LittleEndian.Uint32(packet[12:16])),
+			Channels:   int(packet[11]),
+		}, nil
+	case bytes.HasPrefix(packet, []byte("\x7fFLAC")):
+		return &Format{Container: ContainerOgg, Codec: CodecFLAC}, nil
+	}
+	return &Format{Container: ContainerOgg, Codec: CodecUnknown}, nil
+}
+
+var (
+	mpegSampleRates = [4][3]int{
+		{11025, 12000, 8000},  // MPEG 2.5
+		{},                    // reserved
+		{22050, 24000, 16000}, // MPEG 2
+		{44100, 48000, 32000}, // MPEG 1
+	}
+	// Layer III bitrates in kbit/s for MPEG 1 and for MPEG 2/2.5
+	mpeg1L3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2L3Bitrates = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	adtsSampleRates = [16]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+)
+
+// mpegFrame is a parsed MPEG audio or ADTS frame header
+type mpegFrame struct {
+	format *Format
+	length int // bytes including header; 0 if it cannot be derived
+}
+
+// parseMPEGHeader parses a 4-byte MPEG audio or 7-byte ADTS header
+func parseMPEGHeader(h []byte) *mpegFrame {
+	if len(h) < 4 || h[0] != 0xff || h[1]&0xe0 != 0xe0 {
+		return nil
+	}
+
+	// ADTS shares the sync word; layer bits are always zero
+	if h[1]&0xf6 == 0xf0 {
+		if len(h) < 7 {
+			return nil
+		}
+		rate := adtsSampleRates[h[2]>>2&0x0f]
+		if rate == 0 {
+			return nil
+		}
+		return &mpegFrame{
+			format: &Format{Container: ContainerADTS, Codec: CodecAAC, SampleRate: rate, Channels: int(h[2]&0x01)<<2 | int(h[3]>>6)},
+			length: int(h[3]&0x03)<<11 | int(h[4])<<3 | int(h[5]>>5),
+		}
+	}
+
+	version := h[1] >> 3 & 0x03
+	layer := h[1] >> 1 & 0x03
+	bitrateIdx := h[2] >> 4
+	rateIdx := h[2] >> 2 & 0x03
+	if version == 1 || layer == 0 || bitrateIdx == 0x0f || rateIdx == 3 {
+		return nil
+	}
+
+	rate := mpegSampleRates[version][rateIdx]
+	channels := 2
+	if h[3]>>6 == 3 {
+		channels = 1
+	}
+	frame := &mpegFrame{format: &Format{Container: ContainerMPEG, Codec: CodecMP2, SampleRate: rate, Channels: channels}}
+
+	if layer == 1 { // Layer III
+		frame.format.Codec = CodecMP3
+		padding := int(h[2] >> 1 & 0x01)
+		if version == 3 {
+			frame.length = 144*1000*mpeg1L3Bitrates[bitrateIdx]/rate + padding
+		} else {
+			frame.length = 72*1000*mpeg2L3Bitrates[bitrateIdx]/rate + padding
+		}
+	}
+	return frame
+}
+
+// probeMPEG looks for an MPEG audio or ADTS frame sync after any tag. A match
+// must be followed by a second valid frame unless the file ends first.
+func probeMPEG(r io.ReaderAt, offset, size int64) *Format {
+	limit := offset + probeScanLimit
+	if limit > size {
+		limit = size
+	}
+	buf := make([]byte, limit-offset)
+	n, _ := r.ReadAt(buf, offset)
+	buf = buf[:n]
+
+	next := make([]byte, 7)
+	for i := 0; i+4 <= len(buf); i++ {
+		frame := parseMPEGHeader(buf[i:])
+		if frame == nil {
+			continue
+		}
+		if frame.length <= 0 {
+			// Free-format or layer I/II: accept only at the very start
+			if i == 0 {
+				return frame.format
+			}
+			continue
+		}
+
+		nextOffset := offset + int64(i+frame.length)
+		if nextOffset+4 > size {
+			return frame.format
+		}
+		if k, _ := r.ReadAt(next, nextOffset); k >= 4 {
+			if follow := parseMPEGHeader(next[:k]); follow != nil && *follow.format == *frame.format {
+				return frame.format
+			}
+		}
+	}
+	return nil
+}
+
+// probeMP4 walks the ISO BMFF box tree to the first audio sample entry
+func probeMP4(r io.ReaderAt, size int64) (*Format, error) {
+	format := &Format{Container: ContainerMP4, Codec: CodecUnknown}
+
+	moov, ok := findBox(r, 0, size, "moov")
+	if !ok {
+		return format, nil
+	}
+	err := forEachBox(r, moov.start, moov.end, func(b box) bool {
+		if b.typ != "trak" {
+			return true
+		}
+		stsd, ok := findBoxPath(r, b, "mdia", "minf", "stbl", "stsd")
+		if !ok {
+			return true
+		}
+		// stsd: version/flags, entry count, then the first sample entry
+		entry := make([]byte, 8+28)
+		if _, err := r.ReadAt(entry, stsd.start+8); err != nil {
+			return true
+		}
+		codec := CodecUnknown
+		switch string(entry[4:8]) {
+		case "mp4a":
+			codec = CodecAAC
+		case "alac":
+			codec = CodecALAC
+		case "fLaC":
+			codec = CodecFLAC
+		case "Opus":
+			codec = CodecOpus
+		case ".mp3":
+			codec = CodecMP3
+		default:
+			return true // video or other non-audio track
+		}
+		// AudioSampleEntry: 8 reserved/index bytes, 8 version bytes, then
+		// channel count, sample size, 4 more bytes and a 16.16 sample rate
+		audio := entry[8:]
+		format.Codec = codec
+		format.Channels = int(binary.BigEndian.Uint16(audio[16:18]))
+		format.SampleRate = int(binary.BigEndian.Uint32(audio[24:28]) >> 16)
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid MP4 file: %w", err)
+	}
+	return format, nil
+}
+
+// box is an ISO BMFF box; start and end delimit its payload
+type box struct {
+	typ        string
+	start, end int64
+}
+
+// forEachBox calls fn for each box in [start, end) until fn returns false
+func forEachBox(r io.ReaderAt, start, end int64, fn func(box) bool) error {
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		headerLen := int64(8)
+		switch size {
+		case 0:
+			size = end - offset
+		case 1:
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return err
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerLen = 16
+		}
+		if size < headerLen || offset+size > end {
+			return fmt.Errorf("box %q has invalid size %d", header[4:8], size)
+		}
+		if !fn(box{typ: string(header[4:8]), start: offset + headerLen, end: offset + size}) {
+			return nil
+		}
+		offset += size
+	}
+	return nil
+}
+
+// findBox returns the first direct child box of the given type
+func findBox(r io.ReaderAt, start, end int64, typ string) (box, bool) {
+	var found box
+	ok := false
+	forEachBox(r, start, end, func(b box) bool {
+		if b.typ == typ {
+			found, ok = b, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// findBoxPath descends through nested box types starting inside parent
+func findBoxPath(r io.ReaderAt, parent box, path ...string) (box, bool) {
+	current := parent
+	for _, typ := range path {
+		next, ok := findBox(r, current.start, current.end, typ)
+		if !ok {
+			return box{}, false
+		}
+		current = next
+	}
+	return current, true
+}
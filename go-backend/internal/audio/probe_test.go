@@ -0,0 +1,199 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mp4Box encodes an ISO BMFF box
+func mp4Box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out, uint32(8+len(body)))
+	copy(out[4:], typ)
+	return append(out, body...)
+}
+
+// testM4A builds a minimal MP4 file with one AAC audio sample entry and any
+// extra moov children
+func testM4A(extra ...[]byte) []byte {
+	entry := make([]byte, 28)
+	binary.BigEndian.PutUint16(entry[16:], 2)         // channel count
+	binary.BigEndian.PutUint16(entry[18:], 16)        // sample size
+	binary.BigEndian.PutUint32(entry[24:], 44100<<16) // 16.16 sample rate
+	stsd := append([]byte{0, 0, 0, 0, 0, 0, 0, 1}, mp4Box("mp4a", entry)...)
+
+	stbl := mp4Box("stbl", mp4Box("stsd", stsd))
+	trak := mp4Box("trak",
+		mp4Box("tkhd", make([]byte, 84)),
+		mp4Box("mdia", mp4Box("minf", stbl)),
+	)
+	moov := mp4Box("moov", append([][]byte{mp4Box("mvhd", make([]byte, 100)), trak}, extra...)...)
+	return append(mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00")), moov...)
+}
+
+// writeBytes writes data to a file without a meaningful extension
+func writeBytes(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	return path
+}
+
+// withID3 prefixes a file's content with an ID3v2 tag of the given body size
+func withID3(t *testing.T, path string, bodySize int) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	tag := []byte{'I', 'D', '3', 4, 0, 0,
+		byte(bodySize >> 21 & 0x7f), byte(bodySize >> 14 & 0x7f), byte(bodySize >> 7 & 0x7f), byte(bodySize & 0x7f)}
+	tag = append(tag, make([]byte, bodySize)...)
+	return append(tag, data...)
+}
+
+func TestProbeFile(t *testing.T) {
+	adts := []byte{0xff, 0xf1, 0x50, 0x80, 0x02, 0x1f, 0xfc} // AAC LC, 44.1kHz, stereo, 16-byte frame
+	adts = append(adts, make([]byte, 9)...)
+	adts = append(adts, adts...)
+
+	tests := []struct {
+		name string
+		path func(t *testing.T) string
+		want Format
+	}{
+		{
+			name: "wav",
+			path: func(t *testing.T) string { return writeTestWAV(t, 22050, 16, 1, 0.1) },
+			want: Format{Container: ContainerWAV, Codec: CodecPCM, SampleRate: 22050, Channels: 1},
+		},
+		{
+			name: "flac",
+			path: func(t *testing.T) string { return writeTestFLAC(t, 96000, 24, 2, 0.1) },
+			want: Format{Container: ContainerFLAC, Codec: CodecFLAC, SampleRate: 96000, Channels: 2},
+		},
+		{
+			name: "mp3",
+			path: func(t *testing.T) string { return filepath.Join("testdata", "mozart_44k_stereo.mp3") },
+			want: Format{Container: ContainerMPEG, Codec: CodecMP3, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "mp3-mpeg2",
+			path: func(t *testing.T) string { return filepath.Join("testdata", "speech_22k_mpeg2.mp3") },
+			want: Format{Container: ContainerMPEG, Codec: CodecMP3, SampleRate: 22050, Channels: 1},
+		},
+		{
+			name: "mp3-large-id3",
+			path: func(t *testing.T) string {
+				return writeBytes(t, "tagged", withID3(t, filepath.Join("testdata", "mozart_44k_stereo.mp3"), 200*1024))
+			},
+			want: Format{Container: ContainerMPEG, Codec: CodecMP3, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "vorbis",
+			path: func(t *testing.T) string { return filepath.Join("testdata", "tone_vorbis.ogg") },
+			want: Format{Container: ContainerOgg, Codec: CodecVorbis, SampleRate: 44100, Channels: 1},
+		},
+		{
+			name: "opus",
+			path: func(t *testing.T) string { return filepath.Join("testdata", "tiny_opus.ogg") },
+			want: Format{Container: ContainerOgg, Codec: CodecOpus, SampleRate: 48000, Channels: 1},
+		},
+		{
+			name: "m4a",
+			path: func(t *testing.T) string { return writeBytes(t, "audio.m4a", testM4A()) },
+			want: Format{Container: ContainerMP4, Codec: CodecAAC, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "adts",
+			path: func(t *testing.T) string { return writeBytes(t, "audio.aac", adts) },
+			want: Format{Container: ContainerADTS, Codec: CodecAAC, SampleRate: 44100, Channels: 2},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ProbeFile(tc.path(t))
+			if err != nil {
+				t.Fatalf("ProbeFile() error: %v", err)
+			}
+			if *got != tc.want {
+				t.Fatalf("ProbeFile() = %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProbeFileUnknown(t *testing.T) {
+	path := writeBytes(t, "notes.mp3", []byte(strings.Repeat("not audio at all ", 100)))
+	if _, err := ProbeFile(path); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("ProbeFile(text) error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestDecodeMislabeledFile(t *testing.T) {
+	// A FLAC file named .mp3, as the Android picker sometimes delivers
+	src := writeTestFLAC(t, 44100, 16, 2, 0.5)
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	mislabeled := writeBytes(t, "track.mp3", data)
+
+	want, err := DecodeAudioToPCM(context.Background(), src)
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCM(flac) error: %v", err)
+	}
+	got, err := DecodeAudioToPCM(context.Background(), mislabeled)
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCM(mislabeled) error: %v", err)
+	}
+	if !bytes.Equal(int16Bytes(got), int16Bytes(want)) {
+		t.Fatal("mislabeled FLAC decoded differently from the original")
+	}
+
+	stream, err := OpenPCMStream(context.Background(), mislabeled)
+	if err != nil {
+		t.Fatalf("OpenPCMStream(mislabeled) error: %v", err)
+	}
+	defer stream.Close()
+	if f := stream.Format(); f == nil || f.Codec != CodecFLAC {
+		t.Fatalf("PCMStream.Format() = %v, want flac", f)
+	}
+}
+
+func TestDecodeErrorNamesProbedFormat(t *testing.T) {
+	// Valid FLAC header followed by garbage frames
+	data, err := os.ReadFile(writeTestFLAC(t, 44100, 16, 1, 0.2))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	for i := 100; i < len(data); i++ {
+		data[i] = 0
+	}
+
+	_, err = DecodeAudioToPCM(context.Background(), writeBytes(t, "broken.wav", data))
+	if err == nil {
+		t.Fatal("DecodeAudioToPCM(corrupt) returned nil error")
+	}
+	if !strings.Contains(err.Error(), "flac (44100Hz, 1ch)") {
+		t.Fatalf("error %q does not name the probed format", err)
+	}
+}
+
+func int16Bytes(pcm []int16) []byte {
+	out := make([]byte, len(pcm)*2)
+	for i, s := range pcm {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(s))
+	}
+	return out
+}
@@ -0,0 +1,313 @@
+package audio
+
+import "math"
+
+// normalizer incrementally downmixes and resamples interleaved PCM. Channels
+// are averaged with integer division in every version; the rate converter
+// depends on the version.
+type normalizer struct {
+	srcChannels int
+	downmix     bool
+	frame       []int16 // partial interleaved frame carried between pushes
+	conv        rateConverter
+}
+
+// rateConverter turns a mono stream at one rate into another
+type rateConverter interface {
+	// push consumes mono samples and appends the outputs they complete
+	push(mono []int16, out []int16) []int16
+	// finish flushes the tail once the source is exhausted
+	finish(out []int16) []int16
+}
+
+func newNormalizer(version Version, srcRate, dstRate, srcChannels, dstChannels int) *normalizer {
+	n := &normalizer{srcChannels: srcChannels}
+	if srcRate == dstRate && srcChannels == dstChannels {
+		return n
+	}
+	n.downmix = srcChannels > dstChannels
+	if srcRate != dstRate {
+		if version == V1 {
+			n.conv = &linearResampler{ratio: float64(dstRate) / float64(srcRate)}
+		} else {
+			n.conv = newSincResampler(srcRate, dstRate)
+		}
+	}
+	return n
+}
+
+// push consumes interleaved samples and appends normalized output to out
+func (n *normalizer) push(samples []int16, out []int16) []int16 {
+	mono := samples
+	if n.downmix {
+		mono = n.downmixFrames(samples)
+	}
+	if n.conv == nil {
+		return append(out, mono...)
+	}
+	return n.conv.push(mono, out)
+}
+
+// finish flushes the tail once the source is exhausted
+func (n *normalizer) finish(out []int16) []int16 {
+	if n.conv == nil {
+		return out
+	}
+	return n.conv.finish(out)
+}
+
+// downmixFrames averages complete interleaved frames to mono, carrying any
+// incomplete trailing frame to the next call
+func (n *normalizer) downmixFrames(samples []int16) []int16 {
+	if len(n.frame) > 0 {
+		samples = append(n.frame, samples...)
+	}
+	frames := len(samples) / n.srcChannels
+	mono := make([]int16, frames)
+	for i := 0; i < frames; i++ {
+		var sum int32
+		for ch := 0; ch < n.srcChannels; ch++ {
+			sum += int32(samples[i*n.srcChannels+ch])
+		}
+		mono[i] = int16(sum / int32(n.srcChannels))
+	}
+	n.frame = append(n.frame[:0], samples[frames*n.srcChannels:]...)
+	return mono
+}
+
+// linearResampler is the V1 rate converter. It reproduces resampleToTarget
+// exactly, evaluating linear interpolation at the same source positions.
+type linearResampler struct {
+	ratio  float64
+	window []int16 // mono samples still needed; window[0] is sample number base
+	base   int
+	next   int // index of the next output sample
+}
+
+func (l *linearResampler) push(mono []int16, out []int16) []int16 {
+	l.window = append(l.window, mono...)
+	end := l.base + len(l.window)
+	for {
+		srcIdx := float64(l.next) / l.ratio
+		idx0 := int(srcIdx)
+		if idx0+1 >= end {
+			break
+		}
+		out = append(out, l.interpolate(srcIdx, idx0, idx0+1))
+		l.next++
+	}
+
+	// Drop samples no later output can reference
+	keepFrom := int(float64(l.next) / l.ratio)
+	if drop := keepFrom - l.base; drop > 0 {
+		if drop > len(l.window) {
+			drop = len(l.window)
+		}
+		l.window = append(l.window[:0], l.window[drop:]...)
+		l.base += drop
+	}
+	return out
+}
+
+func (l *linearResampler) finish(out []int16) []int16 {
+	total := l.base + len(l.window)
+	outLen := int(float64(total) * l.ratio)
+	for ; l.next < outLen; l.next++ {
+		srcIdx := float64(l.next) / l.ratio
+		idx0 := int(srcIdx)
+		if idx0 >= total {
+			idx0 = total - 1
+		}
+		idx1 := idx0 + 1
+		if idx1 >= total {
+			idx1 = total - 1
+		}
+		out = append(out, l.interpolate(srcIdx, idx0, idx1))
+	}
+	return out
+}
+
+func (l *linearResampler) interpolate(srcIdx float64, idx0, idx1 int) int16 {
+	t := srcIdx - float64(idx0)
+	val := float64(l.window[idx0-l.base])*(1-t) + float64(l.window[idx1-l.base])*t
+	return int16(val)
+}
+
+const (
+	// sincHalfTaps is the number of input samples used on each side of an
+	// output position
+	sincHalfTaps = 16
+	// sincCoeffBits is the fixed-point precision of the filter taps
+	sincCoeffBits = 15
+	// sincRolloff places the cutoff just below the lower Nyquist frequency
+	sincRolloff = 0.95
+	// kaiserBeta trades transition width for stopband attenuation
+	kaiserBeta = 8.0
+)
+
+// sincResampler is the V2 rate converter: a polyphase Kaiser-windowed sinc
+// filter for the rational ratio up/down. Taps are fixed-point integers and
+// filtering is integer arithmetic, so output is bit-identical everywhere.
+type sincResampler struct {
+	up, down int
+	coeffs   []int32 // up phases of 2*sincHalfTaps taps each
+	window   []int16 // input samples; window[0] is sample number base
+	base     int
+	total    int // input samples pushed so far
+	next     int // index of the next output sample
+}
+
+func newSincResampler(srcRate, dstRate int) *sincResampler {
+	g := gcd(srcRate, dstRate)
+	r := &sincResampler{
+		up:   dstRate / g,
+		down: srcRate / g,
+		// Samples before the start are silence
+		window: make([]int16, sincHalfTaps-1),
+		base:   -(sincHalfTaps - 1),
+	}
+	r.coeffs = sincKernel(r.up, r.down)
+	return r
+}
+
+func (r *sincResampler) push(mono []int16, out []int16) []int16 {
+	r.window = append(r.window, mono...)
+	r.total += len(mono)
+	out = r.drain(out, r.base+len(r.window))
+
+	// Drop samples no later output can reference
+	keepFrom := r.next*r.down/r.up - sincHalfTaps + 1
+	if drop := keepFrom - r.base; drop > 0 {
+		r.window = append(r.window[:0], r.window[drop:]...)
+		r.base += drop
+	}
+	return out
+}
+
+func (r *sincResampler) finish(out []int16) []int16 {
+	// Samples after the end are silence
+	r.window = append(r.window, make([]int16, sincHalfTaps)...)
+	outLen := r.total * r.up / r.down
+	for ; r.next < outLen; r.next++ {
+		out = append(out, r.filter(r.next))
+	}
+	return out
+}
+
+// drain emits every output whose taps lie before end
+func (r *sincResampler) drain(out []int16, end int) []int16 {
+	for {
+		center := r.next * r.down / r.up
+		if center+sincHalfTaps >= end {
+			return out
+		}
+		out = append(out, r.filter(r.next))
+		r.next++
+	}
+}
+
+// filter computes output sample n from the input around its source position
+func (r *sincResampler) filter(n int) int16 {
+	pos := n * r.down
+	center, phase := pos/r.up, pos%r.up
+	taps := r.coeffs[phase*2*sincHalfTaps : (phase+1)*2*sincHalfTaps]
+	start := center - sincHalfTaps + 1 - r.base
+	samples := r.window[start : start+2*sincHalfTaps]
+
+	var acc int64
+	for i, c := range taps {
+		acc += int64(c) * int64(samples[i])
+	}
+	acc += 1 << (sincCoeffBits - 1)
+	return clampInt16(int(acc >> sincCoeffBits))
+}
+
+// sincKernel computes fixed-point taps for every phase. Each tap t of phase
+// p weights input sample center-sincHalfTaps+1+t, at distance
+// x = t-sincHalfTaps+1-p/up from the output position. Every phase sums to
+// exactly 1<<sincCoeffBits so DC passes unchanged.
+//
+// Only IEEE basic operations with explicit rounding are used (no math.Sin,
+// no fused multiply-add), so the taps are identical on every architecture.
+func sincKernel(up, down int) []int32 {
+	cutoff := sincRolloff
+	if up < down {
+		cutoff = float64(cutoff * float64(up) / float64(down))
+	}
+	i0Beta := besselI0(kaiserBeta)
+	scale := float64(1 << sincCoeffBits)
+
+	coeffs := make([]int32, up*2*sincHalfTaps)
+	weights := make([]float64, 2*sincHalfTaps)
+	for p := 0; p < up; p++ {
+		frac := float64(p) / float64(up)
+		var sum float64
+		for t := range weights {
+			x := float64(t-sincHalfTaps+1) - frac
+			u := float64(x / sincHalfTaps)
+			window := besselI0(float64(kaiserBeta*math.Sqrt(float64(1-float64(u*u))))) / i0Beta
+			w := float64(float64(cutoff*sinc(float64(cutoff*x))) * window)
+			weights[t] = w
+			sum += w
+		}
+
+		row := coeffs[p*2*sincHalfTaps : (p+1)*2*sincHalfTaps]
+		var total int32
+		peak := 0
+		for t, w := range weights {
+			row[t] = int32(math.Round(float64(w / sum * scale)))
+			total += row[t]
+			if row[t] > row[peak] {
+				peak = t
+			}
+		}
+		row[peak] += int32(scale) - total
+	}
+	return coeffs
+}
+
+// sinc is the normalized sinc function sin(pi x)/(pi x)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return sinPi(x) / float64(math.Pi*x)
+}
+
+// sinPi computes sin(pi x) with a fixed Taylor series, rounding every step
+// explicitly so results do not depend on the platform's math library or FMA
+func sinPi(x float64) float64 {
+	n := math.Round(x)
+	y := float64(math.Pi * (x - n)) // |y| <= pi/2
+	y2 := float64(y * y)
+
+	// Horner evaluation of y - y^3/3! + y^5/5! - ... through y^23
+	sum := 1.0
+	for k := 23; k >= 3; k -= 2 {
+		sum = float64(1 - float64(float64(y2*sum)/float64(k*(k-1))))
+	}
+	result := float64(y * sum)
+	if int64(n)%2 != 0 {
+		result = -result
+	}
+	return result
+}
+
+// besselI0 is the zeroth-order modified Bessel function of the first kind,
+// summed over a fixed number of series terms
+func besselI0(x float64) float64 {
+	q := float64(float64(x*x) / 4)
+	term, sum := 1.0, 1.0
+	for k := 1; k <= 40; k++ {
+		term = float64(float64(term*q) / float64(k*k))
+		sum += term
+	}
+	return sum
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,113 @@
+package audio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// toneAt returns a mono sine of the given frequency and amplitude
+func toneAt(rate int, freq, amplitude float64, samples int) []int16 {
+	out := make([]int16, samples)
+	for i := range out {
+		out[i] = int16(math.Round(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))))
+	}
+	return out
+}
+
+func TestSincResamplerPreservesDC(t *testing.T) {
+	src := make([]int16, 10000)
+	for i := range src {
+		src[i] = 12345
+	}
+
+	for _, rate := range []int{8000, 22050, 32000, 48000, 96000} {
+		out, err := normalize(src, rate, 1, V2)
+		if err != nil {
+			t.Fatalf("normalize() error: %v", err)
+		}
+		if want := len(src) * TargetSampleRate / rate; len(out) != want {
+			t.Fatalf("rate %d: got %d samples, want %d", rate, len(out), want)
+		}
+		// Away from the zero-padded edges every phase sums to unity gain
+		for i := sincHalfTaps * 8; i < len(out)-sincHalfTaps*8; i++ {
+			if out[i] != 12345 {
+				t.Fatalf("rate %d: sample %d = %d, want 12345", rate, i, out[i])
+			}
+		}
+	}
+}
+
+func TestSincResamplerReproducesTone(t *testing.T) {
+	const freq, amplitude = 1000.0, 10000.0
+	for _, rate := range []int{22050, 32000, 48000} {
+		out, err := normalize(toneAt(rate, freq, amplitude, rate), rate, 1, V2)
+		if err != nil {
+			t.Fatalf("normalize() error: %v", err)
+		}
+		want := toneAt(TargetSampleRate, freq, amplitude, len(out))
+
+		var maxErr float64
+		for i := 100; i < len(out)-100; i++ {
+			maxErr = math.Max(maxErr, math.Abs(float64(out[i])-float64(want[i])))
+		}
+		if maxErr > amplitude*0.005 {
+			t.Fatalf("rate %d: max deviation from ideal tone %.0f, want <= %.0f", rate, maxErr, amplitude*0.005)
+		}
+	}
+}
+
+// TestSincResamplerGolden pins the V2 output. CTIDs hash this output, so a
+// failure here means every V2 CTID would change: add a new Version instead.
+func TestSincResamplerGolden(t *testing.T) {
+	src := make([]int16, 20000)
+	for i := range src {
+		src[i] = int16((i*7919)%65536) / 4
+	}
+
+	tests := []struct {
+		rate int
+		want string
+	}{
+		{rate: 48000, want: "e2c37fe92307ef9544c1176b65d6022306c35da178784025033783389e32e619"},
+		{rate: 22050, want: "4c80abc1d743aa299ef5a81b8e9f061e306842a5d3d6eee3baa67147f5e06460"},
+		{rate: 32000, want: "bdefcda24b15b9573bef8675ee71dc8d26bc519f9e46e76c3b260b59843a1749"},
+	}
+	for _, tc := range tests {
+		out, err := normalize(src, tc.rate, 1, V2)
+		if err != nil {
+			t.Fatalf("normalize() error: %v", err)
+		}
+		sum := sha256.Sum256(int16Bytes(out))
+		if got := hex.EncodeToString(sum[:]); got != tc.want {
+			t.Fatalf("rate %d: output hash %s, want %s", tc.rate, got, tc.want)
+		}
+	}
+}
+
+func TestSinPiMatchesMathSin(t *testing.T) {
+	for x := -20.0; x <= 20.0; x += 0.0137 {
+		if d := math.Abs(sinPi(x) - math.Sin(math.Pi*x)); d > 1e-12 {
+			t.Fatalf("sinPi(%f) off by %g", x, d)
+		}
+	}
+}
+
+func TestMP3UsesRealSampleRate(t *testing.T) {
+	// The fixture is a 22.05kHz MPEG-2 stream: V1 hashed it as if it were
+	// 44.1kHz, i.e. at double speed
+	path := filepath.Join("testdata", "speech_22k_mpeg2.mp3")
+	v1, err := DecodeAudioToPCMVersion(t.Context(), path, V1)
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCMVersion(V1) error: %v", err)
+	}
+	v2, err := DecodeAudioToPCMVersion(t.Context(), path, V2)
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCMVersion(V2) error: %v", err)
+	}
+	if len(v2) != 2*len(v1) {
+		t.Fatalf("V2 decoded %d samples, want twice V1's %d", len(v2), len(v1))
+	}
+}
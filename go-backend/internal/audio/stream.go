@@ -0,0 +1,366 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	goaudio "github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	mp3 "github.com/hajimehoshi/go-mp3"
+)
+
+// streamChunkSamples is the number of source samples decoded per refill.
+// Together with the resampler window it bounds the stream's memory use.
+const streamChunkSamples = 4096
+
+// sampleSource yields interleaved 16-bit samples from a decoder
+type sampleSource interface {
+	// readSamples fills buf and returns io.EOF once the source is exhausted
+	readSamples(buf []int16) (int, error)
+	sampleRate() int
+	channels() int
+	Close() error
+}
+
+// PCMStream is an io.Reader of normalized PCM: 16-bit little-endian samples
+// at TargetSampleRate with TargetChannels. Audio is decoded, downmixed and
+// resampled incrementally, so memory use does not grow with track length.
+// The bytes produced are identical to normalizing DecodeAudioToPCM output.
+type PCMStream struct {
+	ctx    context.Context
+	src    sampleSource
+	format *Format
+	norm   *normalizer
+	in     []int16
+	out    []int16
+	buf    []byte
+	eof    bool
+	tap    func([]int16)
+}
+
+// OpenPCMStream opens an audio file for streaming PCM normalized with
+// LatestVersion
+func OpenPCMStream(ctx context.Context, filePath string) (*PCMStream, error) {
+	return OpenPCMStreamVersion(ctx, filePath, LatestVersion)
+}
+
+// OpenPCMStreamVersion opens an audio file for streaming PCM normalized with
+// the given algorithm version
+func OpenPCMStreamVersion(ctx context.Context, filePath string, version Version) (*PCMStream, error) {
+	src, format, err := openSampleSource(ctx, filePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PCMStream{
+		ctx:    ctx,
+		src:    src,
+		format: format,
+		norm:   newNormalizer(version, src.sampleRate(), TargetSampleRate, src.channels(), TargetChannels),
+		in:     make([]int16, streamChunkSamples),
+	}, nil
+}
+
+// Read implements io.Reader
+func (s *PCMStream) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.eof {
+			return 0, io.EOF
+		}
+		if err := s.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// Format returns the probed source format, or nil if the file was only
+// recognized by the ffmpeg fallback
+func (s *PCMStream) Format() *Format {
+	return s.format
+}
+
+// SourceRate is the sample rate of the decoder output
+func (s *PCMStream) SourceRate() int {
+	return s.src.sampleRate()
+}
+
+// SourceChannels is the channel count of the decoder output
+func (s *PCMStream) SourceChannels() int {
+	return s.src.channels()
+}
+
+// SetSourceTap registers fn to see the interleaved decoder output, at
+// SourceRate with SourceChannels, before downmixing and resampling. fn must
+// not retain the slice.
+func (s *PCMStream) SetSourceTap(fn func([]int16)) {
+	s.tap = fn
+}
+
+// Close releases the underlying decoder
+func (s *PCMStream) Close() error {
+	return s.src.Close()
+}
+
+// fill decodes the next chunk of source samples into the output buffer
+func (s *PCMStream) fill() error {
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	default:
+	}
+
+	n, err := s.src.readSamples(s.in)
+	if s.tap != nil && n > 0 {
+		s.tap(s.in[:n])
+	}
+	s.out = s.norm.push(s.in[:n], s.out[:0])
+	if err == io.EOF {
+		s.out = s.norm.finish(s.out)
+		s.eof = true
+	} else if err != nil {
+		return err
+	}
+
+	if cap(s.buf) < len(s.out)*2 {
+		s.buf = make([]byte, 0, len(s.out)*2)
+	}
+	s.buf = s.buf[:len(s.out)*2]
+	for i, sample := range s.out {
+		binary.LittleEndian.PutUint16(s.buf[i*2:], uint16(sample))
+	}
+	return nil
+}
+
+// openSampleSource probes the file and opens the decoder DecodeAudioToPCM
+// would use for it
+func openSampleSource(ctx context.Context, filePath string, version Version) (sampleSource, *Format, error) {
+	format, err := ProbeFile(filePath)
+	if err != nil {
+		src, err := openFFmpegFallback(ctx, filePath, nil, version, err)
+		return src, nil, err
+	}
+
+	src, err := openFormatSource(ctx, filePath, format, version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", format, err)
+	}
+	return src, format, nil
+}
+
+// openFormatSource opens the streaming decoder for a probed format
+func openFormatSource(ctx context.Context, filePath string, format *Format, version Version) (sampleSource, error) {
+	var src sampleSource
+	var err error
+	switch {
+	case format.Container == ContainerWAV && format.Codec == CodecPCM:
+		return openWAVSource(filePath)
+	case format.Container == ContainerMPEG && format.Codec == CodecMP3:
+		return openMP3Source(filePath, version)
+	case version == V1 && (format.Container == ContainerFLAC || format.Container == ContainerOgg):
+		// V1 hashed what ffmpeg made of these, resampling and downmix included
+		return openFFmpegSource(ctx, filePath, format, version)
+	case format.Container == ContainerFLAC:
+		src, err = openFLACSource(filePath)
+	case format.Container == ContainerOgg && format.Codec == CodecVorbis:
+		src, err = openVorbisSource(filePath)
+	case format.Container == ContainerOgg && format.Codec == CodecOpus:
+		src, err = openOpusSource(filePath)
+	default:
+		return openFFmpegSource(ctx, filePath, format, version)
+	}
+	if err != nil {
+		return openFFmpegFallback(ctx, filePath, format, version, err)
+	}
+	return src, nil
+}
+
+// openFFmpegFallback opens an ffmpeg source after a native decoder rejected
+// the file, keeping the native error if ffmpeg fails too
+func openFFmpegFallback(ctx context.Context, filePath string, format *Format, version Version, err error) (sampleSource, error) {
+	ff, ffErr := openFFmpegSource(ctx, filePath, format, version)
+	if ffErr != nil {
+		return nil, fmt.Errorf("%v; fallback failed: %w", err, ffErr)
+	}
+	return ff, nil
+}
+
+// wavSource streams samples from a WAV file's data chunk
+type wavSource struct {
+	file        *os.File
+	r           *bufio.Reader
+	rate        int
+	numChannels int
+	bytesPer    int
+	bitDepth    int
+	raw         []byte
+	last        [4]byte
+}
+
+func openWAVSource(filePath string) (*wavSource, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	decoder := wav.NewDecoder(file)
+	if !decoder.IsValidFile() {
+		file.Close()
+		return nil, fmt.Errorf("invalid WAV file")
+	}
+	format := decoder.Format()
+	if format == nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to get WAV format")
+	}
+	if err := decoder.FwdToPCM(); err != nil || decoder.PCMChunk == nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read PCM buffer: PCM chunk not found")
+	}
+
+	bitDepth := int(decoder.BitDepth)
+	switch bitDepth {
+	case 8, 16, 24, 32:
+	default:
+		file.Close()
+		return nil, fmt.Errorf("failed to read PCM buffer: unhandled bit depth: %d", bitDepth)
+	}
+
+	return &wavSource{
+		file:        file,
+		r:           bufio.NewReaderSize(decoder.PCMChunk, 64*1024),
+		rate:        format.SampleRate,
+		numChannels: format.NumChannels,
+		bytesPer:    (bitDepth-1)/8 + 1,
+		bitDepth:    bitDepth,
+	}, nil
+}
+
+func (w *wavSource) readSamples(buf []int16) (int, error) {
+	need := len(buf) * w.bytesPer
+	if cap(w.raw) < need {
+		w.raw = make([]byte, need)
+	}
+	raw := w.raw[:need]
+
+	m, err := io.ReadFull(w.r, raw)
+	n := m / w.bytesPer
+	for i := 0; i < n; i++ {
+		buf[i] = w.decode(raw[i*w.bytesPer : (i+1)*w.bytesPer])
+	}
+	if n > 0 {
+		copy(w.last[:], raw[(n-1)*w.bytesPer:n*w.bytesPer])
+	}
+
+	switch err {
+	case nil:
+		return n, nil
+	case io.ErrUnexpectedEOF:
+		// A trailing partial sample is decoded over the previous sample's
+		// bytes, matching how the full-buffer WAV decoder treats it.
+		if rest := m % w.bytesPer; rest > 0 {
+			copy(w.last[:], raw[n*w.bytesPer:m])
+			buf[n] = w.decode(w.last[:w.bytesPer])
+			n++
+		}
+		return n, io.EOF
+	default:
+		return n, err
+	}
+}
+
+// decode converts one little-endian sample and clamps it to int16
+func (w *wavSource) decode(b []byte) int16 {
+	var sample int
+	switch w.bitDepth {
+	case 8:
+		sample = int(b[0])
+	case 16:
+		sample = int(int16(binary.LittleEndian.Uint16(b)))
+	case 24:
+		sample = int(goaudio.Int24LETo32(b))
+	case 32:
+		sample = int(int32(binary.LittleEndian.Uint32(b)))
+	}
+	return clampInt16(sample)
+}
+
+func (w *wavSource) sampleRate() int { return w.rate }
+func (w *wavSource) channels() int   { return w.numChannels }
+func (w *wavSource) Close() error    { return w.file.Close() }
+
+// mp3Source streams samples from go-mp3, which always yields 16-bit stereo
+type mp3Source struct {
+	file *os.File
+	dec  *mp3.Decoder
+	rate int
+	raw  []byte
+	odd  []byte // byte of an incomplete sample carried between reads
+	done bool
+}
+
+func openMP3Source(filePath string, version Version) (*mp3Source, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	dec, err := mp3.NewDecoder(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create MP3 decoder: %w", err)
+	}
+
+	return &mp3Source{file: file, dec: dec, rate: mp3SampleRate(dec, version)}, nil
+}
+
+func (m *mp3Source) readSamples(buf []int16) (int, error) {
+	if m.done {
+		return 0, io.EOF
+	}
+	if cap(m.raw) < len(buf)*2 {
+		m.raw = make([]byte, len(buf)*2)
+	}
+	raw := append(m.raw[:0], m.odd...)
+
+	// Stop at the first decoder error, as the full-buffer decoder does
+	for len(raw) < len(buf)*2 {
+		chunk := raw[len(raw) : len(buf)*2]
+		k, err := m.dec.Read(chunk)
+		raw = raw[:len(raw)+k]
+		if err != nil {
+			m.done = true
+			break
+		}
+	}
+
+	n := len(raw) / 2
+	for i := 0; i < n; i++ {
+		buf[i] = int16(raw[i*2]) | int16(raw[i*2+1])<<8
+	}
+	m.odd = append(m.odd[:0], raw[n*2:]...)
+	if m.done {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *mp3Source) sampleRate() int { return m.rate }
+func (m *mp3Source) channels() int   { return 2 }
+func (m *mp3Source) Close() error    { return m.file.Close() }
+
+func clampInt16(sample int) int16 {
+	if sample > 32767 {
+		return 32767
+	} else if sample < -32768 {
+		return -32768
+	}
+	return int16(sample)
+}
@@ -0,0 +1,231 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goaudio "github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// testSignal returns deterministic interleaved multi-tone samples
+func testSignal(sampleRate, bitDepth, channels int, seconds float64) []int {
+	frames := int(seconds * float64(sampleRate))
+	peak := float64(int(1)<<(bitDepth-1) - 1)
+	data := make([]int, frames*channels)
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < channels; ch++ {
+			freq := 440.0 * float64(ch+1)
+			v := 0.6*math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)) +
+				0.3*math.Sin(2*math.Pi*1234*float64(i)/float64(sampleRate))
+			data[i*channels+ch] = int(v * peak)
+		}
+	}
+	return data
+}
+
+// writeTestWAV writes a deterministic multi-tone WAV file and returns its path
+func writeTestWAV(t *testing.T, sampleRate, bitDepth, channels int, seconds float64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.wav")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("os.Create() error: %v", err)
+	}
+	defer file.Close()
+
+	data := testSignal(sampleRate, bitDepth, channels, seconds)
+	enc := wav.NewEncoder(file, sampleRate, bitDepth, channels, 1)
+	buf := &goaudio.IntBuffer{
+		Data:           data,
+		Format:         &goaudio.Format{NumChannels: channels, SampleRate: sampleRate},
+		SourceBitDepth: bitDepth,
+	}
+	if err := enc.Write(buf); err != nil {
+		t.Fatalf("wav Encoder.Write() error: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("wav Encoder.Close() error: %v", err)
+	}
+	return path
+}
+
+// decodeFull returns DecodeAudioToPCMVersion output as little-endian bytes
+func decodeFull(t *testing.T, path string, version Version) []byte {
+	t.Helper()
+	pcm, err := DecodeAudioToPCMVersion(context.Background(), path, version)
+	if err != nil {
+		t.Fatalf("DecodeAudioToPCM(%s) error: %v", path, err)
+	}
+	out := make([]byte, len(pcm)*2)
+	for i, s := range pcm {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(s))
+	}
+	return out
+}
+
+// decodeStream reads the whole PCMStream using reads of readSize bytes
+func decodeStream(t *testing.T, path string, version Version, readSize int) []byte {
+	t.Helper()
+	stream, err := OpenPCMStreamVersion(context.Background(), path, version)
+	if err != nil {
+		t.Fatalf("OpenPCMStream(%s) error: %v", path, err)
+	}
+	defer stream.Close()
+
+	var out bytes.Buffer
+	buf := make([]byte, readSize)
+	for {
+		n, err := stream.Read(buf)
+		out.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("PCMStream.Read() error: %v", err)
+		}
+	}
+	return out.Bytes()
+}
+
+func TestPCMStreamMatchesFullDecode(t *testing.T) {
+	// V1 decodes FLAC and Ogg with ffmpeg, which the native cases leave out
+	tests := []struct {
+		name   string
+		path   func(t *testing.T) string
+		v2Only bool
+	}{
+		{name: "wav-16bit-stereo-44k", path: func(t *testing.T) string { return writeTestWAV(t, 44100, 16, 2, 1.5) }},
+		{name: "wav-16bit-mono-22k", path: func(t *testing.T) string { return writeTestWAV(t, 22050, 16, 1, 1.5) }},
+		{name: "wav-24bit-stereo-48k", path: func(t *testing.T) string { return writeTestWAV(t, 48000, 24, 2, 1.5) }},
+		{name: "wav-8bit-mono-8k", path: func(t *testing.T) string { return writeTestWAV(t, 8000, 8, 1, 1.5) }},
+		{name: "mp3-44k-stereo", path: func(t *testing.T) string { return filepath.Join("testdata", "mozart_44k_stereo.mp3") }},
+		{name: "mp3-22k-mpeg2", path: func(t *testing.T) string { return filepath.Join("testdata", "speech_22k_mpeg2.mp3") }},
+		{name: "flac-16bit-stereo-48k", path: func(t *testing.T) string { return writeTestFLAC(t, 48000, 16, 2, 1.5) }, v2Only: true},
+		{name: "flac-24bit-mono-96k", path: func(t *testing.T) string { return writeTestFLAC(t, 96000, 24, 1, 0.5) }, v2Only: true},
+		{name: "ogg-vorbis-44k-mono", path: func(t *testing.T) string { return filepath.Join("testdata", "tone_vorbis.ogg") }, v2Only: true},
+		{name: "ogg-opus-48k-mono", path: func(t *testing.T) string { return filepath.Join("testdata", "tiny_opus.ogg") }, v2Only: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := tc.path(t)
+			versions := []Version{V1, V2}
+			if tc.v2Only {
+				versions = versions[1:]
+			}
+			for _, version := range versions {
+				want := decodeFull(t, path, version)
+				if len(want) == 0 {
+					t.Fatalf("v%d: DecodeAudioToPCMVersion() returned no samples", version)
+				}
+
+				for _, readSize := range []int{1, 333, 64 * 1024} {
+					got := decodeStream(t, path, version, readSize)
+					if !bytes.Equal(got, want) {
+						t.Fatalf("v%d read size %d: stream produced %d bytes, full decode %d bytes (content differs)", version, readSize, len(got), len(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizerMatchesResampleAcrossChunkBoundaries(t *testing.T) {
+	src := make([]int16, 30011)
+	for i := range src {
+		src[i] = int16((i * 7919) % 65536)
+	}
+
+	tests := []struct {
+		srcRate, srcChannels int
+	}{
+		{srcRate: 48000, srcChannels: 2},
+		{srcRate: 22050, srcChannels: 1},
+		{srcRate: 32000, srcChannels: 3},
+		{srcRate: 44100, srcChannels: 2},
+	}
+
+	for _, tc := range tests {
+		want, err := resampleToTarget(src, tc.srcRate, TargetSampleRate, tc.srcChannels, TargetChannels)
+		if err != nil {
+			t.Fatalf("resampleToTarget() error: %v", err)
+		}
+
+		for _, chunk := range []int{1, 7, 1000, len(src)} {
+			n := newNormalizer(V1, tc.srcRate, TargetSampleRate, tc.srcChannels, TargetChannels)
+			var got []int16
+			for start := 0; start < len(src); start += chunk {
+				end := start + chunk
+				if end > len(src) {
+					end = len(src)
+				}
+				got = n.push(src[start:end], got)
+			}
+			got = n.finish(got)
+
+			if len(got) != len(want) {
+				t.Fatalf("rate %d ch %d chunk %d: got %d samples, want %d", tc.srcRate, tc.srcChannels, chunk, len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("rate %d ch %d chunk %d: sample %d = %d, want %d", tc.srcRate, tc.srcChannels, chunk, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestSincNormalizerChunkingIsInvisible(t *testing.T) {
+	src := make([]int16, 30011)
+	for i := range src {
+		src[i] = int16((i * 7919) % 65536)
+	}
+
+	for _, srcRate := range []int{48000, 22050, 32000, 8000} {
+		want, err := normalize(src, srcRate, 1, V2)
+		if err != nil {
+			t.Fatalf("normalize() error: %v", err)
+		}
+
+		for _, chunk := range []int{1, 7, 1000} {
+			n := newNormalizer(V2, srcRate, TargetSampleRate, 1, TargetChannels)
+			var got []int16
+			for start := 0; start < len(src); start += chunk {
+				got = n.push(src[start:min(start+chunk, len(src))], got)
+			}
+			got = n.finish(got)
+			if !bytes.Equal(int16Bytes(got), int16Bytes(want)) {
+				t.Fatalf("rate %d chunk %d: chunked output differs from one-shot output", srcRate, chunk)
+			}
+		}
+	}
+}
+
+func TestNormalizerMemoryStaysBounded(t *testing.T) {
+	for _, version := range []Version{V1, V2} {
+		n := newNormalizer(version, 48000, TargetSampleRate, 2, TargetChannels)
+		chunk := make([]int16, streamChunkSamples)
+		var out []int16
+		for i := 0; i < 2000; i++ {
+			out = n.push(chunk, out[:0])
+
+			var window int
+			switch conv := n.conv.(type) {
+			case *linearResampler:
+				window = len(conv.window)
+			case *sincResampler:
+				window = len(conv.window)
+			}
+			if window > 4*sincHalfTaps || len(n.frame) > 1 {
+				t.Fatalf("v%d after %d chunks window=%d frame=%d, want bounded", version, i, window, len(n.frame))
+			}
+		}
+	}
+}
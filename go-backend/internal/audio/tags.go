@@ -0,0 +1,774 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Tags is descriptive metadata embedded in an audio file
+type Tags struct {
+	Title       string `json:"title,omitempty"`
+	Artist      string `json:"artist,omitempty"`
+	Album       string `json:"album,omitempty"`
+	TrackNumber int    `json:"track_number,omitempty"`
+	Year        int    `json:"year,omitempty"`
+	Genre       string `json:"genre,omitempty"`
+	// Picture is the embedded front cover, or the first picture if no
+	// picture is marked as the front cover
+	Picture *Picture `json:"-"`
+}
+
+// Picture is artwork embedded in an audio file
+type Picture struct {
+	MIMEType string
+	Type     int // ID3v2/FLAC picture type
+	Data     []byte
+}
+
+// PictureFrontCover is the picture type of a front cover
+const PictureFrontCover = 3
+
+// setPicture keeps the first picture found, replacing it only with a front
+// cover
+func setPicture(field **Picture, picture *Picture) {
+	if picture == nil || len(picture.Data) == 0 {
+		return
+	}
+	if *field == nil || ((*field).Type != PictureFrontCover && picture.Type == PictureFrontCover) {
+		*field = picture
+	}
+}
+
+// maxTagBytes bounds how much of a file is read for one tag block, so huge
+// embedded pictures do not cost unbounded memory
+const maxTagBytes = 16 << 20
+
+// ReadTagsFile reads the tags embedded in an audio file. It returns nil and
+// no error when the file carries no tags.
+func ReadTagsFile(filePath string) (*Tags, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return ReadTags(file, info.Size())
+}
+
+// ReadTags reads ID3v2 and ID3v1 tags, FLAC and Ogg Vorbis/Opus comments and
+// MP4 ilst atoms. Where a file has several, ID3v2 wins over ID3v1.
+func ReadTags(r io.ReaderAt, size int64) (*Tags, error) {
+	tags := &Tags{}
+
+	head := make([]byte, 12)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	head = head[:n]
+
+	offset := id3v2Size(head)
+	if offset > 0 {
+		if err := readID3v2(r, size, tags); err != nil {
+			return nil, err
+		}
+		head = head[:cap(head)]
+		n, err := r.ReadAt(head, offset)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+		head = head[:n]
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		err = readFLACTags(r, offset+4, size, tags)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		err = readOggTags(r, offset, size, tags)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		err = readMP4Tags(r, size, tags)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// ID3v1 only fills what richer tags left blank
+	if err := readID3v1(r, size, tags); err != nil {
+		return nil, err
+	}
+
+	if *tags == (Tags{}) {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// readAtMost reads up to n bytes at offset, bounded by the file size
+func readAtMost(r io.ReaderAt, offset, n, size int64) ([]byte, error) {
+	if offset >= size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	n = min(n, size-offset, maxTagBytes)
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// setText fills a text field unless it is already set
+func setText(field *string, value string) {
+	value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	if *field == "" && value != "" {
+		*field = value
+	}
+}
+
+// setNumber fills a numeric field from the leading digits of value, so
+// "3/12" gives 3 and "2004-05-01" gives 2004
+func setNumber(field *int, value string) {
+	value = strings.TrimSpace(value)
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	if n, err := strconv.Atoi(value[:end]); err == nil && *field == 0 && n > 0 {
+		*field = n
+	}
+}
+
+// setGenre fills the genre, resolving ID3v1 genre numbers such as "(17)",
+// "(17)Rock" or "17"
+func setGenre(field *string, value string) {
+	value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	if strings.HasPrefix(value, "(") {
+		if end := strings.IndexByte(value, ')'); end > 0 {
+			if rest := strings.TrimSpace(value[end+1:]); rest != "" {
+				value = rest
+			} else {
+				value = value[1:end]
+			}
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		value = id3v1Genre(n)
+	}
+	setText(field, value)
+}
+
+// readID3v2 parses an ID3v2.2, 2.3 or 2.4 tag at the start of the file
+func readID3v2(r io.ReaderAt, size int64, tags *Tags) error {
+	header := make([]byte, 10)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("invalid ID3v2 tag: %w", err)
+	}
+	version, flags := header[3], header[5]
+	if version < 2 || version > 4 {
+		return nil // unknown major version; frames cannot be parsed
+	}
+
+	tagSize := id3v2Size(header) - 10
+	if flags&0x10 != 0 {
+		tagSize -= 10 // footer
+	}
+	body, err := readAtMost(r, 10, tagSize, size)
+	if err != nil {
+		return fmt.Errorf("invalid ID3v2 tag: %w", err)
+	}
+	if flags&0x80 != 0 && version < 4 {
+		body = removeUnsync(body)
+	}
+
+	// Skip the extended header
+	if flags&0x40 != 0 && version >= 3 && len(body) >= 4 {
+		extSize := int(binary.BigEndian.Uint32(body[0:4]))
+		if version == 3 {
+			extSize += 4 // v2.3 does not count the size field itself
+		} else {
+			extSize = synchsafe(body[0:4])
+		}
+		if extSize > len(body) {
+			return nil
+		}
+		body = body[extSize:]
+	}
+
+	var albumArtist string
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+	for len(body) >= headerLen && body[0] != 0 {
+		id := string(body[:idLen])
+		var frameSize int
+		var frameFlags byte
+		switch version {
+		case 2:
+			frameSize = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		case 3:
+			frameSize = int(binary.BigEndian.Uint32(body[4:8]))
+			frameFlags = body[9]
+		case 4:
+			frameSize = synchsafe(body[4:8])
+			frameFlags = body[9]
+		}
+		if frameSize <= 0 || frameSize > len(body)-headerLen {
+			break
+		}
+		data := body[headerLen : headerLen+frameSize]
+		body = body[headerLen+frameSize:]
+
+		if version == 4 && flags&0x80 != 0 {
+			frameFlags |= 0x02 // tag-wide unsynchronisation applies to every frame
+		}
+		data, ok := id3FrameData(data, version, frameFlags)
+		if !ok {
+			continue
+		}
+		if id == "APIC" || id == "PIC" {
+			setPicture(&tags.Picture, parseID3Picture(data, id == "PIC"))
+			continue
+		}
+		if id == "TPE2" || id == "TP2" {
+			// Album artist stands in when there is no track artist
+			setText(&albumArtist, decodeID3Text(data))
+			continue
+		}
+		applyID3Frame(id, decodeID3Text(data), tags)
+	}
+	setText(&tags.Artist, albumArtist)
+	return nil
+}
+
+// id3FrameData strips per-frame headers and undoes per-frame
+// unsynchronisation. Compressed and encrypted frames are skipped.
+func id3FrameData(data []byte, version, flags byte) ([]byte, bool) {
+	switch version {
+	case 3:
+		if flags&0xc0 != 0 {
+			return nil, false
+		}
+		if flags&0x20 != 0 {
+			data = data[min(1, len(data)):] // group identifier
+		}
+	case 4:
+		if flags&0x0c != 0 {
+			return nil, false
+		}
+		if flags&0x40 != 0 {
+			data = data[min(1, len(data)):] // group identifier
+		}
+		if flags&0x01 != 0 {
+			data = data[min(4, len(data)):] // data length indicator
+		}
+		if flags&0x02 != 0 {
+			data = removeUnsync(data)
+		}
+	}
+	return data, true
+}
+
+func applyID3Frame(id, text string, tags *Tags) {
+	switch id {
+	case "TIT2", "TT2":
+		setText(&tags.Title, text)
+	case "TPE1", "TP1":
+		setText(&tags.Artist, text)
+	case "TALB", "TAL":
+		setText(&tags.Album, text)
+	case "TRCK", "TRK":
+		setNumber(&tags.TrackNumber, text)
+	case "TDRC", "TYER", "TYE", "TDOR", "TORY":
+		setNumber(&tags.Year, text)
+	case "TCON", "TCO":
+		setGenre(&tags.Genre, text)
+	}
+}
+
+// parseID3Picture parses an APIC frame, or a PIC frame from ID3v2.2 which
+// has a three-letter image format instead of a MIME type
+func parseID3Picture(data []byte, v22 bool) *Picture {
+	if len(data) < 2 {
+		return nil
+	}
+	encoding, data := data[0], data[1:]
+
+	var mime string
+	if v22 {
+		if len(data) < 3 {
+			return nil
+		}
+		mime = "image/" + strings.ToLower(string(data[:3]))
+		if mime == "image/jpg" {
+			mime = "image/jpeg"
+		}
+		data = data[3:]
+	} else {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return nil
+		}
+		mime = string(data[:end])
+		data = data[end+1:]
+	}
+	if len(data) < 1 {
+		return nil
+	}
+	typ := int(data[0])
+	data = data[1:]
+
+	// Skip the description, terminated by one or two NULs by encoding
+	if encoding == 1 || encoding == 2 {
+		end := -1
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil
+		}
+		data = data[end+2:]
+	} else {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return nil
+		}
+		data = data[end+1:]
+	}
+	return &Picture{MIMEType: pictureMIME(mime, data), Type: typ, Data: data}
+}
+
+// pictureMIME returns the declared MIME type, or one sniffed from the data
+// when the tag leaves it out or uses a bare subtype
+func pictureMIME(declared string, data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG")):
+		return "image/png"
+	}
+	if declared != "" && !strings.Contains(declared, "/") {
+		return "image/" + strings.ToLower(declared)
+	}
+	return declared
+}
+
+// decodeID3Text decodes a text frame body. Only the first of several
+// NUL-separated values is returned.
+func decodeID3Text(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	encoding, data := data[0], data[1:]
+	switch encoding {
+	case 0:
+		return firstValue(latin1(data))
+	case 1, 2:
+		return firstValue(decodeUTF16(data, encoding == 2))
+	case 3:
+		return firstValue(string(data))
+	}
+	return ""
+}
+
+func firstValue(s string) string {
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// decodeUTF16 decodes UTF-16 text with an optional byte order mark
+func decodeUTF16(data []byte, bigEndian bool) string {
+	if len(data) >= 2 {
+		switch {
+		case data[0] == 0xff && data[1] == 0xfe:
+			bigEndian, data = false, data[2:]
+		case data[0] == 0xfe && data[1] == 0xff:
+			bigEndian, data = true, data[2:]
+		}
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = binary.BigEndian.Uint16(data[2*i:])
+		} else {
+			units[i] = binary.LittleEndian.Uint16(data[2*i:])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+func latin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// removeUnsync undoes ID3 unsynchronisation, which inserts a zero after
+// every 0xFF byte
+func removeUnsync(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		out = append(out, data[i])
+		if data[i] == 0xff && i+1 < len(data) && data[i+1] == 0 {
+			i++
+		}
+	}
+	return out
+}
+
+func synchsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// readID3v1 parses the 128-byte ID3v1 (or v1.1) tag at the end of the file
+func readID3v1(r io.ReaderAt, size int64, tags *Tags) error {
+	if size < 128 {
+		return nil
+	}
+	tag := make([]byte, 128)
+	if _, err := r.ReadAt(tag, size-128); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read ID3v1 tag: %w", err)
+	}
+	if string(tag[0:3]) != "TAG" {
+		return nil
+	}
+
+	field := func(b []byte) string { return latin1(bytes.TrimRight(b, "\x00 ")) }
+	setText(&tags.Title, field(tag[3:33]))
+	setText(&tags.Artist, field(tag[33:63]))
+	setText(&tags.Album, field(tag[63:93]))
+	setNumber(&tags.Year, field(tag[93:97]))
+	// ID3v1.1 stores the track number in the last comment byte
+	if tag[125] == 0 && tag[126] != 0 {
+		setNumber(&tags.TrackNumber, strconv.Itoa(int(tag[126])))
+	}
+	if tag[127] != 0xff {
+		setText(&tags.Genre, id3v1Genre(int(tag[127])))
+	}
+	return nil
+}
+
+// readFLACTags reads the VORBIS_COMMENT and PICTURE blocks among the FLAC
+// metadata blocks
+func readFLACTags(r io.ReaderAt, offset, size int64, tags *Tags) error {
+	header := make([]byte, 4)
+	for offset+4 <= size {
+		if _, err := r.ReadAt(header, offset); err != nil {
+			return fmt.Errorf("invalid FLAC metadata: %w", err)
+		}
+		last, typ := header[0]&0x80 != 0, header[0]&0x7f
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		if typ == 4 || typ == 6 {
+			block, err := readAtMost(r, offset+4, length, size)
+			if err != nil {
+				return fmt.Errorf("invalid FLAC metadata: %w", err)
+			}
+			if typ == 4 {
+				applyVorbisComments(block, tags)
+			} else {
+				setPicture(&tags.Picture, parseFLACPicture(block))
+			}
+		}
+		if last {
+			return nil
+		}
+		offset += 4 + length
+	}
+	return nil
+}
+
+// parseFLACPicture parses a FLAC PICTURE block, also found base64-encoded in
+// METADATA_BLOCK_PICTURE comments
+func parseFLACPicture(block []byte) *Picture {
+	next := func() ([]byte, bool) {
+		if len(block) < 4 {
+			return nil, false
+		}
+		n := binary.BigEndian.Uint32(block[0:4])
+		if uint64(n) > uint64(len(block)-4) {
+			return nil, false
+		}
+		field := block[4 : 4+n]
+		block = block[4+n:]
+		return field, true
+	}
+
+	if len(block) < 4 {
+		return nil
+	}
+	typ := int(binary.BigEndian.Uint32(block[0:4]))
+	block = block[4:]
+	mime, ok := next()
+	if !ok {
+		return nil
+	}
+	if _, ok := next(); !ok { // description
+		return nil
+	}
+	if len(block) < 16 {
+		return nil
+	}
+	block = block[16:] // width, height, depth, colors
+	data, ok := next()
+	if !ok {
+		return nil
+	}
+	return &Picture{MIMEType: pictureMIME(string(mime), data), Type: typ, Data: data}
+}
+
+// readOggTags reads the comment header, the second packet of the first
+// logical stream, of an Ogg Vorbis or Opus file
+func readOggTags(r io.ReaderAt, offset, size int64, tags *Tags) error {
+	packets, err := readOggPackets(r, offset, size, 2)
+	if err != nil {
+		return fmt.Errorf("invalid Ogg file: %w", err)
+	}
+	if len(packets) < 2 {
+		return nil
+	}
+
+	comment := packets[1]
+	switch {
+	case bytes.HasPrefix(comment, []byte("\x03vorbis")):
+		applyVorbisComments(comment[7:], tags)
+	case bytes.HasPrefix(comment, []byte("OpusTags")):
+		applyVorbisComments(comment[8:], tags)
+	}
+	return nil
+}
+
+// readOggPackets reassembles the first count packets of the first logical
+// stream, following packets across page boundaries
+func readOggPackets(r io.ReaderAt, offset, size int64, count int) ([][]byte, error) {
+	var packets [][]byte
+	var current []byte
+	var serial uint32
+	header := make([]byte, 27+255)
+
+	for first := true; len(packets) < count && offset+27 <= size; first = false {
+		if _, err := r.ReadAt(header[:27], offset); err != nil {
+			return nil, err
+		}
+		if string(header[0:4]) != "OggS" {
+			return nil, fmt.Errorf("missing page sync at %d", offset)
+		}
+		pageSerial := binary.LittleEndian.Uint32(header[14:18])
+		if first {
+			serial = pageSerial
+		}
+		segments := int(header[26])
+		if _, err := r.ReadAt(header[27:27+segments], offset+27); err != nil {
+			return nil, err
+		}
+		lacing := header[27 : 27+segments]
+		dataLen := 0
+		for _, l := range lacing {
+			dataLen += int(l)
+		}
+		dataStart := offset + 27 + int64(segments)
+		offset = dataStart + int64(dataLen)
+		if pageSerial != serial {
+			continue // another multiplexed stream
+		}
+
+		data := make([]byte, dataLen)
+		if _, err := r.ReadAt(data, dataStart); err != nil && err != io.EOF {
+			return nil, err
+		}
+		for _, l := range lacing {
+			current = append(current, data[:l]...)
+			data = data[l:]
+			if len(current) > maxTagBytes {
+				return nil, fmt.Errorf("packet exceeds %d bytes", maxTagBytes)
+			}
+			if l < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == count {
+					break
+				}
+			}
+		}
+	}
+	return packets, nil
+}
+
+// applyVorbisComments parses a Vorbis comment block (vendor string followed
+// by KEY=value pairs, as used by Vorbis, Opus and FLAC)
+func applyVorbisComments(block []byte, tags *Tags) {
+	next := func() (string, bool) {
+		if len(block) < 4 {
+			return "", false
+		}
+		n := binary.LittleEndian.Uint32(block[0:4])
+		if uint64(n) > uint64(len(block)-4) {
+			return "", false
+		}
+		s := string(block[4 : 4+n])
+		block = block[4+n:]
+		return s, true
+	}
+
+	if _, ok := next(); !ok { // vendor
+		return
+	}
+	if len(block) < 4 {
+		return
+	}
+	count := binary.LittleEndian.Uint32(block[0:4])
+	block = block[4:]
+
+	var albumArtist string
+	for i := uint32(0); i < count; i++ {
+		comment, ok := next()
+		if !ok {
+			break
+		}
+		key, value, found := strings.Cut(comment, "=")
+		if !found {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			setText(&tags.Title, value)
+		case "ARTIST":
+			setText(&tags.Artist, value)
+		case "ALBUMARTIST", "ALBUM ARTIST":
+			setText(&albumArtist, value)
+		case "ALBUM":
+			setText(&tags.Album, value)
+		case "TRACKNUMBER":
+			setNumber(&tags.TrackNumber, value)
+		case "DATE", "YEAR":
+			setNumber(&tags.Year, value)
+		case "GENRE":
+			setGenre(&tags.Genre, value)
+		case "METADATA_BLOCK_PICTURE":
+			if block, err := base64.StdEncoding.DecodeString(value); err == nil {
+				setPicture(&tags.Picture, parseFLACPicture(block))
+			}
+		}
+	}
+	setText(&tags.Artist, albumArtist)
+}
+
+// readMP4Tags reads iTunes-style metadata from moov/udta/meta/ilst
+func readMP4Tags(r io.ReaderAt, size int64, tags *Tags) error {
+	moov, ok := findBox(r, 0, size, "moov")
+	if !ok {
+		return nil
+	}
+	meta, ok := findBoxPath(r, moov, "udta", "meta")
+	if !ok {
+		return nil
+	}
+
+	// meta is a full box with 4 bytes of version and flags, except in some
+	// QuickTime files where children follow directly
+	start := meta.start
+	peek := make([]byte, 8)
+	if _, err := r.ReadAt(peek, meta.start); err == nil && string(peek[4:8]) != "hdlr" {
+		start += 4
+	}
+	ilst, ok := findBox(r, start, meta.end, "ilst")
+	if !ok {
+		return nil
+	}
+
+	var albumArtist string
+	err := forEachBox(r, ilst.start, ilst.end, func(item box) bool {
+		data, ok := findBox(r, item.start, item.end, "data")
+		if !ok || data.end-data.start < 8 {
+			return true
+		}
+		payload, err := readAtMost(r, data.start, data.end-data.start, size)
+		if err != nil || len(payload) < 8 {
+			return true
+		}
+		value := payload[8:] // type indicator and locale come first
+
+		switch item.typ {
+		case "\xa9nam":
+			setText(&tags.Title, string(value))
+		case "\xa9ART":
+			setText(&tags.Artist, string(value))
+		case "aART":
+			setText(&albumArtist, string(value))
+		case "\xa9alb":
+			setText(&tags.Album, string(value))
+		case "\xa9day":
+			setNumber(&tags.Year, string(value))
+		case "\xa9gen":
+			setGenre(&tags.Genre, string(value))
+		case "gnre":
+			// ID3v1 genre number plus one
+			if len(value) >= 2 {
+				setText(&tags.Genre, id3v1Genre(int(binary.BigEndian.Uint16(value))-1))
+			}
+		case "covr":
+			// The type indicator says JPEG (13) or PNG (14); sniffing covers both
+			setPicture(&tags.Picture, &Picture{MIMEType: pictureMIME("", value), Type: PictureFrontCover, Data: value})
+		case "trkn":
+			// Reserved, track number, total tracks
+			if len(value) >= 4 {
+				setNumber(&tags.TrackNumber, strconv.Itoa(int(binary.BigEndian.Uint16(value[2:4]))))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("invalid MP4 metadata: %w", err)
+	}
+	setText(&tags.Artist, albumArtist)
+	return nil
+}
+
+// id3v1Genres is the ID3v1 genre list including the Winamp extensions
+var id3v1Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge",
+	"Hip-Hop", "Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B",
+	"Rap", "Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska",
+	"Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient",
+	"Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance", "Classical",
+	"Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative",
+	"Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic", "Darkwave",
+	"Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap",
+	"Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave",
+	"Psychadelic", "Rave", "Showtunes", "Trailer", "Lo-Fi", "Tribal",
+	"Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll",
+	"Hard Rock", "Folk", "Folk-Rock", "National Folk", "Swing", "Fast Fusion",
+	"Bebob", "Latin", "Revival", "Celtic", "Bluegrass", "Avantgarde",
+	"Gothic Rock", "Progressive Rock", "Psychedelic Rock", "Symphonic Rock",
+	"Slow Rock", "Big Band", "Chorus", "Easy Listening", "Acoustic", "Humour",
+	"Speech", "Chanson", "Opera", "Chamber Music", "Sonata", "Symphony",
+	"Booty Bass", "Primus", "Porn Groove", "Satire", "Slow Jam", "Club",
+	"Tango", "Samba", "Folklore", "Ballad", "Power Ballad", "Rhythmic Soul",
+	"Freestyle", "Duet", "Punk Rock", "Drum Solo", "A capella", "Euro-House",
+	"Dance Hall",
+}
+
+func id3v1Genre(n int) string {
+	if n < 0 || n >= len(id3v1Genres) {
+		return ""
+	}
+	return id3v1Genres[n]
+}
@@ -0,0 +1,360 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// id3Frame builds an ID3v2 frame for the given major version
+func id3Frame(version byte, id string, body []byte) []byte {
+	n := len(body)
+	switch version {
+	case 2:
+		return append([]byte{id[0], id[1], id[2], byte(n >> 16), byte(n >> 8), byte(n)}, body...)
+	case 3:
+		header := append([]byte(id), 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(header[4:], uint32(n))
+		return append(header, body...)
+	default:
+		header := append([]byte(id), byte(n>>21&0x7f), byte(n>>14&0x7f), byte(n>>7&0x7f), byte(n&0x7f), 0, 0)
+		return append(header, body...)
+	}
+}
+
+func id3v2Tag(version byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	n := len(body)
+	header := []byte{'I', 'D', '3', version, 0, 0, byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	return append(header, body...)
+}
+
+func latin1Text(s string) []byte { return append([]byte{0}, s...) }
+func utf8Text(s string) []byte   { return append([]byte{3}, s...) }
+
+func utf16Text(s string) []byte {
+	out := []byte{1, 0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, u)
+	}
+	return out
+}
+
+func id3v1Tag(title, artist, album, year string, track, genre byte) []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:33], title)
+	copy(tag[33:63], artist)
+	copy(tag[63:93], album)
+	copy(tag[93:97], year)
+	tag[126] = track
+	tag[127] = genre
+	return tag
+}
+
+func vorbisComments(vendor string, comments ...string) []byte {
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(vendor)))
+	out = append(out, vendor...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(comments)))
+	for _, c := range comments {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(c)))
+		out = append(out, c...)
+	}
+	return out
+}
+
+func flacWithComments(block []byte) []byte {
+	out := []byte("fLaC")
+	out = append(out, 0, 0, 0, 34) // STREAMINFO
+	out = append(out, make([]byte, 34)...)
+	out = append(out, 0x84, byte(len(block)>>16), byte(len(block)>>8), byte(len(block)))
+	return append(out, block...)
+}
+
+// oggStream lays packets out in pages of at most pageSegments lacing values,
+// so packets can be made to span pages. CRCs are left zero.
+func oggStream(serial uint32, pageSegments int, packets ...[]byte) []byte {
+	var lacing []byte
+	var data []byte
+	for _, p := range packets {
+		n := len(p)
+		for ; n >= 255; n -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(n))
+		data = append(data, p...)
+	}
+
+	var out []byte
+	for seq := uint32(0); len(lacing) > 0; seq++ {
+		segs := lacing[:min(pageSegments, len(lacing))]
+		lacing = lacing[len(segs):]
+		header := make([]byte, 27)
+		copy(header, "OggS")
+		binary.LittleEndian.PutUint32(header[14:], serial)
+		binary.LittleEndian.PutUint32(header[18:], seq)
+		header[26] = byte(len(segs))
+		size := 0
+		for _, l := range segs {
+			size += int(l)
+		}
+		out = append(out, header...)
+		out = append(out, segs...)
+		out = append(out, data[:size]...)
+		data = data[size:]
+	}
+	return out
+}
+
+func testM4AWithTags() []byte {
+	dataBox := func(typ uint32, value []byte) []byte {
+		head := binary.BigEndian.AppendUint32(nil, typ)
+		return mp4Box("data", head, make([]byte, 4), value)
+	}
+	ilst := mp4Box("ilst",
+		mp4Box("\xa9nam", dataBox(1, []byte("Atom Title"))),
+		mp4Box("\xa9ART", dataBox(1, []byte("Atom Artist"))),
+		mp4Box("\xa9alb", dataBox(1, []byte("Atom Album"))),
+		mp4Box("\xa9day", dataBox(1, []byte("2011-03-04T00:00:00Z"))),
+		mp4Box("trkn", dataBox(0, []byte{0, 0, 0, 9, 0, 12, 0, 0})),
+		mp4Box("gnre", dataBox(0, []byte{0, 10})), // Metal (9) plus one
+	)
+	meta := mp4Box("meta", make([]byte, 4), mp4Box("hdlr", make([]byte, 25)), ilst)
+	return testM4A(mp4Box("udta", meta))
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	return data
+}
+
+func TestReadTags(t *testing.T) {
+	mp3 := readFixture(t, "mozart_44k_stereo.mp3")
+
+	tests := []struct {
+		name string
+		data []byte
+		want Tags
+	}{
+		{
+			name: "id3v2.3 utf-16",
+			data: append(id3v2Tag(3,
+				id3Frame(3, "TIT2", utf16Text("Ночной город")),
+				id3Frame(3, "TPE1", latin1Text("Caf\xe9 Band")),
+				id3Frame(3, "TALB", latin1Text("Lights")),
+				id3Frame(3, "TRCK", latin1Text("3/12")),
+				id3Frame(3, "TYER", latin1Text("1999")),
+				id3Frame(3, "TCON", latin1Text("(17)")),
+			), mp3...),
+			want: Tags{Title: "Ночной город", Artist: "Café Band", Album: "Lights", TrackNumber: 3, Year: 1999, Genre: "Rock"},
+		},
+		{
+			name: "id3v2.4 album artist fallback",
+			data: append(id3v2Tag(4,
+				id3Frame(4, "TIT2", utf8Text("Title\x00Alternate")),
+				id3Frame(4, "TPE2", utf8Text("Album Artist")),
+				id3Frame(4, "TDRC", utf8Text("2004-05-01")),
+				id3Frame(4, "TCON", utf8Text("Jazz")),
+			), mp3...),
+			want: Tags{Title: "Title", Artist: "Album Artist", Year: 2004, Genre: "Jazz"},
+		},
+		{
+			name: "id3v2.2",
+			data: append(id3v2Tag(2,
+				id3Frame(2, "TT2", latin1Text("Old Title")),
+				id3Frame(2, "TP1", latin1Text("Old Artist")),
+			), mp3...),
+			want: Tags{Title: "Old Title", Artist: "Old Artist"},
+		},
+		{
+			name: "id3v1.1",
+			data: append(append([]byte{}, mp3...), id3v1Tag("V1 Title", "V1 Artist", "V1 Album", "1987", 7, 13)...),
+			want: Tags{Title: "V1 Title", Artist: "V1 Artist", Album: "V1 Album", TrackNumber: 7, Year: 1987, Genre: "Pop"},
+		},
+		{
+			name: "id3v2 wins over id3v1",
+			data: append(append(id3v2Tag(3, id3Frame(3, "TIT2", latin1Text("V2 Title"))), mp3...),
+				id3v1Tag("V1 Title", "V1 Artist", "", "", 0, 0xff)...),
+			want: Tags{Title: "V2 Title", Artist: "V1 Artist"},
+		},
+		{
+			name: "flac",
+			data: flacWithComments(vorbisComments("reference libFLAC",
+				"title=Flac Title", "ARTIST=Flac Artist", "ALBUM=Flac Album",
+				"TRACKNUMBER=04", "DATE=2019", "GENRE=Ambient")),
+			want: Tags{Title: "Flac Title", Artist: "Flac Artist", Album: "Flac Album", TrackNumber: 4, Year: 2019, Genre: "Ambient"},
+		},
+		{
+			name: "ogg vorbis across pages",
+			data: oggStream(7, 2,
+				append([]byte("\x01vorbis"), make([]byte, 23)...),
+				append([]byte("\x03vorbis"), vorbisComments(string(make([]byte, 600)),
+					"TITLE=Ogg Title", "ALBUMARTIST=Ogg Album Artist")...),
+			),
+			want: Tags{Title: "Ogg Title", Artist: "Ogg Album Artist"},
+		},
+		{
+			name: "opus",
+			data: oggStream(3, 255,
+				append([]byte("OpusHead"), make([]byte, 11)...),
+				append([]byte("OpusTags"), vorbisComments("libopus", "TITLE=Opus Title", "ARTIST=Opus Artist")...),
+			),
+			want: Tags{Title: "Opus Title", Artist: "Opus Artist"},
+		},
+		{
+			name: "mp4",
+			data: testM4AWithTags(),
+			want: Tags{Title: "Atom Title", Artist: "Atom Artist", Album: "Atom Album", TrackNumber: 9, Year: 2011, Genre: "Metal"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ReadTags(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if err != nil {
+				t.Fatalf("ReadTags() error: %v", err)
+			}
+			if got == nil || *got != tc.want {
+				t.Fatalf("ReadTags() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadTagsFileWithoutTags(t *testing.T) {
+	for _, name := range []string{"mozart_44k_stereo.mp3", "tiny_opus.ogg"} {
+		got, err := ReadTagsFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("ReadTagsFile(%s) error: %v", name, err)
+		}
+		if got != nil {
+			t.Fatalf("ReadTagsFile(%s) = %+v, want nil", name, got)
+		}
+	}
+}
+
+func TestReadTagsSurvivesTruncation(t *testing.T) {
+	inputs := [][]byte{
+		id3v2Tag(4, id3Frame(4, "TIT2", utf8Text("Title")), id3Frame(4, "TPE1", utf16Text("Artist"))),
+		id3v2Tag(3, apicFrame(3, "image/jpeg", 3, []byte("cover"), testJPEG)),
+		flacWithComments(vorbisComments("vendor", "TITLE=Title")),
+		oggStream(1, 1, []byte("\x01vorbis"), append([]byte("\x03vorbis"), vorbisComments("v", "TITLE=T")...)),
+		testM4AWithTags(),
+	}
+	for _, data := range inputs {
+		for n := 0; n <= len(data); n++ {
+			// Errors are fine; panics are not
+			ReadTags(bytes.NewReader(data[:n]), int64(n))
+		}
+	}
+}
+
+// testJPEG is enough of a JPEG for format sniffing
+var testJPEG = []byte("\xff\xd8\xff\xe0cover-bytes")
+
+func apicFrame(version byte, mime string, typ byte, description []byte, data []byte) []byte {
+	body := []byte{0}
+	if version == 2 {
+		body = append(body, mime...) // three-letter format
+	} else {
+		body = append(append(body, mime...), 0)
+	}
+	body = append(append(append(body, typ), description...), 0)
+	id := "APIC"
+	if version == 2 {
+		id = "PIC"
+	}
+	return id3Frame(version, id, append(body, data...))
+}
+
+func flacPicture(typ uint32, mime string, data []byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, typ)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(mime)))
+	out = append(out, mime...)
+	out = binary.BigEndian.AppendUint32(out, 0) // description
+	out = append(out, make([]byte, 16)...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(data)))
+	return append(out, data...)
+}
+
+func TestReadTagsPicture(t *testing.T) {
+	mp3 := readFixture(t, "mozart_44k_stereo.mp3")
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+
+	flac := []byte("fLaC")
+	flac = append(flac, 0, 0, 0, 34) // STREAMINFO
+	flac = append(flac, make([]byte, 34)...)
+	for i, block := range [][]byte{flacPicture(4, "image/png", png), flacPicture(3, "image/jpeg", testJPEG)} {
+		typ := byte(6)
+		if i == 1 {
+			typ |= 0x80 // last block
+		}
+		flac = append(flac, typ, byte(len(block)>>16), byte(len(block)>>8), byte(len(block)))
+		flac = append(flac, block...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want Picture
+	}{
+		{
+			name: "id3v2.3 front cover preferred",
+			data: append(id3v2Tag(3,
+				apicFrame(3, "image/png", 4, []byte("back"), png),
+				apicFrame(3, "image/jpeg", 3, []byte("front"), testJPEG),
+			), mp3...),
+			want: Picture{MIMEType: "image/jpeg", Type: 3, Data: testJPEG},
+		},
+		{
+			name: "id3v2.2",
+			data: append(id3v2Tag(2, apicFrame(2, "PNG", 0, nil, png)), mp3...),
+			want: Picture{MIMEType: "image/png", Type: 0, Data: png},
+		},
+		{
+			name: "flac",
+			data: flac,
+			want: Picture{MIMEType: "image/jpeg", Type: 3, Data: testJPEG},
+		},
+		{
+			name: "opus",
+			data: oggStream(3, 255,
+				append([]byte("OpusHead"), make([]byte, 11)...),
+				append([]byte("OpusTags"), vorbisComments("libopus",
+					"METADATA_BLOCK_PICTURE="+base64.StdEncoding.EncodeToString(flacPicture(3, "image/jpeg", testJPEG)))...),
+			),
+			want: Picture{MIMEType: "image/jpeg", Type: 3, Data: testJPEG},
+		},
+		{
+			name: "mp4",
+			data: testM4A(mp4Box("udta", mp4Box("meta", make([]byte, 4), mp4Box("hdlr", make([]byte, 25)),
+				mp4Box("ilst", mp4Box("covr", mp4Box("data", binary.BigEndian.AppendUint32(nil, 14), make([]byte, 4), png)))))),
+			want: Picture{MIMEType: "image/png", Type: PictureFrontCover, Data: png},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ReadTags(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if err != nil {
+				t.Fatalf("ReadTags() error: %v", err)
+			}
+			if got == nil || got.Picture == nil {
+				t.Fatalf("ReadTags() = %+v, want a picture", got)
+			}
+			p := got.Picture
+			if p.MIMEType != tc.want.MIMEType || p.Type != tc.want.Type || !bytes.Equal(p.Data, tc.want.Data) {
+				t.Fatalf("ReadTags() picture = %s type %d %q, want %s type %d %q",
+					p.MIMEType, p.Type, p.Data, tc.want.MIMEType, tc.want.Type, tc.want.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// TranscodeProfile is an encoding a peer can produce on the fly. Bitrates
+// are in bits per second.
+type TranscodeProfile struct {
+	Format         string `json:"format"`
+	MIMEType       string `json:"mime_type"`
+	MinBitrate     int    `json:"min_bitrate"`
+	MaxBitrate     int    `json:"max_bitrate"`
+	DefaultBitrate int    `json:"default_bitrate"`
+}
+
+// Transcode formats
+const (
+	TranscodeOpus = "opus"
+	TranscodeMP3  = "mp3"
+)
+
+// transcoder is how ffmpeg produces a profile
+type transcoder struct {
+	profile TranscodeProfile
+	encoder string   // ffmpeg encoder that must be built in
+	args    []string // output options before the bitrate
+}
+
+var transcoders = []transcoder{
+	{
+		profile: TranscodeProfile{Format: TranscodeOpus, MIMEType: "audio/ogg", MinBitrate: 16000, MaxBitrate: 256000, DefaultBitrate: 96000},
+		encoder: "libopus",
+		args:    []string{"-c:a", "libopus", "-f", "ogg"},
+	},
+	{
+		profile: TranscodeProfile{Format: TranscodeMP3, MIMEType: "audio/mpeg", MinBitrate: 32000, MaxBitrate: 320000, DefaultBitrate: 128000},
+		encoder: "libmp3lame",
+		args:    []string{"-c:a", "libmp3lame", "-f", "mp3"},
+	},
+}
+
+// ProbeTranscodeProfiles returns the profiles the configured ffmpeg has
+// encoders for; none if ffmpeg is unavailable
+func ProbeTranscodeProfiles(ctx context.Context) ([]TranscodeProfile, error) {
+	path, err := lookFFmpeg()
+	if err != nil {
+		return nil, err
+	}
+	var stderr tailBuffer
+	cmd := exec.CommandContext(ctx, path, "-hide_banner", "-encoders")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, newFFmpegError(err, &stderr)
+	}
+
+	// Encoder lines read " A....D libopus   libopus Opus"
+	encoders := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && strings.HasPrefix(fields[0], "A") {
+			encoders[fields[1]] = true
+		}
+	}
+	var profiles []TranscodeProfile
+	for _, t := range transcoders {
+		if encoders[t.encoder] {
+			profiles = append(profiles, t.profile)
+		}
+	}
+	return profiles, nil
+}
+
+// ResolveBitrate picks the bitrate for a request against a profile: zero
+// means the default, anything else must lie within the profile's range
+func (p TranscodeProfile) ResolveBitrate(bitrate int) (int, error) {
+	if bitrate == 0 {
+		return p.DefaultBitrate, nil
+	}
+	if bitrate < p.MinBitrate || bitrate > p.MaxBitrate {
+		return 0, fmt.Errorf("%s bitrate %d outside %d-%d", p.Format, bitrate, p.MinBitrate, p.MaxBitrate)
+	}
+	return bitrate, nil
+}
+
+// Transcode encodes an audio file to format at bitrate bits per second and
+// returns the encoded stream. Reading it to the end reports an
+// *FFmpegError if encoding failed; Close stops the encoder early.
+func Transcode(ctx context.Context, filePath string, format string, bitrate int) (io.ReadCloser, error) {
+	for _, t := range transcoders {
+		if t.profile.Format != format {
+			continue
+		}
+		args := append([]string{"-i", filePath, "-vn", "-map_metadata", "0"}, t.args...)
+		args = append(args, "-b:a", strconv.Itoa(bitrate), "pipe:1")
+		return startFFmpeg(ctx, args...)
+	}
+	return nil, fmt.Errorf("unknown transcode format: %s", format)
+}
@@ -0,0 +1,755 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/host"
+	"github.com/cotune/go-backend/internal/media"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/cotune/go-backend/internal/waveform"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Format identifies node backups
+const Format = "cotune-backup"
+
+// Version is the archive layout this build writes and restores
+const Version = 1
+
+// Archive entries. Artwork and media files are stored under their hashes;
+// the manifest comes last and lists every other entry.
+const (
+	manifestName     = "manifest.json"
+	keyName          = "identity/" + host.KeyFileName
+	tracksName       = "library/tracks.json"
+	fingerprintsName = "library/fingerprints.json" // Track ID -> encoded fingerprint
+	playlistsName    = "library/playlists.json"
+	settingsName     = "settings.json"
+	artworkDir       = "artwork/"
+	mediaDir         = "media/"
+)
+
+// Manifest describes a backup and lets a restore verify every file in it
+type Manifest struct {
+	Format        string      `json:"format"`
+	Version       int         `json:"version"`
+	CreatedAt     int64       `json:"created_at"`
+	PeerID        string      `json:"peer_id"`
+	SchemaVersion int         `json:"schema_version"` // Datastore schema the tracks were saved under
+	Tracks        int         `json:"tracks"`
+	Playlists     int         `json:"playlists"`
+	MediaFiles    int         `json:"media_files"`
+	Artwork       int         `json:"artwork"`
+	Files         []FileEntry `json:"files"`
+	// Vault, set when the identity key and library are sealed, opens them
+	// with the secret the backup was made with
+	Vault json.RawMessage `json:"vault,omitempty"`
+}
+
+// FileEntry is one archived file
+type FileEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Source is the node state a backup is made of
+type Source struct {
+	DataDir string
+	Store   storage.Store
+	Media   *media.Store
+	Artwork *artwork.Store
+	Keyring *vault.Keyring // Opens the identity key of an encrypted node
+}
+
+// Options select what goes into a backup besides identity and library,
+// and how it is protected
+type Options struct {
+	IncludeMedia bool         // Audio files and their waveforms
+	Secret       vault.Secret // Seals the identity key and library
+	Plaintext    bool         // Allows backing up an encrypted node without Secret
+}
+
+// ErrNeedsSecret is returned when an encrypted node is backed up without a
+// secret and Options.Plaintext is not set, and when a sealed backup is
+// restored without one
+var ErrNeedsSecret = errors.New("backup needs a passphrase or key")
+
+// RestoreReport describes a restore
+type RestoreReport struct {
+	PeerID     string `json:"peer_id"`
+	CreatedAt  int64  `json:"created_at"`
+	Tracks     int    `json:"tracks"`
+	Playlists  int    `json:"playlists"`
+	MediaFiles int    `json:"media_files"`
+	Artwork    int    `json:"artwork"`
+	Missing    int    `json:"missing"`   // Tracks left out: no archived or local file
+	Reprocess  int    `json:"reprocess"` // Tracks whose local file changed since the backup
+}
+
+// Create writes a backup of a node as a gzipped tar: the identity key, the
+// tracks, playlists and settings, cover art, and with IncludeMedia the
+// audio files. Files referenced in place are archived under their hash, so
+// a restore moves them into the media store. With a secret the key and
+// library are sealed with a vault of their own; cover art and media are
+// archived as they are stored. An encrypted node is only backed up in the
+// clear when Plaintext allows it.
+func Create(w io.Writer, src Source, opts Options) (*Manifest, error) {
+	if src.Keyring != nil && opts.Secret.IsZero() && !opts.Plaintext {
+		return nil, ErrNeedsSecret
+	}
+	var sealed json.RawMessage
+	var keyring *vault.Keyring
+	if !opts.Secret.IsZero() {
+		var err error
+		if sealed, keyring, err = vault.NewDetached(opts.Secret); err != nil {
+			return nil, fmt.Errorf("failed to create backup vault: %w", err)
+		}
+	}
+
+	key, err := host.LoadKey(src.DataDir, src.Keyring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity key: %w", err)
+	}
+	keyData, err := host.EncodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	peerID, err := keyPeerID(keyData)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := src.Store.GetAllTracks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracks: %w", err)
+	}
+	playlists, err := src.Store.GetAllPlaylists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playlists: %w", err)
+	}
+	settings, err := src.Store.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	aw := &archiveWriter{tw: tar.NewWriter(gz), modTime: time.Now(), keyring: keyring}
+	manifest := &Manifest{
+		Format:        Format,
+		Version:       Version,
+		CreatedAt:     aw.modTime.Unix(),
+		PeerID:        peerID.String(),
+		SchemaVersion: storage.SchemaVersion(),
+		Tracks:        len(tracks),
+		Playlists:     len(playlists),
+		Vault:         sealed,
+	}
+
+	if err := aw.addSealed(keyName, keyData); err != nil {
+		return nil, err
+	}
+
+	covers := make(map[string]bool)
+	for _, track := range tracks {
+		if track.Artwork == "" || covers[track.Artwork] || src.Artwork == nil {
+			continue
+		}
+		data, _, err := src.Artwork.Get(track.Artwork, artwork.SizeOriginal)
+		if errors.Is(err, artwork.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := aw.addData(artworkDir+track.Artwork, data); err != nil {
+			return nil, err
+		}
+		covers[track.Artwork] = true
+	}
+	manifest.Artwork = len(covers)
+
+	archived := tracks
+	if opts.IncludeMedia {
+		if archived, err = aw.addMedia(tracks, src.Media); err != nil {
+			return nil, err
+		}
+		manifest.MediaFiles = aw.mediaFiles
+	}
+
+	if err := aw.addJSON(tracksName, archived); err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[string]string)
+	for _, track := range tracks {
+		if track.FingerprintKey == "" {
+			continue
+		}
+		if encoded, err := src.Store.GetFingerprint(track.ID); err == nil {
+			fingerprints[track.ID] = encoded
+		}
+	}
+	if err := aw.addJSON(fingerprintsName, fingerprints); err != nil {
+		return nil, err
+	}
+	if err := aw.addJSON(playlistsName, playlists); err != nil {
+		return nil, err
+	}
+	if err := aw.addJSON(settingsName, settings); err != nil {
+		return nil, err
+	}
+
+	manifest.Files = aw.files
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := aw.write(manifestName, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := aw.tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// CreateFile writes a backup to path. The archive appears under path only
+// once it is complete, and an existing file is never replaced.
+func CreateFile(path string, src Source, opts Options) (*Manifest, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Create(tmp, src, opts)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write backup: %w", closeErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Linked rather than renamed so an existing file is never replaced
+	if err := os.Link(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return manifest, nil
+}
+
+// archiveWriter adds files to a backup and records them for the manifest
+type archiveWriter struct {
+	tw         *tar.Writer
+	modTime    time.Time
+	files      []FileEntry
+	mediaFiles int
+	keyring    *vault.Keyring // Seals the key and library when set
+}
+
+// addMedia archives the audio of tracks, once per content, and returns the
+// tracks as they are restored: every file in the media store
+func (aw *archiveWriter) addMedia(tracks []*models.Track, store *media.Store) ([]*models.Track, error) {
+	archived := make([]*models.Track, 0, len(tracks))
+	added := make(map[string]bool)
+	for _, track := range tracks {
+		copied := *track
+		archived = append(archived, &copied)
+
+		filePath := track.Path
+		if track.MediaHash != "" && store != nil {
+			if stored, err := store.Path(track.MediaHash); err == nil {
+				filePath = stored
+			}
+		}
+		if filePath == "" {
+			continue
+		}
+		hash := track.MediaHash
+		if hash == "" {
+			var err error
+			if hash, err = hashFile(filePath); errors.Is(err, os.ErrNotExist) {
+				continue // restored only if the file is back in place
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		copied.MediaHash = hash
+		if added[hash] {
+			continue
+		}
+
+		name := mediaDir + hash + strings.ToLower(filepath.Ext(filePath))
+		if err := aw.addPath(name, filePath); errors.Is(err, os.ErrNotExist) {
+			copied.MediaHash = track.MediaHash
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if err := aw.addPath(name+waveform.FileExt, waveform.PathFor(filePath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		added[hash] = true
+		aw.mediaFiles++
+	}
+	return archived, nil
+}
+
+func (aw *archiveWriter) addJSON(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return aw.addSealed(name, data)
+}
+
+// addSealed archives data sealed with the backup's keyring, if it has one,
+// bound to its entry name
+func (aw *archiveWriter) addSealed(name string, data []byte) error {
+	if aw.keyring != nil {
+		var err error
+		if data, err = aw.keyring.Seal(data, name); err != nil {
+			return fmt.Errorf("failed to seal %s: %w", name, err)
+		}
+	}
+	return aw.addData(name, data)
+}
+
+func (aw *archiveWriter) addData(name string, data []byte) error {
+	return aw.add(name, int64(len(data)), bytes.NewReader(data))
+}
+
+func (aw *archiveWriter) addPath(name string, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return aw.add(name, info.Size(), file)
+}
+
+// add archives a file listed in the manifest
+func (aw *archiveWriter) add(name string, size int64, r io.Reader) error {
+	sum := sha256.New()
+	if err := aw.write(name, size, io.TeeReader(r, sum)); err != nil {
+		return err
+	}
+	aw.files = append(aw.files, FileEntry{Name: name, Size: size, SHA256: hex.EncodeToString(sum.Sum(nil))})
+	return nil
+}
+
+func (aw *archiveWriter) write(name string, size int64, r io.Reader) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0600,
+		ModTime:  aw.modTime,
+	}
+	if err := aw.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if _, err := io.CopyN(aw.tw, r, size); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// Restore rebuilds a node in dataDir from a backup. The archive is
+// verified against its manifest before anything is written, and a data
+// directory already holding a library is refused, as is an encrypted one.
+// A sealed backup needs the secret it was made with, and the node it
+// restores is encrypted with that secret; any other is not encrypted until
+// encryption is enabled again. Tracks are not analysed or announced here:
+// the daemon requeues and announces them on start.
+func Restore(r io.Reader, dataDir string, secret vault.Secret) (*RestoreReport, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	// Staged inside the data directory so media can be renamed into place
+	staging, err := os.MkdirTemp(dataDir, ".restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest, err := extract(r, staging)
+	if err != nil {
+		return nil, err
+	}
+	var keyring *vault.Keyring
+	if manifest.Vault != nil {
+		if secret.IsZero() {
+			return nil, ErrNeedsSecret
+		}
+		if keyring, err = vault.OpenDetached(manifest.Vault, secret); err != nil {
+			return nil, fmt.Errorf("failed to open backup: %w", err)
+		}
+	}
+
+	var tracks []*models.Track
+	var playlists []*models.Playlist
+	var settings models.Settings
+	for name, value := range map[string]interface{}{
+		tracksName:    &tracks,
+		playlistsName: &playlists,
+		settingsName:  &settings,
+	} {
+		data, err := readEntry(staging, name, keyring)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	fingerprints, err := readFingerprints(staging, keyring)
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := readEntry(staging, keyName, keyring)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := keyPeerID(keyData); err != nil {
+		return nil, err
+	}
+
+	if vault.Exists(dataDir) {
+		return nil, fmt.Errorf("data directory is encrypted")
+	}
+	store, err := storage.New(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	if existing, err := store.GetAllTracks(); err != nil || len(existing) > 0 {
+		return nil, fmt.Errorf("data directory already holds a library")
+	}
+	if existing, err := store.GetAllPlaylists(); err != nil || len(existing) > 0 {
+		return nil, fmt.Errorf("data directory already holds a library")
+	}
+	mediaStore, err := media.New(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	artworkStore, err := artwork.New(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeKeyring *vault.Keyring
+	if keyring != nil {
+		if _, nodeKeyring, err = vault.Create(dataDir, secret); err != nil {
+			return nil, err
+		}
+		if _, err := store.Reseal(nodeKeyring); err != nil {
+			return nil, fmt.Errorf("failed to encrypt datastore: %w", err)
+		}
+	}
+	if err := restoreKey(dataDir, keyData, nodeKeyring); err != nil {
+		return nil, err
+	}
+	report := &RestoreReport{PeerID: manifest.PeerID, CreatedAt: manifest.CreatedAt}
+
+	for _, file := range manifest.Files {
+		if !strings.HasPrefix(file.Name, artworkDir) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(staging, filepath.FromSlash(file.Name)))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := artworkStore.Put(data); err != nil {
+			return nil, err
+		}
+		report.Artwork++
+	}
+
+	staged := make(map[string]string) // media hash -> staged file
+	for _, file := range manifest.Files {
+		name := strings.TrimPrefix(file.Name, mediaDir)
+		if name == file.Name || strings.HasSuffix(name, waveform.FileExt) {
+			continue
+		}
+		hash := strings.TrimSuffix(name, path.Ext(name))
+		staged[hash] = filepath.Join(staging, filepath.FromSlash(file.Name))
+	}
+
+	restored := make([]*models.Track, 0, len(tracks))
+	for _, track := range tracks {
+		switch {
+		case track.MediaHash != "":
+			if !restoreMedia(track, staged, mediaStore, report) {
+				report.Missing++
+				continue
+			}
+		case track.Path != "":
+			info, err := os.Stat(track.Path)
+			if err != nil {
+				report.Missing++
+				continue
+			}
+			if track.FileSize != 0 && info.Size() != track.FileSize {
+				track.ResetContent()
+				report.Reprocess++
+			}
+			if _, err := os.Stat(waveform.PathFor(track.Path)); err != nil {
+				track.Waveform = false
+			}
+		default:
+			report.Missing++
+			continue
+		}
+		restored = append(restored, track)
+	}
+
+	if err := store.SaveTracks(restored); err != nil {
+		return nil, err
+	}
+	for _, track := range restored {
+		if encoded := fingerprints[track.ID]; encoded != "" && track.FingerprintKey != "" {
+			if err := store.SaveFingerprint(track.ID, encoded); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, pl := range playlists {
+		if err := store.SavePlaylist(pl); err != nil {
+			return nil, err
+		}
+	}
+	if err := store.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	report.Tracks = len(restored)
+	report.Playlists = len(playlists)
+	return report, nil
+}
+
+// readEntry reads a staged entry, opening it with keyring in a sealed
+// backup
+func readEntry(staging, name string, keyring *vault.Keyring) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(staging, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if keyring != nil {
+		if data, err = keyring.Open(data, name); err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+	}
+	return data, nil
+}
+
+// readFingerprints reads the fingerprints of a staged backup
+func readFingerprints(staging string, keyring *vault.Keyring) (map[string]string, error) {
+	data, err := readEntry(staging, fingerprintsName, keyring)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[string]string)
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", fingerprintsName, err)
+	}
+	return fingerprints, nil
+}
+
+// restoreMedia moves the archived file of a track into the media store,
+// falling back to a copy the store already holds
+func restoreMedia(track *models.Track, staged map[string]string, store *media.Store, report *RestoreReport) bool {
+	if stagedPath, ok := staged[track.MediaHash]; ok {
+		delete(staged, track.MediaHash)
+		_, stored, err := store.Adopt(stagedPath, filepath.Ext(stagedPath))
+		if err == nil {
+			report.MediaFiles++
+			peaks := waveform.PathFor(stored)
+			if _, err := os.Stat(peaks); err != nil {
+				os.Rename(waveform.PathFor(stagedPath), peaks)
+			}
+		}
+	}
+	stored, err := store.Path(track.MediaHash)
+	if err != nil {
+		return false
+	}
+	track.Path = stored
+	if _, err := os.Stat(waveform.PathFor(stored)); err != nil {
+		track.Waveform = false
+	}
+	return true
+}
+
+// restoreKey installs the backed up identity, sealed with keyring when it
+// is set, keeping a different key the data directory had as
+// private.key.bak
+func restoreKey(dataDir string, keyData []byte, keyring *vault.Keyring) error {
+	keyPath := filepath.Join(dataDir, host.KeyFileName)
+	if current, err := os.ReadFile(keyPath); err == nil && string(current) != string(keyData) {
+		if err := os.Rename(keyPath, keyPath+".bak"); err != nil {
+			return fmt.Errorf("failed to keep current identity key: %w", err)
+		}
+	}
+	if keyring != nil {
+		key, err := host.DecodeKey(keyData)
+		if err != nil {
+			return fmt.Errorf("invalid identity key: %w", err)
+		}
+		return host.SaveKey(dataDir, key, keyring)
+	}
+	if err := os.WriteFile(keyPath, keyData, 0600); err != nil {
+		return fmt.Errorf("failed to write identity key: %w", err)
+	}
+	return nil
+}
+
+// extract unpacks an archive into dir and verifies it against its manifest
+func extract(r io.Reader, dir string) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifestData []byte
+	extracted := make(map[string]FileEntry)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		name := header.Name
+		if header.Typeflag != tar.TypeReg || !validName(name) {
+			return nil, fmt.Errorf("unexpected archive entry: %s", name)
+		}
+		if name == manifestName {
+			if manifestData, err = io.ReadAll(io.LimitReader(tr, 16<<20)); err != nil {
+				return nil, fmt.Errorf("failed to read manifest: %w", err)
+			}
+			continue
+		}
+		if _, ok := extracted[name]; ok {
+			return nil, fmt.Errorf("duplicate archive entry: %s", name)
+		}
+		entry, err := extractFile(tr, filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		entry.Name = name
+		extracted[name] = entry
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("archive has no manifest")
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("not a backup archive: format %q", manifest.Format)
+	}
+	if manifest.Version != Version {
+		return nil, fmt.Errorf("unsupported backup version: %d", manifest.Version)
+	}
+	if manifest.SchemaVersion > storage.SchemaVersion() {
+		return nil, fmt.Errorf("backup is from a newer release (schema %d, this build supports %d)", manifest.SchemaVersion, storage.SchemaVersion())
+	}
+	for _, file := range manifest.Files {
+		got, ok := extracted[file.Name]
+		if !ok {
+			return nil, fmt.Errorf("archive is missing %s", file.Name)
+		}
+		if got.Size != file.Size || got.SHA256 != file.SHA256 {
+			return nil, fmt.Errorf("archive file %s is corrupt", file.Name)
+		}
+		delete(extracted, file.Name)
+	}
+	for name := range extracted {
+		return nil, fmt.Errorf("archive file %s is not in the manifest", name)
+	}
+	for _, name := range []string{keyName, tracksName, playlistsName, settingsName} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return nil, fmt.Errorf("archive is missing %s", name)
+		}
+	}
+	return &manifest, nil
+}
+
+func extractFile(r io.Reader, target string) (FileEntry, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return FileEntry{}, fmt.Errorf("failed to extract: %w", err)
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return FileEntry{}, fmt.Errorf("failed to extract: %w", err)
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, sum), r)
+	if err != nil {
+		return FileEntry{}, fmt.Errorf("failed to extract: %w", err)
+	}
+	return FileEntry{Size: size, SHA256: hex.EncodeToString(sum.Sum(nil))}, nil
+}
+
+// validName accepts the relative, clean slash paths Create writes
+func validName(name string) bool {
+	return name != "" && !path.IsAbs(name) && path.Clean(name) == name &&
+		name != ".." && !strings.HasPrefix(name, "../") && !strings.Contains(name, "\\")
+}
+
+func keyPeerID(data []byte) (peer.ID, error) {
+	key, err := host.DecodeKey(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid identity key: %w", err)
+	}
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid identity key: %w", err)
+	}
+	return id, nil
+}
+
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", filePath, err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
@@ -0,0 +1,353 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/host"
+	"github.com/cotune/go-backend/internal/media"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/cotune/go-backend/internal/waveform"
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+// newNode sets up a data directory with a key, a stored track, a track
+// referenced in place, a playlist and settings
+func newNode(t *testing.T) (Source, func()) {
+	t.Helper()
+	dataDir := t.TempDir()
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key() error: %v", err)
+	}
+	keyBytes, _ := crypto.MarshalPrivateKey(key)
+	if err := os.WriteFile(filepath.Join(dataDir, host.KeyFileName), []byte(hex.EncodeToString(keyBytes)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := storage.New(dataDir)
+	if err != nil {
+		t.Fatalf("storage.New() error: %v", err)
+	}
+	mediaStore, err := media.New(dataDir)
+	if err != nil {
+		t.Fatalf("media.New() error: %v", err)
+	}
+
+	source := filepath.Join(t.TempDir(), "stored.mp3")
+	os.WriteFile(source, []byte("stored audio"), 0644)
+	hash, storedPath, err := mediaStore.Import(source)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	os.WriteFile(waveform.PathFor(storedPath), []byte("peaks"), 0644)
+
+	inPlace := filepath.Join(t.TempDir(), "in-place.flac")
+	os.WriteFile(inPlace, []byte("in place audio"), 0644)
+
+	tracks := []*models.Track{
+		{ID: "1", CTID: strings.Repeat("a", 64), Title: "Stored", Artist: "Band", Recognized: true, Path: storedPath, MediaHash: hash, FileSize: 12, Waveform: true, Liked: true, FingerprintKey: "fp1:0:abcd"},
+		{ID: "2", CTID: strings.Repeat("b", 64), Title: "In Place", Artist: "Band", Recognized: true, Path: inPlace, FileSize: 14},
+	}
+	if err := store.SaveTracks(tracks); err != nil {
+		t.Fatalf("SaveTracks() error: %v", err)
+	}
+	store.SaveFingerprint("1", "AQIDBA==")
+	store.SavePlaylist(&models.Playlist{ID: "pl-1", Name: "Mix", Entries: []models.PlaylistEntry{{CTID: tracks[1].CTID}}})
+	store.SaveSettings(models.Settings{StorageBudget: 1 << 30})
+
+	return Source{DataDir: dataDir, Store: store, Media: mediaStore}, func() { store.Close() }
+}
+
+func TestBackupRestoresNodeWithMedia(t *testing.T) {
+	src, closeSource := newNode(t)
+	defer closeSource()
+
+	var archive bytes.Buffer
+	manifest, err := Create(&archive, src, Options{IncludeMedia: true})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if manifest.Tracks != 2 || manifest.Playlists != 1 || manifest.MediaFiles != 2 {
+		t.Fatalf("manifest = %+v, want 2 tracks, 1 playlist, 2 media files", manifest)
+	}
+
+	target := t.TempDir()
+	report, err := Restore(bytes.NewReader(archive.Bytes()), target, vault.Secret{})
+	if err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if report.PeerID != manifest.PeerID || report.Tracks != 2 || report.Playlists != 1 || report.MediaFiles != 2 || report.Missing != 0 {
+		t.Fatalf("Restore() = %+v, want every track restored from the archive", report)
+	}
+
+	key, _ := os.ReadFile(filepath.Join(target, host.KeyFileName))
+	original, _ := os.ReadFile(filepath.Join(src.DataDir, host.KeyFileName))
+	if !bytes.Equal(key, original) {
+		t.Fatal("identity key was not restored")
+	}
+
+	store, err := storage.New(target)
+	if err != nil {
+		t.Fatalf("storage.New() error: %v", err)
+	}
+	defer store.Close()
+	for _, id := range []string{"1", "2"} {
+		track, err := store.GetTrack(id)
+		if err != nil {
+			t.Fatalf("GetTrack(%s) error: %v", id, err)
+		}
+		if track.MediaHash == "" || !strings.HasPrefix(track.Path, filepath.Join(target, "media")) {
+			t.Fatalf("track %s at %q, want it in the restored media store", id, track.Path)
+		}
+		if _, err := os.Stat(track.Path); err != nil {
+			t.Fatalf("track %s file: %v", id, err)
+		}
+	}
+	if track, _ := store.GetTrack("1"); !track.Liked || !track.Waveform {
+		t.Fatalf("track 1 = %+v, want its like and waveform kept", track)
+	}
+	if track, _ := store.GetTrack("2"); track.Waveform {
+		t.Fatal("track 2 has no archived waveform but is marked as having one")
+	}
+	if fp, err := store.GetFingerprint("1"); err != nil || fp != "AQIDBA==" {
+		t.Fatalf("GetFingerprint() = %q, %v; want the fingerprint restored", fp, err)
+	}
+	if settings, _ := store.GetSettings(); settings.StorageBudget != 1<<30 {
+		t.Fatalf("settings = %+v, want the storage budget restored", settings)
+	}
+	if _, err := store.GetPlaylist("pl-1"); err != nil {
+		t.Fatalf("GetPlaylist() error: %v", err)
+	}
+}
+
+func TestRestoreWithoutMediaKeepsFilesInPlace(t *testing.T) {
+	src, closeSource := newNode(t)
+	defer closeSource()
+
+	var archive bytes.Buffer
+	if _, err := Create(&archive, src, Options{}); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	tracks, _ := src.Store.GetAllTracks()
+	for _, track := range tracks {
+		if track.ID == "2" {
+			os.WriteFile(track.Path, []byte("edited since the backup"), 0644)
+		}
+	}
+
+	target := t.TempDir()
+	report, err := Restore(&archive, target, vault.Secret{})
+	if err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	// The stored file is not in the archive or the new media store
+	if report.Tracks != 1 || report.Missing != 1 || report.Reprocess != 1 {
+		t.Fatalf("Restore() = %+v, want the in-place track restored for reprocessing", report)
+	}
+
+	store, _ := storage.New(target)
+	defer store.Close()
+	track, err := store.GetTrack("2")
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	if track.CTID != "" || track.MediaHash != "" {
+		t.Fatalf("track = %+v, want an in-place track with its CTID cleared", track)
+	}
+}
+
+func TestRestoreRejectsCorruptArchivesAndExistingLibraries(t *testing.T) {
+	src, closeSource := newNode(t)
+	defer closeSource()
+
+	var archive bytes.Buffer
+	if _, err := Create(&archive, src, Options{}); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	// Rewrite the archive with a track renamed behind the manifest's back
+	var tampered bytes.Buffer
+	gzr, _ := gzip.NewReader(bytes.NewReader(archive.Bytes()))
+	tr := tar.NewReader(gzr)
+	gzw := gzip.NewWriter(&tampered)
+	tw := tar.NewWriter(gzw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		data, _ := io.ReadAll(tr)
+		if header.Name == tracksName {
+			data = bytes.Replace(data, []byte("In Place"), []byte("In Plaice"), 1)
+			header.Size = int64(len(data))
+		}
+		tw.WriteHeader(header)
+		tw.Write(data)
+	}
+	tw.Close()
+	gzw.Close()
+
+	if _, err := Restore(&tampered, t.TempDir(), vault.Secret{}); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("Restore(tampered) error = %v, want corrupt", err)
+	}
+
+	src.Store.Close()
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), src.DataDir, vault.Secret{}); err == nil {
+		t.Fatal("Restore() over an existing library succeeded")
+	}
+}
+
+// newEncryptedNode sets up a node like newNode, encrypted with secret
+func newEncryptedNode(t *testing.T, secret vault.Secret) (Source, crypto.PrivKey) {
+	t.Helper()
+	src, closeSource := newNode(t)
+	closeSource()
+
+	_, keyring, err := vault.Create(src.DataDir, secret)
+	if err != nil {
+		t.Fatalf("vault.Create() error: %v", err)
+	}
+	key, err := host.LoadOrGenerateKey(src.DataDir, keyring)
+	if err != nil {
+		t.Fatalf("LoadOrGenerateKey() error: %v", err)
+	}
+	store, err := storage.Open(src.DataDir, keyring)
+	if err != nil {
+		t.Fatalf("storage.Open() error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if _, err := host.LoadKey(src.DataDir, nil); err != host.ErrKeyLocked {
+		t.Fatalf("LoadKey() without keyring error = %v, want ErrKeyLocked", err)
+	}
+	src.Store, src.Keyring = store, keyring
+	return src, key
+}
+
+func TestBackupOfEncryptedNodeIsSealedWithItsSecret(t *testing.T) {
+	secret := vault.Secret{Key: bytes.Repeat([]byte{1}, vault.KeySize)}
+	src, key := newEncryptedNode(t, secret)
+
+	var archive bytes.Buffer
+	if _, err := Create(&archive, src, Options{}); !errors.Is(err, ErrNeedsSecret) {
+		t.Fatalf("Create() without secret error = %v, want ErrNeedsSecret", err)
+	}
+	manifest, err := Create(&archive, src, Options{Secret: secret})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if manifest.Vault == nil {
+		t.Fatal("manifest has no vault, want the archive sealed")
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents, _ := io.ReadAll(gz)
+	if bytes.Contains(contents, []byte("In Place")) {
+		t.Fatal("archive holds a track title in the clear")
+	}
+
+	target := t.TempDir()
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), target, vault.Secret{}); !errors.Is(err, ErrNeedsSecret) {
+		t.Fatalf("Restore() without secret error = %v, want ErrNeedsSecret", err)
+	}
+	wrong := vault.Secret{Key: make([]byte, vault.KeySize)}
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), target, wrong); !errors.Is(err, vault.ErrWrongSecret) {
+		t.Fatalf("Restore() with a wrong secret error = %v, want ErrWrongSecret", err)
+	}
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), target, secret); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+
+	// The restored node is encrypted with the same secret
+	v, err := vault.Load(target)
+	if err != nil {
+		t.Fatalf("vault.Load() error: %v", err)
+	}
+	keyring, err := v.Unlock(secret)
+	if err != nil {
+		t.Fatalf("Unlock() error: %v", err)
+	}
+	if _, err := host.LoadKey(target, nil); err != host.ErrKeyLocked {
+		t.Fatalf("LoadKey() without keyring error = %v, want ErrKeyLocked", err)
+	}
+	restored, err := host.LoadKey(target, keyring)
+	if err != nil || !restored.Equals(key) {
+		t.Fatalf("restored key = %v, %v; want the node's key", restored, err)
+	}
+	if _, err := storage.New(target); err != storage.ErrLocked {
+		t.Fatalf("storage.New() error = %v, want ErrLocked", err)
+	}
+	restoredStore, err := storage.Open(target, keyring)
+	if err != nil {
+		t.Fatalf("storage.Open() error: %v", err)
+	}
+	defer restoredStore.Close()
+	if track, err := restoredStore.GetTrack("2"); err != nil || track.Title != "In Place" {
+		t.Fatalf("GetTrack() = %+v, %v", track, err)
+	}
+}
+
+func TestPlaintextBackupOfEncryptedNodeIsRestoredUnencrypted(t *testing.T) {
+	src, key := newEncryptedNode(t, vault.Secret{Key: make([]byte, vault.KeySize)})
+
+	var archive bytes.Buffer
+	manifest, err := Create(&archive, src, Options{Plaintext: true})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if manifest.Vault != nil {
+		t.Fatal("plaintext backup has a vault")
+	}
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), src.DataDir, vault.Secret{}); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Fatalf("Restore() into the encrypted node error = %v, want encrypted", err)
+	}
+
+	target := t.TempDir()
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), target, vault.Secret{}); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	restored, err := host.LoadKey(target, nil)
+	if err != nil || !restored.Equals(key) {
+		t.Fatalf("restored key = %v, %v; want the node's key in the clear", restored, err)
+	}
+	restoredStore, err := storage.New(target)
+	if err != nil {
+		t.Fatalf("storage.New() error: %v", err)
+	}
+	defer restoredStore.Close()
+	if track, err := restoredStore.GetTrack("2"); err != nil || track.Title != "In Place" {
+		t.Fatalf("GetTrack() = %+v, %v", track, err)
+	}
+}
+
+func TestCreateFileNeverReplacesAFile(t *testing.T) {
+	src, cleanup := newNode(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "node.tar.gz")
+	if err := os.WriteFile(path, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateFile(path, src, Options{}); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("CreateFile() over a file error = %v, want ErrExist", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep me" {
+		t.Fatalf("file = %q, want it untouched", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Fatalf("backup dir has %d entries, want no temporary file left", len(entries))
+	}
+}
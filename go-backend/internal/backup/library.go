@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// LibraryFormat identifies library exports
+const LibraryFormat = "cotune-library"
+
+// LibraryVersion is the export layout this build writes and imports
+const LibraryVersion = 1
+
+// Library is a library exported without identity, settings or audio: the
+// tracks as stored and the playlists referencing them by CTID
+type Library struct {
+	Format     string             `json:"format"`
+	Version    int                `json:"version"`
+	ExportedAt int64              `json:"exported_at"`
+	Tracks     []*models.Track    `json:"tracks"`
+	Playlists  []*models.Playlist `json:"playlists"`
+}
+
+// NewLibrary builds a library export
+func NewLibrary(tracks []*models.Track, playlists []*models.Playlist) *Library {
+	if tracks == nil {
+		tracks = []*models.Track{}
+	}
+	if playlists == nil {
+		playlists = []*models.Playlist{}
+	}
+	return &Library{
+		Format:     LibraryFormat,
+		Version:    LibraryVersion,
+		ExportedAt: time.Now().Unix(),
+		Tracks:     tracks,
+		Playlists:  playlists,
+	}
+}
+
+// Write encodes the library as JSON
+func (l *Library) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(l); err != nil {
+		return fmt.Errorf("failed to write library: %w", err)
+	}
+	return nil
+}
+
+// ReadLibrary decodes a library written by Write
+func ReadLibrary(r io.Reader) (*Library, error) {
+	var library Library
+	if err := json.NewDecoder(r).Decode(&library); err != nil {
+		return nil, fmt.Errorf("invalid library: %w", err)
+	}
+	if library.Format != LibraryFormat {
+		return nil, fmt.Errorf("not a library export: format %q", library.Format)
+	}
+	if library.Version != LibraryVersion {
+		return nil, fmt.Errorf("unsupported library version: %d", library.Version)
+	}
+	return &library, nil
+}
+
+// M3UItem is one track of an M3U8 playlist
+type M3UItem struct {
+	Path       string
+	Title      string
+	Artist     string
+	DurationMs int64
+	CTID       string // From the #COTUNE-CTID extension; empty in foreign playlists
+}
+
+// ctidDirective carries the CTID of the next item. Other players skip
+// unknown # lines.
+const ctidDirective = "#COTUNE-CTID:"
+
+// M3UItemFor describes a track as an M3U8 item
+func M3UItemFor(track *models.Track) M3UItem {
+	return M3UItem{
+		Path:       track.Path,
+		Title:      track.Title,
+		Artist:     track.Artist,
+		DurationMs: track.DurationMs,
+		CTID:       track.CTID,
+	}
+}
+
+// WriteM3U writes items as an extended M3U8 playlist
+func WriteM3U(w io.Writer, items []M3UItem) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#EXTM3U")
+	for _, item := range items {
+		seconds := int64(-1)
+		if item.DurationMs > 0 {
+			seconds = (item.DurationMs + 500) / 1000
+		}
+		display := item.Title
+		if item.Artist != "" {
+			display = item.Artist + " - " + item.Title
+		}
+		fmt.Fprintf(bw, "#EXTINF:%d,%s\n", seconds, oneLine(display))
+		if item.CTID != "" {
+			fmt.Fprintf(bw, "%s%s\n", ctidDirective, item.CTID)
+		}
+		fmt.Fprintln(bw, oneLine(item.Path))
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write playlist: %w", err)
+	}
+	return nil
+}
+
+// ReadM3U reads an M3U or M3U8 playlist. Relative paths are resolved
+// against baseDir; URLs are skipped, since only local files can be added.
+func ReadM3U(r io.Reader, baseDir string) ([]M3UItem, error) {
+	var items []M3UItem
+	var next M3UItem
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			next.DurationMs, next.Artist, next.Title = parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+		case strings.HasPrefix(line, ctidDirective):
+			next.CTID = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, ctidDirective)))
+		case strings.HasPrefix(line, "#"):
+		default:
+			if !strings.Contains(line, "://") {
+				next.Path = line
+				if !filepath.IsAbs(next.Path) {
+					next.Path = filepath.Join(baseDir, filepath.FromSlash(next.Path))
+				}
+				items = append(items, next)
+			}
+			next = M3UItem{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	return items, nil
+}
+
+// parseExtInf splits "<seconds>[ attributes],<artist> - <title>"
+func parseExtInf(value string) (int64, string, string) {
+	length, display, _ := strings.Cut(value, ",")
+	if fields := strings.Fields(length); len(fields) > 0 {
+		length = fields[0]
+	}
+	var durationMs int64
+	if seconds, err := strconv.ParseFloat(length, 64); err == nil && seconds > 0 {
+		durationMs = int64(seconds * 1000)
+	}
+	display = strings.TrimSpace(display)
+	if artist, title, ok := strings.Cut(display, " - "); ok {
+		return durationMs, strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return durationMs, "", display
+}
+
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,67 @@
+package backup
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+func TestM3URoundTrip(t *testing.T) {
+	items := []M3UItem{
+		{Path: "/music/a.mp3", Title: "First", Artist: "Band", DurationMs: 181400, CTID: strings.Repeat("a", 64)},
+		{Path: "/music/b.flac", Title: "Untitled"},
+	}
+	var buf bytes.Buffer
+	if err := WriteM3U(&buf, items); err != nil {
+		t.Fatalf("WriteM3U() error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "#EXTINF:181,Band - First\n") || !strings.Contains(buf.String(), "#EXTINF:-1,Untitled\n") {
+		t.Fatalf("WriteM3U() =\n%s", buf.String())
+	}
+
+	read, err := ReadM3U(&buf, "/elsewhere")
+	if err != nil {
+		t.Fatalf("ReadM3U() error: %v", err)
+	}
+	if len(read) != 2 || read[0].CTID != items[0].CTID || read[0].Artist != "Band" || read[0].Title != "First" || read[0].DurationMs != 181000 || read[1] != items[1] {
+		t.Fatalf("ReadM3U() = %+v, want %+v", read, items)
+	}
+}
+
+func TestReadM3UResolvesRelativePathsAndSkipsURLs(t *testing.T) {
+	playlist := "\ufeff#EXTM3U\r\n#EXTINF:200 tvg-id=\"x\",Song\r\nsub/song.mp3\r\nhttp://radio.example/stream\r\n# comment\r\n/abs/other.ogg\r\n"
+	items, err := ReadM3U(strings.NewReader(playlist), "/lists")
+	if err != nil {
+		t.Fatalf("ReadM3U() error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("ReadM3U() = %+v, want two local files", items)
+	}
+	if items[0].Path != filepath.Join("/lists", "sub", "song.mp3") || items[0].Title != "Song" || items[0].DurationMs != 200000 {
+		t.Fatalf("first item = %+v", items[0])
+	}
+	if items[1].Path != "/abs/other.ogg" || items[1].Title != "" {
+		t.Fatalf("second item = %+v, want no metadata carried over", items[1])
+	}
+}
+
+func TestReadLibraryChecksFormat(t *testing.T) {
+	var buf bytes.Buffer
+	library := NewLibrary([]*models.Track{{ID: "1", Title: "Song"}}, nil)
+	if err := library.Write(&buf); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	read, err := ReadLibrary(&buf)
+	if err != nil {
+		t.Fatalf("ReadLibrary() error: %v", err)
+	}
+	if len(read.Tracks) != 1 || read.Tracks[0].Title != "Song" || read.Playlists == nil {
+		t.Fatalf("ReadLibrary() = %+v", read)
+	}
+	if _, err := ReadLibrary(strings.NewReader(`{"format":"other","version":1}`)); err == nil {
+		t.Fatal("ReadLibrary() accepted a foreign format")
+	}
+}
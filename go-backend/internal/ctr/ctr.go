@@ -5,20 +5,34 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
 	"sync"
 	"time"
 
+	"github.com/cotune/go-backend/internal/artwork"
 	"github.com/cotune/go-backend/internal/audio"
 	"github.com/cotune/go-backend/internal/dht"
+	"github.com/cotune/go-backend/internal/fingerprint"
+	"github.com/cotune/go-backend/internal/loudness"
 	"github.com/cotune/go-backend/internal/models"
 	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/waveform"
 )
 
+// CTIDVersion is the CTID algorithm applied to newly processed tracks. A CTID
+// is the SHA256 of PCM normalized with the audio version of the same number.
+const CTIDVersion = audio.LatestVersion
+
+// LegacyCTIDWindow is how long a track whose CTID changed keeps announcing
+// the previous one. Peers re-resolve search results well within this time.
+const LegacyCTIDWindow = 7 * 24 * time.Hour
+
 // Service handles Canonical Track Resolution
 type Service struct {
-	store   *storage.Storage
+	store   storage.Store
 	dht     *dht.Service
-	queue   chan *models.Track
+	wake    chan struct{} // signals workers that a job may be runnable
 	workers int
 	wg      sync.WaitGroup
 	mu      sync.RWMutex
@@ -27,19 +41,58 @@ type Service struct {
 	cancel  context.CancelFunc
 	// onProcessed is called after successful processing and save.
 	onProcessed func(context.Context, *models.Track)
+	migration   MigrationProgress
+	// Retry policy: attempt n waits retryBase*2^(n-1), up to maxRetryDelay
+	maxAttempts int
+	retryBase   time.Duration
+	jobMu       sync.Mutex // serializes job state transitions
+	queue       *runQueue  // queued jobs in claim order, guarded by jobMu
+	saveMu      sync.Mutex // serializes re-reading and saving analysed tracks
+	// trustTags lets title and artist read from file tags mark a track recognized
+	trustTags bool
+	artwork   *artwork.Store // receives embedded covers; nil skips them
 }
 
 // New creates a new CTR service
-func New(store *storage.Storage, dhtService *dht.Service) *Service {
+func New(store storage.Store, dhtService *dht.Service) *Service {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Service{
-		store:   store,
-		dht:     dhtService,
-		queue:   make(chan *models.Track, 100),
-		workers: 2, // Process 2 tracks concurrently
-		ctx:     ctx,
-		cancel:  cancel,
+		store:       store,
+		dht:         dhtService,
+		wake:        make(chan struct{}, 1),
+		workers:     DefaultWorkers,
+		maxAttempts: DefaultMaxAttempts,
+		retryBase:   DefaultRetryDelay,
+		queue:       newRunQueue(),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// SetTrustTags decides whether title and artist taken from tags embedded in
+// the file count as user-recognized metadata. Off by default: tags often
+// carry junk, and recognized tracks are shared automatically.
+func (s *Service) SetTrustTags(trust bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trustTags = trust
+}
+
+// SetArtworkStore sets where covers embedded in track files are stored
+func (s *Service) SetArtworkStore(store *artwork.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artwork = store
+}
+
+// SetWorkers sets how many tracks are processed concurrently. It only has
+// an effect before Start.
+func (s *Service) SetWorkers(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > 0 {
+		s.workers = n
 	}
 }
 
@@ -51,9 +104,17 @@ func (s *Service) Start() {
 		return
 	}
 	s.running = true
+	workers := s.workers
 	s.mu.Unlock()
 
-	for i := 0; i < s.workers; i++ {
+	// Jobs left mid-flight by a crash start over
+	if n, err := s.recoverJobs(); err != nil {
+		fmt.Printf("Failed to recover CTR jobs: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("Recovered %d interrupted CTR jobs\n", n)
+	}
+
+	for i := 0; i < workers; i++ {
 		s.wg.Add(1)
 		go s.worker()
 	}
@@ -86,43 +147,93 @@ func (s *Service) Stop(ctx context.Context) error {
 	}
 }
 
-// QueueTrack queues a track for CTR processing
-func (s *Service) QueueTrack(track *models.Track) {
-	s.mu.RLock()
-	running := s.running
-	s.mu.RUnlock()
-
-	if !running {
-		return
-	}
-
-	select {
-	case s.queue <- track:
-	default:
-		// Queue full, skip
-	}
-}
-
 // ProcessTrack processes a track immediately (synchronous)
 func (s *Service) ProcessTrack(ctx context.Context, track *models.Track) error {
-	ctid, err := s.computeCTID(ctx, track.Path)
+	return s.processTrack(ctx, track, nil)
+}
+
+// processTrack computes, saves and announces the CTID of a track, reporting
+// the hashing phase through phase if it is not nil
+func (s *Service) processTrack(ctx context.Context, track *models.Track, phase func(models.JobState)) error {
+	result, err := s.analyze(ctx, track.Path, CTIDVersion, phase)
 	if err != nil {
 		return fmt.Errorf("failed to compute CTID: %w", err)
 	}
+	ctid := result.ctid
 
 	track.CTID = ctid
+	track.CTIDVersion = int(CTIDVersion)
+	if format := result.format; format != nil {
+		track.Format = &models.AudioFormat{
+			Container:  string(format.Container),
+			Codec:      string(format.Codec),
+			SampleRate: format.SampleRate,
+			Channels:   format.Channels,
+		}
+	}
+	// Acoustic fingerprint groups near-identical recordings with different CTIDs
+	var encodedFingerprint string
+	if fp := result.fingerprint; fp != nil {
+		encodedFingerprint = fingerprint.Encode(fp.Frames)
+		track.FingerprintKey = fp.Key
+	}
+	if err := setProperties(track, result.duration); err != nil {
+		return err
+	}
+	track.Loudness = &models.Loudness{
+		IntegratedLUFS: result.loudness.Integrated,
+		RangeLU:        result.loudness.Range,
+		TruePeakDBTP:   result.loudness.TruePeak,
+		GainDB:         result.loudness.Gain(),
+	}
+	s.savePeaks(track, result.peaks)
+	tags := s.readTags(track.Path)
+
+	// The user may have edited or deleted the track while it was analyzed,
+	// and a worker or migration may have analysed it since it was read
+	s.saveMu.Lock()
+	current, err := s.store.GetTrack(track.ID)
+	if err != nil {
+		s.saveMu.Unlock()
+		return fmt.Errorf("track removed during processing: %w", err)
+	}
+	keepUserFields(track, current)
+	track.LegacyCTID, track.LegacyExpires = current.LegacyCTID, current.LegacyExpires
+	if current.CTID != "" && current.CTID != ctid {
+		// Keep announcing the previous ID for a while so peers that only
+		// know it can still find this track
+		track.LegacyCTID = current.CTID
+		track.LegacyExpires = time.Now().Add(LegacyCTIDWindow).Unix()
+	}
+	s.applyTags(track, tags)
+	track.Broken = "" // analysed as it is now
 
 	// Save updated track
-	if err := s.store.SaveTrack(track); err != nil {
+	err = s.store.SaveTrack(track)
+	if err == nil && encodedFingerprint != "" {
+		err = s.store.SaveFingerprint(track.ID, encodedFingerprint)
+	}
+	s.saveMu.Unlock()
+	if err != nil {
 		return fmt.Errorf("failed to save track: %w", err)
 	}
 
-	// If recognized, announce in DHT
-	if track.Recognized {
+	// If shared, announce in DHT
+	if track.IsShared() {
 		if err := s.dht.Provide(ctx, ctid); err != nil {
 			// Non-fatal, log and continue
 			fmt.Printf("Failed to provide CTID in DHT: %v\n", err)
 		}
+		if track.LegacyCTID != "" && track.LegacyExpires > time.Now().Unix() {
+			if err := s.dht.Provide(ctx, track.LegacyCTID); err != nil {
+				fmt.Printf("Failed to provide legacy CTID in DHT: %v\n", err)
+			}
+		}
+		if track.FingerprintKey != "" {
+			if err := s.dht.ProvideFingerprint(ctx, dht.HashFingerprintKey(track.FingerprintKey)); err != nil {
+				fmt.Printf("Failed to provide fingerprint in DHT: %v\n", err)
+			}
+		}
 	}
 
 	s.mu.RLock()
@@ -135,6 +246,26 @@ func (s *Service) ProcessTrack(ctx context.Context, track *models.Track) error {
 	return nil
 }
 
+// EditTrack applies edit to a track as it is stored now and saves it. Edits
+// are serialized with the saves of analysed tracks, so neither overwrites
+// the other. It returns the saved track.
+func (s *Service) EditTrack(id string, edit func(*models.Track) error) (*models.Track, error) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	track, err := s.store.GetTrack(id)
+	if err != nil {
+		return nil, fmt.Errorf("track not found: %w", err)
+	}
+	if err := edit(track); err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveTrack(track); err != nil {
+		return nil, fmt.Errorf("failed to save track: %w", err)
+	}
+	return track, nil
+}
+
 // SetOnProcessed sets a callback triggered after successful ProcessTrack.
 func (s *Service) SetOnProcessed(fn func(context.Context, *models.Track)) {
 	s.mu.Lock()
@@ -142,37 +273,212 @@ func (s *Service) SetOnProcessed(fn func(context.Context, *models.Track)) {
 	s.onProcessed = fn
 }
 
-func (s *Service) worker() {
-	defer s.wg.Done()
+// savePeaks stores the waveform of a track beside its audio. Failing to
+// write it is not fatal to processing.
+func (s *Service) savePeaks(track *models.Track, peaks *waveform.Peaks) {
+	track.Waveform = false
+	// Audio without samples never has peaks; do not queue it for them again
+	track.NoWaveform = peaks == nil
+	if peaks == nil {
+		return
+	}
+	if err := waveform.Save(waveform.PathFor(track.Path), peaks); err != nil {
+		fmt.Printf("Failed to save waveform of %s: %v\n", track.Path, err)
+		return
+	}
+	track.Waveform = true
+}
 
-	for {
-		select {
-		case <-s.ctx.Done():
-			return
-		case track := <-s.queue:
-			ctx, cancel := context.WithTimeout(s.ctx, 5*time.Minute)
-			if err := s.ProcessTrack(ctx, track); err != nil {
-				fmt.Printf("CTR processing error: %v\n", err)
-			}
-			cancel()
+// fileTags is what processing takes from the tags embedded in a file
+type fileTags struct {
+	*audio.Tags
+	artwork string // Hash of the stored cover, empty if there is none
+}
+
+// readTags reads the tags embedded in a track file and stores the embedded
+// cover. It runs outside saveMu; tag read errors are not fatal to
+// processing and yield no tags.
+func (s *Service) readTags(path string) *fileTags {
+	tags, err := audio.ReadTagsFile(path)
+	if err != nil {
+		fmt.Printf("Failed to read tags from %s: %v\n", path, err)
+		return nil
+	}
+	if tags == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	covers := s.artwork
+	s.mu.RUnlock()
+	result := &fileTags{Tags: tags}
+	if tags.Picture != nil && covers != nil {
+		if hash, err := covers.Put(tags.Picture.Data); err != nil {
+			fmt.Printf("Failed to store artwork from %s: %v\n", path, err)
+		} else {
+			result.artwork = hash
 		}
 	}
+	return result
+}
+
+// applyTags pre-fills metadata the user has not entered from tags read by
+// readTags and sets the embedded cover
+func (s *Service) applyTags(track *models.Track, tags *fileTags) {
+	if tags == nil {
+		return
+	}
+
+	fillText(&track.Title, tags.Title)
+	fillText(&track.Artist, tags.Artist)
+	fillText(&track.Album, tags.Album)
+	fillText(&track.Genre, tags.Genre)
+	if track.TrackNumber == 0 {
+		track.TrackNumber = tags.TrackNumber
+	}
+	if track.Year == 0 {
+		track.Year = tags.Year
+	}
+	if tags.artwork != "" {
+		track.Artwork = tags.artwork
+	}
+
+	s.mu.RLock()
+	trust := s.trustTags
+	s.mu.RUnlock()
+	if trust && !track.Recognized && track.Title != "" && track.Artist != "" {
+		track.Recognized = true
+	}
+}
+
+// keepUserFields copies what the user, the cache and folder watching change
+// outside processing from the stored copy of a track
+func keepUserFields(track *models.Track, current *models.Track) {
+	if current.Path != track.Path {
+		// Moved while analysed; the waveform went beside the old path
+		track.Path, track.FileHash, track.Waveform = current.Path, current.FileHash, false
+	}
+	track.Title, track.Artist, track.Album = current.Title, current.Artist, current.Album
+	track.TrackNumber, track.Year, track.Genre = current.TrackNumber, current.Year, current.Genre
+	track.Recognized, track.Liked, track.Unshared = current.Recognized, current.Liked, current.Unshared
+	track.LastAccess, track.Serves = current.LastAccess, current.Serves
 }
 
-// computeCTID computes the Canonical Track ID from an audio file
-func (s *Service) computeCTID(ctx context.Context, filePath string) (string, error) {
-	// Decode audio to PCM
-	pcm, err := audio.DecodeAudioToPCM(ctx, filePath)
+func fillText(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// ComputeCTID computes the CTID of a file with a specific algorithm version,
+// without touching storage. Any version from audio.V1 to CTIDVersion is
+// supported, so IDs announced by peers running older releases can be
+// reproduced.
+func (s *Service) ComputeCTID(ctx context.Context, filePath string, version audio.Version) (string, error) {
+	if version < audio.V1 || version > CTIDVersion {
+		return "", fmt.Errorf("unsupported CTID version: %d", version)
+	}
+	result, err := s.analyze(ctx, filePath, version, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute CTID: %w", err)
+	}
+	return result.ctid, nil
+}
+
+// TrackCTIDVersion returns the algorithm that produced a track's CTID
+func TrackCTIDVersion(track *models.Track) audio.Version {
+	if track.CTIDVersion == 0 {
+		// Tracks saved before versioning used the original algorithm
+		return audio.V1
+	}
+	return audio.Version(track.CTIDVersion)
+}
+
+// analysis is what one decoding pass over a file yields
+type analysis struct {
+	ctid        string
+	fingerprint *fingerprint.Fingerprint
+	format      *audio.Format // nil if only the ffmpeg fallback recognized the file
+	duration    time.Duration
+	loudness    loudness.Result
+	peaks       *waveform.Peaks // nil for empty audio
+}
+
+// analyze streams the decoded audio once, feeding the CTID hash and the
+// fingerprint builder side by side so memory stays bounded for long tracks.
+// The duration is taken from the amount of normalized PCM produced, and
+// loudness and waveform peaks are measured along the way. phase, if not nil,
+// is told when decoding is set up and hashing begins.
+func (s *Service) analyze(ctx context.Context, filePath string, version audio.Version, phase func(models.JobState)) (*analysis, error) {
+	stream, err := audio.OpenPCMStreamVersion(ctx, filePath, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	}
+	defer stream.Close()
+	if phase != nil {
+		phase(models.JobHashing)
+	}
+
+	// Loudness is measured on the decoder output so channels keep their
+	// BS.1770 weighting instead of being downmixed first
+	meter := loudness.NewMeter(stream.SourceRate(), stream.SourceChannels())
+	if format := stream.Format(); format != nil && format.Channels == 1 && stream.SourceChannels() == 2 {
+		// The MP3 decoder duplicates mono into two channels
+		meter = loudness.NewMeter(stream.SourceRate(), 1)
+		stream.SetSourceTap(func(samples []int16) {
+			for i := 0; i < len(samples); i += 2 {
+				meter.Write(samples[i : i+1])
+			}
+		})
+	} else {
+		stream.SetSourceTap(meter.Write)
+	}
+
+	hasher := sha256.New()
+	fp := fingerprint.NewBuilder(audio.TargetSampleRate)
+	peaks := waveform.NewBuilder(audio.TargetSampleRate)
+	n, err := io.Copy(io.MultiWriter(hasher, fp, peaks), stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	}
+
+	frames := n / 2 / audio.TargetChannels
+	return &analysis{
+		ctid:        hex.EncodeToString(hasher.Sum(nil)),
+		fingerprint: fp.Fingerprint(),
+		format:      stream.Format(),
+		duration:    time.Duration(frames) * time.Second / audio.TargetSampleRate,
+		loudness:    meter.Result(),
+		peaks:       peaks.Peaks(),
+	}, nil
+}
+
+// setProperties records the file size, duration and average bitrate of a
+// track's local copy
+func setProperties(track *models.Track, duration time.Duration) error {
+	info, err := os.Stat(track.Path)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode audio: %w", err)
+		return fmt.Errorf("failed to stat track file: %w", err)
 	}
+	track.FileSize = info.Size()
+	track.FileModTime = info.ModTime().Unix()
+	track.DurationMs = duration.Milliseconds()
+	track.Bitrate = 0
+	if track.DurationMs > 0 {
+		track.Bitrate = int(track.FileSize * 8 * 1000 / track.DurationMs)
+	}
+	return nil
+}
 
+// computeCTID computes the Canonical Track ID from fully decoded PCM. It is
+// the reference for the streaming path in analyze.
+func computeCTID(pcm []int16) string {
 	// Normalize PCM
 	normalized := normalizePCM(pcm)
 
 	// Compute SHA256
 	hash := sha256.Sum256(normalized)
-	return hex.EncodeToString(hash[:]), nil
+	return hex.EncodeToString(hash[:])
 }
 
 // normalizePCM normalizes PCM audio data
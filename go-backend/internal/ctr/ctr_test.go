@@ -1,8 +1,19 @@
 package ctr
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
 )
 
 func TestNormalizePCMUsesLittleEndianBytes(t *testing.T) {
@@ -21,3 +32,187 @@ func TestNormalizePCMEmptyInput(t *testing.T) {
 		t.Fatalf("normalizePCM(nil) length = %d, want 0", len(got))
 	}
 }
+
+func TestAnalyzeMatchesBatchCTID(t *testing.T) {
+	path := filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3")
+
+	for _, version := range []audio.Version{audio.V1, audio.V2} {
+		pcm, err := audio.DecodeAudioToPCMVersion(context.Background(), path, version)
+		if err != nil {
+			t.Fatalf("DecodeAudioToPCMVersion() error: %v", err)
+		}
+		want := computeCTID(pcm)
+
+		got, err := (&Service{}).analyze(context.Background(), path, version, nil)
+		if err != nil {
+			t.Fatalf("analyze() error: %v", err)
+		}
+		if got.ctid != want {
+			t.Fatalf("v%d analyze() CTID = %s, want %s", version, got.ctid, want)
+		}
+		if got.format == nil || got.format.Codec != audio.CodecMP3 || got.format.SampleRate != 44100 {
+			t.Fatalf("analyze() format = %v, want mp3 at 44100Hz", got.format)
+		}
+		wantDuration := time.Duration(len(pcm)/audio.TargetChannels) * time.Second / audio.TargetSampleRate
+		if got.duration != wantDuration {
+			t.Fatalf("analyze() duration = %v, want %v", got.duration, wantDuration)
+		}
+	}
+}
+
+func TestCTIDVersionsAgreeWithoutResampling(t *testing.T) {
+	// A 44.1kHz stream needs no rate conversion, so V1 and V2 hash the same PCM
+	path := filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3")
+	v1, err := (&Service{}).ComputeCTID(context.Background(), path, audio.V1)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V1) error: %v", err)
+	}
+	v2, err := (&Service{}).ComputeCTID(context.Background(), path, audio.V2)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V2) error: %v", err)
+	}
+	if v1 != v2 {
+		t.Fatalf("CTIDs differ for 44.1kHz source: v1=%s v2=%s", v1, v2)
+	}
+
+	// A 22.05kHz stream was hashed at the wrong speed by V1
+	path = filepath.Join("..", "audio", "testdata", "speech_22k_mpeg2.mp3")
+	v1, err = (&Service{}).ComputeCTID(context.Background(), path, audio.V1)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V1) error: %v", err)
+	}
+	v2, err = (&Service{}).ComputeCTID(context.Background(), path, audio.V2)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V2) error: %v", err)
+	}
+	if v1 == v2 {
+		t.Fatal("CTIDs equal for 22.05kHz source, want V2 to differ")
+	}
+}
+
+func TestV1CTIDOfFLACAndOggIsFFmpegOutput(t *testing.T) {
+	// Stands in for ffmpeg: the bytes it writes are what the first release
+	// hashed, provided it was asked for 44.1kHz mono 16-bit PCM
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	body := `#!/bin/sh
+case "$*" in
+*"-f s16le -ar 44100 -ac 1 pipe:1"*) printf 'cotune-v1-pcm\n' ;;
+*) echo "unexpected arguments: $*" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	audio.SetFFmpegPath(script)
+	t.Cleanup(func() { audio.SetFFmpegPath("") })
+
+	// sha256 of "cotune-v1-pcm\n"
+	const baseline = "c6628f777633daaf720467d835217a4fe029aabb82d038df2b7047fbb9e4a4ae"
+	for _, name := range []string{"tone_48k_stereo.flac", "tone_vorbis.ogg"} {
+		path := filepath.Join("..", "audio", "testdata", name)
+		v1, err := (&Service{}).ComputeCTID(context.Background(), path, audio.V1)
+		if err != nil {
+			t.Fatalf("ComputeCTID(%s, V1) error: %v", name, err)
+		}
+		if v1 != baseline {
+			t.Fatalf("ComputeCTID(%s, V1) = %s, want %s from ffmpeg's output", name, v1, baseline)
+		}
+		// V2 decodes natively
+		v2, err := (&Service{}).ComputeCTID(context.Background(), path, audio.V2)
+		if err != nil || v2 == baseline {
+			t.Fatalf("ComputeCTID(%s, V2) = %s, %v; want the native decode", name, v2, err)
+		}
+	}
+}
+
+// writeTaggedMP3 copies the test MP3 with an ID3v1.1 tag appended
+func writeTaggedMP3(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:], "Tag Title")
+	copy(tag[33:], "Tag Artist")
+	copy(tag[63:], "Tag Album")
+	copy(tag[93:], "1788")
+	tag[126] = 5  // track
+	tag[127] = 32 // Classical
+
+	path := filepath.Join(t.TempDir(), "tagged.mp3")
+	if err := os.WriteFile(path, append(data, tag...), 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	return path
+}
+
+func TestApplyTagsFillsMetadataAndFollowsPolicy(t *testing.T) {
+	path := writeTaggedMP3(t)
+
+	for _, trust := range []bool{false, true} {
+		s := &Service{trustTags: trust}
+		track := &models.Track{ID: "t", Path: path}
+		s.applyTags(track, s.readTags(track.Path))
+
+		if track.Title != "Tag Title" || track.Artist != "Tag Artist" || track.Album != "Tag Album" ||
+			track.TrackNumber != 5 || track.Year != 1788 || track.Genre != "Classical" {
+			t.Fatalf("trust=%v applyTags() = %+v, want metadata from tags", trust, track)
+		}
+		if track.Recognized != trust {
+			t.Fatalf("trust=%v Recognized = %v, want %v", trust, track.Recognized, trust)
+		}
+	}
+}
+
+func TestApplyTagsKeepsUserMetadata(t *testing.T) {
+	s := &Service{trustTags: true}
+	track := &models.Track{ID: "t", Path: writeTaggedMP3(t), Title: "My Title", Artist: "My Artist", Recognized: true}
+	s.applyTags(track, s.readTags(track.Path))
+
+	if track.Title != "My Title" || track.Artist != "My Artist" {
+		t.Fatalf("applyTags() overwrote user metadata: %+v", track)
+	}
+	if track.Album != "Tag Album" {
+		t.Fatalf("applyTags() Album = %q, want blank fields filled from tags", track.Album)
+	}
+}
+
+func TestApplyTagsStoresEmbeddedCover(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	var cover bytes.Buffer
+	if err := png.Encode(&cover, image.NewGray(image.Rect(0, 0, 40, 40))); err != nil {
+		t.Fatalf("png.Encode() error: %v", err)
+	}
+
+	// ID3v2.3 tag holding a single APIC front cover
+	frame := append([]byte("\x00image/png\x00\x03\x00"), cover.Bytes()...)
+	body := append([]byte("APIC"), byte(len(frame)>>24), byte(len(frame)>>16), byte(len(frame)>>8), byte(len(frame)), 0, 0)
+	body = append(body, frame...)
+	n := len(body)
+	tag := append([]byte{'I', 'D', '3', 3, 0, 0, byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}, body...)
+	path := filepath.Join(t.TempDir(), "cover.mp3")
+	if err := os.WriteFile(path, append(tag, data...), 0o644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	covers, err := artwork.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("artwork.New() error: %v", err)
+	}
+	s := &Service{artwork: covers}
+	track := &models.Track{ID: "t", Path: path}
+	s.applyTags(track, s.readTags(track.Path))
+
+	if track.Artwork == "" || !covers.Has(track.Artwork) {
+		t.Fatalf("applyTags() Artwork = %q, want the stored cover hash", track.Artwork)
+	}
+	got, _, err := covers.Get(track.Artwork, artwork.SizeOriginal)
+	if err != nil || !bytes.Equal(got, cover.Bytes()) {
+		t.Fatalf("stored cover = %d bytes, %v; want the embedded PNG", len(got), err)
+	}
+}
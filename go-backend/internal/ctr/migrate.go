@@ -0,0 +1,201 @@
+package ctr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+// MigrationState is the lifecycle stage of a CTID migration
+type MigrationState string
+
+const (
+	MigrationIdle    MigrationState = "idle"
+	MigrationRunning MigrationState = "running"
+	MigrationDone    MigrationState = "done"
+	MigrationFailed  MigrationState = "failed"
+)
+
+// ErrMigrationRunning is returned when a migration is already in progress
+var ErrMigrationRunning = errors.New("CTID migration already running")
+
+// MigrationProgress reports how far the CTID migration has got
+type MigrationProgress struct {
+	State         MigrationState `json:"state"`
+	TargetVersion int            `json:"target_version"`
+	Total         int            `json:"total"`     // Tracks whose CTID came from an older algorithm
+	Processed     int            `json:"processed"` // Tracks recomputed so far, including failures
+	Changed       int            `json:"changed"`   // Tracks whose CTID differs under the new algorithm
+	Failed        int            `json:"failed"`
+	LastError     string         `json:"last_error,omitempty"`
+	StartedAt     int64          `json:"started_at,omitempty"` // Unix time
+	FinishedAt    int64          `json:"finished_at,omitempty"`
+}
+
+// StartMigration recomputes, in the background, the CTID of every track
+// produced by an older algorithm. Tracks whose CTID changes keep announcing
+// the old one for LegacyCTIDWindow. Tracks that fail keep their old CTID and
+// are retried by the next migration.
+func (s *Service) StartMigration() error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return fmt.Errorf("CTR service not running")
+	}
+	if s.migration.State == MigrationRunning {
+		s.mu.Unlock()
+		return ErrMigrationRunning
+	}
+	s.migration = MigrationProgress{
+		State:         MigrationRunning,
+		TargetVersion: int(CTIDVersion),
+		StartedAt:     time.Now().Unix(),
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		s.runMigration(s.ctx)
+	}()
+	return nil
+}
+
+// MigrationProgress returns a snapshot of the current or last migration
+func (s *Service) MigrationProgress() MigrationProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	progress := s.migration
+	if progress.State == "" {
+		progress.State = MigrationIdle
+		progress.TargetVersion = int(CTIDVersion)
+	}
+	return progress
+}
+
+func (s *Service) runMigration(ctx context.Context) {
+	tracks, err := s.store.GetAllTracks()
+	if err != nil {
+		s.finishMigration(fmt.Errorf("failed to list tracks: %w", err))
+		return
+	}
+
+	var pending []*models.Track
+	for _, track := range tracks {
+		if NeedsMigration(track) {
+			pending = append(pending, track)
+		}
+	}
+	s.updateMigration(func(p *MigrationProgress) { p.Total = len(pending) })
+
+	for _, track := range pending {
+		if err := ctx.Err(); err != nil {
+			s.finishMigration(err)
+			return
+		}
+
+		changed, err := s.migrateTrack(ctx, track.ID)
+		s.updateMigration(func(p *MigrationProgress) {
+			p.Processed++
+			switch {
+			case err != nil:
+				p.Failed++
+				p.LastError = fmt.Sprintf("track %s: %v", track.ID, err)
+			case changed:
+				p.Changed++
+			}
+		})
+	}
+	s.finishMigration(nil)
+}
+
+// migrateTrack recomputes the CTID of a track as it is stored now: the
+// list the migration started from may be stale, and a worker may have
+// analysed or the user deleted the track since. It reports whether the
+// CTID changed.
+func (s *Service) migrateTrack(ctx context.Context, id string) (bool, error) {
+	track, err := s.store.GetTrack(id)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && !NeedsMigration(track)) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	previous := track.CTID
+	trackCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	if err := s.ProcessTrack(trackCtx, track); err != nil {
+		return false, err
+	}
+	return track.CTID != previous, nil
+}
+
+func (s *Service) updateMigration(fn func(*MigrationProgress)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.migration)
+}
+
+func (s *Service) finishMigration(err error) {
+	s.updateMigration(func(p *MigrationProgress) {
+		p.State = MigrationDone
+		if err != nil {
+			p.State = MigrationFailed
+			p.LastError = err.Error()
+		}
+		p.FinishedAt = time.Now().Unix()
+	})
+}
+
+// NeedsMigration reports whether a track's CTID came from an older algorithm
+func NeedsMigration(track *models.Track) bool {
+	return track.CTID != "" && TrackCTIDVersion(track) < CTIDVersion
+}
+
+// ExpireLegacyCTIDs forgets legacy CTIDs whose transition window ended
+// before now and returns how many tracks were updated
+func (s *Service) ExpireLegacyCTIDs(now time.Time) (int, error) {
+	tracks, err := s.store.GetAllTracks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tracks: %w", err)
+	}
+
+	expired := 0
+	for _, track := range tracks {
+		if track.LegacyCTID == "" || track.LegacyExpires > now.Unix() {
+			continue
+		}
+		ok, err := s.expireLegacyCTID(track.ID, now)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// expireLegacyCTID forgets the expired legacy CTID of a track as it is
+// stored now, so a CTID set by analysis since the listing is not undone
+func (s *Service) expireLegacyCTID(id string, now time.Time) (bool, error) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	track, err := s.store.GetTrack(id)
+	if err != nil || track.LegacyCTID == "" || track.LegacyExpires > now.Unix() {
+		return false, nil
+	}
+	track.LegacyCTID = ""
+	track.LegacyExpires = 0
+	if err := s.store.SaveTrack(track); err != nil {
+		return false, fmt.Errorf("failed to save track: %w", err)
+	}
+	return true, nil
+}
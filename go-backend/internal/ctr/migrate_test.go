@@ -0,0 +1,147 @@
+package ctr
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+func TestMigrationRecomputesOldCTIDs(t *testing.T) {
+	store := storage.NewMemory()
+	s := New(store, nil)
+
+	speech := filepath.Join("..", "audio", "testdata", "speech_22k_mpeg2.mp3")
+	mozart := filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3")
+	speechV1, err := s.ComputeCTID(context.Background(), speech, audio.V1)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V1) error: %v", err)
+	}
+	mozartV1, err := s.ComputeCTID(context.Background(), mozart, audio.V1)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V1) error: %v", err)
+	}
+
+	tracks := []*models.Track{
+		{ID: "speech", CTID: speechV1, Path: speech},
+		{ID: "mozart", CTID: mozartV1, Path: mozart},
+		{ID: "missing", CTID: "0000", Path: filepath.Join(t.TempDir(), "gone.mp3")},
+		{ID: "unprocessed", Path: mozart},
+	}
+	for _, track := range tracks {
+		if err := store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+
+	s.runMigration(context.Background())
+
+	progress := s.MigrationProgress()
+	if progress.State != MigrationDone || progress.Total != 3 || progress.Processed != 3 ||
+		progress.Changed != 1 || progress.Failed != 1 || progress.LastError == "" {
+		t.Fatalf("MigrationProgress() = %+v, want done with 3 total, 1 changed, 1 failed", progress)
+	}
+
+	got, err := store.GetTrack("speech")
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	speechV2, _ := s.ComputeCTID(context.Background(), speech, CTIDVersion)
+	if got.CTID != speechV2 || got.CTIDVersion != int(CTIDVersion) {
+		t.Fatalf("migrated CTID = %s v%d, want %s v%d", got.CTID, got.CTIDVersion, speechV2, CTIDVersion)
+	}
+	if got.LegacyCTID != speechV1 || got.LegacyExpires <= time.Now().Unix() {
+		t.Fatalf("legacy CTID = %s until %d, want %s in the future", got.LegacyCTID, got.LegacyExpires, speechV1)
+	}
+
+	got, err = store.GetTrack("mozart")
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	if got.CTID != mozartV1 || got.CTIDVersion != int(CTIDVersion) || got.LegacyCTID != "" {
+		t.Fatalf("unchanged track = %+v, want same CTID at v%d without legacy", got, CTIDVersion)
+	}
+
+	got, err = store.GetTrack("missing")
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	if !NeedsMigration(got) {
+		t.Fatalf("failed track = %+v, want it left for the next migration", got)
+	}
+}
+
+func TestMigrationUsesTrackAsStoredNow(t *testing.T) {
+	store := storage.NewMemory()
+	s := New(store, nil)
+
+	speech := filepath.Join("..", "audio", "testdata", "speech_22k_mpeg2.mp3")
+	speechV1, err := s.ComputeCTID(context.Background(), speech, audio.V1)
+	if err != nil {
+		t.Fatalf("ComputeCTID(V1) error: %v", err)
+	}
+	speechV2, _ := s.ComputeCTID(context.Background(), speech, CTIDVersion)
+
+	// A worker migrated the track after the migration listed it
+	expires := time.Now().Add(time.Hour).Unix()
+	store.SaveTrack(&models.Track{ID: "speech", CTID: speechV2, CTIDVersion: int(CTIDVersion), LegacyCTID: speechV1, LegacyExpires: expires, Path: speech})
+	if changed, err := s.migrateTrack(context.Background(), "speech"); err != nil || changed {
+		t.Fatalf("migrateTrack() = %v, %v; want the migrated track skipped", changed, err)
+	}
+	if changed, err := s.migrateTrack(context.Background(), "deleted"); err != nil || changed {
+		t.Fatalf("migrateTrack(deleted) = %v, %v; want it skipped", changed, err)
+	}
+
+	// Processing a stale copy keeps the legacy CTID of the stored track
+	stale := &models.Track{ID: "speech", Path: speech}
+	if err := s.ProcessTrack(context.Background(), stale); err != nil {
+		t.Fatalf("ProcessTrack() error: %v", err)
+	}
+	got, _ := store.GetTrack("speech")
+	if got.CTID != speechV2 || got.LegacyCTID != speechV1 || got.LegacyExpires != expires {
+		t.Fatalf("track after processing a stale copy = %+v, want legacy %s until %d kept", got, speechV1, expires)
+	}
+}
+
+func TestExpireLegacyCTIDs(t *testing.T) {
+	store := storage.NewMemory()
+	s := New(store, nil)
+
+	now := time.Now()
+	tracks := []*models.Track{
+		{ID: "expired", CTID: "new-1", LegacyCTID: "old-1", LegacyExpires: now.Add(-time.Minute).Unix()},
+		{ID: "active", CTID: "new-2", LegacyCTID: "old-2", LegacyExpires: now.Add(time.Hour).Unix()},
+	}
+	for _, track := range tracks {
+		if err := store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+
+	n, err := s.ExpireLegacyCTIDs(now)
+	if err != nil {
+		t.Fatalf("ExpireLegacyCTIDs() error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("ExpireLegacyCTIDs() = %d, want 1", n)
+	}
+	if got, _ := store.GetTrack("expired"); got.LegacyCTID != "" || got.LegacyExpires != 0 {
+		t.Fatalf("expired track = %+v, want legacy CTID cleared", got)
+	}
+	if got, _ := store.GetTrack("active"); got.LegacyCTID != "old-2" {
+		t.Fatalf("active track = %+v, want legacy CTID kept", got)
+	}
+}
+
+func TestComputeCTIDRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3")
+	for _, version := range []audio.Version{0, CTIDVersion + 1} {
+		if _, err := (&Service{}).ComputeCTID(context.Background(), path, version); err == nil {
+			t.Fatalf("ComputeCTID(v%d) error = nil, want unsupported version", version)
+		}
+	}
+}
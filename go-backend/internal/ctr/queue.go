@@ -0,0 +1,371 @@
+package ctr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+const (
+	// DefaultWorkers is how many tracks are processed concurrently
+	DefaultWorkers = 2
+	// DefaultMaxAttempts is how many times a job runs before it is failed
+	DefaultMaxAttempts = 5
+	// DefaultRetryDelay is the wait before the first retry; it doubles after
+	// every further failure
+	DefaultRetryDelay = 30 * time.Second
+	// maxRetryDelay caps the backoff between attempts
+	maxRetryDelay = time.Hour
+	// idlePoll bounds how long an idle worker sleeps between job scans
+	idlePoll = time.Minute
+	// jobTimeout bounds a single processing attempt
+	jobTimeout = 5 * time.Minute
+)
+
+// QueueTrack persists a CTR job for a track. Jobs survive restarts and are
+// picked up once the service is running. Queueing a track that already has
+// a pending job is a no-op; a failed job starts over.
+func (s *Service) QueueTrack(track *models.Track) error {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	now := time.Now().Unix()
+	job, err := s.store.GetJob(track.ID)
+	if err == nil && jobPending(job.State) {
+		return nil
+	}
+	if err != nil {
+		job = &models.Job{ID: track.ID, TrackID: track.ID, CreatedAt: now}
+	}
+	resetJob(job, now)
+	if err := s.store.SaveJob(job); err != nil {
+		return fmt.Errorf("failed to queue track: %w", err)
+	}
+	s.queue.push(job)
+
+	s.notify()
+	return nil
+}
+
+// ListJobs returns jobs in the given state, or all jobs if state is empty,
+// oldest first
+func (s *Service) ListJobs(state models.JobState) ([]*models.Job, error) {
+	jobs, err := s.store.GetAllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if state == "" || job.State == state {
+			result = append(result, job)
+		}
+	}
+	sortJobs(result)
+	return result, nil
+}
+
+// JobCounts returns the number of jobs in each state
+func (s *Service) JobCounts() (map[models.JobState]int, error) {
+	jobs, err := s.store.GetAllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.JobState]int)
+	for _, job := range jobs {
+		counts[job.State]++
+	}
+	return counts, nil
+}
+
+// RetryJob queues a failed job again with a fresh attempt budget
+func (s *Service) RetryJob(id string) error {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	job, err := s.store.GetJob(id)
+	if err != nil {
+		return err
+	}
+	if job.State != models.JobFailed {
+		return fmt.Errorf("job %s is %s, not failed", id, job.State)
+	}
+	resetJob(job, time.Now().Unix())
+	if err := s.store.SaveJob(job); err != nil {
+		return err
+	}
+	s.queue.push(job)
+
+	s.notify()
+	return nil
+}
+
+// RetryFailedJobs queues every failed job again and returns how many there were
+func (s *Service) RetryFailedJobs() (int, error) {
+	failed, err := s.ListJobs(models.JobFailed)
+	if err != nil {
+		return 0, err
+	}
+
+	retried := 0
+	for _, job := range failed {
+		if err := s.RetryJob(job.ID); err != nil {
+			return retried, err
+		}
+		retried++
+	}
+	return retried, nil
+}
+
+// RequeueUnprocessed queues every track missing something CTR computes and
+// without a job, such as tracks added before jobs were persisted or
+// processed by a release that computed less. Failed jobs are left for an
+// explicit retry.
+func (s *Service) RequeueUnprocessed() (int, error) {
+	tracks, err := s.store.GetAllTracks()
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, track := range tracks {
+		if !needsAnalysis(track) {
+			continue
+		}
+		if _, err := s.store.GetJob(track.ID); err == nil {
+			continue
+		}
+		if err := s.QueueTrack(track); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// recoverJobs puts jobs interrupted mid-flight back in the queue and
+// rebuilds the in-memory queue from the stored jobs
+func (s *Service) recoverJobs() (int, error) {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	jobs, err := s.store.GetAllJobs()
+	if err != nil {
+		return 0, err
+	}
+
+	s.queue = newRunQueue()
+	recovered := 0
+	for _, job := range jobs {
+		if job.State == models.JobDecoding || job.State == models.JobHashing {
+			job.State = models.JobQueued
+			job.UpdatedAt = time.Now().Unix()
+			if err := s.store.SaveJob(job); err != nil {
+				return recovered, err
+			}
+			recovered++
+		}
+		if job.State == models.JobQueued {
+			s.queue.push(job)
+		}
+	}
+	return recovered, nil
+}
+
+func (s *Service) worker() {
+	defer s.wg.Done()
+
+	for {
+		job, wait := s.claimJob(time.Now())
+		if job != nil {
+			// Another job may be runnable for an idle worker
+			s.notify()
+			s.runJob(job)
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// claimJob moves the oldest runnable job to decoding and returns it. If no
+// job is runnable it returns how long to sleep before looking again.
+func (s *Service) claimJob(now time.Time) (*models.Job, time.Duration) {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return nil, idlePoll
+	}
+
+	for {
+		entry, wait, ok := s.queue.pop(now)
+		if !ok {
+			return nil, wait
+		}
+
+		job, err := s.store.GetJob(entry.id)
+		if errors.Is(err, storage.ErrNotFound) {
+			// Deleted with its track
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Failed to load CTR job %s: %v\n", entry.id, err)
+			s.queue.add(entry)
+			return nil, idlePoll
+		}
+		if job.State != models.JobQueued {
+			// A stale entry: the job was claimed or failed since
+			continue
+		}
+		if time.Unix(job.NextAttempt, 0).After(now) {
+			s.queue.push(job)
+			continue
+		}
+
+		job.State = models.JobDecoding
+		job.Attempts++
+		job.UpdatedAt = now.Unix()
+		if err := s.store.SaveJob(job); err != nil {
+			fmt.Printf("Failed to claim CTR job %s: %v\n", job.ID, err)
+			job.State = models.JobQueued
+			job.Attempts--
+			s.queue.push(job)
+			return nil, idlePoll
+		}
+		return job, 0
+	}
+}
+
+// runJob processes a claimed job and records the outcome
+func (s *Service) runJob(job *models.Job) {
+	track, err := s.store.GetTrack(job.TrackID)
+	if err != nil {
+		// The track is gone; retrying cannot help
+		s.finishJob(job, err, false)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, jobTimeout)
+	err = s.processTrack(ctx, track, func(state models.JobState) {
+		s.setJobState(job, state)
+	})
+	cancel()
+
+	if err != nil && s.ctx.Err() != nil {
+		// Shutting down: the attempt does not count
+		s.jobMu.Lock()
+		job.State = models.JobQueued
+		job.Attempts--
+		job.UpdatedAt = time.Now().Unix()
+		if saveErr := s.store.SaveJob(job); saveErr != nil {
+			fmt.Printf("Failed to save CTR job %s: %v\n", job.ID, saveErr)
+		}
+		s.queue.push(job)
+		s.jobMu.Unlock()
+		return
+	}
+	s.finishJob(job, err, true)
+}
+
+func (s *Service) setJobState(job *models.Job, state models.JobState) {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	job.State = state
+	job.UpdatedAt = time.Now().Unix()
+	if err := s.store.SaveJob(job); err != nil {
+		fmt.Printf("Failed to save CTR job %s: %v\n", job.ID, err)
+	}
+}
+
+// finishJob removes a job that succeeded, so finished jobs are not kept
+// and the table only holds pending and failed jobs, or on error schedules a retry with backoff until the
+// attempt budget is spent
+func (s *Service) finishJob(job *models.Job, err error, retry bool) {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	now := time.Now()
+	job.UpdatedAt = now.Unix()
+	switch {
+	case err == nil:
+		if delErr := s.store.DeleteJob(job.ID); delErr != nil {
+			fmt.Printf("Failed to remove CTR job %s: %v\n", job.ID, delErr)
+		}
+		return
+	case retry && job.Attempts < s.maxAttempts:
+		job.State = models.JobQueued
+		job.LastError = err.Error()
+		job.NextAttempt = now.Add(s.retryDelay(job.Attempts)).Unix()
+	default:
+		job.State = models.JobFailed
+		job.LastError = err.Error()
+		job.NextAttempt = 0
+	}
+	if saveErr := s.store.SaveJob(job); saveErr != nil {
+		fmt.Printf("Failed to save CTR job %s: %v\n", job.ID, saveErr)
+		return
+	}
+	if job.State == models.JobQueued {
+		s.queue.push(job)
+	}
+}
+
+// retryDelay is the backoff after the given number of failed attempts
+func (s *Service) retryDelay(attempts int) time.Duration {
+	delay := s.retryBase
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// notify wakes an idle worker without blocking
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// needsAnalysis reports whether a track lacks its CTID, technical
+// properties, loudness or a waveform its audio can produce
+func needsAnalysis(track *models.Track) bool {
+	return track.CTID == "" || track.FileSize == 0 || track.Loudness == nil || (!track.Waveform && !track.NoWaveform)
+}
+
+func jobPending(state models.JobState) bool {
+	return state == models.JobQueued || state == models.JobDecoding || state == models.JobHashing
+}
+
+func resetJob(job *models.Job, now int64) {
+	job.State = models.JobQueued
+	job.Attempts = 0
+	job.LastError = ""
+	job.NextAttempt = 0
+	job.UpdatedAt = now
+}
+
+func sortJobs(jobs []*models.Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt != jobs[j].CreatedAt {
+			return jobs[i].CreatedAt < jobs[j].CreatedAt
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+}
@@ -0,0 +1,252 @@
+package ctr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+func newQueueTestService(t *testing.T) (*Service, storage.Store) {
+	t.Helper()
+	store := storage.NewMemory()
+	return New(store, nil), store
+}
+
+func stopService(t *testing.T, s *Service) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+}
+
+// waitForJobState polls until the job reaches state or the test times out
+func waitForJobState(t *testing.T, store storage.Store, id string, state models.JobState) *models.Job {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		job, err := store.GetJob(id)
+		if err == nil && job.State == state {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s = %+v (err %v), want state %s", id, job, err, state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitForJobRemoved polls until a finished job is removed or the test
+// times out
+func waitForJobRemoved(t *testing.T, store storage.Store, id string) {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		job, err := store.GetJob(id)
+		if err != nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s = %+v, want it removed when done", id, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueuedJobIsPersistedAndRunsOnStart(t *testing.T) {
+	s, store := newQueueTestService(t)
+
+	track := &models.Track{ID: "mozart", Path: filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3")}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	// Queued before Start: nothing may be dropped
+	if err := s.QueueTrack(track); err != nil {
+		t.Fatalf("QueueTrack() error: %v", err)
+	}
+	if job, err := store.GetJob(track.ID); err != nil || job.State != models.JobQueued {
+		t.Fatalf("GetJob() = %+v, %v; want queued", job, err)
+	}
+
+	s.Start()
+	defer stopService(t, s)
+
+	waitForJobRemoved(t, store, track.ID)
+	got, err := store.GetTrack(track.ID)
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	if got.CTID == "" || got.CTIDVersion != int(CTIDVersion) {
+		t.Fatalf("processed track = %+v, want CTID at v%d", got, CTIDVersion)
+	}
+	info, err := os.Stat(track.Path)
+	if err != nil {
+		t.Fatalf("os.Stat() error: %v", err)
+	}
+	wantBitrate := int(info.Size() * 8 * 1000 / got.DurationMs)
+	if got.FileSize != info.Size() || got.DurationMs <= 0 || got.Bitrate != wantBitrate {
+		t.Fatalf("processed track properties = size %d, %dms, %dbps; want size %d and bitrate %d",
+			got.FileSize, got.DurationMs, got.Bitrate, info.Size(), wantBitrate)
+	}
+	if l := got.Loudness; l == nil || l.IntegratedLUFS <= -70 || l.IntegratedLUFS >= 0 || l.TruePeakDBTP > 1 {
+		t.Fatalf("processed track loudness = %+v, want a measurement of audible music", got.Loudness)
+	}
+	if peaks, err := waveform.Load(waveform.PathFor(got.Path)); !got.Waveform || err != nil || peaks.Levels[0].Len() == 0 {
+		t.Fatalf("processed track waveform = %v, %v; want peaks beside the audio", got.Waveform, err)
+	}
+}
+
+func TestFailingJobRetriesThenFails(t *testing.T) {
+	s, store := newQueueTestService(t)
+	s.maxAttempts = 3
+	s.retryBase = time.Millisecond
+
+	track := &models.Track{ID: "missing", Path: filepath.Join(t.TempDir(), "gone.mp3")}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	if err := s.QueueTrack(track); err != nil {
+		t.Fatalf("QueueTrack() error: %v", err)
+	}
+
+	s.Start()
+	job := waitForJobState(t, store, track.ID, models.JobFailed)
+	stopService(t, s)
+
+	if job.Attempts != 3 || job.LastError == "" {
+		t.Fatalf("failed job = %+v, want 3 attempts with an error", job)
+	}
+	failed, err := s.ListJobs(models.JobFailed)
+	if err != nil || len(failed) != 1 || failed[0].ID != track.ID {
+		t.Fatalf("ListJobs(failed) = %+v, %v; want the failed job", failed, err)
+	}
+
+	if err := s.RetryJob(track.ID); err != nil {
+		t.Fatalf("RetryJob() error: %v", err)
+	}
+	job, err = store.GetJob(track.ID)
+	if err != nil || job.State != models.JobQueued || job.Attempts != 0 {
+		t.Fatalf("retried job = %+v, %v; want queued with a fresh budget", job, err)
+	}
+	if err := s.RetryJob(track.ID); err == nil {
+		t.Fatal("RetryJob() on a queued job error = nil, want error")
+	}
+}
+
+func TestStartRecoversInterruptedJobs(t *testing.T) {
+	s, store := newQueueTestService(t)
+
+	track := &models.Track{ID: "mozart", Path: filepath.Join("..", "audio", "testdata", "mozart_44k_stereo.mp3")}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	// As left behind by a crash mid-hash
+	if err := store.SaveJob(&models.Job{ID: track.ID, TrackID: track.ID, State: models.JobHashing, Attempts: 1}); err != nil {
+		t.Fatalf("SaveJob() error: %v", err)
+	}
+
+	s.Start()
+	defer stopService(t, s)
+
+	waitForJobRemoved(t, store, track.ID)
+}
+
+func TestClaimJobTakesOldestDueJob(t *testing.T) {
+	s, store := newQueueTestService(t)
+
+	now := time.Unix(1000, 0)
+	jobs := []*models.Job{
+		{ID: "newer", TrackID: "newer", State: models.JobQueued, CreatedAt: 20},
+		{ID: "retry", TrackID: "retry", State: models.JobQueued, CreatedAt: 5, NextAttempt: now.Unix() + 30},
+		{ID: "older", TrackID: "older", State: models.JobQueued, CreatedAt: 10},
+		{ID: "deleted", TrackID: "deleted", State: models.JobQueued, CreatedAt: 1},
+		{ID: "failed", TrackID: "failed", State: models.JobFailed, CreatedAt: 2},
+	}
+	for _, job := range jobs {
+		if err := store.SaveJob(job); err != nil {
+			t.Fatalf("SaveJob() error: %v", err)
+		}
+	}
+	if _, err := s.recoverJobs(); err != nil {
+		t.Fatalf("recoverJobs() error: %v", err)
+	}
+	// Removed with its track after the queue was built
+	if err := store.DeleteJob("deleted"); err != nil {
+		t.Fatalf("DeleteJob() error: %v", err)
+	}
+
+	for _, want := range []string{"older", "newer"} {
+		job, _ := s.claimJob(now)
+		if job == nil || job.ID != want || job.State != models.JobDecoding {
+			t.Fatalf("claimJob() = %+v, want %s decoding", job, want)
+		}
+	}
+	if job, wait := s.claimJob(now); job != nil || wait != 30*time.Second {
+		t.Fatalf("claimJob() = %+v, %v; want nothing for 30s", job, wait)
+	}
+	if job, _ := s.claimJob(now.Add(30 * time.Second)); job == nil || job.ID != "retry" {
+		t.Fatalf("claimJob() after the delay = %+v, want retry", job)
+	}
+	if job, wait := s.claimJob(now.Add(time.Hour)); job != nil || wait != idlePoll {
+		t.Fatalf("claimJob() on an empty queue = %+v, %v; want nothing", job, wait)
+	}
+}
+
+func TestRequeueUnprocessedSkipsProcessedAndFailed(t *testing.T) {
+	s, store := newQueueTestService(t)
+
+	tracks := []*models.Track{
+		{ID: "processed", CTID: "abcd", FileSize: 1024, Loudness: &models.Loudness{IntegratedLUFS: -14}, Waveform: true},
+		{ID: "new"},
+		{ID: "no-properties", CTID: "ef01"},
+		{ID: "failed"},
+		{ID: "silent", CTID: "2345", FileSize: 512, Loudness: &models.Loudness{IntegratedLUFS: -70}, NoWaveform: true},
+	}
+	for _, track := range tracks {
+		if err := store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+	if err := store.SaveJob(&models.Job{ID: "failed", TrackID: "failed", State: models.JobFailed}); err != nil {
+		t.Fatalf("SaveJob() error: %v", err)
+	}
+
+	n, err := s.RequeueUnprocessed()
+	if err != nil {
+		t.Fatalf("RequeueUnprocessed() error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("RequeueUnprocessed() = %d, want 2", n)
+	}
+	for _, id := range []string{"new", "no-properties"} {
+		if job, err := store.GetJob(id); err != nil || job.State != models.JobQueued {
+			t.Fatalf("GetJob(%s) = %+v, %v; want queued", id, job, err)
+		}
+	}
+	for _, id := range []string{"processed", "silent"} {
+		if _, err := store.GetJob(id); err == nil {
+			t.Fatalf("GetJob(%s) error = nil, want no job", id)
+		}
+	}
+}
+
+func TestRetryDelayDoublesUpToCap(t *testing.T) {
+	s := &Service{retryBase: 30 * time.Second}
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		20: maxRetryDelay,
+	} {
+		if got := s.retryDelay(attempts); got != want {
+			t.Fatalf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
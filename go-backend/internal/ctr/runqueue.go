@@ -0,0 +1,87 @@
+package ctr
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// queuedJob is the in-memory place of a queued job. The stored job stays
+// authoritative: an entry may be stale and is checked again when claimed.
+type queuedJob struct {
+	id          string
+	createdAt   int64
+	nextAttempt int64
+}
+
+// jobHeap is a min-heap of queued jobs ordered by less
+type jobHeap struct {
+	jobs []queuedJob
+	less func(a, b queuedJob) bool
+}
+
+func (h *jobHeap) Len() int           { return len(h.jobs) }
+func (h *jobHeap) Less(i, j int) bool { return h.less(h.jobs[i], h.jobs[j]) }
+func (h *jobHeap) Swap(i, j int)      { h.jobs[i], h.jobs[j] = h.jobs[j], h.jobs[i] }
+func (h *jobHeap) Push(x any)         { h.jobs = append(h.jobs, x.(queuedJob)) }
+
+func (h *jobHeap) Pop() any {
+	last := h.jobs[len(h.jobs)-1]
+	h.jobs = h.jobs[:len(h.jobs)-1]
+	return last
+}
+
+// runQueue keeps queued jobs ordered so a claim does not read the whole
+// job table. Jobs waiting out a retry delay sit in delayed by NextAttempt
+// and move to ready, oldest first, once they are due. It is rebuilt from
+// the store by recoverJobs and guarded by jobMu.
+type runQueue struct {
+	ready   jobHeap
+	delayed jobHeap
+}
+
+func newRunQueue() *runQueue {
+	return &runQueue{
+		ready: jobHeap{less: func(a, b queuedJob) bool {
+			if a.createdAt != b.createdAt {
+				return a.createdAt < b.createdAt
+			}
+			return a.id < b.id
+		}},
+		delayed: jobHeap{less: func(a, b queuedJob) bool {
+			return a.nextAttempt < b.nextAttempt
+		}},
+	}
+}
+
+// push adds a queued job
+func (q *runQueue) push(job *models.Job) {
+	q.add(queuedJob{id: job.ID, createdAt: job.CreatedAt, nextAttempt: job.NextAttempt})
+}
+
+// add puts an entry back, such as one that could not be claimed
+func (q *runQueue) add(entry queuedJob) {
+	if entry.nextAttempt > 0 {
+		heap.Push(&q.delayed, entry)
+		return
+	}
+	heap.Push(&q.ready, entry)
+}
+
+// pop returns the oldest job that is due at now. If none is, it returns
+// false and how long until the next delayed job is due, up to idlePoll.
+func (q *runQueue) pop(now time.Time) (queuedJob, time.Duration, bool) {
+	for q.delayed.Len() > 0 && !time.Unix(q.delayed.jobs[0].nextAttempt, 0).After(now) {
+		heap.Push(&q.ready, heap.Pop(&q.delayed))
+	}
+	if q.ready.Len() > 0 {
+		return heap.Pop(&q.ready).(queuedJob), 0, true
+	}
+
+	wait := idlePoll
+	if q.delayed.Len() > 0 {
+		wait = min(wait, time.Unix(q.delayed.jobs[0].nextAttempt, 0).Sub(now))
+	}
+	return queuedJob{}, wait, false
+}
@@ -0,0 +1,395 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cotune/go-backend/internal/backup"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/playlist"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// Library export formats
+const (
+	LibraryJSON = "json"
+	LibraryM3U8 = "m3u8"
+)
+
+// LibraryImport reports a library import
+type LibraryImport struct {
+	Tracks    int `json:"tracks"`
+	Playlists int `json:"playlists"`
+	Existing  int `json:"existing"` // Already in the library
+	Missing   int `json:"missing"`  // No file on this device
+}
+
+// ErrOutsideExportDir is returned for a path an API client gave outside
+// the export dir
+var ErrOutsideExportDir = errors.New("path is outside the export dir")
+
+// SetExportDir sets the directory API clients write backups and library
+// exports to and import library files from
+func (d *Daemon) SetExportDir(dir string) {
+	d.exportDir = dir
+}
+
+// ExportPath resolves a path an API client gave for a backup, export or
+// import. A relative path is taken within the export dir; an absolute one,
+// with symlinks followed, must lie in it.
+func (d *Daemon) ExportPath(path string) (string, error) {
+	if d.exportDir == "" {
+		return "", fmt.Errorf("export dir not configured")
+	}
+	root, err := filepath.EvalSymlinks(d.exportDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve export dir: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Not written yet: the directory it goes in must be inside
+		dir, dirErr := filepath.EvalSymlinks(filepath.Dir(path))
+		if dirErr != nil {
+			return "", fmt.Errorf("failed to resolve path: %w", dirErr)
+		}
+		resolved, err = filepath.Join(dir, filepath.Base(path)), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideExportDir, path)
+	}
+	return resolved, nil
+}
+
+// SetBackupSource sets the node state BackupToFile archives
+func (d *Daemon) SetBackupSource(src backup.Source) {
+	d.backupSource = &src
+}
+
+// BackupToFile writes a backup of the node to path. The archive appears
+// under path only once it is complete, and an existing file is never
+// replaced. The backup of an encrypted node is sealed with its vault
+// secret, which opts must give unless it sets Plaintext.
+func (d *Daemon) BackupToFile(path string, opts backup.Options) (*backup.Manifest, error) {
+	if d.backupSource == nil {
+		return nil, fmt.Errorf("backup not configured")
+	}
+	d.vaultMu.Lock()
+	v, keyring := d.vault, d.keyring
+	d.vaultMu.Unlock()
+	if v != nil && !opts.Secret.IsZero() {
+		// Sealed with the secret the node is unlocked with, so the archive
+		// opens with what the user already keeps
+		if _, err := v.Unlock(opts.Secret); err != nil {
+			return nil, err
+		}
+	}
+
+	src := *d.backupSource
+	src.Keyring = keyring
+	manifest, err := backup.CreateFile(path, src, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("backup-written", "path", path, "tracks", manifest.Tracks, "playlists", manifest.Playlists, "media_files", manifest.MediaFiles, "sealed", manifest.Vault != nil)
+	return manifest, nil
+}
+
+// LibraryFormatFor returns the format a library file is in: the given one,
+// or the one its extension names
+func LibraryFormatFor(format string, path string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".m3u", ".m3u8":
+			format = LibraryM3U8
+		default:
+			format = LibraryJSON
+		}
+	}
+	switch format = strings.ToLower(format); format {
+	case LibraryJSON, LibraryM3U8:
+		return format, nil
+	case "m3u":
+		return LibraryM3U8, nil
+	default:
+		return "", fmt.Errorf("unknown library format: %s", format)
+	}
+}
+
+// ExportLibrary writes the library, or with playlistID one playlist and its
+// local tracks. JSON carries the full metadata and the playlists; M3U8
+// lists the local files of the tracks in order.
+func (d *Daemon) ExportLibrary(w io.Writer, format string, playlistID string) error {
+	tracks, playlists, err := d.exportedLibrary(playlistID)
+	if err != nil {
+		return err
+	}
+	if format == LibraryJSON {
+		return backup.NewLibrary(tracks, playlists).Write(w)
+	}
+	if format != LibraryM3U8 {
+		return fmt.Errorf("unknown library format: %s", format)
+	}
+
+	items := make([]backup.M3UItem, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Path != "" {
+			items = append(items, backup.M3UItemFor(track))
+		}
+	}
+	return backup.WriteM3U(w, items)
+}
+
+// ExportLibraryFile exports the library like ExportLibrary into a new file,
+// in the given format or the one the file's extension names
+func (d *Daemon) ExportLibraryFile(path string, format string, playlistID string) error {
+	format, err := LibraryFormatFor(format, path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = d.ExportLibrary(tmp, format, playlistID)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	// Linked rather than renamed so an existing file is never replaced
+	if err := os.Link(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// exportedLibrary returns the tracks and playlists an export covers
+func (d *Daemon) exportedLibrary(playlistID string) ([]*models.Track, []*models.Playlist, error) {
+	if playlistID == "" {
+		tracks, err := d.store.GetAllTracks()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		playlists, err := d.ListPlaylists()
+		if err != nil {
+			return nil, nil, err
+		}
+		return tracks, playlists, nil
+	}
+
+	pl, err := d.store.GetPlaylist(playlistID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tracks := make([]*models.Track, 0, len(pl.Entries))
+	for _, entry := range pl.Entries {
+		if track, err := d.store.FindTrackByCTID(entry.CTID); err == nil {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, []*models.Playlist{pl}, nil
+}
+
+// ImportLibrary adds the tracks and playlists of an export. JSON tracks are
+// added if their file is on this device and their CTID is not already in
+// the library; playlists are added as new, unshared playlists. M3U8 files
+// are added in place, with relative paths resolved against baseDir.
+func (d *Daemon) ImportLibrary(ctx context.Context, r io.Reader, format string, baseDir string) (*LibraryImport, error) {
+	var result *LibraryImport
+	var err error
+	switch format {
+	case LibraryJSON:
+		result, err = d.importLibraryJSON(ctx, r)
+	case LibraryM3U8:
+		result, err = d.importM3U(ctx, r, baseDir)
+	default:
+		return nil, fmt.Errorf("unknown library format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("library-imported", "format", format, "tracks", result.Tracks, "playlists", result.Playlists, "existing", result.Existing, "missing", result.Missing)
+	return result, nil
+}
+
+// ImportLibraryFile imports a library export or playlist file, in the
+// given format or the one the file's extension names. Relative paths in a
+// playlist are relative to its directory.
+func (d *Daemon) ImportLibraryFile(ctx context.Context, path string, format string) (*LibraryImport, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	format, err := LibraryFormatFor(format, path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open library: %w", err)
+	}
+	defer file.Close()
+
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	return d.ImportLibrary(ctx, file, format, baseDir)
+}
+
+func (d *Daemon) importLibraryJSON(ctx context.Context, r io.Reader) (*LibraryImport, error) {
+	library, err := backup.ReadLibrary(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LibraryImport{}
+	var added []*models.Track
+	for _, track := range library.Tracks {
+		if track.CTID != "" {
+			if _, err := d.store.FindTrackByCTID(track.CTID); err == nil {
+				result.Existing++
+				continue
+			}
+		}
+		if !d.locateTrackFile(track) {
+			result.Missing++
+			continue
+		}
+		if track.ID == "" {
+			track.ID = generateTrackID()
+		} else if _, err := d.store.GetTrack(track.ID); err == nil {
+			track.ID = generateTrackID()
+		}
+		added = append(added, track)
+	}
+	if err := d.store.SaveTracks(added); err != nil {
+		return nil, err
+	}
+	result.Tracks = len(added)
+
+	if _, err := d.ctr.RequeueUnprocessed(); err != nil {
+		d.logger.Warn("ctr-requeue-error", "error", err)
+	}
+	for _, track := range added {
+		if track.IsShared() {
+			if err := d.announceTrack(ctx, track); err != nil {
+				// Non-fatal; periodic announce will retry
+				d.logger.Warn("track-announce-error", "track_id", track.ID, "error", err)
+			}
+		}
+	}
+
+	for _, pl := range library.Playlists {
+		if err := playlist.ValidateEntries(pl.Entries); err != nil || strings.TrimSpace(pl.Name) == "" {
+			continue
+		}
+		now := time.Now().Unix()
+		imported := &models.Playlist{
+			ID:          generatePlaylistID(),
+			Name:        pl.Name,
+			Description: pl.Description,
+			Entries:     pl.Entries,
+			Author:      pl.Author,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := d.store.SavePlaylist(imported); err != nil {
+			return nil, err
+		}
+		result.Playlists++
+	}
+	return result, nil
+}
+
+// locateTrackFile points an imported track at its file on this device: the
+// media store copy of its content, or the file at its path. Files that
+// changed since the export are analysed again.
+func (d *Daemon) locateTrackFile(track *models.Track) bool {
+	if track.MediaHash != "" && d.media != nil {
+		if path, err := d.media.Path(track.MediaHash); err == nil {
+			track.Path = path
+			return true
+		}
+	}
+	if track.Path == "" {
+		return false
+	}
+	info, err := os.Stat(track.Path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	track.MediaHash = ""
+	if track.FileSize != 0 && info.Size() != track.FileSize {
+		track.ResetContent()
+	}
+	if _, err := os.Stat(waveform.PathFor(track.Path)); err != nil {
+		track.Waveform = false
+	}
+	return true
+}
+
+// importM3U adds the files of an M3U8 playlist in place, titled from the
+// playlist where the entry names them
+func (d *Daemon) importM3U(ctx context.Context, r io.Reader, baseDir string) (*LibraryImport, error) {
+	items, err := backup.ReadM3U(r, baseDir)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := d.store.GetAllTracks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracks: %w", err)
+	}
+	known := make(map[string]bool, len(tracks))
+	for _, track := range tracks {
+		known[track.Path] = true
+	}
+
+	result := &LibraryImport{}
+	for _, item := range items {
+		if known[item.Path] {
+			result.Existing++
+			continue
+		}
+		if item.CTID != "" {
+			if _, err := d.store.FindTrackByCTID(item.CTID); err == nil {
+				result.Existing++
+				continue
+			}
+		}
+		if info, err := os.Stat(item.Path); err != nil || !info.Mode().IsRegular() {
+			result.Missing++
+			continue
+		}
+		title, artist := item.Title, item.Artist
+		if artist == "" {
+			title = "" // a bare name is likely the file name, tags know better
+		}
+		if _, err := d.AddTrack(ctx, item.Path, title, artist, true); err != nil {
+			d.logger.Warn("library-import-error", "path", item.Path, "error", err)
+			result.Missing++
+			continue
+		}
+		known[item.Path] = true
+		result.Tracks++
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportPathStaysInExportDir(t *testing.T) {
+	d := newTestDaemon(t)
+	root := t.TempDir()
+	exportDir := filepath.Join(root, "exports")
+	if err := os.Mkdir(exportDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	d.SetExportDir(exportDir)
+	// A link inside that leads out must not let a client escape
+	if err := os.Symlink(root, filepath.Join(exportDir, "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(exportDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{
+		"node.tar.gz":                            "node.tar.gz",
+		filepath.Join(exportDir, "lib.m3u8"):     "lib.m3u8",
+		filepath.Join(exportDir, "a", "..", "b"): "b",
+	} {
+		if got, err := d.ExportPath(path); err != nil || got != filepath.Join(resolved, want) {
+			t.Fatalf("ExportPath(%q) = %q, %v; want %s in the export dir", path, got, err, want)
+		}
+	}
+
+	for _, path := range []string{
+		"../node.tar.gz",
+		filepath.Join(root, "node.tar.gz"),
+		"/etc/passwd",
+		filepath.Join("out", "node.tar.gz"),
+		exportDir,
+	} {
+		if got, err := d.ExportPath(path); !errors.Is(err, ErrOutsideExportDir) {
+			t.Fatalf("ExportPath(%q) = %q, %v; want ErrOutsideExportDir", path, got, err)
+		}
+	}
+}
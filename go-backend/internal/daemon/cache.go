@@ -0,0 +1,229 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/quota"
+	"github.com/cotune/go-backend/internal/streaming"
+)
+
+// SetStorageBudget sets how many bytes the media store may hold before
+// cached replicas are evicted; 0 is unlimited
+func (d *Daemon) SetStorageBudget(bytes int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.budget = bytes
+}
+
+// UpdateStorageBudget changes the storage budget and saves it in the
+// settings, so it outlives a restart
+func (d *Daemon) UpdateStorageBudget(bytes int64) error {
+	settings, err := d.store.GetSettings()
+	if err != nil {
+		return err
+	}
+	settings.StorageBudget = bytes
+	if err := d.store.SaveSettings(settings); err != nil {
+		return err
+	}
+	d.SetStorageBudget(bytes)
+	return nil
+}
+
+// StorageBudget returns the media store budget in bytes; 0 is unlimited
+func (d *Daemon) StorageBudget() int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.budget
+}
+
+// StorageUsage reports the media store usage by category
+func (d *Daemon) StorageUsage() (quota.Usage, error) {
+	files, inPlace, err := d.classifyMedia()
+	if err != nil {
+		return quota.Usage{}, err
+	}
+	return quota.Measure(files, inPlace, d.StorageBudget()), nil
+}
+
+func (d *Daemon) classifyMedia() ([]quota.File, []*models.Track, error) {
+	if d.media == nil {
+		return nil, nil, fmt.Errorf("media store not configured")
+	}
+	entries, err := d.media.List()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list media: %w", err)
+	}
+	tracks, err := d.store.GetAllTracks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tracks: %w", err)
+	}
+	files, inPlace := quota.Classify(entries, tracks)
+	return files, inPlace, nil
+}
+
+// CacheTrack fetches a CTID into the media store as a cache replica and
+// queues it for processing, which verifies the content and computes its
+// CTID. A track already held locally is returned instead of fetching. The
+// CTID returned is the one fetched, which differs from ctid when a
+// near-identical recording stood in for it.
+func (d *Daemon) CacheTrack(ctx context.Context, ctid string) (*models.Track, string, error) {
+	if d.media == nil {
+		return nil, "", fmt.Errorf("media store not configured")
+	}
+	if track, err := d.store.FindTrackByCTID(ctid); err == nil {
+		d.touchTrack(track, false)
+		return track, ctid, nil
+	}
+
+	tmp, err := d.media.CreateTemp()
+	if err != nil {
+		return nil, "", err
+	}
+	_, fetched, err := d.FetchTrackTranscoded(ctx, ctid, tmp, streaming.StreamRequest{})
+	if err != nil {
+		os.Remove(tmp)
+		return nil, "", err
+	}
+	// The CTID announced by the provider is left to CTR to verify
+	format, _ := audio.ProbeFile(tmp)
+	hash, path, err := d.media.Adopt(tmp, format.Ext())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to store fetched file: %w", err)
+	}
+	if existing, err := d.store.FindTracksByMediaHash(hash); err == nil && len(existing) > 0 {
+		d.touchTrack(existing[0], false)
+		return existing[0], fetched, nil
+	}
+
+	track := &models.Track{
+		ID:         generateTrackID(),
+		Path:       path,
+		MediaHash:  hash,
+		Origin:     models.OriginReplica,
+		LastAccess: time.Now().Unix(),
+	}
+	if hint, ok := d.search.LookupHint(ctid); ok && hint.Title != "Unknown" && hint.Artist != "Unknown" {
+		track.Title, track.Artist = hint.Title, hint.Artist
+		track.Recognized = track.Title != "" && track.Artist != ""
+	}
+	if err := d.store.SaveTrack(track); err != nil {
+		return nil, "", fmt.Errorf("failed to save track: %w", err)
+	}
+	if err := d.ctr.QueueTrack(track); err != nil {
+		return nil, "", err
+	}
+
+	if _, err := d.EnforceBudget(hash); err != nil {
+		d.logger.Warn("storage-budget-error", "error", err)
+	}
+	return track, fetched, nil
+}
+
+// onServe counts a replica streamed to a peer for eviction ranking
+func (d *Daemon) onServe(track *models.Track) {
+	d.touchTrack(track, true)
+}
+
+// touchTrack records an access to a replica, and a serve if served is set
+func (d *Daemon) touchTrack(track *models.Track, served bool) {
+	if !track.IsReplica() {
+		return
+	}
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+
+	current, err := d.store.GetTrack(track.ID)
+	if err != nil {
+		return
+	}
+	current.LastAccess = time.Now().Unix()
+	if served {
+		current.Serves++
+	}
+	if err := d.store.SaveTrack(current); err != nil {
+		d.logger.Warn("cache-touch-error", "track_id", track.ID, "error", err)
+	}
+}
+
+// EnforceBudget evicts cached replicas, least recently used and least
+// popular first, until the media store fits the budget. Files whose hash
+// is in protect are kept. Evicted CTIDs are no longer provided.
+func (d *Daemon) EnforceBudget(protect ...string) (quota.Eviction, error) {
+	var result quota.Eviction
+	budget := d.StorageBudget()
+	if budget <= 0 || d.media == nil {
+		return result, nil
+	}
+
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+
+	files, _, err := d.classifyMedia()
+	if err != nil {
+		return result, err
+	}
+	keep := make(map[string]bool, len(protect))
+	for _, hash := range protect {
+		keep[hash] = true
+	}
+
+	var used int64
+	for _, file := range files {
+		used += file.Size
+	}
+	for _, file := range quota.PlanEviction(files, budget, keep) {
+		freed, err := d.evict(file, &result)
+		if err != nil {
+			return result, err
+		}
+		used -= freed
+	}
+
+	if result.Files > 0 {
+		d.logger.Info("cache-evicted", "files", result.Files, "tracks", result.Tracks, "freed_bytes", result.FreedBytes, "used_bytes", used, "budget_bytes", budget)
+	}
+	if used > budget {
+		// Only owned and liked files are left to count
+		d.logger.Warn("storage-budget-exceeded", "used_bytes", used, "budget_bytes", budget)
+	}
+	return result, nil
+}
+
+// evict deletes the replicas referencing a cached file and the file. A file
+// that gained an import or a like since it was planned is kept.
+func (d *Daemon) evict(file quota.File, result *quota.Eviction) (int64, error) {
+	tracks := make([]*models.Track, 0, len(file.Tracks))
+	for _, planned := range file.Tracks {
+		track, err := d.store.GetTrack(planned.ID)
+		if err != nil {
+			continue
+		}
+		if !track.IsReplica() || track.Liked {
+			return 0, nil
+		}
+		tracks = append(tracks, track)
+	}
+
+	for _, track := range tracks {
+		ctids, err := d.forgetTrack(track)
+		if err != nil {
+			return 0, fmt.Errorf("failed to evict track: %w", err)
+		}
+		result.CTIDs = append(result.CTIDs, ctids...)
+		result.Tracks++
+	}
+
+	freed, err := d.media.Remove(file.Hash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evict file: %w", err)
+	}
+	result.Files++
+	result.FreedBytes += freed
+	return freed, nil
+}
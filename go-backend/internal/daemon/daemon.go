@@ -2,21 +2,29 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/backup"
 	"github.com/cotune/go-backend/internal/ctr"
 	"github.com/cotune/go-backend/internal/dht"
+	"github.com/cotune/go-backend/internal/fsck"
 	"github.com/cotune/go-backend/internal/host"
+	"github.com/cotune/go-backend/internal/media"
 	"github.com/cotune/go-backend/internal/models"
 	"github.com/cotune/go-backend/internal/search"
 	"github.com/cotune/go-backend/internal/storage"
 	"github.com/cotune/go-backend/internal/streaming"
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/cotune/go-backend/internal/watch"
+	"github.com/cotune/go-backend/internal/waveform"
 	libp2phost "github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
@@ -28,14 +36,28 @@ type Daemon struct {
 	ctr            *ctr.Service
 	search         *search.Service
 	streaming      *streaming.Service
-	store          *storage.Storage
+	store          storage.Store
+	media          *media.Store
+	backupSource   *backup.Source // what BackupToFile archives; nil disables backups
+	exportDir      string         // where API clients write backups and exports and read imports
+	watcher        *watch.Service // follows watched folders; nil disables them
+	verifier       *fsck.Verifier // checks track files against what CTR processed
+	vaultMu        sync.Mutex     // serializes vault operations
+	dataDir        string         // holds the identity key and vault
+	vault          *vault.Vault   // nil while the node is not encrypted
+	keyring        *vault.Keyring // seals the node; nil while it is not encrypted
+	onLock         func()         // stops the daemon for Lock
+	budget         int64          // media store bytes before replicas are evicted; 0 is unlimited
+	cacheMu        sync.Mutex     // serializes replica access records and eviction
 	logger         *slog.Logger
 	mu             sync.RWMutex
 	running        bool
 	announceTicker *time.Ticker
 	metricsTicker  *time.Ticker
+	ffmpeg         audio.FFmpegInfo // probed at Start
 	ctx            context.Context
 	cancel         context.CancelFunc
+	background     sync.WaitGroup // tasks started by Start, which Stop waits for
 }
 
 // New creates a new daemon
@@ -45,7 +67,7 @@ func New(
 	ctrService *ctr.Service,
 	searchService *search.Service,
 	streamingService *streaming.Service,
-	store *storage.Storage,
+	store storage.Store,
 	logger *slog.Logger,
 ) *Daemon {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -60,6 +82,7 @@ func New(
 		search:    searchService,
 		streaming: streamingService,
 		store:     store,
+		verifier:  fsck.New(store, ctrService),
 		logger:    logger,
 		ctx:       ctx,
 		cancel:    cancel,
@@ -67,9 +90,18 @@ func New(
 
 	// Keep search token index in sync when CTR computes CTID in background.
 	dm.ctr.SetOnProcessed(dm.onTrackProcessed)
+	// Serves rank cached replicas for eviction
+	dm.streaming.SetOnServe(dm.onServe)
+	// Withdraw tracks verification finds broken, share recovered ones again
+	dm.verifier.SetOnChange(dm.onTrackVerified)
 	return dm
 }
 
+// SetMediaStore sets the managed store imports are copied into
+func (d *Daemon) SetMediaStore(store *media.Store) {
+	d.media = store
+}
+
 // Start starts the daemon
 func (d *Daemon) Start(ctx context.Context) error {
 	d.mu.Lock()
@@ -80,27 +112,118 @@ func (d *Daemon) Start(ctx context.Context) error {
 	d.running = true
 	d.mu.Unlock()
 
+	// Formats without a native decoder need ffmpeg
+	d.probeFFmpeg(ctx)
+
 	// Start CTR service
 	d.ctr.Start()
 
+	// Tracks imported before jobs were persisted still need a CTID
+	if n, err := d.ctr.RequeueUnprocessed(); err != nil {
+		d.logger.Warn("ctr-requeue-error", "error", err)
+	} else if n > 0 {
+		d.logger.Info("ctr-requeued-tracks", "tracks", n)
+	}
+
+	// Recompute CTIDs left by older algorithm versions in the background
+	if err := d.ctr.StartMigration(); err != nil {
+		d.logger.Warn("ctid-migration-start-error", "error", err)
+	}
+
+	// Files orphaned while the daemon was down are collected once at start,
+	// then the cache is fitted to the budget
+	d.goBackground(func() {
+		if _, err := d.GCMedia(); err != nil {
+			d.logger.Warn("media-gc-error", "error", err)
+		}
+		if _, err := d.EnforceBudget(); err != nil {
+			d.logger.Warn("storage-budget-error", "error", err)
+		}
+	})
+
+	// Import from watched folders once CTR takes jobs
+	if d.watcher != nil {
+		settings, err := d.store.GetSettings()
+		if err != nil {
+			return err
+		}
+		if err := d.watcher.Start(settings.WatchFolders); err != nil {
+			d.logger.Warn("watch-start-error", "error", err)
+		}
+	}
+
 	// Start periodic announce
 	d.announceTicker = time.NewTicker(4 * time.Minute)
-	go d.announceLoop()
+	d.goBackground(d.announceLoop)
 
 	// Emit periodic network stats for observability in server mode.
 	d.metricsTicker = time.NewTicker(30 * time.Second)
-	go d.metricsLoop()
+	d.goBackground(d.metricsLoop)
 
 	// Initial announce
+	d.goBackground(func() {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+		d.announceAllTracks(d.ctx)
+	})
+
+	return nil
+}
+
+// goBackground runs fn in a goroutine Stop waits for. fn must return once
+// d.ctx is done.
+func (d *Daemon) goBackground(fn func()) {
+	d.background.Add(1)
 	go func() {
-		time.Sleep(5 * time.Second)
-		d.announceAllTracks(context.Background())
+		defer d.background.Done()
+		fn()
 	}()
+}
 
-	return nil
+// probeFFmpeg checks the ffmpeg fallback, records the result for Status and
+// offers the transcode profiles its encoders allow
+func (d *Daemon) probeFFmpeg(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	info := audio.ProbeFFmpeg(ctx)
+	if info.Available {
+		d.logger.Info("ffmpeg-available", "path", info.Path, "version", info.Version)
+	} else {
+		d.logger.Warn("ffmpeg-unavailable", "path", info.Path, "error", info.Error)
+	}
+	d.mu.Lock()
+	d.ffmpeg = info
+	d.mu.Unlock()
+	if !info.Available {
+		return
+	}
+
+	profiles, err := audio.ProbeTranscodeProfiles(ctx)
+	if err != nil {
+		d.logger.Warn("ffmpeg-encoders-probe-error", "error", err)
+		return
+	}
+	formats := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		formats = append(formats, p.Format)
+	}
+	d.logger.Info("transcode-profiles", "formats", formats)
+	d.streaming.SetTranscodeProfiles(profiles)
+}
+
+// FFmpeg returns the result of the ffmpeg probe run at Start
+func (d *Daemon) FFmpeg() audio.FFmpegInfo {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.ffmpeg
 }
 
-// Stop stops the daemon
+// Stop stops the daemon and waits for everything it runs in the background
+// to return, so the store can be closed after it
 func (d *Daemon) Stop(ctx context.Context) error {
 	d.mu.Lock()
 	if !d.running {
@@ -111,6 +234,16 @@ func (d *Daemon) Stop(ctx context.Context) error {
 	d.mu.Unlock()
 
 	d.cancel()
+	d.verifier.Wait()
+	if d.streaming != nil {
+		d.streaming.Close()
+	}
+
+	if d.watcher != nil {
+		if err := d.watcher.Close(); err != nil {
+			d.logger.Warn("watch-close-error", "error", err)
+		}
+	}
 
 	if d.announceTicker != nil {
 		d.announceTicker.Stop()
@@ -119,6 +252,17 @@ func (d *Daemon) Stop(ctx context.Context) error {
 		d.metricsTicker.Stop()
 	}
 
+	done := make(chan struct{})
+	go func() {
+		d.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("background tasks did not stop: %w", ctx.Err())
+	}
+
 	// Stop CTR service
 	if err := d.ctr.Stop(ctx); err != nil {
 		return fmt.Errorf("failed to stop CTR: %w", err)
@@ -168,48 +312,84 @@ func (d *Daemon) logNetworkStats() {
 
 // announceAllTracks announces all recognized tracks in DHT
 func (d *Daemon) announceAllTracks(ctx context.Context) {
+	if n, err := d.ctr.ExpireLegacyCTIDs(time.Now()); err != nil {
+		d.logger.Warn("legacy-ctid-expire-error", "error", err)
+	} else if n > 0 {
+		d.logger.Info("legacy-ctid-expired", "tracks", n)
+	}
+
 	tracks, err := d.store.GetAllTracks()
 	if err != nil {
 		return
 	}
 
 	for _, track := range tracks {
-		if !track.Recognized || track.CTID == "" {
-			continue
+		if ctx.Err() != nil {
+			return
 		}
-		d.search.UpdateLocalIndex(track)
-
-		// Announce CTID in DHT
-		if err := d.dht.Provide(ctx, track.CTID); err != nil {
-			// Non-fatal, continue
+		if !track.IsShared() {
 			continue
 		}
+		// Non-fatal, continue
+		d.announceTrack(ctx, track)
+	}
 
-		// Announce tokens in DHT (for search)
-		tokens := d.search.Tokenize(track.Title + " " + track.Artist)
-		for _, token := range tokens {
-			tokenHash := dht.HashToken(token)
-			if err := d.dht.ProvideToken(ctx, tokenHash); err != nil {
-				// Non-fatal, continue
-				continue
-			}
-		}
+	d.announcePlaylists(ctx)
+}
+
+// announceTrack adds a shared track to the local search index and announces
+// its CTID, search tokens and fingerprint key in DHT. Only a failure to
+// provide the CTID is returned.
+func (d *Daemon) announceTrack(ctx context.Context, track *models.Track) error {
+	d.search.UpdateLocalIndex(track)
+
+	// Announce CTID in DHT
+	if err := d.dht.Provide(ctx, track.CTID); err != nil {
+		return fmt.Errorf("failed to provide CTID in DHT: %w", err)
+	}
+	d.announceLegacyCTID(ctx, track)
+
+	d.announceTokens(ctx, track)
+	d.announceFingerprint(ctx, track)
+	return nil
+}
+
+// announceTokens announces the title and artist tokens of a track in DHT
+// for search. Failures are non-fatal; periodic announce will retry.
+func (d *Daemon) announceTokens(ctx context.Context, track *models.Track) {
+	for _, token := range d.search.Tokenize(track.Title + " " + track.Artist) {
+		d.dht.ProvideToken(ctx, dht.HashToken(token))
+	}
+}
+
+// announceFingerprint announces the track's fingerprint key in DHT so peers
+// can discover near-identical recordings. Failures are non-fatal.
+func (d *Daemon) announceFingerprint(ctx context.Context, track *models.Track) {
+	if track.FingerprintKey == "" {
+		return
+	}
+	if err := d.dht.ProvideFingerprint(ctx, dht.HashFingerprintKey(track.FingerprintKey)); err != nil {
+		d.logger.Warn("fingerprint-provide-error", "ctid", track.CTID, "error", err)
+	}
+}
+
+// announceLegacyCTID keeps the pre-migration CTID of a track reachable
+// during its transition window. Failures are non-fatal.
+func (d *Daemon) announceLegacyCTID(ctx context.Context, track *models.Track) {
+	if track.LegacyCTID == "" || track.LegacyExpires <= time.Now().Unix() {
+		return
+	}
+	if err := d.dht.Provide(ctx, track.LegacyCTID); err != nil {
+		d.logger.Warn("legacy-ctid-provide-error", "ctid", track.CTID, "legacy_ctid", track.LegacyCTID, "error", err)
 	}
 }
 
 func (d *Daemon) onTrackProcessed(ctx context.Context, track *models.Track) {
-	if track == nil || !track.Recognized || track.CTID == "" {
+	if track == nil || !track.IsShared() {
 		return
 	}
 	d.search.UpdateLocalIndex(track)
-	tokens := d.search.Tokenize(track.Title + " " + track.Artist)
-	for _, token := range tokens {
-		tokenHash := dht.HashToken(token)
-		if err := d.dht.ProvideToken(ctx, tokenHash); err != nil {
-			// Non-fatal; periodic announce will retry.
-			continue
-		}
-	}
+	d.announceTokens(ctx, track)
 }
 
 // ShareTrack shares a track (announces it in DHT)
@@ -236,26 +416,14 @@ func (d *Daemon) ShareTrack(ctx context.Context, trackID string) error {
 	if !track.Recognized {
 		return fmt.Errorf("track not recognized (user must enter title/artist)")
 	}
-
-	// Announce CTID in DHT
-	if err := d.dht.Provide(ctx, track.CTID); err != nil {
-		return fmt.Errorf("failed to provide CTID in DHT: %w", err)
-	}
-
-	// Announce tokens in DHT (for search)
-	tokens := d.search.Tokenize(track.Title + " " + track.Artist)
-	for _, token := range tokens {
-		tokenHash := dht.HashToken(token)
-		if err := d.dht.ProvideToken(ctx, tokenHash); err != nil {
-			// Non-fatal, continue
-			continue
+	if track.Unshared {
+		track.Unshared = false
+		if err := d.store.SaveTrack(track); err != nil {
+			return fmt.Errorf("failed to save track: %w", err)
 		}
 	}
 
-	// Update local search index
-	d.search.UpdateLocalIndex(track)
-
-	return nil
+	return d.announceTrack(ctx, track)
 }
 
 // Search performs a search
@@ -275,61 +443,234 @@ func (d *Daemon) FindProviders(ctx context.Context, ctid string, max int) ([]pee
 	return d.dht.FindProviders(ctx, ctid, max)
 }
 
-// FetchTrack fetches a track from the network
+// FetchTrack fetches a track from the network, preferring providers that
+// advertise the highest-quality copy
 func (d *Daemon) FetchTrack(ctx context.Context, ctid string, outputPath string) error {
+	_, _, err := d.FetchTrackTranscoded(ctx, ctid, outputPath, streaming.StreamRequest{})
+	return err
+}
+
+// FetchTrackTranscoded is FetchTrack asking providers to transcode to
+// opts.Format at opts.Bitrate. The header says what was received; it is nil
+// when no format was asked for. The CTID fetched differs from ctid when no
+// provider had it and a near-identical recording was fetched instead.
+func (d *Daemon) FetchTrackTranscoded(ctx context.Context, ctid string, outputPath string, opts streaming.StreamRequest) (*streaming.StreamHeader, string, error) {
 	// Find providers
 	providers, err := d.dht.FindProviders(ctx, ctid, 12)
 	if err != nil {
-		return fmt.Errorf("failed to find providers: %w", err)
+		return nil, "", fmt.Errorf("failed to find providers: %w", err)
 	}
 
 	if len(providers) == 0 {
-		return fmt.Errorf("no providers found for CTID: %s", ctid)
+		// Fall back to a near-identical recording announced under another CTID
+		if header, fetched, err := d.fetchSimilarTrack(ctx, ctid, outputPath, opts); err == nil {
+			return header, fetched, nil
+		}
+		return nil, "", fmt.Errorf("no providers found for CTID: %s", ctid)
 	}
 
-	// Try each provider
+	// Try each provider, best advertised copy first
+	providers = d.search.RankProviders(ctx, ctid, providers)
 	var lastErr error
 	for _, provider := range providers {
-		err := d.streaming.StreamFromPeer(ctx, provider.ID, ctid, outputPath)
+		header, err := d.streaming.StreamFromPeerTranscoded(ctx, provider.ID, ctid, outputPath, opts)
 		if err == nil {
-			return nil
+			return header, ctid, nil
 		}
 		lastErr = err
 	}
 
-	return fmt.Errorf("failed to fetch from all providers: %w", lastErr)
+	return nil, "", fmt.Errorf("failed to fetch from all providers: %w", lastErr)
 }
 
-// AddTrack adds a new track (copies file and processes)
-func (d *Daemon) AddTrack(ctx context.Context, sourcePath string, title string, artist string) (*models.Track, error) {
-	// Generate track ID
-	trackID := generateTrackID()
+// fetchSimilarTrack fetches the best acoustically matching recording of ctid
+// and returns the CTID it fetched
+func (d *Daemon) fetchSimilarTrack(ctx context.Context, ctid string, outputPath string, opts streaming.StreamRequest) (*streaming.StreamHeader, string, error) {
+	similar, err := d.search.FindSimilar(ctx, ctid, 5)
+	if err != nil {
+		return nil, "", err
+	}
 
-	// Copy file to storage
-	destPath, err := d.copyTrackFile(trackID, sourcePath)
+	lastErr := fmt.Errorf("no similar recordings found for CTID: %s", ctid)
+	for _, candidate := range similar {
+		for _, providerID := range candidate.Providers {
+			pid, err := peer.Decode(providerID)
+			if err != nil {
+				continue
+			}
+			header, err := d.streaming.StreamFromPeerTranscoded(ctx, pid, candidate.CTID, outputPath, opts)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			d.logger.Info("fetch-similar-recording", "ctid", ctid, "fetched_ctid", candidate.CTID, "score", candidate.Score)
+			return header, candidate.CTID, nil
+		}
+	}
+	return nil, "", lastErr
+}
+
+// GetArtwork returns the cover of a CTID from the local library or, failing
+// that, from the first provider that has one
+func (d *Daemon) GetArtwork(ctx context.Context, ctid string, size artwork.Size) ([]byte, string, error) {
+	data, mime, err := d.streaming.LocalArtwork(ctid, size)
+	if err == nil {
+		return data, mime, nil
+	}
+
+	providers, err := d.dht.FindProviders(ctx, ctid, 5)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy file: %w", err)
+		return nil, "", fmt.Errorf("failed to find providers: %w", err)
+	}
+	lastErr := streaming.ErrNoArtwork
+	for _, provider := range providers {
+		if provider.ID == d.h.ID() {
+			continue
+		}
+		data, mime, err := d.streaming.FetchArtwork(ctx, provider.ID, ctid, size)
+		if err == nil {
+			return data, mime, nil
+		}
+		if !errors.Is(err, streaming.ErrNoArtwork) {
+			d.logger.Warn("fetch-artwork-error", "ctid", ctid, "peer", provider.ID.String(), "error", err)
+		}
+		lastErr = err
+	}
+	return nil, "", lastErr
+}
+
+// GetWaveform returns the waveform peaks of a CTID from the local library
+// or, failing that, from the first provider that has them. Only levels of at
+// most maxPeaks peaks are kept when maxPeaks is positive.
+func (d *Daemon) GetWaveform(ctx context.Context, ctid string, maxPeaks int) (*waveform.Peaks, error) {
+	peaks, err := d.streaming.LocalWaveform(ctid, maxPeaks)
+	if err == nil {
+		return peaks, nil
 	}
 
-	// Create track
+	providers, err := d.dht.FindProviders(ctx, ctid, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find providers: %w", err)
+	}
+	lastErr := streaming.ErrNoWaveform
+	for _, provider := range providers {
+		if provider.ID == d.h.ID() {
+			continue
+		}
+		peaks, err := d.streaming.FetchWaveform(ctx, provider.ID, ctid, maxPeaks)
+		if err == nil {
+			return peaks, nil
+		}
+		if !errors.Is(err, streaming.ErrNoWaveform) {
+			d.logger.Warn("fetch-waveform-error", "ctid", ctid, "peer", provider.ID.String(), "error", err)
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// GetLoudness returns the loudness measured for a local track, looked up by
+// track ID or, when that is empty, by CTID
+func (d *Daemon) GetLoudness(trackID string, ctid string) (*models.Loudness, error) {
+	var track *models.Track
+	var err error
+	if trackID != "" {
+		track, err = d.store.GetTrack(trackID)
+	} else {
+		track, err = d.store.FindTrackByCTID(ctid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("track not found: %w", err)
+	}
+	if track.Loudness == nil {
+		return nil, fmt.Errorf("loudness not measured yet: %s", track.ID)
+	}
+	return track.Loudness, nil
+}
+
+// FindSimilar returns recordings acoustically similar to a CTID
+func (d *Daemon) FindSimilar(ctx context.Context, ctid string, max int) ([]*search.SimilarResult, error) {
+	return d.search.FindSimilar(ctx, ctid, max)
+}
+
+// AddTrack adds a new track and queues it for processing. The file is
+// imported into the media store, reusing the stored copy of identical
+// content, unless inPlace is set: then the track references the file where
+// it is and the daemon never moves or deletes it.
+func (d *Daemon) AddTrack(ctx context.Context, sourcePath string, title string, artist string, inPlace bool) (*models.Track, error) {
+	// Generate track ID
+	trackID := generateTrackID()
+
 	track := &models.Track{
 		ID:         trackID,
 		Title:      title,
 		Artist:     artist,
-		Path:       destPath,
 		Liked:      false,
 		Recognized: title != "" && artist != "",
 	}
+	if inPlace {
+		path, err := filepath.Abs(sourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to open source: %w", err)
+		}
+		track.Path = path
+	} else {
+		if d.media == nil {
+			return nil, fmt.Errorf("media store not configured")
+		}
+		hash, path, err := d.media.Import(sourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import file: %w", err)
+		}
+		track.Path, track.MediaHash = path, hash
+	}
+
+	if err := d.addTrack(track); err != nil {
+		return nil, err
+	}
+	return track, nil
+}
 
+// addTrack saves a new track and queues it for CTR processing
+func (d *Daemon) addTrack(track *models.Track) error {
 	// Save track
 	if err := d.store.SaveTrack(track); err != nil {
-		return nil, fmt.Errorf("failed to save track: %w", err)
+		return fmt.Errorf("failed to save track: %w", err)
 	}
 
 	// Queue for CTR processing
-	d.ctr.QueueTrack(track)
+	if err := d.ctr.QueueTrack(track); err != nil {
+		return err
+	}
 
-	return track, nil
+	// Imports count against the budget too, making room at the cache's expense
+	if track.MediaHash != "" {
+		if _, err := d.EnforceBudget(); err != nil {
+			d.logger.Warn("storage-budget-error", "error", err)
+		}
+	}
+	return nil
+}
+
+// GCMedia removes the files in the media store that no track references
+func (d *Daemon) GCMedia() (media.GCResult, error) {
+	if d.media == nil {
+		return media.GCResult{}, nil
+	}
+	result, err := d.media.GC(func(hash string) (bool, error) {
+		refs, err := d.store.MediaRefs(hash)
+		return refs > 0, err
+	}, media.DefaultGCGrace)
+	if err != nil {
+		return result, fmt.Errorf("media gc: %w", err)
+	}
+	if result.Removed > 0 {
+		d.logger.Info("media-gc", "removed", result.Removed, "freed_bytes", result.FreedBytes)
+	}
+	return result, nil
 }
 
 // ProcessTrack processes a track (computes CTID)
@@ -342,6 +683,35 @@ func (d *Daemon) ProcessTrack(ctx context.Context, trackID string) error {
 	return d.ctr.ProcessTrack(ctx, track)
 }
 
+// StartCTIDMigration starts recomputing CTIDs produced by older algorithms
+func (d *Daemon) StartCTIDMigration() error {
+	return d.ctr.StartMigration()
+}
+
+// CTIDMigrationProgress returns the progress of the current or last CTID migration
+func (d *Daemon) CTIDMigrationProgress() ctr.MigrationProgress {
+	return d.ctr.MigrationProgress()
+}
+
+// ListJobs returns CTR jobs in a state, or all jobs if state is empty
+func (d *Daemon) ListJobs(state models.JobState) ([]*models.Job, error) {
+	return d.ctr.ListJobs(state)
+}
+
+// RetryJobs queues failed CTR jobs again: the given ones, or all failed jobs
+// if ids is empty. It returns how many were queued.
+func (d *Daemon) RetryJobs(ids []string) (int, error) {
+	if len(ids) == 0 {
+		return d.ctr.RetryFailedJobs()
+	}
+	for i, id := range ids {
+		if err := d.ctr.RetryJob(id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
 // GetPeerInfo returns peer information
 func (d *Daemon) GetPeerInfo() map[string]interface{} {
 	addrs := make([]string, 0)
@@ -386,10 +756,20 @@ func (d *Daemon) Status() map[string]interface{} {
 
 	d.mu.RLock()
 	running := d.running
+	ffmpeg := d.ffmpeg
 	d.mu.RUnlock()
 
+	jobs, err := d.ctr.JobCounts()
+	if err != nil {
+		d.logger.Warn("ctr-job-count-error", "error", err)
+	}
+
 	return map[string]interface{}{
 		"running":            running,
+		"locked":             false,
+		"encrypted":          d.VaultStatus().Encrypted,
+		"ctr_jobs":           jobs,
+		"ffmpeg":             ffmpeg,
 		"peer_id":            d.h.ID().String(),
 		"addresses":          addrs,
 		"connected_peers":    len(d.h.Network().Peers()),
@@ -430,6 +810,25 @@ func (d *Daemon) FetchTrackFromPeer(ctx context.Context, peerID peer.ID, ctid st
 	return d.streaming.StreamFromPeer(ctx, peerID, ctid, outputPath)
 }
 
+// FetchTrackFromPeerTranscoded fetches a track from a specific peer, asking
+// it to transcode to opts.Format at opts.Bitrate
+func (d *Daemon) FetchTrackFromPeerTranscoded(ctx context.Context, peerID peer.ID, ctid string, outputPath string, opts streaming.StreamRequest) (*streaming.StreamHeader, error) {
+	return d.streaming.StreamFromPeerTranscoded(ctx, peerID, ctid, outputPath, opts)
+}
+
+// TranscodeProfiles returns the transcode profiles of a peer, or of this
+// peer when peerID is empty
+func (d *Daemon) TranscodeProfiles(ctx context.Context, peerID string) ([]audio.TranscodeProfile, error) {
+	if peerID == "" {
+		return d.streaming.TranscodeProfiles(), nil
+	}
+	pid, err := peer.Decode(peerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer ID: %w", err)
+	}
+	return d.streaming.FetchProfiles(ctx, pid)
+}
+
 // GetRelayAddresses returns relay addresses for this peer
 func (d *Daemon) GetRelayAddresses() []string {
 	relays := make([]string, 0)
@@ -487,34 +886,3 @@ func findSubstring(s, substr string) bool {
 func generateTrackID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
 }
-
-func (d *Daemon) copyTrackFile(trackID string, sourcePath string) (string, error) {
-	// Determine storage directory
-	storageDir := filepath.Join(filepath.Dir(sourcePath), "cotune_tracks")
-	if err := os.MkdirAll(storageDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create storage dir: %w", err)
-	}
-
-	// Determine destination path
-	ext := filepath.Ext(sourcePath)
-	destPath := filepath.Join(storageDir, trackID+ext)
-
-	// Copy file
-	source, err := os.Open(sourcePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open source: %w", err)
-	}
-	defer source.Close()
-
-	dest, err := os.Create(destPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create dest: %w", err)
-	}
-	defer dest.Close()
-
-	if _, err := io.Copy(dest, source); err != nil {
-		return "", fmt.Errorf("failed to copy: %w", err)
-	}
-
-	return destPath, nil
-}
@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStopWaitsForBackgroundTasks(t *testing.T) {
+	d := newTestDaemon(t)
+	d.running = true
+
+	// As an announce still walking the library when the node is locked
+	var finished atomic.Bool
+	d.goBackground(func() {
+		<-d.ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("Stop() returned before the background task, which may still use the store")
+	}
+}
+
+func TestStopGivesUpOnStuckBackgroundTasks(t *testing.T) {
+	d := newTestDaemon(t)
+	d.running = true
+
+	release := make(chan struct{})
+	defer close(release)
+	d.goBackground(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := d.Stop(ctx); err == nil {
+		t.Fatal("Stop() with a task ignoring the daemon context error = nil, want error")
+	}
+}
@@ -0,0 +1,336 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/playlist"
+)
+
+// resolveWorkers bounds the provider lookups of one OpenPlaylist
+const resolveWorkers = 8
+
+// PlaylistUpdate lists what UpdatePlaylist changes; nil fields are left as
+// they are
+type PlaylistUpdate struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	CTIDs       *[]string `json:"ctids,omitempty"` // replaces the tracks, in order
+}
+
+// OpenedPlaylist is a playlist record fetched by ID with each track
+// resolved to its providers
+type OpenedPlaylist struct {
+	RecordID    string           `json:"record_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Author      string           `json:"author"`
+	Entries     []ResolvedEntry  `json:"entries"`
+	Saved       *models.Playlist `json:"saved,omitempty"` // local copy, when saving was asked for
+}
+
+// ResolvedEntry is a playlist track with the peers providing it
+type ResolvedEntry struct {
+	models.PlaylistEntry
+	Local     bool     `json:"local"` // held in the local library
+	Providers []string `json:"providers"`
+}
+
+// CreatePlaylist creates a playlist of CTIDs in order. Titles, artists and
+// durations are filled in from the library or from search results.
+func (d *Daemon) CreatePlaylist(name string, description string, ctids []string) (*models.Playlist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("playlist name is required")
+	}
+	entries, err := d.playlistEntries(ctids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	pl := &models.Playlist{
+		ID:          generatePlaylistID(),
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Entries:     entries,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := d.store.SavePlaylist(pl); err != nil {
+		return nil, err
+	}
+	return pl, nil
+}
+
+// GetPlaylist returns a local playlist
+func (d *Daemon) GetPlaylist(id string) (*models.Playlist, error) {
+	return d.store.GetPlaylist(id)
+}
+
+// ListPlaylists returns the local playlists, oldest first
+func (d *Daemon) ListPlaylists() ([]*models.Playlist, error) {
+	playlists, err := d.store.GetAllPlaylists()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(playlists, func(i, j int) bool {
+		if playlists[i].CreatedAt != playlists[j].CreatedAt {
+			return playlists[i].CreatedAt < playlists[j].CreatedAt
+		}
+		return playlists[i].ID < playlists[j].ID
+	})
+	return playlists, nil
+}
+
+// UpdatePlaylist changes a playlist. A shared playlist is published again
+// under the ID of its new record.
+func (d *Daemon) UpdatePlaylist(ctx context.Context, id string, update PlaylistUpdate) (*models.Playlist, error) {
+	pl, err := d.store.GetPlaylist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, fmt.Errorf("playlist name is required")
+		}
+		pl.Name = name
+	}
+	if update.Description != nil {
+		pl.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.CTIDs != nil {
+		if pl.Entries, err = d.playlistEntries(*update.CTIDs); err != nil {
+			return nil, err
+		}
+	}
+	pl.UpdatedAt = time.Now().Unix()
+
+	if !pl.Shared {
+		if err := d.store.SavePlaylist(pl); err != nil {
+			return nil, err
+		}
+		return pl, nil
+	}
+	if err := d.publishPlaylist(ctx, pl); err != nil {
+		// Saved; periodic announce will retry
+		d.logger.Warn("playlist-provide-error", "playlist_id", pl.ID, "record_id", pl.RecordID, "error", err)
+	}
+	return pl, nil
+}
+
+// DeletePlaylist deletes a local playlist and stops announcing its record
+func (d *Daemon) DeletePlaylist(id string) error {
+	pl, err := d.store.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+	if err := d.store.DeletePlaylist(id); err != nil {
+		return fmt.Errorf("failed to delete playlist: %w", err)
+	}
+	if pl.Shared {
+		d.dht.UnprovidePlaylist(pl.RecordID)
+	}
+	return nil
+}
+
+// SharePlaylist publishes a playlist as a record announced in DHT and
+// returns the record ID peers open it by
+func (d *Daemon) SharePlaylist(ctx context.Context, id string) (string, error) {
+	pl, err := d.store.GetPlaylist(id)
+	if err != nil {
+		return "", err
+	}
+	pl.Shared = true
+	if err := d.publishPlaylist(ctx, pl); err != nil {
+		return pl.RecordID, err
+	}
+	d.logger.Info("playlist-shared", "playlist_id", pl.ID, "record_id", pl.RecordID, "tracks", len(pl.Entries))
+	return pl.RecordID, nil
+}
+
+// UnsharePlaylist stops announcing and serving a playlist's record
+func (d *Daemon) UnsharePlaylist(id string) error {
+	pl, err := d.store.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+	if !pl.Shared {
+		return nil
+	}
+	pl.Shared = false
+	if err := d.store.SavePlaylist(pl); err != nil {
+		return err
+	}
+	d.dht.UnprovidePlaylist(pl.RecordID)
+	return nil
+}
+
+// publishPlaylist saves a shared playlist under the ID of its current
+// record and announces the record, withdrawing the previous one
+func (d *Daemon) publishPlaylist(ctx context.Context, pl *models.Playlist) error {
+	recordID, _, err := playlist.NewRecord(pl, d.h.ID().String()).Encode()
+	if err != nil {
+		return err
+	}
+	if pl.RecordID != "" && pl.RecordID != recordID {
+		d.dht.UnprovidePlaylist(pl.RecordID)
+	}
+	pl.RecordID = recordID
+	if err := d.store.SavePlaylist(pl); err != nil {
+		return err
+	}
+	return d.dht.ProvidePlaylist(ctx, recordID)
+}
+
+// announcePlaylists announces the records of shared playlists in DHT
+func (d *Daemon) announcePlaylists(ctx context.Context) {
+	playlists, err := d.store.GetAllPlaylists()
+	if err != nil {
+		return
+	}
+	for _, pl := range playlists {
+		if !pl.Shared || pl.RecordID == "" {
+			continue
+		}
+		if err := d.dht.ProvidePlaylist(ctx, pl.RecordID); err != nil {
+			// Non-fatal, continue
+			continue
+		}
+	}
+}
+
+// OpenPlaylist fetches a playlist record by ID, from the library or from a
+// peer providing it, and resolves each track to its providers. With save
+// the playlist is kept as a local, unshared copy.
+func (d *Daemon) OpenPlaylist(ctx context.Context, recordID string, save bool) (*OpenedPlaylist, error) {
+	if !playlist.ValidID(recordID) {
+		return nil, fmt.Errorf("invalid playlist record ID: %q", recordID)
+	}
+	record, err := d.fetchPlaylistRecord(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := &OpenedPlaylist{
+		RecordID:    recordID,
+		Name:        record.Name,
+		Description: record.Description,
+		Author:      record.Author,
+		Entries:     d.resolveEntries(ctx, record.Entries),
+	}
+	d.logger.Info("playlist-opened", "record_id", recordID, "author", record.Author, "tracks", len(record.Entries))
+
+	if save {
+		now := time.Now().Unix()
+		pl := &models.Playlist{
+			ID:          generatePlaylistID(),
+			Name:        record.Name,
+			Description: record.Description,
+			Entries:     record.Entries,
+			Author:      record.Author,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if pl.Author == d.h.ID().String() {
+			pl.Author = ""
+		}
+		if err := d.store.SavePlaylist(pl); err != nil {
+			return nil, err
+		}
+		opened.Saved = pl
+	}
+	return opened, nil
+}
+
+func (d *Daemon) fetchPlaylistRecord(ctx context.Context, recordID string) (*playlist.Record, error) {
+	if data, err := d.streaming.LocalPlaylist(recordID); err == nil {
+		return playlist.Decode(recordID, data)
+	}
+
+	providers, err := d.dht.FindPlaylistProviders(ctx, recordID, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find providers: %w", err)
+	}
+	lastErr := fmt.Errorf("no providers for playlist %s", recordID)
+	for _, provider := range providers {
+		if provider.ID == d.h.ID() {
+			continue
+		}
+		record, err := d.streaming.FetchPlaylist(ctx, provider.ID, recordID)
+		if err == nil {
+			return record, nil
+		}
+		d.logger.Warn("fetch-playlist-error", "record_id", recordID, "peer", provider.ID.String(), "error", err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// resolveEntries looks up the providers of each track, a few at a time
+func (d *Daemon) resolveEntries(ctx context.Context, entries []models.PlaylistEntry) []ResolvedEntry {
+	resolved := make([]ResolvedEntry, len(entries))
+	sem := make(chan struct{}, resolveWorkers)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		resolved[i] = ResolvedEntry{PlaylistEntry: entry, Providers: []string{}}
+		if _, err := d.store.FindTrackByCTID(entry.CTID); err == nil {
+			resolved[i].Local = true
+		}
+
+		wg.Add(1)
+		go func(r *ResolvedEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			providers, err := d.dht.FindProviders(ctx, r.CTID, 5)
+			if err != nil {
+				return
+			}
+			for _, provider := range providers {
+				if provider.ID != d.h.ID() {
+					r.Providers = append(r.Providers, provider.ID.String())
+				}
+			}
+		}(&resolved[i])
+	}
+	wg.Wait()
+	return resolved
+}
+
+// playlistEntries builds entries for CTIDs, describing each from the
+// library or, failing that, from search results
+func (d *Daemon) playlistEntries(ctids []string) ([]models.PlaylistEntry, error) {
+	entries := make([]models.PlaylistEntry, 0, len(ctids))
+	for _, ctid := range ctids {
+		entry := models.PlaylistEntry{CTID: strings.ToLower(strings.TrimSpace(ctid))}
+		if track, err := d.store.FindTrackByCTID(entry.CTID); err == nil {
+			entry.Title, entry.Artist, entry.DurationMs = track.Title, track.Artist, track.DurationMs
+		} else if hint, ok := d.search.LookupHint(entry.CTID); ok {
+			if hint.Title != "Unknown" {
+				entry.Title = hint.Title
+			}
+			if hint.Artist != "Unknown" {
+				entry.Artist = hint.Artist
+			}
+			entry.DurationMs = hint.DurationMs
+		}
+		entries = append(entries, entry)
+	}
+	if err := playlist.ValidateEntries(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func generatePlaylistID() string {
+	return fmt.Sprintf("pl-%d", time.Now().UnixNano())
+}
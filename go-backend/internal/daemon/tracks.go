@@ -0,0 +1,230 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/cotune/go-backend/internal/dht"
+	"github.com/cotune/go-backend/internal/media"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// MetadataUpdate lists the metadata UpdateTrackMetadata changes; nil fields
+// are left as they are
+type MetadataUpdate struct {
+	Title       *string `json:"title,omitempty"`
+	Artist      *string `json:"artist,omitempty"`
+	Album       *string `json:"album,omitempty"`
+	TrackNumber *int    `json:"track_number,omitempty"`
+	Year        *int    `json:"year,omitempty"`
+	Genre       *string `json:"genre,omitempty"`
+}
+
+// DeleteTrack removes a track from the library and stops announcing it.
+// With deleteFile its audio file is deleted as well unless another track
+// references it; otherwise a file in the media store is left to media GC
+// and a file referenced in place is kept.
+func (d *Daemon) DeleteTrack(trackID string, deleteFile bool) error {
+	track, err := d.store.GetTrack(trackID)
+	if err != nil {
+		return fmt.Errorf("track not found: %w", err)
+	}
+	if _, err := d.forgetTrack(track); err != nil {
+		return err
+	}
+	if err := d.removeTrackFiles(track, deleteFile); err != nil {
+		return err
+	}
+
+	d.logger.Info("track-deleted", "track_id", track.ID, "ctid", track.CTID, "file_deleted", deleteFile)
+	return nil
+}
+
+// UpdateTrackMetadata changes the metadata of a track. Title and artist
+// decide whether the track is recognized; search tokens only the old
+// metadata had stop being announced.
+func (d *Daemon) UpdateTrackMetadata(ctx context.Context, trackID string, update MetadataUpdate) (*models.Track, error) {
+	if (update.TrackNumber != nil && *update.TrackNumber < 0) || (update.Year != nil && *update.Year < 0) {
+		return nil, fmt.Errorf("track number and year must not be negative")
+	}
+	var old models.Track
+	track, err := d.ctr.EditTrack(trackID, func(track *models.Track) error {
+		old = *track
+		setText(&track.Title, update.Title)
+		setText(&track.Artist, update.Artist)
+		setText(&track.Album, update.Album)
+		setText(&track.Genre, update.Genre)
+		if update.TrackNumber != nil {
+			track.TrackNumber = *update.TrackNumber
+		}
+		if update.Year != nil {
+			track.Year = *update.Year
+		}
+		if update.Title != nil || update.Artist != nil {
+			track.Recognized = track.Title != "" && track.Artist != ""
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if old.IsShared() && track.IsShared() {
+		// Still announced under the same CTID: only the tokens change
+		d.search.RemoveFromLocalIndex(&old)
+		d.withdrawTokens(&old)
+		d.search.UpdateLocalIndex(track)
+		d.announceTokens(ctx, track)
+	} else {
+		d.withdraw(&old)
+		if track.IsShared() {
+			if err := d.announceTrack(ctx, track); err != nil {
+				// Non-fatal; periodic announce will retry
+				d.logger.Warn("track-announce-error", "track_id", track.ID, "error", err)
+			}
+		}
+	}
+
+	d.logger.Info("track-metadata-updated", "track_id", track.ID, "recognized", track.Recognized)
+	return track, nil
+}
+
+// UnshareTrack stops announcing and serving a track while keeping it in the
+// library. ShareTrack shares it again.
+func (d *Daemon) UnshareTrack(trackID string) error {
+	track, err := d.store.GetTrack(trackID)
+	if err != nil {
+		return fmt.Errorf("track not found: %w", err)
+	}
+	if track.Unshared {
+		return nil
+	}
+
+	track.Unshared = true
+	if err := d.store.SaveTrack(track); err != nil {
+		return fmt.Errorf("failed to save track: %w", err)
+	}
+	d.withdraw(track)
+
+	d.logger.Info("track-unshared", "track_id", track.ID, "ctid", track.CTID)
+	return nil
+}
+
+// forgetTrack deletes a track and its processing job and withdraws what
+// only it announced, returning the CTIDs no longer provided
+func (d *Daemon) forgetTrack(track *models.Track) ([]string, error) {
+	if err := d.store.DeleteTrack(track.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete track: %w", err)
+	}
+	d.store.DeleteJob(track.ID)
+	return d.withdraw(track), nil
+}
+
+// withdraw stops announcing the CTIDs, search tokens and fingerprint key of
+// a track that no shared local track has any more, and drops the track from
+// the local search index. Call it once the store holds the track deleted,
+// unshared or renamed. The DHT has no withdrawal: records already published
+// lapse after their TTL.
+func (d *Daemon) withdraw(track *models.Track) []string {
+	d.search.RemoveFromLocalIndex(track)
+
+	var withdrawn []string
+	for _, ctid := range []string{track.CTID, track.LegacyCTID} {
+		if ctid == "" {
+			continue
+		}
+		others, err := d.store.FindTracksByCTID(ctid)
+		others = slices.DeleteFunc(others, func(other *models.Track) bool { return other.ID == track.ID })
+		if anyShared(others, err) {
+			continue // another local track still provides it
+		}
+		d.dht.Unprovide(ctid)
+		withdrawn = append(withdrawn, ctid)
+	}
+	d.withdrawTokens(track)
+	if track.FingerprintKey != "" && !anyShared(d.store.FindTracksByFingerprintKey(track.FingerprintKey)) {
+		d.dht.UnprovideFingerprint(dht.HashFingerprintKey(track.FingerprintKey))
+	}
+	return withdrawn
+}
+
+// withdrawTokens stops announcing the search tokens of a track that no
+// shared local track has any more
+func (d *Daemon) withdrawTokens(track *models.Track) {
+	for _, token := range d.search.Tokenize(track.Title + " " + track.Artist) {
+		// The token lookup matches prefixes; only the exact token is provided
+		others, err := d.store.FindTracksByToken(token)
+		others = slices.DeleteFunc(others, func(other *models.Track) bool {
+			return !slices.Contains(d.search.Tokenize(other.Title+" "+other.Artist), token)
+		})
+		if !anyShared(others, err) {
+			d.dht.UnprovideToken(dht.HashToken(token))
+		}
+	}
+}
+
+// removeTrackFiles deletes the files of a deleted track that no other track
+// references. The waveform beside a file referenced in place was written by
+// CTR and goes with the track; the audio only goes with deleteFile.
+func (d *Daemon) removeTrackFiles(track *models.Track, deleteFile bool) error {
+	if track.MediaHash != "" {
+		if !deleteFile || d.media == nil {
+			return nil
+		}
+		if refs, err := d.store.MediaRefs(track.MediaHash); err != nil || refs > 0 {
+			return err
+		}
+		if _, err := d.media.Remove(track.MediaHash); err != nil && !errors.Is(err, media.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	if track.Path == "" {
+		return nil
+	}
+	tracks, err := d.store.GetAllTracks()
+	if err != nil {
+		return fmt.Errorf("failed to list tracks: %w", err)
+	}
+	for _, other := range tracks {
+		if other.Path == track.Path {
+			return nil
+		}
+	}
+	paths := []string{waveform.PathFor(track.Path)}
+	if deleteFile {
+		paths = append(paths, track.Path)
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+	}
+	return nil
+}
+
+// anyShared reports whether a lookup found a shared track. A failed lookup
+// counts as found so that nothing is withdrawn by mistake.
+func anyShared(tracks []*models.Track, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, track := range tracks {
+		if track.IsShared() {
+			return true
+		}
+	}
+	return false
+}
+
+func setText(field *string, value *string) {
+	if value != nil {
+		*field = strings.TrimSpace(*value)
+	}
+}
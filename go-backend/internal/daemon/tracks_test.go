@@ -0,0 +1,83 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+
+	"github.com/cotune/go-backend/internal/ctr"
+	"github.com/cotune/go-backend/internal/dht"
+	"github.com/cotune/go-backend/internal/fsck"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/search"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+// newTestDaemon returns a daemon over an in-memory store, with a DHT that
+// only tracks what it provides and a host on a mock network
+func newTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	net := mocknet.New()
+	t.Cleanup(func() { net.Close() })
+	h, err := net.GenPeer()
+	if err != nil {
+		t.Fatalf("GenPeer() error: %v", err)
+	}
+
+	store := storage.NewMemory()
+	ctrService := ctr.New(store, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &Daemon{
+		h:        h,
+		dht:      &dht.Service{},
+		ctr:      ctrService,
+		search:   search.New(store, nil, h),
+		store:    store,
+		verifier: fsck.New(store, ctrService),
+		logger:   slog.Default(),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func TestWithdrawKeepsCTIDOfSharedCopy(t *testing.T) {
+	d := newTestDaemon(t)
+
+	// The unshared copy sorts first, so a single lookup would find only it
+	for _, track := range []*models.Track{
+		{ID: "a-unshared", CTID: "ctid-1", Recognized: true, Unshared: true},
+		{ID: "b-shared", CTID: "ctid-1", Recognized: true},
+		{ID: "c-deleted", CTID: "ctid-1", LegacyCTID: "ctid-0", Recognized: true},
+	} {
+		if err := d.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack(%s) error: %v", track.ID, err)
+		}
+	}
+
+	deleted, err := d.store.GetTrack("c-deleted")
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	withdrawn, err := d.forgetTrack(deleted)
+	if err != nil {
+		t.Fatalf("forgetTrack() error: %v", err)
+	}
+	if !slices.Equal(withdrawn, []string{"ctid-0"}) {
+		t.Fatalf("forgetTrack() withdrew %v, want only the legacy CTID nobody else has", withdrawn)
+	}
+
+	if err := d.UnshareTrack("b-shared"); err != nil {
+		t.Fatalf("UnshareTrack() error: %v", err)
+	}
+	shared, err := d.store.GetTrack("b-shared")
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	if withdrawn := d.withdraw(shared); !slices.Equal(withdrawn, []string{"ctid-1"}) {
+		t.Fatalf("withdraw() = %v, want ctid-1 once no copy is shared", withdrawn)
+	}
+}
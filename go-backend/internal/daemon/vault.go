@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cotune/go-backend/internal/host"
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/vault"
+)
+
+var (
+	// ErrNotEncrypted is returned by vault operations on a node that is not
+	// encrypted
+	ErrNotEncrypted = errors.New("node is not encrypted")
+	// ErrEncrypted is returned when enabling encryption twice
+	ErrEncrypted = errors.New("node is already encrypted")
+)
+
+// VaultStatus describes the encryption of the node
+type VaultStatus struct {
+	Encrypted bool `json:"encrypted"`
+	*vault.Info
+}
+
+// SetVault sets the data directory the identity key and vault live in, and
+// the unlocked vault of an encrypted node (nil for both otherwise)
+func (d *Daemon) SetVault(dataDir string, v *vault.Vault, keyring *vault.Keyring) {
+	d.vaultMu.Lock()
+	defer d.vaultMu.Unlock()
+	d.dataDir = dataDir
+	d.vault = v
+	d.keyring = keyring
+}
+
+// SetOnLock sets what Lock runs to stop the daemon until it is unlocked
+// again
+func (d *Daemon) SetOnLock(fn func()) {
+	d.onLock = fn
+}
+
+// VaultStatus describes whether and how the node is encrypted
+func (d *Daemon) VaultStatus() VaultStatus {
+	d.vaultMu.Lock()
+	defer d.vaultMu.Unlock()
+	return d.vaultStatus()
+}
+
+func (d *Daemon) vaultStatus() VaultStatus {
+	if d.vault == nil {
+		return VaultStatus{}
+	}
+	info := d.vault.Info()
+	return VaultStatus{Encrypted: true, Info: &info}
+}
+
+// EnableEncryption creates a vault unlocked by secret and seals the
+// identity key and library with it. The daemon starts locked from then on.
+func (d *Daemon) EnableEncryption(secret vault.Secret) (VaultStatus, error) {
+	d.vaultMu.Lock()
+	defer d.vaultMu.Unlock()
+
+	if d.vault != nil {
+		return VaultStatus{}, ErrEncrypted
+	}
+	if d.dataDir == "" {
+		return VaultStatus{}, fmt.Errorf("encryption not configured")
+	}
+	// A crash before the node is sealed is finished by the next unlock,
+	// which seals whatever is still in the clear
+	v, keyring, err := vault.Create(d.dataDir, secret)
+	if err != nil {
+		return VaultStatus{}, err
+	}
+	d.vault = v
+	if err := d.seal(keyring); err != nil {
+		return d.vaultStatus(), err
+	}
+	d.logger.Info("encryption-enabled", "kdf", v.Info().KDF, "key_id", keyring.KeyID())
+	return d.vaultStatus(), nil
+}
+
+// ChangeVaultSecret replaces the secret unlocking the node. The data key
+// is kept, so nothing is resealed.
+func (d *Daemon) ChangeVaultSecret(old, secret vault.Secret) (VaultStatus, error) {
+	d.vaultMu.Lock()
+	defer d.vaultMu.Unlock()
+
+	if d.vault == nil {
+		return VaultStatus{}, ErrNotEncrypted
+	}
+	if err := d.vault.ChangeSecret(old, secret); err != nil {
+		return VaultStatus{}, err
+	}
+	d.logger.Info("vault-secret-changed", "kdf", d.vault.Info().KDF)
+	return d.vaultStatus(), nil
+}
+
+// RotateVaultKey replaces the data key the identity key and library are
+// sealed with, resealing them. An interrupted rotation is finished when the
+// node is next unlocked.
+func (d *Daemon) RotateVaultKey(secret vault.Secret) (VaultStatus, error) {
+	d.vaultMu.Lock()
+	defer d.vaultMu.Unlock()
+
+	if d.vault == nil {
+		return VaultStatus{}, ErrNotEncrypted
+	}
+	keyring, err := d.vault.BeginRotation(secret)
+	if err != nil {
+		return VaultStatus{}, err
+	}
+	if err := d.seal(keyring); err != nil {
+		return d.vaultStatus(), err
+	}
+	if err := d.vault.FinishRotation(keyring); err != nil {
+		return d.vaultStatus(), err
+	}
+	d.logger.Info("vault-key-rotated", "key_id", keyring.KeyID())
+	return d.vaultStatus(), nil
+}
+
+// Lock stops the daemon and forgets the keyring; the node serves nothing
+// but unlocking until the secret is given again
+func (d *Daemon) Lock() error {
+	d.vaultMu.Lock()
+	encrypted := d.vault != nil
+	d.vaultMu.Unlock()
+
+	if !encrypted {
+		return ErrNotEncrypted
+	}
+	if d.onLock == nil {
+		return fmt.Errorf("locking not supported")
+	}
+	d.logger.Info("lock-requested")
+	d.onLock()
+	return nil
+}
+
+// Keyring returns the keyring of an encrypted node, nil otherwise
+func (d *Daemon) Keyring() *vault.Keyring {
+	d.vaultMu.Lock()
+	defer d.vaultMu.Unlock()
+	return d.keyring
+}
+
+// seal writes the identity key and library sealed with keyring's current
+// key, which becomes the node's keyring
+func (d *Daemon) seal(keyring *vault.Keyring) error {
+	if err := host.SaveKey(d.dataDir, d.h.Peerstore().PrivKey(d.h.ID()), keyring); err != nil {
+		return fmt.Errorf("failed to seal identity key: %w", err)
+	}
+	// A store that keeps nothing at rest has nothing to seal
+	if store, ok := d.store.(storage.Sealable); ok {
+		n, err := store.Reseal(keyring)
+		if err != nil {
+			return fmt.Errorf("failed to seal library: %w", err)
+		}
+		d.logger.Info("library-sealed", "records", n, "key_id", keyring.KeyID())
+	}
+	d.keyring = keyring
+	return nil
+}
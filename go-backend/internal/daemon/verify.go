@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"context"
+
+	"github.com/cotune/go-backend/internal/fsck"
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// StartVerify checks the files of every track in the background. With deep
+// every file is read to check its content as well; VerifyReport follows
+// progress.
+func (d *Daemon) StartVerify(deep bool) error {
+	if err := d.verifier.Start(d.ctx, fsck.Options{Deep: deep}); err != nil {
+		return err
+	}
+	d.logger.Info("verify-started", "deep", deep)
+	return nil
+}
+
+// VerifyReport returns the report of the current or last verification
+func (d *Daemon) VerifyReport() fsck.Report {
+	return d.verifier.Report()
+}
+
+// onTrackVerified withdraws a track verification found broken, queues one
+// whose content was reset or waveform lost, and announces a recovered one
+func (d *Daemon) onTrackVerified(ctx context.Context, before *models.Track, after *models.Track) {
+	if after.Broken != "" {
+		d.logger.Warn("track-broken", "track_id", after.ID, "path", after.Path, "problem", after.Broken)
+	}
+	if before.IsShared() && !after.IsShared() {
+		d.withdraw(before)
+	}
+	if (before.CTID != "" && after.CTID == "") || (before.Waveform && !after.Waveform) {
+		if err := d.ctr.QueueTrack(after); err != nil {
+			d.logger.Warn("ctr-queue-error", "track_id", after.ID, "error", err)
+		}
+	}
+	if !before.IsShared() && after.IsShared() {
+		d.logger.Info("track-recovered", "track_id", after.ID)
+		if err := d.announceTrack(ctx, after); err != nil {
+			// Non-fatal; periodic announce will retry
+			d.logger.Warn("track-announce-error", "track_id", after.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/watch"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// WatchStatus lists the watched folders and the progress of scanning them
+type WatchStatus struct {
+	Folders []string `json:"folders"`
+	watch.Progress
+}
+
+// SetWatcher sets the service following watched folders; Start starts it
+// with the folders in the settings
+func (d *Daemon) SetWatcher(w *watch.Service) {
+	d.watcher = w
+}
+
+// AddWatchFolder starts watching a folder, importing the audio files in it,
+// and saves it in the settings
+func (d *Daemon) AddWatchFolder(folder string) (*WatchStatus, error) {
+	if d.watcher == nil {
+		return nil, fmt.Errorf("folder watching not configured")
+	}
+	folder, err := watch.CleanFolder(folder)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.watcher.Add(folder); err != nil {
+		return nil, err
+	}
+	if err := d.saveWatchFolders(); err != nil {
+		return nil, err
+	}
+	d.logger.Info("watch-folder-added", "folder", folder)
+	return d.WatchStatus(), nil
+}
+
+// RemoveWatchFolder stops watching a folder and drops it from the settings.
+// Tracks imported from it stay in the library.
+func (d *Daemon) RemoveWatchFolder(folder string) (*WatchStatus, error) {
+	if d.watcher == nil {
+		return nil, fmt.Errorf("folder watching not configured")
+	}
+	if err := d.watcher.Remove(folder); err != nil {
+		return nil, err
+	}
+	if err := d.saveWatchFolders(); err != nil {
+		return nil, err
+	}
+	d.logger.Info("watch-folder-removed", "folder", folder)
+	return d.WatchStatus(), nil
+}
+
+// RescanWatchFolders scans every watched folder again
+func (d *Daemon) RescanWatchFolders() (*WatchStatus, error) {
+	if d.watcher == nil {
+		return nil, fmt.Errorf("folder watching not configured")
+	}
+	d.watcher.Rescan("")
+	return d.WatchStatus(), nil
+}
+
+// WatchStatus returns the watched folders and scan progress
+func (d *Daemon) WatchStatus() *WatchStatus {
+	status := &WatchStatus{Folders: []string{}}
+	if d.watcher != nil {
+		status.Folders = d.watcher.Folders()
+		status.Progress = d.watcher.Progress()
+	}
+	return status
+}
+
+func (d *Daemon) saveWatchFolders() error {
+	settings, err := d.store.GetSettings()
+	if err != nil {
+		return err
+	}
+	settings.WatchFolders = d.watcher.Folders()
+	return d.store.SaveSettings(settings)
+}
+
+// ImportFile adds a file from a watched folder in place, for watch.Library
+func (d *Daemon) ImportFile(ctx context.Context, path string, hash string) (*models.Track, error) {
+	track := &models.Track{
+		ID:       generateTrackID(),
+		Path:     path,
+		FileHash: hash,
+	}
+	if err := d.addTrack(track); err != nil {
+		return nil, err
+	}
+	d.logger.Info("watch-track-imported", "track_id", track.ID, "path", path)
+	return track, nil
+}
+
+// MoveTrack points a track at the new path of its file, taking its
+// waveform along, for watch.Library
+func (d *Daemon) MoveTrack(trackID string, path string) error {
+	track, err := d.store.GetTrack(trackID)
+	if err != nil {
+		return fmt.Errorf("track not found: %w", err)
+	}
+	if track.MediaHash != "" {
+		return fmt.Errorf("track %s is in the media store", trackID)
+	}
+
+	if track.Waveform {
+		err := os.Rename(waveform.PathFor(track.Path), waveform.PathFor(path))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			d.logger.Warn("waveform-move-error", "track_id", trackID, "error", err)
+		}
+		if err != nil {
+			track.Waveform = false
+		}
+	}
+	track.Path = path
+	track.Broken = "" // the content was found again, under its hash
+	if err := d.store.SaveTrack(track); err != nil {
+		return fmt.Errorf("failed to save track: %w", err)
+	}
+	if !track.Waveform {
+		return d.ctr.QueueTrack(track)
+	}
+	return nil
+}
+
+// RemoveTrack removes a track whose file was deleted, for watch.Library
+func (d *Daemon) RemoveTrack(trackID string) error {
+	return d.DeleteTrack(trackID, false)
+}
@@ -14,6 +14,8 @@ const (
 	CTIDNamespace = "/ctid/"
 	// TokenNamespace is the namespace prefix for tokens in DHT
 	TokenNamespace = "/token/"
+	// FingerprintNamespace is the namespace prefix for fingerprint keys in DHT
+	FingerprintNamespace = "/fp/"
 )
 
 // ctidToCID converts a CTID (hex string) to a CID
@@ -63,3 +65,10 @@ func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(hash[:])
 }
+
+// HashFingerprintKey hashes a fingerprint key to a hex string. The namespace
+// is mixed in so fingerprint records never collide with token records.
+func HashFingerprintKey(key string) string {
+	hash := sha256.Sum256([]byte(FingerprintNamespace + key))
+	return hex.EncodeToString(hash[:])
+}
@@ -59,3 +59,17 @@ func TestTokenHashToCIDRejectsInvalidValues(t *testing.T) {
 		}
 	}
 }
+
+func TestHashFingerprintKeyIsNamespaced(t *testing.T) {
+	key := "fp1:12:abcd"
+	got := HashFingerprintKey(key)
+	if len(got) != 64 {
+		t.Fatalf("HashFingerprintKey() length = %d, want 64", len(got))
+	}
+	if got == HashToken(key) {
+		t.Fatal("HashFingerprintKey() collides with HashToken()")
+	}
+	if _, err := tokenHashToCID(got); err != nil {
+		t.Fatalf("tokenHashToCID(HashFingerprintKey()) error: %v", err)
+	}
+}
@@ -299,12 +299,88 @@ func (s *Service) ProvideToken(ctx context.Context, tokenHash string) error {
 	return nil
 }
 
+// ProvideFingerprint announces that this peer has a recording matching a
+// fingerprint key
+func (s *Service) ProvideFingerprint(ctx context.Context, keyHash string) error {
+	if err := s.ensureBootstrapConnectivity(ctx); err != nil {
+		return err
+	}
+
+	cid, err := tokenHashToCID(keyHash)
+	if err != nil {
+		return fmt.Errorf("invalid fingerprint key hash: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := s.dht.Provide(ctx, cid, true); err != nil {
+		return fmt.Errorf("failed to provide fingerprint: %w", err)
+	}
+	s.trackProvided("fp:" + keyHash)
+
+	return nil
+}
+
+// ProvidePlaylist announces that this peer can provide a playlist record
+func (s *Service) ProvidePlaylist(ctx context.Context, recordID string) error {
+	if err := s.ensureBootstrapConnectivity(ctx); err != nil {
+		return err
+	}
+
+	// Record IDs are SHA256 of the record, like CTIDs of audio
+	cid, err := tokenHashToCID(recordID)
+	if err != nil {
+		return fmt.Errorf("invalid playlist record ID: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := s.dht.Provide(ctx, cid, true); err != nil {
+		return fmt.Errorf("failed to provide playlist: %w", err)
+	}
+	s.trackProvided("playlist:" + recordID)
+
+	return nil
+}
+
+// Unprovide stops counting a CTID as provided. The DHT has no withdrawal:
+// records already published lapse after their TTL once the CTID is no
+// longer announced.
+func (s *Service) Unprovide(ctid string) {
+	s.untrackProvided("ctid:" + ctid)
+}
+
+// UnprovideToken stops counting a token as provided, like Unprovide
+func (s *Service) UnprovideToken(tokenHash string) {
+	s.untrackProvided("token:" + tokenHash)
+}
+
+// UnprovideFingerprint stops counting a fingerprint key as provided, like
+// Unprovide
+func (s *Service) UnprovideFingerprint(keyHash string) {
+	s.untrackProvided("fp:" + keyHash)
+}
+
+// UnprovidePlaylist stops counting a playlist record as provided, like
+// Unprovide
+func (s *Service) UnprovidePlaylist(recordID string) {
+	s.untrackProvided("playlist:" + recordID)
+}
+
 func (s *Service) trackProvided(key string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.providedKeys[key] = struct{}{}
 }
 
+func (s *Service) untrackProvided(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.providedKeys, key)
+}
+
 func (s *Service) Stats() Stats {
 	stats := Stats{
 		WANActive:       s.dht.WANActive(),
@@ -386,6 +462,61 @@ func (s *Service) FindProvidersForToken(ctx context.Context, tokenHash string, m
 	return result, nil
 }
 
+// FindProvidersForFingerprint finds peers holding recordings that match a
+// fingerprint key (used for similarity lookups)
+func (s *Service) FindProvidersForFingerprint(ctx context.Context, keyHash string, max int) ([]peer.AddrInfo, error) {
+	if err := s.ensureBootstrapConnectivity(ctx); err != nil {
+		log.Printf("FindProvidersForFingerprint: bootstrap reconnect failed: %v", err)
+	}
+
+	cid, err := tokenHashToCID(keyHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fingerprint key hash: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	providers := s.dht.FindProvidersAsync(ctx, cid, max)
+
+	result := make([]peer.AddrInfo, 0, max)
+	for p := range providers {
+		result = append(result, p)
+		if len(result) >= max {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// FindPlaylistProviders finds peers that can provide a playlist record
+func (s *Service) FindPlaylistProviders(ctx context.Context, recordID string, max int) ([]peer.AddrInfo, error) {
+	if err := s.ensureBootstrapConnectivity(ctx); err != nil {
+		log.Printf("FindPlaylistProviders: bootstrap reconnect failed: %v", err)
+	}
+
+	cid, err := tokenHashToCID(recordID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid playlist record ID: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	providers := s.dht.FindProvidersAsync(ctx, cid, max)
+
+	result := make([]peer.AddrInfo, 0, max)
+	for p := range providers {
+		result = append(result, p)
+		if len(result) >= max {
+			break
+		}
+	}
+
+	return result, nil
+}
+
 // FindPeer resolves a peer's reachable addresses through DHT routing.
 func (s *Service) FindPeer(ctx context.Context, id peer.ID) (peer.AddrInfo, error) {
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
@@ -0,0 +1,372 @@
+package fingerprint
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const (
+	// analysisRate is the approximate sample rate the signal is reduced to
+	// before spectral analysis. Only 300-2000 Hz is used, so this is plenty.
+	analysisRate = 11025
+	// frameSize is the FFT window length in samples (~186ms at 11025 Hz)
+	frameSize = 2048
+	// hopSize is the distance between consecutive frames (~46ms at 11025 Hz)
+	hopSize = 512
+	// numBands is the number of log-spaced energy bands per frame
+	numBands = 33
+	minFreq  = 300.0
+	maxFreq  = 2000.0
+
+	// durationBucket is the width of the duration component of Key, in seconds
+	durationBucket = 10
+	// shapeBits is the number of band comparisons in the shape component of Key
+	shapeBits = 16
+	// keyVersion prefixes every fingerprint key so the derivation can evolve
+	keyVersion = "fp1"
+
+	// MatchThreshold is the minimum Similarity score for two fingerprints to
+	// be considered the same recording. Unrelated audio scores around 0.5.
+	MatchThreshold = 0.65
+	// maxOffsetFrames bounds the alignment search in Similarity (~6s)
+	maxOffsetFrames = 128
+)
+
+// Fingerprint is a robust acoustic fingerprint of a track.
+//
+// Each frame is a 32-bit sub-fingerprint whose bits encode the sign of the
+// energy difference between adjacent bands across adjacent frames, so it
+// survives re-encoding, bitrate changes and small amounts of padding.
+type Fingerprint struct {
+	Frames   []uint32
+	Duration float64 // Seconds of audio analysed
+	Key      string  // Coarse lookup key shared by near-identical recordings
+}
+
+// Compute computes the fingerprint of mono PCM audio at the given sample rate.
+// It returns nil if the audio is too short to produce a single frame.
+func Compute(pcm []int16, sampleRate int) *Fingerprint {
+	b := NewBuilder(sampleRate)
+	b.AddSamples(pcm)
+	return b.Fingerprint()
+}
+
+// Builder computes a fingerprint incrementally from a stream of mono PCM, so
+// callers never need to hold the whole track in memory. The result is the
+// same as calling Compute on the concatenated samples.
+type Builder struct {
+	sampleRate int
+	factor     int
+	edges      []int
+	window     []float64
+	re, im     []float64
+
+	blockSum float64   // running sum of the current downsampling block
+	blockLen int       // samples accumulated in the current block
+	signal   []float64 // downsampled samples not yet consumed by a frame
+	odd      []byte    // incomplete sample carried between Write calls
+
+	frames    []uint32
+	numFrames int
+	avg       [numBands]float64
+	prev      [numBands]float64
+	total     int
+}
+
+// NewBuilder creates a Builder for mono PCM at the given sample rate
+func NewBuilder(sampleRate int) *Builder {
+	b := &Builder{sampleRate: sampleRate}
+	if sampleRate <= 0 {
+		return b
+	}
+
+	b.factor = sampleRate / analysisRate
+	if b.factor < 1 {
+		b.factor = 1
+	}
+	b.edges = bandEdges(sampleRate / b.factor)
+	b.window = hannWindow(frameSize)
+	b.re = make([]float64, frameSize)
+	b.im = make([]float64, frameSize)
+	return b
+}
+
+// Write feeds 16-bit little-endian mono PCM bytes, so a Builder can sit
+// behind an io.MultiWriter next to a hash
+func (b *Builder) Write(p []byte) (int, error) {
+	data := p
+	if len(b.odd) > 0 {
+		data = append(b.odd, p...)
+	}
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	b.odd = append(b.odd[:0], data[len(samples)*2:]...)
+	b.AddSamples(samples)
+	return len(p), nil
+}
+
+// AddSamples feeds mono PCM samples
+func (b *Builder) AddSamples(pcm []int16) {
+	if b.sampleRate <= 0 {
+		return
+	}
+	b.total += len(pcm)
+
+	for _, sample := range pcm {
+		b.blockSum += float64(sample)
+		b.blockLen++
+		if b.blockLen < b.factor {
+			continue
+		}
+		b.signal = append(b.signal, b.blockSum/float64(b.factor)/32768)
+		b.blockSum, b.blockLen = 0, 0
+
+		if len(b.signal) == frameSize {
+			b.processFrame()
+			b.signal = append(b.signal[:0], b.signal[hopSize:]...)
+		}
+	}
+}
+
+// Fingerprint returns the fingerprint of all samples fed so far, or nil if
+// the audio is too short to produce a single frame.
+func (b *Builder) Fingerprint() *Fingerprint {
+	if len(b.frames) == 0 {
+		return nil
+	}
+
+	var avg [numBands]float64
+	for i := range avg {
+		avg[i] = b.avg[i] / float64(b.numFrames)
+	}
+	duration := float64(b.total) / float64(b.sampleRate)
+
+	return &Fingerprint{
+		Frames:   append([]uint32(nil), b.frames...),
+		Duration: duration,
+		Key:      makeKey(duration, avg),
+	}
+}
+
+// processFrame analyses the frameSize samples at the start of signal
+func (b *Builder) processFrame() {
+	for i := 0; i < frameSize; i++ {
+		b.re[i] = b.signal[i] * b.window[i]
+		b.im[i] = 0
+	}
+	fft(b.re, b.im)
+
+	var energy [numBands]float64
+	for band := 0; band < numBands; band++ {
+		var sum float64
+		for k := b.edges[band]; k < b.edges[band+1]; k++ {
+			sum += b.re[k]*b.re[k] + b.im[k]*b.im[k]
+		}
+		energy[band] = math.Log1p(sum)
+		b.avg[band] += energy[band]
+	}
+
+	if b.numFrames > 0 {
+		var word uint32
+		for band := 0; band < numBands-1; band++ {
+			diff := (energy[band] - energy[band+1]) - (b.prev[band] - b.prev[band+1])
+			if diff > 0 {
+				word |= 1 << uint(band)
+			}
+		}
+		b.frames = append(b.frames, word)
+	}
+	b.prev = energy
+	b.numFrames++
+}
+
+// Similarity returns a score in [0, 1] describing how alike two fingerprints
+// are. The score is one minus the lowest bit error rate over all alignments
+// within a few seconds of each other; identical audio scores 1.
+func Similarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shorter := len(a)
+	if len(b) < shorter {
+		shorter = len(b)
+	}
+	minOverlap := shorter / 2
+	if minOverlap < 1 {
+		minOverlap = 1
+	}
+
+	best := 1.0
+	for offset := -maxOffsetFrames; offset <= maxOffsetFrames; offset++ {
+		ai, bi := 0, 0
+		if offset > 0 {
+			ai = offset
+		} else {
+			bi = -offset
+		}
+		overlap := len(a) - ai
+		if rest := len(b) - bi; rest < overlap {
+			overlap = rest
+		}
+		if overlap < minOverlap {
+			continue
+		}
+
+		errBits := 0
+		for i := 0; i < overlap; i++ {
+			errBits += bits.OnesCount32(a[ai+i] ^ b[bi+i])
+		}
+		ber := float64(errBits) / float64(overlap*32)
+		if ber < best {
+			best = ber
+		}
+	}
+
+	return 1 - best
+}
+
+// Encode serializes fingerprint frames to a compact base64 string.
+func Encode(frames []uint32) string {
+	buf := make([]byte, len(frames)*4)
+	for i, f := range frames {
+		binary.LittleEndian.PutUint32(buf[i*4:], f)
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// Decode parses a string produced by Encode.
+func Decode(s string) ([]uint32, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fingerprint encoding: %w", err)
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid fingerprint length: %d", len(buf))
+	}
+	frames := make([]uint32, len(buf)/4)
+	for i := range frames {
+		frames[i] = binary.LittleEndian.Uint32(buf[i*4:])
+	}
+	return frames, nil
+}
+
+// NeighborKeys returns the key itself plus the keys a re-encode of the same
+// recording may have been given: the adjacent duration buckets, so lookups
+// still find recordings that straddle a bucket boundary, and in each bucket
+// the spectral shapes differing in one band comparison, which lossy
+// encoders flip for bands of nearly equal energy.
+func NeighborKeys(key string) []string {
+	parts := strings.Split(key, ":")
+	if len(parts) != 3 || parts[0] != keyVersion {
+		return []string{key}
+	}
+	bucket, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return []string{key}
+	}
+	shape, err := strconv.ParseUint(parts[2], 16, 16)
+	if err != nil {
+		return []string{key}
+	}
+
+	keys := []string{key}
+	for _, b := range []int{bucket, bucket - 1, bucket + 1} {
+		if b < 0 {
+			continue
+		}
+		if b != bucket {
+			keys = append(keys, formatKey(b, uint16(shape)))
+		}
+		for i := 0; i < shapeBits; i++ {
+			keys = append(keys, formatKey(b, uint16(shape)^(1<<uint(i))))
+		}
+	}
+	return keys
+}
+
+// makeKey derives the coarse lookup key from the track duration and the
+// average spectral shape. Only widely separated bands are compared so the
+// key is stable across encoders.
+func makeKey(duration float64, avg [numBands]float64) string {
+	var shape uint16
+	for i := 0; i < shapeBits; i++ {
+		if avg[2*i] > avg[2*i+2] {
+			shape |= 1 << uint(i)
+		}
+	}
+	return formatKey(int(duration)/durationBucket, shape)
+}
+
+func formatKey(bucket int, shape uint16) string {
+	return fmt.Sprintf("%s:%d:%04x", keyVersion, bucket, shape)
+}
+
+// bandEdges returns numBands+1 FFT bin indices delimiting log-spaced bands.
+func bandEdges(rate int) []int {
+	edges := make([]int, numBands+1)
+	ratio := maxFreq / minFreq
+	for i := range edges {
+		freq := minFreq * math.Pow(ratio, float64(i)/numBands)
+		bin := int(freq * frameSize / float64(rate))
+		if bin >= frameSize/2 {
+			bin = frameSize/2 - 1
+		}
+		if i > 0 && bin <= edges[i-1] {
+			bin = edges[i-1] + 1
+		}
+		edges[i] = bin
+	}
+	return edges
+}
+
+func hannWindow(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+	}
+	return w
+}
+
+// fft is an in-place iterative radix-2 FFT. len(re) must be a power of two.
+func fft(re, im []float64) {
+	n := len(re)
+
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			re[i], re[j] = re[j], re[i]
+			im[i], im[j] = im[j], im[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		angle := -2 * math.Pi / float64(size)
+		wr, wi := math.Cos(angle), math.Sin(angle)
+		for start := 0; start < n; start += size {
+			cr, ci := 1.0, 0.0
+			for k := 0; k < size/2; k++ {
+				a := start + k
+				b := a + size/2
+				tr := re[b]*cr - im[b]*ci
+				ti := re[b]*ci + im[b]*cr
+				re[b] = re[a] - tr
+				im[b] = im[a] - ti
+				re[a] += tr
+				im[a] += ti
+				cr, ci = cr*wr-ci*wi, cr*wi+ci*wr
+			}
+		}
+	}
+}
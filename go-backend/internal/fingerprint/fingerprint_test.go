@@ -0,0 +1,154 @@
+package fingerprint
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// synthTrack generates a deterministic melody-like signal: a sequence of
+// notes with harmonics plus a little noise, at 44.1kHz mono.
+func synthTrack(seed int64, seconds float64) []int16 {
+	const rate = 44100
+	rng := rand.New(rand.NewSource(seed))
+	n := int(seconds * rate)
+	pcm := make([]int16, n)
+
+	noteLen := rate / 4
+	freq := 0.0
+	phase := 0.0
+	for i := 0; i < n; i++ {
+		if i%noteLen == 0 {
+			freq = 220 * math.Pow(2, float64(rng.Intn(24))/12)
+		}
+		phase += 2 * math.Pi * freq / rate
+		v := 0.5*math.Sin(phase) + 0.25*math.Sin(2*phase) + 0.12*math.Sin(3*phase)
+		v += 0.02 * (rng.Float64()*2 - 1)
+		pcm[i] = int16(v * 20000)
+	}
+	return pcm
+}
+
+func TestSimilarityIdenticalAudio(t *testing.T) {
+	fp := Compute(synthTrack(1, 20), 44100)
+	if fp == nil {
+		t.Fatal("Compute() returned nil")
+	}
+	if got := Similarity(fp.Frames, fp.Frames); got != 1 {
+		t.Fatalf("Similarity(self) = %f, want 1", got)
+	}
+}
+
+func TestSimilarityRobustToPaddingAndNoise(t *testing.T) {
+	original := synthTrack(2, 20)
+
+	// 30ms of leading silence, slight gain change and added noise
+	rng := rand.New(rand.NewSource(99))
+	padding := make([]int16, 44100*30/1000)
+	variant := append(padding, make([]int16, len(original))...)
+	for i, s := range original {
+		v := float64(s)*0.9 + (rng.Float64()*2-1)*300
+		variant[len(padding)+i] = int16(v)
+	}
+
+	a := Compute(original, 44100)
+	b := Compute(variant, 44100)
+	score := Similarity(a.Frames, b.Frames)
+	if score < MatchThreshold {
+		t.Fatalf("Similarity(original, variant) = %f, want >= %f", score, MatchThreshold)
+	}
+	if a.Key != b.Key {
+		t.Fatalf("Key mismatch: %q vs %q", a.Key, b.Key)
+	}
+}
+
+func TestSimilarityDifferentAudio(t *testing.T) {
+	a := Compute(synthTrack(3, 20), 44100)
+	b := Compute(synthTrack(4, 20), 44100)
+	if score := Similarity(a.Frames, b.Frames); score >= MatchThreshold {
+		t.Fatalf("Similarity(different) = %f, want < %f", score, MatchThreshold)
+	}
+}
+
+func TestComputeShortInput(t *testing.T) {
+	if fp := Compute(make([]int16, 100), 44100); fp != nil {
+		t.Fatalf("Compute(short) = %+v, want nil", fp)
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	frames := []uint32{0, 1, 0xdeadbeef, math.MaxUint32}
+	got, err := Decode(Encode(frames))
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if len(got) != len(frames) {
+		t.Fatalf("Decode() length = %d, want %d", len(got), len(frames))
+	}
+	for i := range frames {
+		if got[i] != frames[i] {
+			t.Fatalf("Decode()[%d] = %x, want %x", i, got[i], frames[i])
+		}
+	}
+
+	if _, err := Decode("abc"); err == nil {
+		t.Fatal("Decode(invalid) returned nil error")
+	}
+}
+
+func TestNeighborKeys(t *testing.T) {
+	got := NeighborKeys("fp1:12:abcd")
+	if len(got) != 3*(1+shapeBits) {
+		t.Fatalf("NeighborKeys() returned %d keys, want %d", len(got), 3*(1+shapeBits))
+	}
+	if got[0] != "fp1:12:abcd" {
+		t.Fatalf("NeighborKeys()[0] = %q, want the key itself", got[0])
+	}
+	keys := make(map[string]bool)
+	for _, key := range got {
+		keys[key] = true
+	}
+	for _, want := range []string{"fp1:11:abcd", "fp1:13:abcd", "fp1:12:abcc", "fp1:12:2bcd", "fp1:11:abcf", "fp1:13:ebcd"} {
+		if !keys[want] {
+			t.Fatalf("NeighborKeys() = %v, missing %q", got, want)
+		}
+	}
+	if len(keys) != len(got) {
+		t.Fatalf("NeighborKeys() = %v, want no duplicates", got)
+	}
+	if keys["fp1:12:abce"] {
+		t.Fatal("NeighborKeys() holds a shape two comparisons away")
+	}
+
+	if got := NeighborKeys("fp1:0:abcd"); len(got) != 2*(1+shapeBits) {
+		t.Fatalf("NeighborKeys(bucket 0) returned %d keys, want %d", len(got), 2*(1+shapeBits))
+	}
+}
+
+func TestBuilderWriteMatchesCompute(t *testing.T) {
+	pcm := synthTrack(5, 12)
+	want := Compute(pcm, 44100)
+
+	raw := make([]byte, len(pcm)*2)
+	for i, s := range pcm {
+		raw[i*2] = byte(uint16(s))
+		raw[i*2+1] = byte(uint16(s) >> 8)
+	}
+
+	// Odd chunk sizes split samples across writes
+	b := NewBuilder(44100)
+	for start := 0; start < len(raw); start += 1001 {
+		end := start + 1001
+		if end > len(raw) {
+			end = len(raw)
+		}
+		if _, err := b.Write(raw[start:end]); err != nil {
+			t.Fatalf("Builder.Write() error: %v", err)
+		}
+	}
+	got := b.Fingerprint()
+
+	if got.Key != want.Key || Encode(got.Frames) != Encode(want.Frames) {
+		t.Fatalf("Builder fingerprint differs from Compute: key %q vs %q", got.Key, want.Key)
+	}
+}
@@ -0,0 +1,309 @@
+package fsck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/ctr"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// Problems verification records in models.Track.Broken
+const (
+	ProblemMissing  = "missing"       // the file is gone or unreadable
+	ProblemModified = "modified"      // size or mtime changed since CTR processed the file
+	ProblemMismatch = "ctid-mismatch" // the audio no longer hashes to the track's CTID
+	ProblemCorrupt  = "corrupt"       // a media store file no longer matches its hash, or does not decode
+)
+
+// ErrRunning is returned when a verification is already in progress
+var ErrRunning = errors.New("verification already running")
+
+// CTIDComputer recomputes CTIDs for deep verification; ctr.Service is one
+type CTIDComputer interface {
+	ComputeCTID(ctx context.Context, filePath string, version audio.Version) (string, error)
+}
+
+// Options selects how thoroughly tracks are verified
+type Options struct {
+	// Deep reads every file: media store files are hashed and the CTID of
+	// files referenced in place is recomputed
+	Deep bool `json:"deep"`
+}
+
+// Issue is a problem found with one track
+type Issue struct {
+	TrackID string `json:"track_id"`
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Report describes the current or last verification
+type Report struct {
+	Running    bool    `json:"running"`
+	Deep       bool    `json:"deep"`
+	StartedAt  int64   `json:"started_at,omitempty"` // Unix time
+	FinishedAt int64   `json:"finished_at,omitempty"`
+	Total      int     `json:"total"`
+	Checked    int     `json:"checked"`
+	OK         int     `json:"ok"`
+	Missing    int     `json:"missing"`
+	Modified   int     `json:"modified"`
+	Mismatched int     `json:"mismatched"`
+	Corrupt    int     `json:"corrupt"`
+	Recovered  int     `json:"recovered"` // Broken tracks whose file checked out again
+	Repaired   int     `json:"repaired"`  // Tracks whose missing waveform is computed again
+	LastError  string  `json:"last_error,omitempty"`
+	Issues     []Issue `json:"issues"`
+}
+
+// Verifier checks that the files of the library's tracks are still what CTR
+// processed. Tracks whose file is missing or changed are marked broken,
+// which stops them being announced and served; a file that was edited has
+// its content fields reset so CTR processes it again, and a broken track
+// whose file checks out again is shared again.
+type Verifier struct {
+	store    storage.Store
+	ctids    CTIDComputer // nil disables CTID recomputation
+	onChange func(ctx context.Context, before *models.Track, after *models.Track)
+
+	mu     sync.RWMutex
+	report Report
+	wg     sync.WaitGroup
+}
+
+// New creates a verifier. ctids recomputes CTIDs for deep verification and
+// may be nil, in which case only media store files are hashed.
+func New(store storage.Store, ctids CTIDComputer) *Verifier {
+	return &Verifier{store: store, ctids: ctids}
+}
+
+// SetOnChange sets a callback run after a track changed by verification is
+// saved, with the track as it was before
+func (v *Verifier) SetOnChange(fn func(ctx context.Context, before *models.Track, after *models.Track)) {
+	v.onChange = fn
+}
+
+// Start verifies the library in the background; Report follows progress
+func (v *Verifier) Start(ctx context.Context, opts Options) error {
+	if err := v.begin(opts); err != nil {
+		return err
+	}
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		v.finish(v.run(ctx, opts))
+	}()
+	return nil
+}
+
+// Run verifies the library and returns the report
+func (v *Verifier) Run(ctx context.Context, opts Options) (Report, error) {
+	if err := v.begin(opts); err != nil {
+		return Report{}, err
+	}
+	err := v.run(ctx, opts)
+	v.finish(err)
+	return v.Report(), err
+}
+
+// Wait waits for a verification started by Start to stop
+func (v *Verifier) Wait() {
+	v.wg.Wait()
+}
+
+// Report returns a snapshot of the current or last verification
+func (v *Verifier) Report() Report {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	report := v.report
+	report.Issues = append([]Issue{}, v.report.Issues...)
+	return report
+}
+
+func (v *Verifier) begin(opts Options) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.report.Running {
+		return ErrRunning
+	}
+	v.report = Report{Running: true, Deep: opts.Deep, StartedAt: time.Now().Unix()}
+	return nil
+}
+
+func (v *Verifier) finish(err error) {
+	v.update(func(r *Report) {
+		r.Running = false
+		r.FinishedAt = time.Now().Unix()
+		if err != nil {
+			r.LastError = err.Error()
+		}
+	})
+}
+
+func (v *Verifier) update(fn func(*Report)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	fn(&v.report)
+}
+
+func (v *Verifier) run(ctx context.Context, opts Options) error {
+	tracks, err := v.store.GetAllTracks()
+	if err != nil {
+		return fmt.Errorf("failed to list tracks: %w", err)
+	}
+	v.update(func(r *Report) { r.Total = len(tracks) })
+
+	for _, track := range tracks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := v.verify(ctx, track, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify checks one track and saves what changed
+func (v *Verifier) verify(ctx context.Context, track *models.Track, opts Options) error {
+	problem, detail := v.check(ctx, track, opts)
+	if err := ctx.Err(); err != nil {
+		return err // an interrupted deep check proves nothing
+	}
+
+	// The track may have been processed, moved or deleted meanwhile
+	current, err := v.store.GetTrack(track.ID)
+	if err != nil || current.Path != track.Path {
+		v.update(func(r *Report) { r.Checked++ })
+		return nil
+	}
+	track = current
+
+	before := *track
+	peaksLost := false
+	switch problem {
+	case "":
+		track.Broken = ""
+		if track.Waveform && !exists(waveform.PathFor(track.Path)) {
+			track.Waveform = false
+			peaksLost = true
+		}
+	case ProblemModified, ProblemMismatch:
+		track.ResetContent()
+		track.Broken = problem
+	default:
+		track.Broken = problem
+	}
+
+	v.update(func(r *Report) {
+		r.Checked++
+		switch problem {
+		case "":
+			r.OK++
+			if before.Broken != "" {
+				r.Recovered++
+			}
+			if peaksLost {
+				r.Repaired++
+			}
+			return
+		case ProblemMissing:
+			r.Missing++
+		case ProblemModified:
+			r.Modified++
+		case ProblemMismatch:
+			r.Mismatched++
+		case ProblemCorrupt:
+			r.Corrupt++
+		}
+		r.Issues = append(r.Issues, Issue{TrackID: track.ID, Path: track.Path, Problem: problem, Detail: detail})
+	})
+
+	if track.Broken == before.Broken && !peaksLost {
+		return nil
+	}
+	if err := v.store.SaveTrack(track); err != nil {
+		return fmt.Errorf("failed to save track %s: %w", track.ID, err)
+	}
+	if v.onChange != nil {
+		v.onChange(ctx, &before, track)
+	}
+	return nil
+}
+
+// check returns the problem with a track's file, if any, and a description
+func (v *Verifier) check(ctx context.Context, track *models.Track, opts Options) (string, string) {
+	info, err := os.Stat(track.Path)
+	if err != nil {
+		return ProblemMissing, err.Error()
+	}
+	if !info.Mode().IsRegular() {
+		return ProblemMissing, "not a regular file"
+	}
+	if track.FileSize != 0 && info.Size() != track.FileSize {
+		return ProblemModified, fmt.Sprintf("size %d, processed at %d", info.Size(), track.FileSize)
+	}
+	// Media store files are content-addressed, and the store refreshes their
+	// mtime when the content is imported again
+	if track.MediaHash == "" && track.FileModTime != 0 && info.ModTime().Unix() != track.FileModTime {
+		return ProblemModified, fmt.Sprintf("modified at %s", info.ModTime().UTC().Format(time.RFC3339))
+	}
+	if !opts.Deep {
+		return "", ""
+	}
+
+	if track.MediaHash != "" {
+		hash, err := hashFile(track.Path)
+		if err != nil {
+			return ProblemMissing, err.Error()
+		}
+		if hash != track.MediaHash {
+			return ProblemCorrupt, fmt.Sprintf("content hashes to %s", hash)
+		}
+		return "", ""
+	}
+	if track.CTID == "" || v.ctids == nil {
+		return "", ""
+	}
+	ctid, err := v.ctids.ComputeCTID(ctx, track.Path, ctr.TrackCTIDVersion(track))
+	if err != nil {
+		return ProblemCorrupt, err.Error()
+	}
+	if ctid != track.CTID {
+		return ProblemMismatch, fmt.Sprintf("audio hashes to %s", ctid)
+	}
+	return "", ""
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,151 @@
+package fsck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// fakeCTIDs hashes files to a fixed CTID per path
+type fakeCTIDs map[string]string
+
+func (f fakeCTIDs) ComputeCTID(ctx context.Context, filePath string, version audio.Version) (string, error) {
+	return f[filePath], nil
+}
+
+// processedTrack saves a track as CTR leaves it after processing path
+func processedTrack(t *testing.T, store storage.Store, id string, path string, data string) *models.Track {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := &models.Track{
+		ID:          id,
+		Path:        path,
+		CTID:        "ctid-" + id,
+		Title:       "Song " + id,
+		Artist:      "Artist",
+		Recognized:  true,
+		FileSize:    info.Size(),
+		FileModTime: info.ModTime().Unix(),
+	}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	return track
+}
+
+func getTrack(t *testing.T, store storage.Store, id string) *models.Track {
+	t.Helper()
+	track, err := store.GetTrack(id)
+	if err != nil {
+		t.Fatalf("GetTrack(%s) error: %v", id, err)
+	}
+	return track
+}
+
+func TestRunMarksBrokenTracksAndRecoversThem(t *testing.T) {
+	store := storage.NewMemory()
+
+	dir := t.TempDir()
+	processedTrack(t, store, "ok", filepath.Join(dir, "ok.mp3"), "intact")
+	processedTrack(t, store, "gone", filepath.Join(dir, "gone.mp3"), "deleted later")
+	processedTrack(t, store, "edited", filepath.Join(dir, "edited.mp3"), "before edit")
+	peaks := processedTrack(t, store, "peaks", filepath.Join(dir, "peaks.mp3"), "lost its waveform")
+	peaks.Waveform = true
+	store.SaveTrack(peaks)
+
+	os.Rename(filepath.Join(dir, "gone.mp3"), filepath.Join(dir, "elsewhere.mp3"))
+	os.WriteFile(filepath.Join(dir, "edited.mp3"), []byte("after the edit"), 0644)
+
+	var changed []string
+	v := New(store, nil)
+	v.SetOnChange(func(ctx context.Context, before *models.Track, after *models.Track) {
+		changed = append(changed, after.ID)
+	})
+	report, err := v.Run(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if report.Running || report.Total != 4 || report.OK != 2 || report.Missing != 1 || report.Modified != 1 || report.Repaired != 1 {
+		t.Fatalf("report = %+v, want 4 tracks: 2 ok, 1 missing, 1 modified, 1 repaired", report)
+	}
+	if len(report.Issues) != 2 || len(changed) != 3 {
+		t.Fatalf("issues = %+v, changed = %v; want 2 issues and 3 tracks changed", report.Issues, changed)
+	}
+
+	gone := getTrack(t, store, "gone")
+	if gone.Broken != ProblemMissing || gone.IsShared() || gone.CTID == "" {
+		t.Fatalf("missing track = %+v, want broken but keeping its CTID", gone)
+	}
+	edited := getTrack(t, store, "edited")
+	if edited.Broken != ProblemModified || edited.CTID != "" || edited.FileSize != 0 {
+		t.Fatalf("edited track = %+v, want broken with its content reset", edited)
+	}
+	if getTrack(t, store, "peaks").Waveform {
+		t.Fatal("track without peaks file still has Waveform set")
+	}
+	if !getTrack(t, store, "ok").IsShared() {
+		t.Fatal("intact track no longer shared")
+	}
+
+	// The file comes back
+	os.Rename(filepath.Join(dir, "elsewhere.mp3"), filepath.Join(dir, "gone.mp3"))
+	report, err = v.Run(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("second Run() error: %v", err)
+	}
+	if report.Missing != 0 || report.Recovered != 2 {
+		t.Fatalf("second report = %+v, want both broken tracks recovered", report)
+	}
+	if !getTrack(t, store, "gone").IsShared() {
+		t.Fatal("recovered track not shared again")
+	}
+}
+
+func TestDeepRunChecksContent(t *testing.T) {
+	store := storage.NewMemory()
+
+	dir := t.TempDir()
+	inPlace := processedTrack(t, store, "in-place", filepath.Join(dir, "in-place.flac"), "audio")
+	stored := processedTrack(t, store, "stored", filepath.Join(dir, "stored.flac"), "flipped bit")
+	sum := sha256.Sum256([]byte("original bits"))
+	stored.MediaHash = hex.EncodeToString(sum[:])
+	store.SaveTrack(stored)
+	os.WriteFile(waveform.PathFor(inPlace.Path), []byte("peaks"), 0644)
+
+	v := New(store, fakeCTIDs{inPlace.Path: "recomputed"})
+	report, err := v.Run(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if report.OK != 2 {
+		t.Fatalf("shallow report = %+v, want both tracks ok", report)
+	}
+
+	report, err = v.Run(context.Background(), Options{Deep: true})
+	if err != nil {
+		t.Fatalf("deep Run() error: %v", err)
+	}
+	if !report.Deep || report.Mismatched != 1 || report.Corrupt != 1 {
+		t.Fatalf("deep report = %+v, want 1 CTID mismatch and 1 corrupt file", report)
+	}
+	if track := getTrack(t, store, "in-place"); track.Broken != ProblemMismatch || track.CTID != "" {
+		t.Fatalf("in-place track = %+v, want CTID reset", track)
+	}
+	if track := getTrack(t, store, "stored"); track.Broken != ProblemCorrupt || track.CTID == "" {
+		t.Fatalf("stored track = %+v, want corrupt", track)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cotune/go-backend/internal/vault"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -20,10 +21,11 @@ import (
 	"github.com/multiformats/go-multiaddr"
 )
 
-// New creates a new libp2p host with all required protocols
-func New(ctx context.Context, listenAddr string, dataDir string, enableRelay bool) (host.Host, error) {
+// New creates a new libp2p host with all required protocols. keyring
+// seals the identity key of an encrypted node and is nil otherwise.
+func New(ctx context.Context, listenAddr string, dataDir string, keyring *vault.Keyring, enableRelay bool) (host.Host, error) {
 	// Generate or load private key
-	privKey, err := loadOrGenerateKey(dataDir)
+	privKey, err := LoadOrGenerateKey(dataDir, keyring)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load/generate key: %w", err)
 	}
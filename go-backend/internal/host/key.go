@@ -3,30 +3,120 @@ package host
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/cotune/go-backend/internal/vault"
 	"github.com/libp2p/go-libp2p/core/crypto"
 )
 
-const keyFileName = "private.key"
+// KeyFileName is the file in the data directory holding the node's
+// identity key. It holds the key in hex, sealed with the vault keyring on
+// an encrypted node.
+const KeyFileName = "private.key"
 
-func loadOrGenerateKey(dataDir string) (crypto.PrivKey, error) {
-	keyPath := filepath.Join(dataDir, keyFileName)
+// keyContext binds a sealed identity key to its purpose
+const keyContext = "identity"
+
+// ErrKeyLocked is returned when reading a sealed identity key without a
+// keyring
+var ErrKeyLocked = errors.New("identity key is encrypted")
+
+// DecodeKey parses the contents of a key file that is not sealed
+func DecodeKey(data []byte) (crypto.PrivKey, error) {
+	privKey, _, err := decodeKey(data, nil)
+	return privKey, err
+}
+
+// EncodeKey returns the contents of a key file holding key unsealed
+func EncodeKey(key crypto.PrivKey) ([]byte, error) {
+	keyBytes, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+	return []byte(hex.EncodeToString(keyBytes)), nil
+}
+
+// decodeKey parses the contents of a key file, opening a sealed key with
+// keyring; current reports whether it was sealed with the keyring's
+// current key
+func decodeKey(data []byte, keyring *vault.Keyring) (privKey crypto.PrivKey, current bool, err error) {
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode key: %w", err)
+	}
+	if vault.IsSealed(keyBytes) {
+		if keyring == nil {
+			return nil, false, ErrKeyLocked
+		}
+		current = keyring.IsCurrent(keyBytes)
+		if keyBytes, err = keyring.Open(keyBytes, keyContext); err != nil {
+			return nil, false, fmt.Errorf("failed to open key: %w", err)
+		}
+	}
+
+	privKey, err = crypto.UnmarshalPrivateKey(keyBytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal key: %w", err)
+	}
+
+	return privKey, current, nil
+}
+
+// LoadKey reads the identity key from dataDir; keyring opens it on an
+// encrypted node and may be nil otherwise
+func LoadKey(dataDir string, keyring *vault.Keyring) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, KeyFileName))
+	if err != nil {
+		return nil, err
+	}
+	privKey, _, err := decodeKey(data, keyring)
+	return privKey, err
+}
+
+// SaveKey writes the identity key to dataDir, sealed when keyring is set
+func SaveKey(dataDir string, key crypto.PrivKey, keyring *vault.Keyring) error {
+	keyBytes, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+	if keyring != nil {
+		if keyBytes, err = keyring.Seal(keyBytes, keyContext); err != nil {
+			return err
+		}
+	}
+
+	// Replace the file in one step so a crash cannot lose the identity
+	keyPath := filepath.Join(dataDir, KeyFileName)
+	if err := os.WriteFile(keyPath+".tmp", []byte(hex.EncodeToString(keyBytes)), 0600); err != nil {
+		return fmt.Errorf("failed to save key: %w", err)
+	}
+	if err := os.Rename(keyPath+".tmp", keyPath); err != nil {
+		return fmt.Errorf("failed to save key: %w", err)
+	}
+	return nil
+}
+
+// LoadOrGenerateKey loads the identity key from dataDir, generating one on
+// first start. With a keyring a key written in the clear or sealed before
+// a rotation is sealed again with its current key.
+func LoadOrGenerateKey(dataDir string, keyring *vault.Keyring) (crypto.PrivKey, error) {
+	keyPath := filepath.Join(dataDir, KeyFileName)
 
 	// Try to load existing key
 	if data, err := os.ReadFile(keyPath); err == nil {
-		keyBytes, err := hex.DecodeString(string(data))
+		privKey, current, err := decodeKey(data, keyring)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode key: %w", err)
+			return nil, err
 		}
-
-		privKey, err := crypto.UnmarshalPrivateKey(keyBytes)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal key: %w", err)
+		if keyring != nil && !current {
+			if err := SaveKey(dataDir, privKey, keyring); err != nil {
+				return nil, err
+			}
 		}
-
 		return privKey, nil
 	}
 
@@ -37,13 +127,8 @@ func loadOrGenerateKey(dataDir string) (crypto.PrivKey, error) {
 	}
 
 	// Save key
-	keyBytes, err := crypto.MarshalPrivateKey(privKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal key: %w", err)
-	}
-
-	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(keyBytes)), 0600); err != nil {
-		return nil, fmt.Errorf("failed to save key: %w", err)
+	if err := SaveKey(dataDir, privKey, keyring); err != nil {
+		return nil, err
 	}
 
 	return privKey, nil
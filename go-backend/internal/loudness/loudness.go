@@ -0,0 +1,348 @@
+package loudness
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// ReferenceLUFS is the ReplayGain 2.0 target loudness
+	ReferenceLUFS = -18.0
+	// SilenceLUFS is reported as the integrated loudness of audio that never
+	// rises above the absolute gate
+	SilenceLUFS = absoluteGate
+	// SilenceDBTP is reported as the true peak of digital silence; it is
+	// below anything 24-bit audio can hold
+	SilenceDBTP = -144.0
+
+	absoluteGate       = -70.0
+	integratedRelative = -10.0 // LU below the ungated mean, BS.1770-4
+	rangeRelative      = -20.0 // LU below the ungated mean, EBU Tech 3342
+	blockSubBlocks     = 4     // 400 ms momentary blocks of 100 ms sub-blocks
+	shortTermSubBlocks = 30    // 3 s short-term windows
+)
+
+// Result is an EBU R128 measurement
+type Result struct {
+	Integrated float64 // LUFS; SilenceLUFS if nothing passes the gate
+	Range      float64 // LU; 0 for tracks shorter than one short-term window
+	TruePeak   float64 // dBTP; SilenceDBTP for digital silence
+}
+
+// Gain returns the gain in dB that brings the measured audio to
+// ReferenceLUFS, reduced where needed so the true peak stays at or below
+// 0 dBTP. Silence gets no gain.
+func (r Result) Gain() float64 {
+	if r.Integrated <= SilenceLUFS {
+		return 0
+	}
+	gain := ReferenceLUFS - r.Integrated
+	if r.TruePeak+gain > 0 {
+		gain = -r.TruePeak
+	}
+	return gain
+}
+
+// Meter measures integrated loudness, loudness range and true peak of
+// interleaved 16-bit PCM per ITU-R BS.1770-4 and EBU Tech 3342. Memory grows
+// by one float per 100 ms of audio.
+type Meter struct {
+	channels int
+	weights  []float64
+	filters  []kFilter
+	peaks    []*truePeak
+
+	frameFill   int       // channels of the current frame seen so far
+	subBlockLen int       // samples per channel in 100 ms
+	subSum      []float64 // per-channel sum of squares in the current sub-block
+	subFill     int
+	recent      []float64 // weighted mean squares of the latest sub-blocks
+	blocks      []float64 // weighted mean square of every 400 ms block
+	shortTerm   []float64 // weighted mean square of every 3 s window
+}
+
+// NewMeter creates a meter for the given sample rate and channel count. With
+// five or more channels the order is taken to be L, R, C, LFE, Ls, Rs.
+func NewMeter(rate, channels int) *Meter {
+	m := &Meter{
+		channels:    channels,
+		weights:     make([]float64, channels),
+		filters:     make([]kFilter, channels),
+		peaks:       make([]*truePeak, channels),
+		subBlockLen: max(1, rate/10),
+		subSum:      make([]float64, channels),
+	}
+	for ch := 0; ch < channels; ch++ {
+		m.weights[ch] = channelWeight(ch, channels)
+		m.filters[ch] = newKFilter(float64(rate))
+		m.peaks[ch] = newTruePeak(rate)
+	}
+	return m
+}
+
+// channelWeight is the BS.1770 weight of a channel; the LFE is excluded and
+// surround channels count +1.5 dB
+func channelWeight(ch, channels int) float64 {
+	if channels < 5 {
+		return 1
+	}
+	switch ch {
+	case 3:
+		return 0
+	case 4, 5:
+		return 1.41
+	}
+	return 1
+}
+
+// Write feeds interleaved samples. Frames may be split across calls.
+func (m *Meter) Write(samples []int16) {
+	for _, sample := range samples {
+		ch := m.frameFill
+		x := float64(sample) / 32768
+		m.peaks[ch].push(x)
+		y := m.filters[ch].process(x)
+		m.subSum[ch] += y * y
+
+		m.frameFill++
+		if m.frameFill < m.channels {
+			continue
+		}
+		m.frameFill = 0
+		m.subFill++
+		if m.subFill == m.subBlockLen {
+			m.endSubBlock()
+		}
+	}
+}
+
+// endSubBlock closes a 100 ms sub-block and emits the blocks and short-term
+// windows ending with it
+func (m *Meter) endSubBlock() {
+	var z float64
+	for ch := range m.subSum {
+		z += m.weights[ch] * m.subSum[ch] / float64(m.subBlockLen)
+		m.subSum[ch] = 0
+	}
+	m.subFill = 0
+
+	m.recent = append(m.recent, z)
+	if len(m.recent) > shortTermSubBlocks {
+		m.recent = m.recent[1:]
+	}
+	if len(m.recent) >= blockSubBlocks {
+		m.blocks = append(m.blocks, mean(m.recent[len(m.recent)-blockSubBlocks:]))
+	}
+	if len(m.recent) == shortTermSubBlocks {
+		m.shortTerm = append(m.shortTerm, mean(m.recent))
+	}
+}
+
+// Result returns the measurement of everything written so far
+func (m *Meter) Result() Result {
+	r := Result{
+		Integrated: SilenceLUFS,
+		TruePeak:   SilenceDBTP,
+	}
+
+	if gated := gate(m.blocks, integratedRelative); len(gated) > 0 {
+		r.Integrated = energyToLUFS(mean(gated))
+	}
+
+	if gated := gate(m.shortTerm, rangeRelative); len(gated) > 0 {
+		levels := make([]float64, len(gated))
+		for i, z := range gated {
+			levels[i] = energyToLUFS(z)
+		}
+		sort.Float64s(levels)
+		r.Range = percentile(levels, 0.95) - percentile(levels, 0.10)
+	}
+
+	var peak float64
+	for _, p := range m.peaks {
+		peak = math.Max(peak, p.max)
+	}
+	if peak > 0 {
+		r.TruePeak = max(20*math.Log10(peak), SilenceDBTP)
+	}
+	return r
+}
+
+// gate applies the absolute gate and then a gate relative LU below the
+// mean of what passed the absolute one
+func gate(energies []float64, relative float64) []float64 {
+	var absolute []float64
+	for _, z := range energies {
+		if energyToLUFS(z) > absoluteGate {
+			absolute = append(absolute, z)
+		}
+	}
+	if len(absolute) == 0 {
+		return nil
+	}
+
+	threshold := energyToLUFS(mean(absolute)) + relative
+	var gated []float64
+	for _, z := range absolute {
+		if energyToLUFS(z) > threshold {
+			gated = append(gated, z)
+		}
+	}
+	return gated
+}
+
+func energyToLUFS(z float64) float64 {
+	if z <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(z)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile of sorted values, interpolating between neighbours
+func percentile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// biquad is a second-order IIR section in transposed direct form II
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+// kFilter is the BS.1770 K-weighting: a high shelf modelling the head
+// followed by the RLB high-pass
+type kFilter struct {
+	shelf, highPass biquad
+}
+
+// newKFilter derives the K-weighting coefficients for any sample rate from
+// the analog prototypes, matching the published 48 kHz coefficients
+func newKFilter(rate float64) kFilter {
+	const (
+		shelfFreq = 1681.974450955533
+		shelfGain = 3.999843853973347
+		shelfQ    = 0.7071752369554196
+		passFreq  = 38.13547087602444
+		passQ     = 0.5003270373238773
+	)
+
+	k := math.Tan(math.Pi * shelfFreq / rate)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * passFreq / rate)
+	a0 = 1 + k/passQ + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/passQ + k*k) / a0,
+	}
+	return kFilter{shelf: shelf, highPass: highPass}
+}
+
+func (f *kFilter) process(x float64) float64 {
+	return f.highPass.process(f.shelf.process(x))
+}
+
+// truePeakTaps is the interpolation filter length per phase
+const truePeakTaps = 12
+
+// truePeak estimates the peak of the reconstructed signal by oversampling:
+// 4x below 96 kHz and 2x below 192 kHz, as BS.1770-4 Annex 2 recommends
+type truePeak struct {
+	phases  [][]float64 // polyphase interpolation filter; nil without oversampling
+	history []float64   // ring of the latest truePeakTaps samples
+	pos     int
+	max     float64
+}
+
+func newTruePeak(rate int) *truePeak {
+	factor := 4
+	switch {
+	case rate >= 192000:
+		factor = 1
+	case rate >= 96000:
+		factor = 2
+	}
+
+	p := &truePeak{history: make([]float64, truePeakTaps)}
+	if factor == 1 {
+		return p
+	}
+	// Hann-windowed sinc lowpass at the original Nyquist frequency
+	length := truePeakTaps * factor
+	center := float64(length-1) / 2
+	p.phases = make([][]float64, factor)
+	for phase := range p.phases {
+		p.phases[phase] = make([]float64, truePeakTaps)
+		for tap := 0; tap < truePeakTaps; tap++ {
+			n := float64(tap*factor + phase)
+			t := (n - center) / float64(factor)
+			window := 0.5 - 0.5*math.Cos(2*math.Pi*(n+0.5)/float64(length))
+			p.phases[phase][tap] = sinc(t) * window
+		}
+		// Unity gain at DC for every phase
+		var sum float64
+		for _, c := range p.phases[phase] {
+			sum += c
+		}
+		for tap := range p.phases[phase] {
+			p.phases[phase][tap] /= sum
+		}
+	}
+	return p
+}
+
+func (p *truePeak) push(x float64) {
+	p.max = math.Max(p.max, math.Abs(x))
+	if p.phases == nil {
+		return
+	}
+	p.history[p.pos] = x
+	p.pos = (p.pos + 1) % truePeakTaps
+	for _, coeffs := range p.phases {
+		var y float64
+		for tap, c := range coeffs {
+			// Oldest sample meets the first tap
+			y += c * p.history[(p.pos+tap)%truePeakTaps]
+		}
+		p.max = math.Max(p.max, math.Abs(y))
+	}
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
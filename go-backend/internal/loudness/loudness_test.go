@@ -0,0 +1,122 @@
+package loudness
+
+import (
+	"math"
+	"testing"
+)
+
+// sine returns interleaved samples of a sine on every channel
+func sine(rate, channels int, freq, dbfs, phase float64, seconds float64) []int16 {
+	amplitude := math.Pow(10, dbfs/20) * 32767
+	n := int(float64(rate) * seconds)
+	out := make([]int16, 0, n*channels)
+	for i := 0; i < n; i++ {
+		v := int16(math.Round(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)+phase)))
+		for ch := 0; ch < channels; ch++ {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func TestKFilterMatchesPublished48kCoefficients(t *testing.T) {
+	f := newKFilter(48000)
+	got := []float64{f.shelf.b0, f.shelf.b1, f.shelf.b2, f.shelf.a1, f.shelf.a2, f.highPass.a1, f.highPass.a2}
+	want := []float64{1.53512485958697, -2.69169618940638, 1.19839281085285, -1.69065929318241, 0.73248077421585, -1.99004745483398, 0.99007225036621}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-8 {
+			t.Fatalf("coefficient %d = %.14f, want %.14f", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIntegratedLoudnessOfReferenceSine(t *testing.T) {
+	// EBU Tech 3341 case 1: stereo 1 kHz at -23 dBFS reads -23 LUFS
+	for _, rate := range []int{44100, 48000} {
+		m := NewMeter(rate, 2)
+		m.Write(sine(rate, 2, 1000, -23, 0, 20))
+		r := m.Result()
+		if math.Abs(r.Integrated+23) > 0.1 {
+			t.Fatalf("%dHz Integrated = %.2f LUFS, want -23", rate, r.Integrated)
+		}
+		if r.Range > 0.1 {
+			t.Fatalf("%dHz Range = %.2f LU, want 0 for a steady tone", rate, r.Range)
+		}
+		if math.Abs(r.TruePeak+23) > 0.1 {
+			t.Fatalf("%dHz TruePeak = %.2f dBTP, want -23", rate, r.TruePeak)
+		}
+	}
+}
+
+func TestLoudnessRangeOfTwoLevels(t *testing.T) {
+	// EBU Tech 3342 case 1: 20 s at -20 dBFS then 20 s at -30 dBFS reads 10 LU
+	m := NewMeter(48000, 2)
+	m.Write(sine(48000, 2, 1000, -20, 0, 20))
+	m.Write(sine(48000, 2, 1000, -30, 0, 20))
+	if r := m.Result(); math.Abs(r.Range-10) > 0.2 {
+		t.Fatalf("Range = %.2f LU, want 10", r.Range)
+	}
+}
+
+func TestTruePeakFindsInterSamplePeaks(t *testing.T) {
+	// A quarter-rate sine offset by 45 degrees never samples its crest: the
+	// sample peak is 3 dB under the true peak
+	m := NewMeter(48000, 1)
+	m.Write(sine(48000, 1, 12000, -6, math.Pi/4, 1))
+	r := m.Result()
+	if math.Abs(r.TruePeak+6) > 0.3 {
+		t.Fatalf("TruePeak = %.2f dBTP, want about -6", r.TruePeak)
+	}
+}
+
+func TestSilenceAndShortInput(t *testing.T) {
+	m := NewMeter(44100, 2)
+	m.Write(make([]int16, 44100*2*2))
+	r := m.Result()
+	if r.Integrated != SilenceLUFS || r.TruePeak != SilenceDBTP || r.Gain() != 0 {
+		t.Fatalf("silence = %+v gain %.2f, want gated silence without gain", r, r.Gain())
+	}
+
+	m = NewMeter(44100, 1)
+	m.Write(sine(44100, 1, 1000, -10, 0, 0.2))
+	if r := m.Result(); r.Integrated != SilenceLUFS || r.Range != 0 {
+		t.Fatalf("200ms input = %+v, want no complete block", r)
+	}
+}
+
+func TestGainTargetsReferenceWithoutClipping(t *testing.T) {
+	quiet := Result{Integrated: -30, TruePeak: -20}
+	if got := quiet.Gain(); math.Abs(got-12) > 1e-9 {
+		t.Fatalf("Gain() = %.2f, want 12", got)
+	}
+	loud := Result{Integrated: -8, TruePeak: 0.5}
+	if got := loud.Gain(); math.Abs(got+10) > 1e-9 {
+		t.Fatalf("Gain() = %.2f, want -10", got)
+	}
+	peaky := Result{Integrated: -30, TruePeak: -3}
+	if got := peaky.Gain(); math.Abs(got-3) > 1e-9 {
+		t.Fatalf("Gain() = %.2f, want 3 to keep the peak at 0 dBTP", got)
+	}
+}
+
+func TestSurroundWeights(t *testing.T) {
+	if channelWeight(3, 6) != 0 || channelWeight(4, 6) != 1.41 || channelWeight(1, 2) != 1 {
+		t.Fatal("channelWeight() does not follow BS.1770")
+	}
+}
+
+func TestWriteAcceptsFramesSplitAcrossCalls(t *testing.T) {
+	samples := sine(44100, 3, 440, -12, 0, 5)
+	whole := NewMeter(44100, 3)
+	whole.Write(samples)
+
+	split := NewMeter(44100, 3)
+	for len(samples) > 0 {
+		n := min(4096, len(samples))
+		split.Write(samples[:n])
+		samples = samples[n:]
+	}
+	if whole.Result() != split.Result() {
+		t.Fatalf("split writes = %+v, want %+v", split.Result(), whole.Result())
+	}
+}
@@ -0,0 +1,277 @@
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// DefaultGCGrace keeps files imported moments ago from being collected
+// before the track referencing them is saved
+const DefaultGCGrace = 10 * time.Minute
+
+// tmpPrefix marks imports still being copied
+const tmpPrefix = ".import-"
+
+// ErrNotFound is returned for files the store does not hold
+var ErrNotFound = errors.New("media not found")
+
+// Store keeps imported audio content-addressed by the SHA256 of the file
+// under <data dir>/media. A file imported again, for any track, is stored
+// once; tracks reference it by hash and Store.GC removes files no track
+// references any more.
+type Store struct {
+	dir string
+}
+
+// Entry is one stored file
+type Entry struct {
+	Hash    string    `json:"hash"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// GCResult reports a garbage collection
+type GCResult struct {
+	Removed    int   `json:"removed"`
+	FreedBytes int64 `json:"freed_bytes"`
+}
+
+// New creates a media store under dataDir
+func New(dataDir string) (*Store, error) {
+	dir := filepath.Join(dataDir, "media")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create media dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory the store keeps files in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Import copies a file into the store and returns its hash and stored
+// path. The copy is hashed as it is written; content already in the store
+// is not stored twice, and the existing file is returned instead.
+func (s *Store) Import(sourcePath string) (hash string, path string, err error) {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open source: %w", err)
+	}
+	defer source.Close()
+
+	tmp, err := os.CreateTemp(s.dir, tmpPrefix+"*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create media file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	sum := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, sum), source); err != nil {
+		tmp.Close()
+		return "", "", fmt.Errorf("failed to copy: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to copy: %w", err)
+	}
+	hash = hex.EncodeToString(sum.Sum(nil))
+	return s.adopt(tmp.Name(), hash, strings.ToLower(filepath.Ext(sourcePath)))
+}
+
+// CreateTemp creates an empty file in the store for content written
+// elsewhere, such as a fetch, before Adopt takes it over. GC removes it if
+// it is abandoned.
+func (s *Store) CreateTemp() (string, error) {
+	tmp, err := os.CreateTemp(s.dir, tmpPrefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create media file: %w", err)
+	}
+	return tmp.Name(), tmp.Close()
+}
+
+// Adopt moves a file created by CreateTemp into the store under its hash
+// with extension ext, deduplicating like Import
+func (s *Store) Adopt(tmpPath string, ext string) (hash string, path string, err error) {
+	defer os.Remove(tmpPath)
+
+	file, err := os.Open(tmpPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open media file: %w", err)
+	}
+	sum := sha256.New()
+	_, err = io.Copy(sum, file)
+	file.Close()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash media file: %w", err)
+	}
+	return s.adopt(tmpPath, hex.EncodeToString(sum.Sum(nil)), ext)
+}
+
+// adopt renames a hashed temporary file into place unless the content is
+// already stored
+func (s *Store) adopt(tmpPath string, hash string, ext string) (string, string, error) {
+	if existing, err := s.Path(hash); err == nil {
+		// Refresh the mtime so a concurrent GC grants the new reference its grace
+		now := time.Now()
+		os.Chtimes(existing, now, now)
+		return hash, existing, nil
+	}
+
+	// The extension keeps stored files recognizable to users and tools
+	path := filepath.Join(s.dir, hash[:2], hash+ext)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create media dir: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", "", fmt.Errorf("failed to store media file: %w", err)
+	}
+	return hash, path, nil
+}
+
+// Path returns the stored file with a hash
+func (s *Store) Path(hash string) (string, error) {
+	if !validHash(hash) {
+		return "", ErrNotFound
+	}
+	matches, err := filepath.Glob(filepath.Join(s.dir, hash[:2], hash+"*"))
+	if err != nil {
+		return "", err
+	}
+	for _, match := range matches {
+		if isMediaFile(filepath.Base(match)) {
+			return match, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// List returns every stored file
+func (s *Store) List() ([]Entry, error) {
+	var entries []Entry
+	err := s.walk(func(path string, info fs.FileInfo) error {
+		name := filepath.Base(path)
+		if !isMediaFile(name) {
+			return nil
+		}
+		entries = append(entries, Entry{
+			Hash:    name[:sha256.Size*2],
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// Remove deletes a stored file and the waveform beside it
+func (s *Store) Remove(hash string) (int64, error) {
+	path, err := s.Path(hash)
+	if err != nil {
+		return 0, err
+	}
+	var freed int64
+	for _, p := range []string{path, waveform.PathFor(path)} {
+		info, err := os.Stat(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return freed, err
+		}
+		if err := os.Remove(p); err != nil {
+			return freed, fmt.Errorf("failed to remove media file: %w", err)
+		}
+		freed += info.Size()
+	}
+	return freed, nil
+}
+
+// GC removes the files referenced reports no track uses, and abandoned
+// partial imports, unless they were written within grace
+func (s *Store) GC(referenced func(hash string) (bool, error), grace time.Duration) (GCResult, error) {
+	var result GCResult
+	cutoff := time.Now().Add(-grace)
+	err := s.walk(func(path string, info fs.FileInfo) error {
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+		name := filepath.Base(path)
+		if strings.HasPrefix(name, tmpPrefix) {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove partial import: %w", err)
+			}
+			result.Removed++
+			result.FreedBytes += info.Size()
+			return nil
+		}
+		if !isMediaFile(name) {
+			return nil
+		}
+		hash := name[:sha256.Size*2]
+		used, err := referenced(hash)
+		if err != nil || used {
+			return err
+		}
+		freed, err := s.Remove(hash)
+		if err != nil {
+			return err
+		}
+		result.Removed++
+		result.FreedBytes += freed
+		return nil
+	})
+	return result, err
+}
+
+// walk visits the regular files in the store
+func (s *Store) walk(fn func(path string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // removed while walking
+		}
+		if err != nil {
+			return err
+		}
+		return fn(path, info)
+	})
+}
+
+// isMediaFile reports whether a file name is a stored file: a hash with an
+// optional extension, not a waveform
+func isMediaFile(name string) bool {
+	if len(name) < sha256.Size*2 || !validHash(name[:sha256.Size*2]) {
+		return false
+	}
+	rest := name[sha256.Size*2:]
+	if rest == "" {
+		return true
+	}
+	return rest[0] == '.' && !strings.Contains(rest[1:], ".") && rest != waveform.FileExt
+}
+
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
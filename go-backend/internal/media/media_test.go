@@ -0,0 +1,101 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+func writeSource(t *testing.T, name string, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	return path
+}
+
+func TestImportDeduplicatesContent(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	hash1, path1, err := store.Import(writeSource(t, "song.MP3", "same audio"))
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	hash2, path2, err := store.Import(writeSource(t, "copy of song.mp3", "same audio"))
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if hash1 != hash2 || path1 != path2 {
+		t.Fatalf("Import() of identical content = %s %s and %s %s, want the same file", hash1, path1, hash2, path2)
+	}
+	if filepath.Ext(path1) != ".mp3" {
+		t.Fatalf("stored path = %s, want the lowercased source extension", path1)
+	}
+	if data, err := os.ReadFile(path1); err != nil || string(data) != "same audio" {
+		t.Fatalf("stored file = %q, %v; want the source content", data, err)
+	}
+
+	hash3, _, err := store.Import(writeSource(t, "other.flac", "other audio"))
+	if err != nil || hash3 == hash1 {
+		t.Fatalf("Import() of other content = %s, %v; want a new hash", hash3, err)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 2 {
+		t.Fatalf("List() = %+v, %v; want two files", entries, err)
+	}
+	if got, err := store.Path(hash1); err != nil || got != path1 {
+		t.Fatalf("Path() = %s, %v; want %s", got, err, path1)
+	}
+}
+
+func TestGCRemovesUnreferencedFiles(t *testing.T) {
+	store, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	kept, _, err := store.Import(writeSource(t, "kept.mp3", "kept"))
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	orphan, orphanPath, err := store.Import(writeSource(t, "orphan.mp3", "orphan"))
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if err := os.WriteFile(waveform.PathFor(orphanPath), []byte("peaks"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	partial := filepath.Join(store.Dir(), tmpPrefix+"abandoned")
+	if err := os.WriteFile(partial, []byte("half"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	referenced := func(hash string) (bool, error) { return hash == kept, nil }
+
+	// Everything is within the grace period
+	if result, err := store.GC(referenced, time.Hour); err != nil || result.Removed != 0 {
+		t.Fatalf("GC() within grace = %+v, %v; want nothing removed", result, err)
+	}
+
+	result, err := store.GC(referenced, -time.Second)
+	if err != nil {
+		t.Fatalf("GC() error: %v", err)
+	}
+	if result.Removed != 2 || result.FreedBytes != int64(len("orphan")+len("peaks")+len("half")) {
+		t.Fatalf("GC() = %+v, want the orphan with its waveform and the partial import", result)
+	}
+	if _, err := store.Path(orphan); err != ErrNotFound {
+		t.Fatalf("Path(orphan) error = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(waveform.PathFor(orphanPath)); !os.IsNotExist(err) {
+		t.Fatalf("orphan waveform error = %v, want removed", err)
+	}
+	if _, err := store.Path(kept); err != nil {
+		t.Fatalf("Path(kept) error: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+// JobState is the lifecycle stage of a CTR job. A job that succeeds is
+// deleted, so there is no done state.
+type JobState string
+
+const (
+	JobQueued   JobState = "queued"   // Waiting for a worker, possibly until NextAttempt
+	JobDecoding JobState = "decoding" // Opening and probing the audio file
+	JobHashing  JobState = "hashing"  // Streaming decoded PCM into the CTID hash and fingerprint
+	JobFailed   JobState = "failed"   // Gave up; retried only on request
+)
+
+// Job is a persisted request to compute the CTID of a track. There is at
+// most one job per track, so ID equals TrackID.
+type Job struct {
+	ID          string   `json:"id"`
+	TrackID     string   `json:"track_id"`
+	State       JobState `json:"state"`
+	Attempts    int      `json:"attempts"`
+	LastError   string   `json:"last_error,omitempty"`
+	NextAttempt int64    `json:"next_attempt,omitempty"` // Unix time before which a queued job is not run
+	CreatedAt   int64    `json:"created_at"`             // Unix time
+	UpdatedAt   int64    `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Playlist is an ordered list of tracks referenced by CTID, so it resolves
+// on any peer that can find providers for them
+type Playlist struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Entries     []PlaylistEntry `json:"entries"`
+	Author      string          `json:"author,omitempty"`    // Peer ID of the author of a playlist saved from the network; empty for own playlists
+	Shared      bool            `json:"shared"`              // Record announced in DHT
+	RecordID    string          `json:"record_id,omitempty"` // SHA256 of the last published record; changes with every edit
+	CreatedAt   int64           `json:"created_at"`          // Unix time
+	UpdatedAt   int64           `json:"updated_at"`
+}
+
+// PlaylistEntry is one track of a playlist. Title, artist and duration let
+// a playlist be shown before its CTIDs are resolved.
+type PlaylistEntry struct {
+	CTID       string `json:"ctid"`
+	Title      string `json:"title,omitempty"`
+	Artist     string `json:"artist,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// Settings are the daemon settings changed at runtime, kept across
+// restarts and carried by backups
+type Settings struct {
+	StorageBudget int64    `json:"storage_budget"`          // Media store bytes before cached replicas are evicted; 0 is unlimited
+	WatchFolders  []string `json:"watch_folders,omitempty"` // Folders whose audio files are imported in place
+}
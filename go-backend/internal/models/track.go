@@ -2,11 +2,143 @@ package models
 
 // Track represents a music track
 type Track struct {
-	ID         string `json:"id"`
-	CTID       string `json:"ctid"` // Canonical Track ID (SHA256 of normalized PCM)
-	Title      string `json:"title"`
-	Artist     string `json:"artist"`
-	Path       string `json:"path"`       // Local file path
-	Liked      bool   `json:"liked"`      // User liked this track
-	Recognized bool   `json:"recognized"` // User has entered title/artist
+	ID             string       `json:"id"`
+	CTID           string       `json:"ctid"`                          // Canonical Track ID (SHA256 of normalized PCM)
+	CTIDVersion    int          `json:"ctid_version,omitempty"`        // Algorithm that produced CTID; 0 for tracks from before versioning (v1)
+	LegacyCTID     string       `json:"legacy_ctid,omitempty"`         // CTID under the previous algorithm, announced until LegacyExpires
+	LegacyExpires  int64        `json:"legacy_ctid_expires,omitempty"` // Unix time the transition window for LegacyCTID ends
+	Title          string       `json:"title"`
+	Artist         string       `json:"artist"`
+	Album          string       `json:"album,omitempty"`
+	TrackNumber    int          `json:"track_number,omitempty"`
+	Year           int          `json:"year,omitempty"`
+	Genre          string       `json:"genre,omitempty"`
+	Path           string       `json:"path"`                      // Local file path
+	MediaHash      string       `json:"media_hash,omitempty"`      // SHA256 of the file in the managed media store; empty for files referenced in place
+	FileHash       string       `json:"file_hash,omitempty"`       // SHA256 of a file referenced in place, recorded for files from watched folders
+	Origin         TrackOrigin  `json:"origin,omitempty"`          // How the file got here; empty for imports
+	LastAccess     int64        `json:"last_access,omitempty"`     // Unix time the file was last fetched or served
+	Serves         int          `json:"serves,omitempty"`          // Times the file was streamed to peers
+	Liked          bool         `json:"liked"`                     // User liked this track
+	Recognized     bool         `json:"recognized"`                // User has entered title/artist
+	Unshared       bool         `json:"unshared,omitempty"`        // User stopped sharing; not announced or served
+	FingerprintKey string       `json:"fingerprint_key,omitempty"` // Coarse key shared by near-identical recordings
+	Format         *AudioFormat `json:"format,omitempty"`          // Format probed from the file content
+	DurationMs     int64        `json:"duration_ms,omitempty"`     // Length of the decoded audio
+	Bitrate        int          `json:"bitrate,omitempty"`         // Average bits per second over the whole file
+	FileSize       int64        `json:"file_size,omitempty"`       // Bytes; zero until CTR has processed the file
+	FileModTime    int64        `json:"file_mtime,omitempty"`      // Unix time the file was last modified when CTR processed it
+	Broken         string       `json:"broken,omitempty"`          // Problem verification found with the file; not announced or served until fixed
+	Artwork        string       `json:"artwork,omitempty"`         // SHA256 of the embedded cover in the artwork store
+	Loudness       *Loudness    `json:"loudness,omitempty"`        // Nil until CTR has measured the file
+	Waveform       bool         `json:"waveform,omitempty"`        // A peaks file is stored beside Path
+	NoWaveform     bool         `json:"no_waveform,omitempty"`     // CTR found no audio to draw peaks from; not retried until the content changes
+}
+
+// TrackOrigin tells user-owned files from cached copies of network tracks
+type TrackOrigin string
+
+const (
+	OriginImport  TrackOrigin = "import"  // Added by the user; never evicted
+	OriginReplica TrackOrigin = "replica" // Fetched from a peer into the cache; evictable unless liked
+)
+
+// IsReplica reports whether the track is a cached copy fetched from the
+// network rather than a user import
+func (t *Track) IsReplica() bool {
+	return t.Origin == OriginReplica
+}
+
+// IsShared reports whether the track is announced and served to peers:
+// processed, recognized, not unshared by the user and not broken
+func (t *Track) IsShared() bool {
+	return t.CTID != "" && t.Recognized && !t.Unshared && t.Broken == ""
+}
+
+// ResetContent clears what CTR derived from the content of the track's
+// file, for a file edited since, so that CTR analyses it again
+func (t *Track) ResetContent() {
+	t.CTID, t.CTIDVersion = "", 0
+	t.LegacyCTID, t.LegacyExpires = "", 0
+	t.FingerprintKey = ""
+	t.FileSize, t.FileModTime = 0, 0
+	t.NoWaveform = false
+}
+
+// Loudness is an EBU R128 measurement of a track
+type Loudness struct {
+	IntegratedLUFS float64 `json:"integrated_lufs"`
+	RangeLU        float64 `json:"range_lu"`
+	TruePeakDBTP   float64 `json:"true_peak_dbtp"`
+	// GainDB brings the track to the ReplayGain 2.0 reference of -18 LUFS
+	// without pushing the true peak over 0 dBTP; players apply it as is
+	GainDB float64 `json:"gain_db"`
+}
+
+// AudioFormat describes the encoded audio of a track file
+type AudioFormat struct {
+	Container  string `json:"container"`   // e.g. "mpeg", "flac", "ogg", "mp4"
+	Codec      string `json:"codec"`       // e.g. "mp3", "flac", "vorbis", "aac"
+	SampleRate int    `json:"sample_rate"` // Hz
+	Channels   int    `json:"channels"`
+}
+
+// Properties returns the technical properties of a track's local copy
+func (t *Track) Properties() AudioProperties {
+	p := AudioProperties{
+		DurationMs: t.DurationMs,
+		Bitrate:    t.Bitrate,
+		FileSize:   t.FileSize,
+		Loudness:   t.Loudness,
+	}
+	if t.Format != nil {
+		p.Codec = t.Format.Codec
+		p.SampleRate = t.Format.SampleRate
+		p.Channels = t.Format.Channels
+	}
+	return p
+}
+
+// AudioProperties describes one copy of a recording. Peers holding the same
+// CTID may have it in different codecs and bitrates.
+type AudioProperties struct {
+	Codec      string    `json:"codec,omitempty"`
+	SampleRate int       `json:"sample_rate,omitempty"`
+	Channels   int       `json:"channels,omitempty"`
+	Bitrate    int       `json:"bitrate,omitempty"` // Average bits per second
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	FileSize   int64     `json:"file_size,omitempty"`
+	Loudness   *Loudness `json:"loudness,omitempty"`
+}
+
+// losslessCodecs are codecs that reproduce the source PCM exactly
+var losslessCodecs = map[string]bool{"flac": true, "alac": true, "pcm": true}
+
+// Lossless reports whether the codec reproduces the source exactly
+func (p AudioProperties) Lossless() bool {
+	return losslessCodecs[p.Codec]
+}
+
+func (p AudioProperties) known() bool {
+	return p.Codec != "" && p.Codec != "unknown"
+}
+
+// BetterThan reports whether p is a higher-quality copy than q. A known
+// codec beats an unknown one and lossless beats lossy. Lossy copies are
+// then ranked by bitrate; lossless ones, whose bitrate only reflects
+// compression, by sample rate. Channel count breaks remaining ties.
+func (p AudioProperties) BetterThan(q AudioProperties) bool {
+	if p.known() != q.known() {
+		return p.known()
+	}
+	if p.Lossless() != q.Lossless() {
+		return p.Lossless()
+	}
+	if !p.Lossless() && p.Bitrate != q.Bitrate {
+		return p.Bitrate > q.Bitrate
+	}
+	if p.SampleRate != q.SampleRate {
+		return p.SampleRate > q.SampleRate
+	}
+	return p.Channels > q.Channels
 }
@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestAudioPropertiesBetterThan(t *testing.T) {
+	flac := AudioProperties{Codec: "flac", SampleRate: 44100, Channels: 2, Bitrate: 900000}
+	hiresFLAC := AudioProperties{Codec: "flac", SampleRate: 96000, Channels: 2, Bitrate: 800000}
+	mp3High := AudioProperties{Codec: "mp3", SampleRate: 44100, Channels: 2, Bitrate: 320000}
+	mp3Low := AudioProperties{Codec: "mp3", SampleRate: 48000, Channels: 2, Bitrate: 128000}
+	mono := AudioProperties{Codec: "mp3", SampleRate: 44100, Channels: 1, Bitrate: 320000}
+	unknown := AudioProperties{}
+
+	tests := []struct {
+		name   string
+		better AudioProperties
+		worse  AudioProperties
+	}{
+		{"lossless beats lossy", flac, mp3High},
+		{"lossless ranked by sample rate", hiresFLAC, flac},
+		{"lossy ranked by bitrate", mp3High, mp3Low},
+		{"channels break ties", mp3High, mono},
+		{"known beats unknown", mp3Low, unknown},
+		{"unknown codec name is unknown", mp3Low, AudioProperties{Codec: "unknown", Bitrate: 999000}},
+	}
+	for _, tc := range tests {
+		if !tc.better.BetterThan(tc.worse) {
+			t.Fatalf("%s: %+v.BetterThan(%+v) = false, want true", tc.name, tc.better, tc.worse)
+		}
+		if tc.worse.BetterThan(tc.better) {
+			t.Fatalf("%s: %+v.BetterThan(%+v) = true, want false", tc.name, tc.worse, tc.better)
+		}
+	}
+	if flac.BetterThan(flac) {
+		t.Fatal("BetterThan() of equal properties = true, want false")
+	}
+}
@@ -0,0 +1,111 @@
+package playlist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// RecordVersion is the layout of records this build publishes and reads
+const RecordVersion = 1
+
+// MaxRecordSize bounds the records peers accept
+const MaxRecordSize = 1 << 20
+
+// MaxEntries bounds the tracks of one playlist
+const MaxEntries = 5000
+
+// Record is the published form of a playlist. Its ID is the SHA256 of its
+// encoding, so a record fetched from any peer can be verified, and editing
+// a playlist publishes a new record under a new ID.
+type Record struct {
+	Version     int                    `json:"version"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Author      string                 `json:"author"` // Peer ID
+	Entries     []models.PlaylistEntry `json:"entries"`
+}
+
+// NewRecord builds the record of a playlist. Playlists saved from the
+// network keep their author; own playlists are authored by self.
+func NewRecord(playlist *models.Playlist, self string) Record {
+	author := playlist.Author
+	if author == "" {
+		author = self
+	}
+	entries := playlist.Entries
+	if entries == nil {
+		entries = []models.PlaylistEntry{}
+	}
+	return Record{
+		Version:     RecordVersion,
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		Author:      author,
+		Entries:     entries,
+	}
+}
+
+// Encode returns the record's ID and encoding
+func (r Record) Encode() (string, []byte, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode playlist record: %w", err)
+	}
+	return RecordID(data), data, nil
+}
+
+// RecordID returns the ID of an encoded record
+func RecordID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Decode parses an encoded record, verifying it against the ID it was
+// requested by
+func Decode(id string, data []byte) (*Record, error) {
+	if len(data) > MaxRecordSize {
+		return nil, fmt.Errorf("playlist record too large: %d bytes", len(data))
+	}
+	if RecordID(data) != id {
+		return nil, fmt.Errorf("playlist record does not match ID %s", id)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("invalid playlist record: %w", err)
+	}
+	if record.Version != RecordVersion {
+		return nil, fmt.Errorf("unsupported playlist record version: %d", record.Version)
+	}
+	if err := ValidateEntries(record.Entries); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ValidateEntries checks that entries fit a record and reference tracks by
+// well-formed CTIDs
+func ValidateEntries(entries []models.PlaylistEntry) error {
+	if len(entries) > MaxEntries {
+		return fmt.Errorf("playlist has %d tracks, at most %d are allowed", len(entries), MaxEntries)
+	}
+	for i, entry := range entries {
+		if !ValidID(entry.CTID) {
+			return fmt.Errorf("entry %d: invalid CTID: %q", i, entry.CTID)
+		}
+	}
+	return nil
+}
+
+// ValidID reports whether id is a hex SHA256, as CTIDs and record IDs are
+func ValidID(id string) bool {
+	if len(id) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,60 @@
+package playlist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+func TestRecordRoundTripAndVerification(t *testing.T) {
+	playlist := &models.Playlist{
+		ID:   "local-id",
+		Name: "Road Trip",
+		Entries: []models.PlaylistEntry{
+			{CTID: strings.Repeat("b", 64), Title: "Second", Artist: "Band"},
+			{CTID: strings.Repeat("a", 64), Title: "First", Artist: "Band", DurationMs: 180000},
+		},
+		CreatedAt: 100,
+		UpdatedAt: 200,
+	}
+
+	id, data, err := NewRecord(playlist, "peer-self").Encode()
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	record, err := Decode(id, data)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if record.Author != "peer-self" || record.Name != playlist.Name || len(record.Entries) != 2 || record.Entries[0] != playlist.Entries[0] {
+		t.Fatalf("Decode() = %+v, want the playlist in order, authored by self", record)
+	}
+
+	// Local bookkeeping does not change the record
+	playlist.ID, playlist.UpdatedAt, playlist.Shared = "other-id", 300, true
+	if again, _, _ := NewRecord(playlist, "peer-self").Encode(); again != id {
+		t.Fatalf("record ID changed to %s by local fields, want %s", again, id)
+	}
+	// Saved playlists keep their author
+	playlist.Author = "peer-friend"
+	if record := NewRecord(playlist, "peer-self"); record.Author != "peer-friend" {
+		t.Fatalf("NewRecord() author = %s, want peer-friend", record.Author)
+	}
+
+	tampered := []byte(strings.Replace(string(data), "Road Trip", "Road Trap", 1))
+	if _, err := Decode(id, tampered); err == nil {
+		t.Fatal("Decode() accepted a record that does not match its ID")
+	}
+}
+
+func TestValidateEntriesRejectsMalformedCTIDs(t *testing.T) {
+	if err := ValidateEntries([]models.PlaylistEntry{{CTID: strings.Repeat("a", 64)}}); err != nil {
+		t.Fatalf("ValidateEntries(valid) error: %v", err)
+	}
+	for _, ctid := range []string{"", "not-hex", strings.Repeat("z", 64), strings.Repeat("a", 63)} {
+		if err := ValidateEntries([]models.PlaylistEntry{{CTID: ctid}}); err == nil {
+			t.Fatalf("ValidateEntries(%q) error = nil, want invalid", ctid)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cotune/go-backend/internal/media"
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// Category is what a stored file counts as against the budget. A file
+// shared by several tracks takes the most protected category among them.
+type Category string
+
+const (
+	CategoryOwned        Category = "owned"        // Referenced by a user import
+	CategoryLiked        Category = "liked"        // Only replicas, at least one of them liked
+	CategoryCache        Category = "cache"        // Only unliked replicas; evictable
+	CategoryUnreferenced Category = "unreferenced" // No track; removed by media GC
+)
+
+// ServeWeight is how much recency one serve to a peer is worth when
+// ranking cached files for eviction, so popular files outlive idle ones
+const ServeWeight = 24 * time.Hour
+
+// maxCountedServes stops a burst of serves long ago from pinning a file
+const maxCountedServes = 30
+
+// File is a file in the media store with the tracks referencing it
+type File struct {
+	media.Entry
+	Category Category        `json:"category"`
+	Tracks   []*models.Track `json:"-"`
+}
+
+// Bucket totals one category
+type Bucket struct {
+	Files  int   `json:"files"`
+	Tracks int   `json:"tracks"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// Usage reports how the media store is used against the budget
+type Usage struct {
+	BudgetBytes int64               `json:"budget_bytes"` // 0 means unlimited
+	UsedBytes   int64               `json:"used_bytes"`
+	Categories  map[Category]Bucket `json:"categories"`
+	InPlace     Bucket              `json:"in_place"` // Files referenced where they are, outside the budget
+}
+
+// Eviction reports the cached files removed to fit the budget
+type Eviction struct {
+	Files      int      `json:"files"`
+	Tracks     int      `json:"tracks"`
+	FreedBytes int64    `json:"freed_bytes"`
+	CTIDs      []string `json:"ctids,omitempty"` // No longer provided
+}
+
+// sizeUnits are the suffixes ParseSize accepts, in powers of 1024
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a budget such as "512MB", "20GB" or a plain number of
+// bytes. Units are powers of 1024; "0" means unlimited.
+func ParseSize(text string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(text))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %q", text)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// Classify groups tracks by the media file they reference and categorizes
+// each file. Tracks referencing files in place are returned separately.
+func Classify(entries []media.Entry, tracks []*models.Track) (files []File, inPlace []*models.Track) {
+	byHash := make(map[string][]*models.Track)
+	for _, track := range tracks {
+		if track.MediaHash == "" {
+			inPlace = append(inPlace, track)
+			continue
+		}
+		byHash[track.MediaHash] = append(byHash[track.MediaHash], track)
+	}
+
+	files = make([]File, 0, len(entries))
+	for _, entry := range entries {
+		refs := byHash[entry.Hash]
+		files = append(files, File{Entry: entry, Category: categorize(refs), Tracks: refs})
+	}
+	return files, inPlace
+}
+
+func categorize(tracks []*models.Track) Category {
+	if len(tracks) == 0 {
+		return CategoryUnreferenced
+	}
+	category := CategoryCache
+	for _, track := range tracks {
+		if !track.IsReplica() {
+			return CategoryOwned
+		}
+		if track.Liked {
+			category = CategoryLiked
+		}
+	}
+	return category
+}
+
+// Measure totals files by category
+func Measure(files []File, inPlace []*models.Track, budget int64) Usage {
+	usage := Usage{
+		BudgetBytes: budget,
+		Categories:  make(map[Category]Bucket),
+	}
+	for _, category := range []Category{CategoryOwned, CategoryLiked, CategoryCache, CategoryUnreferenced} {
+		usage.Categories[category] = Bucket{}
+	}
+	for _, file := range files {
+		bucket := usage.Categories[file.Category]
+		bucket.Files++
+		bucket.Tracks += len(file.Tracks)
+		bucket.Bytes += file.Size
+		usage.Categories[file.Category] = bucket
+		usage.UsedBytes += file.Size
+	}
+	for _, track := range inPlace {
+		usage.InPlace.Files++
+		usage.InPlace.Tracks++
+		usage.InPlace.Bytes += track.FileSize
+	}
+	return usage
+}
+
+// PlanEviction picks the cached files to remove, least valuable first,
+// until the store fits the budget. Owned and liked files are never picked,
+// nor files whose hash is in protect. A budget of 0 is unlimited.
+func PlanEviction(files []File, budget int64, protect map[string]bool) []File {
+	if budget <= 0 {
+		return nil
+	}
+	var used int64
+	var candidates []File
+	for _, file := range files {
+		used += file.Size
+		if file.Category == CategoryCache && !protect[file.Hash] {
+			candidates = append(candidates, file)
+		}
+	}
+	if used <= budget {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		si, sj := score(candidates[i]), score(candidates[j])
+		if si != sj {
+			return si < sj
+		}
+		return candidates[i].Hash < candidates[j].Hash
+	})
+	var evict []File
+	for _, file := range candidates {
+		if used <= budget {
+			break
+		}
+		evict = append(evict, file)
+		used -= file.Size
+	}
+	return evict
+}
+
+// score ranks a cached file by its most recent access, moved forward by
+// ServeWeight for every serve
+func score(file File) int64 {
+	var best int64
+	for _, track := range file.Tracks {
+		s := track.LastAccess + int64(min(track.Serves, maxCountedServes))*int64(ServeWeight/time.Second)
+		if s > best {
+			best = s
+		}
+	}
+	return best
+}
@@ -0,0 +1,113 @@
+package quota
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/media"
+	"github.com/cotune/go-backend/internal/models"
+)
+
+func hash(c byte) string {
+	return strings.Repeat(string(c), 64)
+}
+
+func TestClassifyAndMeasure(t *testing.T) {
+	entries := []media.Entry{
+		{Hash: hash('a'), Size: 100},
+		{Hash: hash('b'), Size: 200},
+		{Hash: hash('c'), Size: 300},
+		{Hash: hash('d'), Size: 400},
+	}
+	tracks := []*models.Track{
+		// An import and a replica of the same content: the import wins
+		{ID: "import", MediaHash: hash('a')},
+		{ID: "replica-of-import", MediaHash: hash('a'), Origin: models.OriginReplica},
+		{ID: "liked", MediaHash: hash('b'), Origin: models.OriginReplica, Liked: true},
+		{ID: "cached", MediaHash: hash('c'), Origin: models.OriginReplica},
+		{ID: "in-place", Path: "/music/song.mp3", FileSize: 50},
+	}
+
+	files, inPlace := Classify(entries, tracks)
+	want := []Category{CategoryOwned, CategoryLiked, CategoryCache, CategoryUnreferenced}
+	for i, file := range files {
+		if file.Category != want[i] {
+			t.Fatalf("file %d category = %s, want %s", i, file.Category, want[i])
+		}
+	}
+	if len(inPlace) != 1 || inPlace[0].ID != "in-place" {
+		t.Fatalf("in place tracks = %v, want the in-place track", inPlace)
+	}
+
+	usage := Measure(files, inPlace, 500)
+	if usage.UsedBytes != 1000 || usage.BudgetBytes != 500 {
+		t.Fatalf("Measure() = %+v, want 1000 of 500 bytes used", usage)
+	}
+	if owned := usage.Categories[CategoryOwned]; owned.Files != 1 || owned.Tracks != 2 || owned.Bytes != 100 {
+		t.Fatalf("owned = %+v, want one file of two tracks", owned)
+	}
+	if usage.InPlace.Bytes != 50 {
+		t.Fatalf("in place = %+v, want 50 bytes", usage.InPlace)
+	}
+}
+
+func TestPlanEvictionRanksByRecencyAndPopularity(t *testing.T) {
+	day := int64(ServeWeight.Seconds())
+	cached := func(c byte, lastAccess int64, serves int) File {
+		return File{
+			Entry:    media.Entry{Hash: hash(c), Size: 100},
+			Category: CategoryCache,
+			Tracks:   []*models.Track{{Origin: models.OriginReplica, LastAccess: lastAccess, Serves: serves}},
+		}
+	}
+	files := []File{
+		{Entry: media.Entry{Hash: hash('o'), Size: 1000}, Category: CategoryOwned},
+		{Entry: media.Entry{Hash: hash('l'), Size: 1000}, Category: CategoryLiked},
+		cached('a', 10*day, 0), // recent
+		cached('b', 1*day, 0),  // oldest
+		cached('c', 2*day, 0),  // old
+		cached('d', 1*day, 20), // old but popular
+		cached('p', 0, 0),      // protected
+	}
+
+	if plan := PlanEviction(files, 0, nil); plan != nil {
+		t.Fatalf("PlanEviction(unlimited) = %v, want nothing", plan)
+	}
+	if plan := PlanEviction(files, 3000, nil); plan != nil {
+		t.Fatalf("PlanEviction(fits) = %v, want nothing", plan)
+	}
+
+	plan := PlanEviction(files, 2250, map[string]bool{hash('p'): true})
+	var got []string
+	for _, file := range plan {
+		got = append(got, file.Hash[:1])
+	}
+	if strings.Join(got, "") != "bca" {
+		t.Fatalf("PlanEviction() = %v, want b, c then a; popular d kept", got)
+	}
+
+	// Owned and liked files alone over budget: nothing can go
+	if plan := PlanEviction(files[:2], 100, nil); len(plan) != 0 {
+		t.Fatalf("PlanEviction(owned and liked) = %v, want nothing", plan)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"0":       0,
+		"1048576": 1 << 20,
+		"512MB":   512 << 20,
+		"1.5gb":   3 << 29,
+		" 2 TB ":  2 << 40,
+	}
+	for text, want := range tests {
+		if got, err := ParseSize(text); err != nil || got != want {
+			t.Fatalf("ParseSize(%q) = %d, %v; want %d", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"", "lots", "-1GB"} {
+		if _, err := ParseSize(text); err == nil {
+			t.Fatalf("ParseSize(%q) error = nil, want invalid", text)
+		}
+	}
+}
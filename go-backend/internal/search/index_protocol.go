@@ -11,6 +11,8 @@ import (
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/cotune/go-backend/internal/models"
 )
 
 const (
@@ -21,13 +23,24 @@ const (
 // IndexQueryRequest represents a request to query a peer's local index
 type IndexQueryRequest struct {
 	Token string `json:"token"`
+	// FingerprintKey, when set, asks for recordings matching the key instead
+	// of a token. Matching hints carry the full fingerprint for scoring.
+	FingerprintKey string `json:"fingerprint_key,omitempty"`
+	// CTID, when set, asks for the peer's own copy of that recording so its
+	// technical properties can be compared with other providers.
+	CTID string `json:"ctid,omitempty"`
 }
 
-// IndexTrackHint carries metadata for a CTID from a remote peer.
+// IndexTrackHint carries metadata for a CTID from a remote peer. The audio
+// properties describe the copy that peer holds.
 type IndexTrackHint struct {
-	CTID   string `json:"ctid"`
-	Title  string `json:"title"`
-	Artist string `json:"artist"`
+	CTID           string `json:"ctid"`
+	Title          string `json:"title"`
+	Artist         string `json:"artist"`
+	FingerprintKey string `json:"fingerprint_key,omitempty"`
+	Fingerprint    string `json:"fingerprint,omitempty"`
+	Artwork        string `json:"artwork,omitempty"` // Cover hash; fetch over the artwork protocol
+	models.AudioProperties
 }
 
 // IndexQueryResponse represents a response with tracks for a token.
@@ -37,6 +50,22 @@ type IndexQueryResponse struct {
 
 // QueryPeerIndex queries a peer's local index for a token and returns CTID hints.
 func QueryPeerIndex(ctx context.Context, h host.Host, peerID peer.ID, token string) ([]IndexTrackHint, error) {
+	return queryPeer(ctx, h, peerID, IndexQueryRequest{Token: token})
+}
+
+// QueryPeerFingerprint asks a peer for recordings matching a fingerprint key.
+// Returned hints include the encoded fingerprint.
+func QueryPeerFingerprint(ctx context.Context, h host.Host, peerID peer.ID, key string) ([]IndexTrackHint, error) {
+	return queryPeer(ctx, h, peerID, IndexQueryRequest{FingerprintKey: key})
+}
+
+// QueryPeerCTID asks a peer for the properties of its copy of a CTID. The
+// result is empty if the peer does not share it.
+func QueryPeerCTID(ctx context.Context, h host.Host, peerID peer.ID, ctid string) ([]IndexTrackHint, error) {
+	return queryPeer(ctx, h, peerID, IndexQueryRequest{CTID: ctid})
+}
+
+func queryPeer(ctx context.Context, h host.Host, peerID peer.ID, req IndexQueryRequest) ([]IndexTrackHint, error) {
 	// Connect if not connected
 	if h.Network().Connectedness(peerID) != network.Connected {
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
@@ -56,7 +85,6 @@ func QueryPeerIndex(ctx context.Context, h host.Host, peerID peer.ID, token stri
 	defer stream.Close()
 
 	// Send request
-	req := IndexQueryRequest{Token: token}
 	if err := writeJSON(stream, req); err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -80,6 +108,15 @@ func (s *Service) HandleIndexQuery(stream network.Stream) {
 		return
 	}
 
+	if req.FingerprintKey != "" {
+		writeJSON(stream, IndexQueryResponse{Tracks: s.fingerprintHints(req.FingerprintKey)})
+		return
+	}
+	if req.CTID != "" {
+		writeJSON(stream, IndexQueryResponse{Tracks: s.ctidHints(req.CTID)})
+		return
+	}
+
 	// Query local index
 	s.mu.RLock()
 	ctids := make([]string, 0)
@@ -94,7 +131,7 @@ func (s *Service) HandleIndexQuery(stream network.Stream) {
 		if tracks, err := s.store.FindTracksByToken(req.Token); err == nil {
 			seen := make(map[string]struct{}, len(tracks))
 			for _, tr := range tracks {
-				if tr == nil || tr.CTID == "" || !tr.Recognized {
+				if tr == nil || !tr.IsShared() {
 					continue
 				}
 				if _, ok := seen[tr.CTID]; ok {
@@ -120,6 +157,9 @@ func (s *Service) HandleIndexQuery(stream network.Stream) {
 			if track.Artist != "" {
 				hint.Artist = track.Artist
 			}
+			hint.FingerprintKey = track.FingerprintKey
+			hint.Artwork = track.Artwork
+			hint.AudioProperties = track.Properties()
 		}
 		tracks = append(tracks, hint)
 	}
@@ -131,6 +171,50 @@ func (s *Service) HandleIndexQuery(stream network.Stream) {
 	writeJSON(stream, resp)
 }
 
+// fingerprintHints returns shared local recordings matching a fingerprint key
+func (s *Service) fingerprintHints(key string) []IndexTrackHint {
+	tracks, err := s.store.FindTracksByFingerprintKey(key)
+	if err != nil {
+		return []IndexTrackHint{}
+	}
+
+	hints := make([]IndexTrackHint, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.IsShared() {
+			continue
+		}
+		encoded, err := s.store.GetFingerprint(track.ID)
+		if err != nil {
+			continue
+		}
+		hints = append(hints, IndexTrackHint{
+			CTID:            track.CTID,
+			Title:           track.Title,
+			Artist:          track.Artist,
+			FingerprintKey:  track.FingerprintKey,
+			Fingerprint:     encoded,
+			AudioProperties: track.Properties(),
+		})
+	}
+	return hints
+}
+
+// ctidHints describes the shared local copy of a CTID, if there is one
+func (s *Service) ctidHints(ctid string) []IndexTrackHint {
+	track, err := s.store.FindTrackByCTID(ctid)
+	if err != nil || track == nil || !track.IsShared() {
+		return []IndexTrackHint{}
+	}
+	return []IndexTrackHint{{
+		CTID:            ctid,
+		Title:           track.Title,
+		Artist:          track.Artist,
+		FingerprintKey:  track.FingerprintKey,
+		Artwork:         track.Artwork,
+		AudioProperties: track.Properties(),
+	}}
+}
+
 // RegisterIndexProtocol registers the index query protocol handler
 func (s *Service) RegisterIndexProtocol(h host.Host) {
 	h.SetStreamHandler(protocol.ID(IndexProtocol), s.HandleIndexQuery)
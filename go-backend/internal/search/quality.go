@@ -0,0 +1,67 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// providerQueryTimeout bounds how long providers may take to describe their copy
+const providerQueryTimeout = 5 * time.Second
+
+// RankProviders orders the providers of a CTID best copy first. Each one is
+// asked for the properties of its copy in parallel; providers that do not
+// answer, such as peers running older releases, follow in their original
+// order.
+func (s *Service) RankProviders(ctx context.Context, ctid string, providers []peer.AddrInfo) []peer.AddrInfo {
+	if len(providers) < 2 {
+		return providers
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providerQueryTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	properties := make(map[peer.ID]models.AudioProperties, len(providers))
+	for _, provider := range providers {
+		if provider.ID == s.host.ID() {
+			continue
+		}
+		wg.Add(1)
+		go func(provider peer.AddrInfo) {
+			defer wg.Done()
+			s.resolveProvider(ctx, provider)
+			hints, err := QueryPeerCTID(ctx, s.host, provider.ID, ctid)
+			if err != nil || len(hints) == 0 {
+				return
+			}
+			mu.Lock()
+			properties[provider.ID] = hints[0].AudioProperties
+			mu.Unlock()
+		}(provider)
+	}
+	wg.Wait()
+
+	return sortByQuality(providers, properties)
+}
+
+// sortByQuality returns providers with known properties first, best copy
+// first, followed by the rest in their original order
+func sortByQuality(providers []peer.AddrInfo, properties map[peer.ID]models.AudioProperties) []peer.AddrInfo {
+	sorted := append([]peer.AddrInfo(nil), providers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, iok := properties[sorted[i].ID]
+		pj, jok := properties[sorted[j].ID]
+		if iok != jok {
+			return iok
+		}
+		return iok && pi.BetterThan(pj)
+	})
+	return sorted
+}
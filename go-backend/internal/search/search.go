@@ -3,10 +3,11 @@ package search
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
 	"sync"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
 
 	"github.com/cotune/go-backend/internal/dht"
@@ -17,21 +18,24 @@ import (
 
 // Service handles search functionality
 type Service struct {
-	store *storage.Storage
+	store storage.Store
 	dht   *dht.Service
 	host  host.Host
 	mu    sync.RWMutex
 	// Local index: token -> []CTID
 	localIndex map[string][]string
+	// Metadata last seen from remote peers: CTID -> hint
+	remoteHints map[string]IndexTrackHint
 }
 
 // New creates a new search service
-func New(store *storage.Storage, dhtService *dht.Service, h host.Host) *Service {
+func New(store storage.Store, dhtService *dht.Service, h host.Host) *Service {
 	svc := &Service{
-		store:      store,
-		dht:        dhtService,
-		host:       h,
-		localIndex: make(map[string][]string),
+		store:       store,
+		dht:         dhtService,
+		host:        h,
+		localIndex:  make(map[string][]string),
+		remoteHints: make(map[string]IndexTrackHint),
 	}
 	// Register index protocol handler
 	svc.RegisterIndexProtocol(h)
@@ -40,11 +44,18 @@ func New(store *storage.Storage, dhtService *dht.Service, h host.Host) *Service
 
 // SearchResult represents a search result
 type SearchResult struct {
-	CTID       string   `json:"ctid"`
-	Title      string   `json:"title"`
-	Artist     string   `json:"artist"`
-	Recognized bool     `json:"recognized"`
-	Providers  []string `json:"providers"` // Peer IDs that can provide this track
+	CTID           string   `json:"ctid"`
+	Title          string   `json:"title"`
+	Artist         string   `json:"artist"`
+	Recognized     bool     `json:"recognized"`
+	Providers      []string `json:"providers"` // Peer IDs that can provide this track
+	FingerprintKey string   `json:"fingerprint_key,omitempty"`
+	// Alternates lists CTIDs of near-identical recordings grouped under this result
+	Alternates []string `json:"alternates,omitempty"`
+	// Artwork is the cover hash; empty if no copy seen has a cover
+	Artwork string `json:"artwork,omitempty"`
+	// Properties of the local copy, or of the best copy a provider advertised
+	models.AudioProperties
 }
 
 // Search performs a search query
@@ -70,30 +81,49 @@ func (s *Service) Search(ctx context.Context, query string, maxResults int) ([]*
 	fmt.Printf("search-service-network-results query=%q count=%d\n", query, len(networkResults))
 
 	// Merge results
+	results := mergeResults(localResults, networkResults, maxResults)
+
+	fmt.Printf("search-service-done query=%q total=%d\n", query, len(results))
+	return results, nil
+}
+
+// mergeResults merges local and network results, local first. Results whose
+// fingerprint key matches an earlier result are grouped as its alternates
+// instead of being listed separately.
+func mergeResults(localResults, networkResults []*SearchResult, maxResults int) []*SearchResult {
 	seenCTIDs := make(map[string]bool)
+	byFingerprint := make(map[string]*SearchResult)
 	var results []*SearchResult
 
+	add := func(result *SearchResult) {
+		if seenCTIDs[result.CTID] {
+			return
+		}
+		seenCTIDs[result.CTID] = true
+		if result.FingerprintKey != "" {
+			if primary, ok := byFingerprint[result.FingerprintKey]; ok {
+				primary.Alternates = append(primary.Alternates, result.CTID)
+				return
+			}
+			byFingerprint[result.FingerprintKey] = result
+		}
+		results = append(results, result)
+	}
+
 	// Add local results first
 	for _, result := range localResults {
-		if !seenCTIDs[result.CTID] {
-			seenCTIDs[result.CTID] = true
-			results = append(results, result)
-		}
+		add(result)
 	}
 
 	// Add network results
 	for _, result := range networkResults {
-		if !seenCTIDs[result.CTID] {
-			seenCTIDs[result.CTID] = true
-			results = append(results, result)
-			if len(results) >= maxResults {
-				break
-			}
+		if len(results) >= maxResults {
+			break
 		}
+		add(result)
 	}
 
-	fmt.Printf("search-service-done query=%q total=%d\n", query, len(results))
-	return results, nil
+	return results
 }
 
 // searchLocal searches in local storage
@@ -116,11 +146,14 @@ func (s *Service) searchLocal(tokens []string) []*SearchResult {
 		}
 
 		results = append(results, &SearchResult{
-			CTID:       track.CTID,
-			Title:      track.Title,
-			Artist:     track.Artist,
-			Recognized: track.Recognized,
-			Providers:  []string{}, // Local track, no providers needed
+			CTID:            track.CTID,
+			Title:           track.Title,
+			Artist:          track.Artist,
+			Recognized:      track.Recognized,
+			Providers:       []string{}, // Local track, no providers needed
+			FingerprintKey:  track.FingerprintKey,
+			Artwork:         track.Artwork,
+			AudioProperties: track.Properties(),
 		})
 	}
 
@@ -166,18 +199,7 @@ func (s *Service) searchNetwork(ctx context.Context, tokens []string, maxResults
 		// Step 2: Query each provider for CTIDs matching this token
 		// Use the index query protocol to get CTIDs from peer's local index
 		for _, provider := range providers {
-			// Some DHT responses return provider IDs without addrs. Resolve addrs via DHT FindPeer.
-			if len(provider.Addrs) == 0 {
-				if info, findErr := s.dht.FindPeer(ctx, provider.ID); findErr == nil && len(info.Addrs) > 0 {
-					provider.Addrs = info.Addrs
-					fmt.Printf("search-network-findpeer-resolved peer=%s addrs=%d\n", provider.ID.String(), len(info.Addrs))
-				} else if findErr != nil {
-					fmt.Printf("search-network-findpeer-error peer=%s err=%v\n", provider.ID.String(), findErr)
-				}
-			}
-			if len(provider.Addrs) > 0 {
-				s.host.Peerstore().AddAddrs(provider.ID, provider.Addrs, peerstore.TempAddrTTL)
-			}
+			s.resolveProvider(ctx, provider)
 
 			// Query peer's local index for this token
 			peerHints, err := QueryPeerIndex(ctx, s.host, provider.ID, token)
@@ -188,16 +210,19 @@ func (s *Service) searchNetwork(ctx context.Context, tokens []string, maxResults
 			}
 			fmt.Printf("search-network-query-peer-index-ok peer=%s token=%q ctids=%d\n", provider.ID.String(), token, len(peerHints))
 
-			// Add CTIDs to set
+			// Add CTIDs to set, keeping the best copy advertised for each
 			for _, hint := range peerHints {
 				if hint.CTID == "" {
 					continue
 				}
 				ctidSet[hint.CTID] = true
-				remoteHints[hint.CTID] = hint
+				if known, ok := remoteHints[hint.CTID]; !ok || hint.AudioProperties.BetterThan(known.AudioProperties) {
+					remoteHints[hint.CTID] = hint
+				}
 			}
 		}
 	}
+	s.rememberHints(remoteHints)
 
 	// Step 3: For each CTID, find providers via FindProviders(/ctid/<CTID>)
 	results := make([]*SearchResult, 0)
@@ -216,12 +241,19 @@ func (s *Service) searchNetwork(ctx context.Context, tokens []string, maxResults
 
 		// Find track metadata locally if available
 		track, err := s.store.FindTrackByCTID(ctid)
-		var title, artist string
+		var title, artist, fingerprintKey, cover string
+		var properties models.AudioProperties
 		recognized := true
 		if err == nil && track != nil {
 			title = track.Title
 			artist = track.Artist
+			fingerprintKey = track.FingerprintKey
+			cover = track.Artwork
+			properties = track.Properties()
 		} else {
+			fingerprintKey = remoteHints[ctid].FingerprintKey
+			cover = remoteHints[ctid].Artwork
+			properties = remoteHints[ctid].AudioProperties
 			// Fallback to remote metadata received from index query.
 			if hint, ok := remoteHints[ctid]; ok && (hint.Title != "" || hint.Artist != "") {
 				title = hint.Title
@@ -245,11 +277,14 @@ func (s *Service) searchNetwork(ctx context.Context, tokens []string, maxResults
 		}
 
 		results = append(results, &SearchResult{
-			CTID:       ctid,
-			Title:      title,
-			Artist:     artist,
-			Recognized: recognized,
-			Providers:  providerStrs,
+			CTID:            ctid,
+			Title:           title,
+			Artist:          artist,
+			Recognized:      recognized,
+			Providers:       providerStrs,
+			FingerprintKey:  fingerprintKey,
+			Artwork:         cover,
+			AudioProperties: properties,
 		})
 	}
 
@@ -257,73 +292,98 @@ func (s *Service) searchNetwork(ctx context.Context, tokens []string, maxResults
 	return results, nil
 }
 
+// resolveProvider makes sure the peerstore has addresses for a provider.
+// Some DHT responses return provider IDs without addrs; resolve them via FindPeer.
+func (s *Service) resolveProvider(ctx context.Context, provider peer.AddrInfo) {
+	if len(provider.Addrs) == 0 {
+		if info, findErr := s.dht.FindPeer(ctx, provider.ID); findErr == nil && len(info.Addrs) > 0 {
+			provider.Addrs = info.Addrs
+			fmt.Printf("search-network-findpeer-resolved peer=%s addrs=%d\n", provider.ID.String(), len(info.Addrs))
+		} else if findErr != nil {
+			fmt.Printf("search-network-findpeer-error peer=%s err=%v\n", provider.ID.String(), findErr)
+		}
+	}
+	if len(provider.Addrs) > 0 {
+		s.host.Peerstore().AddAddrs(provider.ID, provider.Addrs, peerstore.TempAddrTTL)
+	}
+}
+
+// rememberHints caches remote metadata so later lookups by CTID (for example
+// similarity queries) can reuse it.
+func (s *Service) rememberHints(hints map[string]IndexTrackHint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remoteHints == nil {
+		s.remoteHints = make(map[string]IndexTrackHint)
+	}
+	for ctid, hint := range hints {
+		s.remoteHints[ctid] = hint
+	}
+}
+
+// LookupHint returns remote metadata previously seen for a CTID.
+func (s *Service) LookupHint(ctid string) (IndexTrackHint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hint, ok := s.remoteHints[ctid]
+	return hint, ok
+}
+
 // UpdateLocalIndex updates the local token index
 func (s *Service) UpdateLocalIndex(track *models.Track) {
-	if track.CTID == "" || !track.Recognized {
+	if !track.IsShared() {
 		return
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.indexLocked(track)
+}
 
-	// Tokenize title and artist
-	titleTokens := s.tokenize(track.Title)
-	artistTokens := s.tokenize(track.Artist)
+// indexLocked adds the title and artist tokens of a track to the local
+// index. The caller holds mu.
+func (s *Service) indexLocked(track *models.Track) {
+	for _, token := range s.tokenize(track.Title + " " + track.Artist) {
+		if !slices.Contains(s.localIndex[token], track.CTID) {
+			s.localIndex[token] = append(s.localIndex[token], track.CTID)
+		}
+	}
+}
 
-	allTokens := make(map[string]bool)
-	for _, token := range titleTokens {
-		allTokens[token] = true
+// RemoveFromLocalIndex drops the tokens of a track from the local token
+// index. The index is keyed by CTID, so the CTID is dropped everywhere and
+// the tokens of other shared tracks with the same CTID are indexed again.
+func (s *Service) RemoveFromLocalIndex(track *models.Track) {
+	if track.CTID == "" {
+		return
 	}
-	for _, token := range artistTokens {
-		allTokens[token] = true
+	others, err := s.store.FindTracksByCTID(track.CTID)
+	if err != nil {
+		return
 	}
 
-	// Add to index
-	for token := range allTokens {
-		if s.localIndex[token] == nil {
-			s.localIndex[token] = []string{}
-		}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		// Check if CTID already in list
-		found := false
-		for _, ctid := range s.localIndex[token] {
-			if ctid == track.CTID {
-				found = true
-				break
-			}
+	for token, ctids := range s.localIndex {
+		kept := slices.DeleteFunc(ctids, func(ctid string) bool { return ctid == track.CTID })
+		if len(kept) == 0 {
+			delete(s.localIndex, token)
+		} else {
+			s.localIndex[token] = kept
 		}
-
-		if !found {
-			s.localIndex[token] = append(s.localIndex[token], track.CTID)
+	}
+	for _, other := range others {
+		if other.ID != track.ID && other.IsShared() {
+			s.indexLocked(other)
 		}
 	}
 }
 
 // tokenize tokenizes a string into search tokens
 func (s *Service) tokenize(text string) []string {
-	// Simple tokenization: split by whitespace and punctuation
-	text = strings.ToLower(text)
-
-	// Remove punctuation
-	text = strings.ReplaceAll(text, ",", " ")
-	text = strings.ReplaceAll(text, ".", " ")
-	text = strings.ReplaceAll(text, "!", " ")
-	text = strings.ReplaceAll(text, "?", " ")
-	text = strings.ReplaceAll(text, "-", " ")
-	text = strings.ReplaceAll(text, "_", " ")
-
-	// Split by whitespace
-	parts := strings.Fields(text)
-
-	// Filter out empty and very short tokens
-	tokens := make([]string, 0, len(parts))
-	for _, part := range parts {
-		if len(part) >= 2 {
-			tokens = append(tokens, part)
-		}
-	}
-
-	return tokens
+	// Same tokens as the storage token index, so local lookups match
+	return storage.Tokenize(text)
 }
 
 // Tokenize tokenizes a string (exported for use by daemon)
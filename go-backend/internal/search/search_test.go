@@ -1,8 +1,12 @@
 package search
 
 import (
+	"context"
 	"testing"
 
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/cotune/go-backend/internal/fingerprint"
 	"github.com/cotune/go-backend/internal/models"
 	"github.com/cotune/go-backend/internal/storage"
 )
@@ -97,14 +101,230 @@ func TestUpdateLocalIndexDeduplicatesCTID(t *testing.T) {
 	}
 }
 
-func TestUpdateLocalIndexIgnoresUnrecognizedAndEmptyCTID(t *testing.T) {
+func TestUpdateLocalIndexIgnoresUnsharedTracks(t *testing.T) {
 	svc, cleanup := newTestService(t)
 	defer cleanup()
 
 	svc.UpdateLocalIndex(&models.Track{ID: "a", CTID: "", Title: "No CTID", Recognized: true})
 	svc.UpdateLocalIndex(&models.Track{ID: "b", CTID: "ctid", Title: "Hidden", Recognized: false})
+	svc.UpdateLocalIndex(&models.Track{ID: "c", CTID: "ctid", Title: "Unshared", Artist: "Band", Recognized: true, Unshared: true})
+
+	if len(svc.localIndex) != 0 {
+		t.Fatalf("localIndex = %+v, want empty", svc.localIndex)
+	}
+}
+
+func TestRemoveFromLocalIndexKeepsCTIDOfSharedCopy(t *testing.T) {
+	svc, cleanup := newTestService(t)
+	defer cleanup()
+
+	original := &models.Track{ID: "original", CTID: "ctid-1", Title: "Song", Artist: "Band", Recognized: true}
+	dup := &models.Track{ID: "copy", CTID: "ctid-1", Title: "Song", Artist: "Band", Recognized: true}
+	for _, track := range []*models.Track{original, dup} {
+		if err := svc.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack(%s) error: %v", track.ID, err)
+		}
+		svc.UpdateLocalIndex(track)
+	}
+
+	// The copy still shares the CTID
+	if err := svc.store.DeleteTrack(original.ID); err != nil {
+		t.Fatalf("DeleteTrack() error: %v", err)
+	}
+	svc.RemoveFromLocalIndex(original)
+	if ctids := svc.localIndex["song"]; len(ctids) != 1 {
+		t.Fatalf("localIndex[song] = %v, want the CTID kept for the copy", ctids)
+	}
 
+	// Unsharing the copy leaves nobody sharing it
+	dup.Unshared = true
+	if err := svc.store.SaveTrack(dup); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	svc.RemoveFromLocalIndex(dup)
 	if len(svc.localIndex) != 0 {
 		t.Fatalf("localIndex = %+v, want empty", svc.localIndex)
 	}
 }
+
+func TestRemoveFromLocalIndexLooksPastUnsharedCopies(t *testing.T) {
+	svc, cleanup := newTestService(t)
+	defer cleanup()
+
+	tracks := []*models.Track{
+		{ID: "a-unshared", CTID: "ctid-1", Title: "Song", Recognized: true, Unshared: true},
+		{ID: "b-shared", CTID: "ctid-1", Title: "Song", Recognized: true},
+		{ID: "c-removed", CTID: "ctid-1", Title: "Song", Recognized: true},
+	}
+	for _, track := range tracks {
+		if err := svc.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack(%s) error: %v", track.ID, err)
+		}
+		svc.UpdateLocalIndex(track)
+	}
+
+	if err := svc.store.DeleteTrack("c-removed"); err != nil {
+		t.Fatalf("DeleteTrack() error: %v", err)
+	}
+	svc.RemoveFromLocalIndex(tracks[2])
+	if ctids := svc.localIndex["song"]; len(ctids) != 1 {
+		t.Fatalf("localIndex[song] = %v, want the CTID kept for the shared copy", ctids)
+	}
+}
+
+func TestRemoveFromLocalIndexDropsStaleTokensOfRenamedCopy(t *testing.T) {
+	svc, cleanup := newTestService(t)
+	defer cleanup()
+
+	original := &models.Track{ID: "original", CTID: "ctid-1", Title: "Song", Artist: "Band", Recognized: true}
+	dup := &models.Track{ID: "copy", CTID: "ctid-1", Title: "Other", Artist: "Band", Recognized: true}
+	for _, track := range []*models.Track{original, dup} {
+		if err := svc.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack(%s) error: %v", track.ID, err)
+		}
+		svc.UpdateLocalIndex(track)
+	}
+
+	renamed := *original
+	renamed.Title = "Tune"
+	if err := svc.store.SaveTrack(&renamed); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	svc.RemoveFromLocalIndex(original)
+	svc.UpdateLocalIndex(&renamed)
+
+	if ctids, ok := svc.localIndex["song"]; ok {
+		t.Fatalf("localIndex[song] = %v, want the old title dropped", ctids)
+	}
+	for _, token := range []string{"tune", "other", "band"} {
+		if ctids := svc.localIndex[token]; len(ctids) != 1 || ctids[0] != "ctid-1" {
+			t.Fatalf("localIndex[%s] = %v, want ctid-1", token, ctids)
+		}
+	}
+}
+
+func TestMergeResultsGroupsByFingerprintKey(t *testing.T) {
+	local := []*SearchResult{
+		{CTID: "ctid-a", Title: "Song", FingerprintKey: "fp1:20:abcd"},
+	}
+	network := []*SearchResult{
+		{CTID: "ctid-b", Title: "Song (320k)", FingerprintKey: "fp1:20:abcd"},
+		{CTID: "ctid-c", Title: "Other"},
+		{CTID: "ctid-a", Title: "Song"},
+	}
+
+	results := mergeResults(local, network, 10)
+	if len(results) != 2 {
+		t.Fatalf("mergeResults() returned %d results, want 2: %+v", len(results), results)
+	}
+	if results[0].CTID != "ctid-a" {
+		t.Fatalf("results[0].CTID = %q, want ctid-a", results[0].CTID)
+	}
+	if len(results[0].Alternates) != 1 || results[0].Alternates[0] != "ctid-b" {
+		t.Fatalf("results[0].Alternates = %v, want [ctid-b]", results[0].Alternates)
+	}
+	if results[1].CTID != "ctid-c" {
+		t.Fatalf("results[1].CTID = %q, want ctid-c", results[1].CTID)
+	}
+}
+
+func TestFindSimilarScoresLocalRecordings(t *testing.T) {
+	svc, cleanup := newTestService(t)
+	defer cleanup()
+
+	frames := make([]uint32, 200)
+	for i := range frames {
+		frames[i] = uint32(i) * 2654435761
+	}
+	near := append([]uint32(nil), frames...)
+	near[10] ^= 0xff
+	unrelated := make([]uint32, 200)
+	for i := range unrelated {
+		unrelated[i] = ^frames[i]
+	}
+
+	tracks := []*models.Track{
+		{ID: "ref", CTID: "ctid-ref", FingerprintKey: "fp1:20:abcd"},
+		{ID: "near", CTID: "ctid-near", FingerprintKey: "fp1:21:abcd"},
+		// One band comparison flipped by the encoder
+		{ID: "reencoded", CTID: "ctid-reencoded", FingerprintKey: "fp1:20:abcc"},
+		{ID: "other", CTID: "ctid-other", FingerprintKey: "fp1:20:abcd"},
+	}
+	fingerprints := map[string][]uint32{"ref": frames, "near": near, "reencoded": near, "other": unrelated}
+	for _, track := range tracks {
+		if err := svc.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack(%s) error: %v", track.ID, err)
+		}
+		if err := svc.store.SaveFingerprint(track.ID, fingerprint.Encode(fingerprints[track.ID])); err != nil {
+			t.Fatalf("SaveFingerprint(%s) error: %v", track.ID, err)
+		}
+	}
+
+	results, err := svc.FindSimilar(context.Background(), "ctid-ref", 10)
+	if err != nil {
+		t.Fatalf("FindSimilar() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("FindSimilar() returned %d results, want 2: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if (result.CTID != "ctid-near" && result.CTID != "ctid-reencoded") || result.Score < fingerprint.MatchThreshold {
+			t.Fatalf("FindSimilar() = %+v, want ctid-near and ctid-reencoded above threshold", result)
+		}
+	}
+
+	if _, err := svc.FindSimilar(context.Background(), "ctid-unknown", 10); err == nil {
+		t.Fatal("FindSimilar(unknown) returned nil error")
+	}
+}
+
+func TestSortByQualityPrefersBestCopy(t *testing.T) {
+	providers := []peer.AddrInfo{{ID: "silent"}, {ID: "mp3"}, {ID: "old"}, {ID: "flac"}}
+	properties := map[peer.ID]models.AudioProperties{
+		"mp3":  {Codec: "mp3", SampleRate: 44100, Channels: 2, Bitrate: 320000},
+		"flac": {Codec: "flac", SampleRate: 44100, Channels: 2, Bitrate: 900000},
+	}
+
+	got := sortByQuality(providers, properties)
+	want := []peer.ID{"flac", "mp3", "silent", "old"}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Fatalf("sortByQuality()[%d] = %s, want %s (all: %v)", i, got[i].ID, id, got)
+		}
+	}
+	if providers[0].ID != "silent" {
+		t.Fatal("sortByQuality() reordered its input")
+	}
+}
+
+func TestCTIDHintsDescribeSharedCopy(t *testing.T) {
+	svc, cleanup := newTestService(t)
+	defer cleanup()
+
+	tracks := []*models.Track{
+		{
+			ID: "shared", CTID: "ctid-shared", Title: "Song", Artist: "Band", Recognized: true,
+			Format:     &models.AudioFormat{Container: "flac", Codec: "flac", SampleRate: 48000, Channels: 2},
+			DurationMs: 180000, Bitrate: 850000, FileSize: 19125000,
+		},
+		{ID: "private", CTID: "ctid-private", Title: "Draft"},
+		{ID: "unshared", CTID: "ctid-unshared", Title: "Song", Artist: "Band", Recognized: true, Unshared: true},
+	}
+	for _, track := range tracks {
+		if err := svc.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack(%s) error: %v", track.ID, err)
+		}
+	}
+
+	hints := svc.ctidHints("ctid-shared")
+	want := models.AudioProperties{Codec: "flac", SampleRate: 48000, Channels: 2, Bitrate: 850000, DurationMs: 180000, FileSize: 19125000}
+	if len(hints) != 1 || hints[0].AudioProperties != want {
+		t.Fatalf("ctidHints(shared) = %+v, want properties %+v", hints, want)
+	}
+	if hints := svc.ctidHints("ctid-private"); len(hints) != 0 {
+		t.Fatalf("ctidHints(private) = %+v, want none for unrecognized track", hints)
+	}
+	if hints := svc.ctidHints("ctid-unshared"); len(hints) != 0 {
+		t.Fatalf("ctidHints(unshared) = %+v, want none for unshared track", hints)
+	}
+}
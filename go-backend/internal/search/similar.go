@@ -0,0 +1,222 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	dhtpkg "github.com/cotune/go-backend/internal/dht"
+	"github.com/cotune/go-backend/internal/fingerprint"
+)
+
+const (
+	// similarLookups bounds the fingerprint key lookups FindSimilar runs at once
+	similarLookups = 4
+	// similarAnswers is how many peer answers are enough for FindSimilar;
+	// lookups still outstanding then are cancelled
+	similarAnswers = 10
+)
+
+// SimilarResult is a recording acoustically similar to a reference CTID
+type SimilarResult struct {
+	CTID      string   `json:"ctid"`
+	Title     string   `json:"title"`
+	Artist    string   `json:"artist"`
+	Score     float64  `json:"score"`     // Fingerprint similarity in [0, 1]
+	Providers []string `json:"providers"` // Remote peers holding it; empty if local
+}
+
+// similarCandidate is a recording found under a fingerprint key
+type similarCandidate struct {
+	hint      IndexTrackHint
+	providers []string
+}
+
+// FindSimilar returns recordings whose acoustic fingerprint matches the given
+// CTID, best match first. The reference fingerprint comes from local storage
+// or, for remote tracks, from peers announcing the same fingerprint key.
+func (s *Service) FindSimilar(ctx context.Context, ctid string, maxResults int) ([]*SimilarResult, error) {
+	var reference []uint32
+	var key string
+
+	if track, err := s.store.FindTrackByCTID(ctid); err == nil && track != nil && track.FingerprintKey != "" {
+		key = track.FingerprintKey
+		if encoded, err := s.store.GetFingerprint(track.ID); err == nil {
+			if frames, err := fingerprint.Decode(encoded); err == nil {
+				reference = frames
+			}
+		}
+	} else if hint, ok := s.LookupHint(ctid); ok {
+		key = hint.FingerprintKey
+	}
+	if key == "" {
+		return nil, fmt.Errorf("no fingerprint known for CTID: %s", ctid)
+	}
+
+	candidates := make(map[string]*similarCandidate)
+	keys := fingerprint.NeighborKeys(key)
+	for _, k := range keys {
+		s.collectLocalCandidates(k, candidates)
+	}
+	if s.dht != nil {
+		s.collectNeighborCandidates(ctx, ctid, keys, reference != nil, candidates)
+	}
+
+	if reference == nil {
+		if c, ok := candidates[ctid]; ok {
+			if frames, err := fingerprint.Decode(c.hint.Fingerprint); err == nil {
+				reference = frames
+			}
+		}
+	}
+	if reference == nil {
+		return nil, fmt.Errorf("fingerprint unavailable for CTID: %s", ctid)
+	}
+
+	results := make([]*SimilarResult, 0, len(candidates))
+	for candidateCTID, c := range candidates {
+		if candidateCTID == ctid {
+			continue
+		}
+		frames, err := fingerprint.Decode(c.hint.Fingerprint)
+		if err != nil {
+			continue
+		}
+		score := fingerprint.Similarity(reference, frames)
+		if score < fingerprint.MatchThreshold {
+			continue
+		}
+		results = append(results, &SimilarResult{
+			CTID:      candidateCTID,
+			Title:     c.hint.Title,
+			Artist:    c.hint.Artist,
+			Score:     score,
+			Providers: c.providers,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if maxResults > 0 && len(results) > maxResults {
+		results = results[:maxResults]
+	}
+
+	return results, nil
+}
+
+// collectLocalCandidates adds local tracks matching a fingerprint key
+func (s *Service) collectLocalCandidates(key string, candidates map[string]*similarCandidate) {
+	tracks, err := s.store.FindTracksByFingerprintKey(key)
+	if err != nil {
+		return
+	}
+	for _, track := range tracks {
+		if track.CTID == "" {
+			continue
+		}
+		if _, ok := candidates[track.CTID]; ok {
+			continue
+		}
+		encoded, err := s.store.GetFingerprint(track.ID)
+		if err != nil {
+			continue
+		}
+		candidates[track.CTID] = &similarCandidate{
+			hint: IndexTrackHint{
+				CTID:            track.CTID,
+				Title:           track.Title,
+				Artist:          track.Artist,
+				FingerprintKey:  track.FingerprintKey,
+				Fingerprint:     encoded,
+				AudioProperties: track.Properties(),
+			},
+			providers: []string{},
+		}
+	}
+}
+
+// collectNeighborCandidates looks up fingerprint keys in order, closest
+// first, running at most similarLookups lookups at once. Once peers have
+// answered similarAnswers times, and one of them sent the reference
+// fingerprint unless it is known locally, outstanding lookups are cancelled.
+func (s *Service) collectNeighborCandidates(ctx context.Context, ctid string, keys []string, haveReference bool, candidates map[string]*similarCandidate) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	answers := 0
+	next := make(chan string)
+	for range min(similarLookups, len(keys)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range next {
+				n := s.collectNetworkCandidates(ctx, k, &mu, candidates)
+				mu.Lock()
+				answers += n
+				_, found := candidates[ctid]
+				if answers >= similarAnswers && (haveReference || found) {
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, k := range keys {
+		select {
+		case next <- k:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+}
+
+// collectNetworkCandidates asks peers announcing a fingerprint key for their
+// matching recordings, adding them to candidates under mu. It returns how
+// many peers answered.
+func (s *Service) collectNetworkCandidates(ctx context.Context, key string, mu *sync.Mutex, candidates map[string]*similarCandidate) int {
+	providers, err := s.dht.FindProvidersForFingerprint(ctx, dhtpkg.HashFingerprintKey(key), 10)
+	if err != nil {
+		fmt.Printf("search-similar-providers-error key=%s err=%v\n", key, err)
+		return 0
+	}
+
+	answered := 0
+	for _, provider := range providers {
+		if provider.ID == s.host.ID() {
+			continue
+		}
+		s.resolveProvider(ctx, provider)
+
+		hints, err := QueryPeerFingerprint(ctx, s.host, provider.ID, key)
+		if err != nil {
+			fmt.Printf("search-similar-query-error peer=%s key=%s err=%v\n", provider.ID.String(), key, err)
+			continue
+		}
+		answered++
+		mu.Lock()
+		for _, hint := range hints {
+			if hint.CTID == "" || hint.Fingerprint == "" {
+				continue
+			}
+			c, ok := candidates[hint.CTID]
+			if !ok {
+				c = &similarCandidate{hint: hint, providers: []string{}}
+				candidates[hint.CTID] = c
+			}
+			if !slices.Contains(c.providers, provider.ID.String()) {
+				c.providers = append(c.providers, provider.ID.String())
+			}
+		}
+		mu.Unlock()
+	}
+	return answered
+}
@@ -0,0 +1,40 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/cotune/go-backend/internal/storage"
+	"github.com/cotune/go-backend/internal/storage/storetest"
+	"github.com/cotune/go-backend/internal/vault"
+)
+
+func TestBadgerStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storage.Store {
+		store, err := storage.New(t.TempDir())
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		return store
+	})
+}
+
+func TestEncryptedBadgerStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storage.Store {
+		dir := t.TempDir()
+		_, keyring, err := vault.Create(dir, vault.Secret{Key: make([]byte, vault.KeySize)})
+		if err != nil {
+			t.Fatalf("vault.Create() error: %v", err)
+		}
+		store, err := storage.Open(dir, keyring)
+		if err != nil {
+			t.Fatalf("Open() error: %v", err)
+		}
+		return store
+	})
+}
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storage.Store {
+		return storage.NewMemory()
+	})
+}
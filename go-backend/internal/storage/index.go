@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cotune/go-backend/internal/models"
+	badgerdb "github.com/dgraph-io/badger"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+// Secondary indexes map a track attribute to track IDs with empty values:
+//
+//	/idx/ctid/<ctid>/<id>
+//	/idx/legacy/<legacy ctid>/<id>
+//	/idx/token/<token>/<id>       title and artist tokens, see Tokenize
+//	/idx/artist/<artist>/<id>     lowercased artist
+//	/idx/fpkey/<fingerprint key>/<id>
+//	/idx/media/<media hash>/<id>
+//	/idx/liked/<id>
+//
+// SaveTrack and DeleteTrack keep them in the same transaction as the track.
+// In an encrypted datastore values are blinded with the keyring, so the
+// indexes reveal which tracks share a value but not the value. Tokens are
+// searched by prefix: a scan over /idx/token/<prefix> in the clear, and
+// since blinding hides prefixes, an entry for every prefix of at least
+// two bytes of each token when encrypted.
+const (
+	indexPrefix  = "/idx"
+	indexVersion = "3" // bump to rebuild indexes on the next start
+)
+
+// indexVersionKey records which index layout the stored indexes follow,
+// and which key blinds them
+var indexVersionKey = datastore.NewKey("/meta/index-version")
+
+// indexKeys returns every index entry of a track
+func (s *Storage) indexKeys(track *models.Track) []datastore.Key {
+	id := keyComponent(track.ID)
+	var keys []datastore.Key
+	add := func(index, value string) {
+		keys = append(keys, datastore.NewKey(fmt.Sprintf("%s/%s/%s/%s", indexPrefix, index, s.indexValue(value), id)))
+	}
+
+	if track.CTID != "" {
+		add("ctid", track.CTID)
+	}
+	if track.LegacyCTID != "" {
+		add("legacy", track.LegacyCTID)
+	}
+	seen := make(map[string]bool)
+	for _, token := range Tokenize(track.Title + " " + track.Artist) {
+		for _, prefix := range s.tokenPrefixes(token) {
+			if !seen[prefix] {
+				seen[prefix] = true
+				add("token", prefix)
+			}
+		}
+	}
+	if artist := strings.ToLower(strings.TrimSpace(track.Artist)); artist != "" {
+		add("artist", artist)
+	}
+	if track.FingerprintKey != "" {
+		add("fpkey", track.FingerprintKey)
+	}
+	if track.MediaHash != "" {
+		add("media", track.MediaHash)
+	}
+	if track.Liked {
+		keys = append(keys, datastore.NewKey(fmt.Sprintf("%s/liked/%s", indexPrefix, id)))
+	}
+	return keys
+}
+
+// indexValue is the key segment an index value is stored under; indexes
+// without values, such as liked, are queried with an empty one
+func (s *Storage) indexValue(value string) string {
+	if s.keyring != nil && value != "" {
+		return s.keyring.Blind(value)
+	}
+	return keyComponent(value)
+}
+
+// indexVersion is the layout and blinding the indexes are built with
+func (s *Storage) indexVersion() string {
+	if s.keyring != nil {
+		return indexVersion + "/" + s.keyring.KeyID()
+	}
+	return indexVersion
+}
+
+// tokenPrefixes returns the token index values of a token: the token
+// itself, and in an encrypted datastore also its shorter prefixes, cut at
+// rune boundaries
+func (s *Storage) tokenPrefixes(token string) []string {
+	if s.keyring == nil {
+		return []string{token}
+	}
+	var prefixes []string
+	for i := range token {
+		if i >= 2 {
+			prefixes = append(prefixes, token[:i])
+		}
+	}
+	return append(prefixes, token)
+}
+
+// keyComponent escapes a value for use as one datastore key segment; dots are
+// escaped too since key paths are cleaned
+func keyComponent(value string) string {
+	return strings.ReplaceAll(url.PathEscape(value), ".", "%2E")
+}
+
+// Tokenize splits text into the lowercase search tokens the token index
+// holds: punctuation separates words and tokens shorter than two bytes are
+// dropped. Searches must tokenize queries the same way.
+func Tokenize(text string) []string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer(",", " ", ".", " ", "!", " ", "?", " ", "-", " ", "_", " ").Replace(text)
+
+	parts := strings.Fields(text)
+	tokens := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if len(part) >= 2 {
+			tokens = append(tokens, part)
+		}
+	}
+	return tokens
+}
+
+// updateIndexes replaces the index entries of old (nil for a new track) with
+// those of track within txn
+func (s *Storage) updateIndexes(ctx context.Context, txn datastore.Write, old, track *models.Track) error {
+	keep := make(map[datastore.Key]bool)
+	for _, key := range s.indexKeys(track) {
+		keep[key] = true
+	}
+	if old != nil {
+		for _, key := range s.indexKeys(old) {
+			if keep[key] {
+				delete(keep, key)
+				continue
+			}
+			if err := txn.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+	for key := range keep {
+		if err := txn.Put(ctx, key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexedIDs returns the track IDs under an index value, in key order
+func (s *Storage) indexedIDs(index, value string, limit int) ([]string, error) {
+	prefix := fmt.Sprintf("%s/%s/%s", indexPrefix, index, s.indexValue(value))
+	q, err := s.ds.Query(context.Background(), query.Query{
+		Prefix:   prefix,
+		KeysOnly: true,
+		Limit:    limit,
+		Orders:   []query.Order{query.OrderByKey{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	defer q.Close()
+
+	var ids []string
+	for result := range q.Next() {
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to query index: %w", result.Error)
+		}
+		id, err := url.PathUnescape(datastore.NewKey(result.Key).BaseNamespace())
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// prefixedIDs returns the track IDs under every index value starting with
+// prefix, once each, seeking to the first such entry rather than scanning
+// the index
+func (s *Storage) prefixedIDs(index, prefix string) ([]string, error) {
+	start := []byte(fmt.Sprintf("%s/%s/%s", indexPrefix, index, keyComponent(prefix)))
+	seen := make(map[string]bool)
+	var ids []string
+	err := s.ds.DB.View(func(txn *badgerdb.Txn) error {
+		opts := badgerdb.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(start); it.ValidForPrefix(start); it.Next() {
+			id, err := url.PathUnescape(datastore.RawKey(string(it.Item().Key())).BaseNamespace())
+			if err != nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	return ids, nil
+}
+
+// tracksByIndex loads the tracks under an index value
+func (s *Storage) tracksByIndex(index, value string, limit int) ([]*models.Track, error) {
+	ids, err := s.indexedIDs(index, value, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.tracksByID(ids), nil
+}
+
+// tracksByID loads tracks by ID
+func (s *Storage) tracksByID(ids []string) []*models.Track {
+	tracks := make([]*models.Track, 0, len(ids))
+	for _, id := range ids {
+		track, err := s.getTrack(s.ds, id)
+		if err != nil {
+			// An index entry without its track is skipped until the next rebuild
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
+
+// RebuildIndexes drops every secondary index entry and recreates them from
+// the stored tracks. It returns the number of tracks indexed.
+func (s *Storage) RebuildIndexes() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rebuildIndexes()
+}
+
+func (s *Storage) rebuildIndexes() (int, error) {
+	ctx := context.Background()
+
+	stale, err := s.ds.Query(ctx, query.Query{Prefix: indexPrefix, KeysOnly: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	var keys []datastore.Key
+	for result := range stale.Next() {
+		if result.Error != nil {
+			stale.Close()
+			return 0, fmt.Errorf("failed to query indexes: %w", result.Error)
+		}
+		keys = append(keys, datastore.NewKey(result.Key))
+	}
+	stale.Close()
+
+	batch, err := s.ds.Batch(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start batch: %w", err)
+	}
+	for _, key := range keys {
+		if err := batch.Delete(ctx, key); err != nil {
+			return 0, fmt.Errorf("failed to delete index entry: %w", err)
+		}
+	}
+
+	tracks, err := s.ds.Query(ctx, query.Query{Prefix: "/tracks"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query tracks: %w", err)
+	}
+	defer tracks.Close()
+	n := 0
+	for result := range tracks.Next() {
+		if result.Error != nil {
+			return n, fmt.Errorf("failed to query tracks: %w", result.Error)
+		}
+		var track models.Track
+		if err := s.decode(datastore.NewKey(result.Key), result.Value, &track); err != nil {
+			continue
+		}
+		for _, key := range s.indexKeys(&track) {
+			if err := batch.Put(ctx, key, nil); err != nil {
+				return n, fmt.Errorf("failed to write index entry: %w", err)
+			}
+		}
+		n++
+	}
+
+	if err := batch.Put(ctx, indexVersionKey, []byte(s.indexVersion())); err != nil {
+		return n, fmt.Errorf("failed to write index version: %w", err)
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return n, fmt.Errorf("failed to commit indexes: %w", err)
+	}
+	return n, nil
+}
+
+// ensureIndexes rebuilds the indexes of a datastore written before they
+// existed, with an older layout or under another key
+func (s *Storage) ensureIndexes() error {
+	version, err := s.ds.Get(context.Background(), indexVersionKey)
+	if err == nil && string(version) == s.indexVersion() {
+		return nil
+	}
+	if err != nil && err != datastore.ErrNotFound {
+		return fmt.Errorf("failed to read index version: %w", err)
+	}
+	_, err = s.rebuildIndexes()
+	return err
+}
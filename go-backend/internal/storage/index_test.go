@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+func trackIDs(tracks []*models.Track) []string {
+	ids := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.ID)
+	}
+	return ids
+}
+
+func TestIndexesAreBuiltForOlderDatastores(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	// A track written before indexes existed, and a stale entry
+	ctx := context.Background()
+	data, _ := json.Marshal(&models.Track{ID: "old", CTID: "ctid-old", Title: "Old Song"})
+	if err := store.ds.Put(ctx, datastore.NewKey(trackKey("old")), data); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	if err := store.ds.Put(ctx, datastore.NewKey("/idx/token/ghost/gone"), nil); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	if err := store.ds.Delete(ctx, indexVersionKey); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	store.Close()
+
+	store, err = New(dir)
+	if err != nil {
+		t.Fatalf("New() reopen error: %v", err)
+	}
+	defer store.Close()
+	if got, err := store.FindTrackByCTID("ctid-old"); err != nil || got.ID != "old" {
+		t.Fatalf("FindTrackByCTID() = %v, %v; want the unindexed track", got, err)
+	}
+	if ids, _ := store.indexedIDs("token", "ghost", 0); len(ids) != 0 {
+		t.Fatalf("stale index entries = %v, want none after rebuild", ids)
+	}
+
+	if n, err := store.RebuildIndexes(); err != nil || n != 1 {
+		t.Fatalf("RebuildIndexes() = %d, %v; want 1", n, err)
+	}
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// Memory is a Store holding everything in maps, for tests and nodes that
+// keep nothing between runs. Values are kept JSON-encoded, as in badger, so
+// callers never share them with the store. Lookups scan every track; it is
+// meant for small libraries.
+type Memory struct {
+	mu           sync.RWMutex
+	tracks       map[string][]byte
+	fingerprints map[string]string
+	jobs         map[string][]byte
+	playlists    map[string][]byte
+	settings     []byte
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		tracks:       make(map[string][]byte),
+		fingerprints: make(map[string]string),
+		jobs:         make(map[string][]byte),
+		playlists:    make(map[string][]byte),
+	}
+}
+
+// SaveTrack saves a track
+func (m *Memory) SaveTrack(track *models.Track) error {
+	return m.SaveTracks([]*models.Track{track})
+}
+
+// SaveTracks saves many tracks
+func (m *Memory) SaveTracks(tracks []*models.Track) error {
+	encoded := make([][]byte, len(tracks))
+	for i, track := range tracks {
+		data, err := json.Marshal(track)
+		if err != nil {
+			return fmt.Errorf("failed to marshal track: %w", err)
+		}
+		encoded[i] = data
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, track := range tracks {
+		m.tracks[track.ID] = encoded[i]
+	}
+	return nil
+}
+
+// GetTrack retrieves a track by ID
+func (m *Memory) GetTrack(id string) (*models.Track, error) {
+	m.mu.RLock()
+	data, ok := m.tracks[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("track not found: %w", ErrNotFound)
+	}
+	return decodeTrack(data)
+}
+
+// GetAllTracks returns all tracks ordered by ID
+func (m *Memory) GetAllTracks() ([]*models.Track, error) {
+	return m.findTracks(func(*models.Track) bool { return true }, 0)
+}
+
+// DeleteTrack deletes a track
+func (m *Memory) DeleteTrack(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tracks, id)
+	delete(m.fingerprints, id)
+	return nil
+}
+
+// FindTrackByCTID finds a track by CTID, then by legacy CTID
+func (m *Memory) FindTrackByCTID(ctid string) (*models.Track, error) {
+	if ctid != "" {
+		for _, match := range []func(*models.Track) bool{
+			func(t *models.Track) bool { return t.CTID == ctid },
+			func(t *models.Track) bool { return t.LegacyCTID == ctid },
+		} {
+			tracks, err := m.findTracks(match, 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(tracks) > 0 {
+				return tracks[0], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("track not found: %w", ErrNotFound)
+}
+
+// FindTracksByCTID finds every track with a CTID or legacy CTID
+func (m *Memory) FindTracksByCTID(ctid string) ([]*models.Track, error) {
+	if ctid == "" {
+		return nil, nil
+	}
+	return m.findTracks(func(t *models.Track) bool {
+		return t.CTID == ctid || t.LegacyCTID == ctid
+	}, 0)
+}
+
+// FindTracksByToken finds tracks with a title or artist token, as produced
+// by Tokenize, starting with token
+func (m *Memory) FindTracksByToken(token string) ([]*models.Track, error) {
+	token = strings.ToLower(token)
+	return m.findTracks(func(t *models.Track) bool {
+		return slices.ContainsFunc(Tokenize(t.Title+" "+t.Artist), func(have string) bool {
+			return strings.HasPrefix(have, token)
+		})
+	}, 0)
+}
+
+// FindTracksByArtist finds tracks by artist, ignoring case
+func (m *Memory) FindTracksByArtist(artist string) ([]*models.Track, error) {
+	artist = strings.ToLower(strings.TrimSpace(artist))
+	if artist == "" {
+		return nil, nil
+	}
+	return m.findTracks(func(t *models.Track) bool {
+		return strings.ToLower(strings.TrimSpace(t.Artist)) == artist
+	}, 0)
+}
+
+// LikedTracks returns the tracks the user liked
+func (m *Memory) LikedTracks() ([]*models.Track, error) {
+	return m.findTracks(func(t *models.Track) bool { return t.Liked }, 0)
+}
+
+// FindTracksByFingerprintKey finds tracks sharing a fingerprint key
+func (m *Memory) FindTracksByFingerprintKey(key string) ([]*models.Track, error) {
+	if key == "" {
+		return nil, nil
+	}
+	return m.findTracks(func(t *models.Track) bool { return t.FingerprintKey == key }, 0)
+}
+
+// SaveFingerprint saves the encoded acoustic fingerprint of a track
+func (m *Memory) SaveFingerprint(trackID string, fingerprint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fingerprints[trackID] = fingerprint
+	return nil
+}
+
+// GetFingerprint returns the encoded acoustic fingerprint of a track
+func (m *Memory) GetFingerprint(trackID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	fingerprint, ok := m.fingerprints[trackID]
+	if !ok {
+		return "", fmt.Errorf("fingerprint not found: %w", ErrNotFound)
+	}
+	return fingerprint, nil
+}
+
+// MediaRefs counts the tracks referencing a file in the media store
+func (m *Memory) MediaRefs(hash string) (int, error) {
+	tracks, err := m.FindTracksByMediaHash(hash)
+	return len(tracks), err
+}
+
+// FindTracksByMediaHash finds the tracks referencing a file in the media
+// store
+func (m *Memory) FindTracksByMediaHash(hash string) ([]*models.Track, error) {
+	if hash == "" {
+		return nil, nil
+	}
+	return m.findTracks(func(t *models.Track) bool { return t.MediaHash == hash }, 0)
+}
+
+// findTracks returns up to limit (0 for all) tracks matching, ordered by ID
+func (m *Memory) findTracks(match func(*models.Track) bool, limit int) ([]*models.Track, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tracks []*models.Track
+	for _, id := range sortedKeys(m.tracks) {
+		track, err := decodeTrack(m.tracks[id])
+		if err != nil {
+			continue
+		}
+		if match(track) {
+			tracks = append(tracks, track)
+			if len(tracks) == limit {
+				break
+			}
+		}
+	}
+	return tracks, nil
+}
+
+// sortedKeys returns the IDs of a map in the order badger lists keys
+func sortedKeys(values map[string][]byte) []string {
+	ids := make([]string, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func decodeTrack(data []byte) (*models.Track, error) {
+	var track models.Track
+	if err := json.Unmarshal(data, &track); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal track: %w", err)
+	}
+	return &track, nil
+}
+
+// SaveJob saves a CTR job
+func (m *Memory) SaveJob(job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = data
+	return nil
+}
+
+// GetJob retrieves a CTR job by ID
+func (m *Memory) GetJob(id string) (*models.Job, error) {
+	m.mu.RLock()
+	data, ok := m.jobs[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("job not found: %w", ErrNotFound)
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetAllJobs returns all CTR jobs ordered by ID
+func (m *Memory) GetAllJobs() ([]*models.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var jobs []*models.Job
+	for _, id := range sortedKeys(m.jobs) {
+		var job models.Job
+		if err := json.Unmarshal(m.jobs[id], &job); err != nil {
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// DeleteJob deletes a CTR job
+func (m *Memory) DeleteJob(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+// SavePlaylist saves a playlist
+func (m *Memory) SavePlaylist(playlist *models.Playlist) error {
+	data, err := json.Marshal(playlist)
+	if err != nil {
+		return fmt.Errorf("failed to marshal playlist: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.playlists[playlist.ID] = data
+	return nil
+}
+
+// GetPlaylist retrieves a playlist by ID
+func (m *Memory) GetPlaylist(id string) (*models.Playlist, error) {
+	m.mu.RLock()
+	data, ok := m.playlists[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("playlist not found: %w", ErrNotFound)
+	}
+
+	var playlist models.Playlist
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal playlist: %w", err)
+	}
+	return &playlist, nil
+}
+
+// GetAllPlaylists returns all playlists ordered by ID
+func (m *Memory) GetAllPlaylists() ([]*models.Playlist, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var playlists []*models.Playlist
+	for _, id := range sortedKeys(m.playlists) {
+		var playlist models.Playlist
+		if err := json.Unmarshal(m.playlists[id], &playlist); err != nil {
+			continue
+		}
+		playlists = append(playlists, &playlist)
+	}
+	return playlists, nil
+}
+
+// FindPlaylistByRecord finds the shared playlist published as a record
+func (m *Memory) FindPlaylistByRecord(recordID string) (*models.Playlist, error) {
+	playlists, err := m.GetAllPlaylists()
+	if err != nil {
+		return nil, err
+	}
+	for _, playlist := range playlists {
+		if playlist.Shared && playlist.RecordID == recordID {
+			return playlist, nil
+		}
+	}
+	return nil, fmt.Errorf("playlist not found: %w", ErrNotFound)
+}
+
+// DeletePlaylist deletes a playlist
+func (m *Memory) DeletePlaylist(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.playlists, id)
+	return nil
+}
+
+// GetSettings returns the daemon settings, zero if none were saved
+func (m *Memory) GetSettings() (models.Settings, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var settings models.Settings
+	if m.settings == nil {
+		return settings, nil
+	}
+	if err := json.Unmarshal(m.settings, &settings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveSettings saves the daemon settings
+func (m *Memory) SaveSettings(settings models.Settings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings = data
+	return nil
+}
+
+// Close does nothing; the contents are dropped with the Memory
+func (m *Memory) Close() error {
+	return nil
+}
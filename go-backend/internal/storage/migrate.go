@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	badger "github.com/ipfs/go-ds-badger"
+)
+
+// schemaVersionKey holds the version of the datastore layout as a decimal
+// string. Datastores written before it existed are version 0.
+var schemaVersionKey = datastore.NewKey("/meta/schema-version")
+
+// Migration rewrites stored values from the previous schema version to
+// Version. Rewrite sees raw values rather than models types, which change
+// with later versions, opened if the datastore is encrypted; it returns nil
+// to leave a value as it is. A migration interrupted by a crash runs again
+// from the start, so Rewrite must be idempotent.
+type Migration struct {
+	Version     int
+	Description string
+	Prefix      string // keys the migration visits, e.g. "/tracks"
+	Rewrite     func(key datastore.Key, value []byte) ([]byte, error)
+}
+
+// migrations are applied in order; versions follow each other from 1
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "record ctid_version 1 on tracks hashed before CTID versioning",
+		Prefix:      "/tracks",
+		Rewrite:     explicitCTIDVersion,
+	},
+}
+
+// SchemaVersion returns the datastore schema version this build writes
+func SchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// MigrationReport describes the migrations run, or in a dry run pending,
+// when a datastore was opened
+type MigrationReport struct {
+	FromVersion int               `json:"from_version"`
+	ToVersion   int               `json:"to_version"`
+	DryRun      bool              `json:"dry_run,omitempty"`
+	Backup      string            `json:"backup,omitempty"` // Badger backup taken before migrating
+	Migrations  []MigrationResult `json:"migrations,omitempty"`
+}
+
+// MigrationResult reports one migration
+type MigrationResult struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Changed     int    `json:"changed"` // Values rewritten
+}
+
+// Migrations returns what opening the datastore migrated
+func (s *Storage) Migrations() MigrationReport {
+	return s.migration
+}
+
+// DryRunMigrations opens the datastore in dataDir and runs its pending
+// migrations without writing anything, reporting how many values each
+// would rewrite. keyring opens an encrypted datastore and is nil otherwise.
+func DryRunMigrations(dataDir string, keyring *vault.Keyring) (MigrationReport, error) {
+	ds, err := badger.NewDatastore(filepath.Join(dataDir, "datastore"), &badger.DefaultOptions)
+	if err != nil {
+		return MigrationReport{}, fmt.Errorf("failed to open datastore: %w", err)
+	}
+	defer ds.Close()
+
+	s := &Storage{ds: ds, path: dataDir}
+	if _, err := s.checkKeyring(keyring); err != nil {
+		return MigrationReport{}, err
+	}
+	s.keyring = keyring
+	return s.migrate(true)
+}
+
+// migrate brings the datastore to SchemaVersion. Unless dryRun is set it
+// backs the datastore up first and records the version after every
+// migration, so a failed migration is retried on the next start.
+func (s *Storage) migrate(dryRun bool) (MigrationReport, error) {
+	ctx := context.Background()
+	latest := SchemaVersion()
+	report := MigrationReport{ToVersion: latest, DryRun: dryRun}
+
+	from, fresh, err := s.schemaVersion(ctx)
+	if err != nil {
+		return report, err
+	}
+	if fresh {
+		report.FromVersion = latest
+		if dryRun {
+			return report, nil
+		}
+		if err := s.ds.Put(ctx, schemaVersionKey, []byte(strconv.Itoa(latest))); err != nil {
+			return report, fmt.Errorf("failed to write schema version: %w", err)
+		}
+		return report, nil
+	}
+	report.FromVersion = from
+	if from > latest {
+		return report, fmt.Errorf("datastore schema version %d is newer than this build supports (%d)", from, latest)
+	}
+	if from == latest {
+		return report, nil
+	}
+
+	var overlay map[datastore.Key][]byte
+	if dryRun {
+		// Later migrations must see what earlier ones would have written
+		overlay = make(map[datastore.Key][]byte)
+	} else if report.Backup, err = s.backup(from); err != nil {
+		return report, fmt.Errorf("failed to back up datastore: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= from {
+			continue
+		}
+		changed, err := s.runMigration(ctx, m, overlay)
+		if err != nil {
+			return report, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		report.Migrations = append(report.Migrations, MigrationResult{
+			Version:     m.Version,
+			Description: m.Description,
+			Changed:     changed,
+		})
+	}
+	return report, nil
+}
+
+// schemaVersion reads the stored schema version; fresh reports an empty
+// datastore, which needs no migrations
+func (s *Storage) schemaVersion(ctx context.Context) (version int, fresh bool, err error) {
+	value, err := s.ds.Get(ctx, schemaVersionKey)
+	if err == nil {
+		version, err := strconv.Atoi(string(value))
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid schema version %q", value)
+		}
+		return version, false, nil
+	}
+	if err != datastore.ErrNotFound {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	q, err := s.ds.Query(ctx, query.Query{KeysOnly: true, Limit: 1})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query datastore: %w", err)
+	}
+	results, err := q.Rest()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query datastore: %w", err)
+	}
+	return 0, len(results) == 0, nil
+}
+
+// runMigration rewrites the values under the migration's prefix and
+// stamps its version in the same batch. With an overlay it only records
+// the rewritten values there.
+func (s *Storage) runMigration(ctx context.Context, m Migration, overlay map[datastore.Key][]byte) (int, error) {
+	q, err := s.ds.Query(ctx, query.Query{Prefix: m.Prefix})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", m.Prefix, err)
+	}
+	defer q.Close()
+
+	var batch datastore.Batch
+	if overlay == nil {
+		if batch, err = s.ds.Batch(ctx); err != nil {
+			return 0, fmt.Errorf("failed to start batch: %w", err)
+		}
+	}
+
+	changed := 0
+	for result := range q.Next() {
+		if result.Error != nil {
+			return changed, fmt.Errorf("failed to query %s: %w", m.Prefix, result.Error)
+		}
+		key := datastore.NewKey(result.Key)
+		value, err := s.open(key, result.Value)
+		if err != nil {
+			return changed, fmt.Errorf("%s: %w", key, err)
+		}
+		if pending, ok := overlay[key]; ok {
+			value = pending
+		}
+		rewritten, err := m.Rewrite(key, value)
+		if err != nil {
+			return changed, fmt.Errorf("%s: %w", key, err)
+		}
+		if rewritten == nil {
+			continue
+		}
+		changed++
+		if overlay != nil {
+			overlay[key] = rewritten
+			continue
+		}
+		if rewritten, err = s.seal(key, rewritten); err != nil {
+			return changed, err
+		}
+		if err := batch.Put(ctx, key, rewritten); err != nil {
+			return changed, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+	}
+
+	if overlay != nil {
+		return changed, nil
+	}
+	if err := batch.Put(ctx, schemaVersionKey, []byte(strconv.Itoa(m.Version))); err != nil {
+		return changed, fmt.Errorf("failed to write schema version: %w", err)
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return changed, fmt.Errorf("failed to commit migration: %w", err)
+	}
+	return changed, nil
+}
+
+// backup writes a full badger backup of the datastore to
+// <data>/backups/datastore-v<version>-<time>.badger and returns its path
+func (s *Storage) backup(version int) (string, error) {
+	dir := filepath.Join(s.path, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("datastore-v%d-%s.badger", version, time.Now().UTC().Format("20060102T150405Z")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.ds.DB.Backup(f, 0); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// explicitCTIDVersion stores ctid_version 1 on tracks that have a CTID but
+// were hashed before the version was recorded
+func explicitCTIDVersion(key datastore.Key, value []byte) ([]byte, error) {
+	var track map[string]json.RawMessage
+	if err := json.Unmarshal(value, &track); err != nil {
+		// Unreadable tracks are skipped by every reader; leave them be
+		return nil, nil
+	}
+	var ctid string
+	if raw, ok := track["ctid"]; !ok || json.Unmarshal(raw, &ctid) != nil || ctid == "" {
+		return nil, nil
+	}
+	if _, ok := track["ctid_version"]; ok {
+		return nil, nil
+	}
+	track["ctid_version"] = json.RawMessage("1")
+	return json.Marshal(track)
+}
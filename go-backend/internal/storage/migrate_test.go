@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	badger "github.com/ipfs/go-ds-badger"
+)
+
+// openRaw opens the datastore of a data directory without migrating it
+func openRaw(t *testing.T, dir string) *badger.Datastore {
+	t.Helper()
+	ds, err := badger.NewDatastore(filepath.Join(dir, "datastore"), &badger.DefaultOptions)
+	if err != nil {
+		t.Fatalf("badger.NewDatastore() error: %v", err)
+	}
+	return ds
+}
+
+// loadFixture writes testdata/<name>.json, a map of keys to the values an
+// older build stored, into a new data directory
+func loadFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() error: %v", err)
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+
+	dir := t.TempDir()
+	ds := openRaw(t, dir)
+	defer ds.Close()
+	for key, value := range values {
+		if err := ds.Put(context.Background(), datastore.NewKey(key), value); err != nil {
+			t.Fatalf("Put(%s) error: %v", key, err)
+		}
+	}
+	return dir
+}
+
+func rawTrack(t *testing.T, ds datastore.Read, id string) map[string]any {
+	t.Helper()
+	value, err := ds.Get(context.Background(), datastore.NewKey(trackKey(id)))
+	if err != nil {
+		t.Fatalf("Get(%s) error: %v", id, err)
+	}
+	var track map[string]any
+	if err := json.Unmarshal(value, &track); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	return track
+}
+
+func TestMigrationsAreConsecutive(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 || m.Rewrite == nil || m.Prefix == "" {
+			t.Fatalf("migration %d = version %d, want version %d with a prefix and Rewrite", i, m.Version, i+1)
+		}
+	}
+}
+
+func TestNewMigratesSchemaV0Fixture(t *testing.T) {
+	dir := loadFixture(t, "schema-v0")
+
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	report := store.Migrations()
+	if report.FromVersion != 0 || report.ToVersion != SchemaVersion() || len(report.Migrations) != SchemaVersion() {
+		t.Fatalf("Migrations() = %+v, want every migration from version 0", report)
+	}
+	if report.Migrations[0].Changed != 1 {
+		t.Fatalf("migration 1 changed %d values, want only the unversioned track", report.Migrations[0].Changed)
+	}
+	for id, want := range map[string]int{"legacy": 1, "versioned": 2, "pending": 0} {
+		track, err := store.GetTrack(id)
+		if err != nil || track.CTIDVersion != want {
+			t.Fatalf("GetTrack(%s) = %+v, %v; want ctid_version %d", id, track, err, want)
+		}
+	}
+	if got, err := store.FindTracksByArtist("old artist"); err != nil || len(got) != 1 {
+		t.Fatalf("FindTracksByArtist() = %v, %v; want the migrated track indexed", trackIDs(got), err)
+	}
+	if job, err := store.GetJob("pending"); err != nil || job.State != "queued" {
+		t.Fatalf("GetJob() = %+v, %v; want the fixture job untouched", job, err)
+	}
+	store.Close()
+
+	// The backup holds the datastore as it was before migrating
+	restored := t.TempDir()
+	backup, err := os.Open(report.Backup)
+	if err != nil {
+		t.Fatalf("os.Open(backup) error: %v", err)
+	}
+	defer backup.Close()
+	ds := openRaw(t, restored)
+	if err := ds.DB.Load(backup, 16); err != nil {
+		t.Fatalf("DB.Load() error: %v", err)
+	}
+	if track := rawTrack(t, ds, "legacy"); track["ctid_version"] != nil {
+		t.Fatalf("backed up track = %v, want it without ctid_version", track)
+	}
+	if _, err := ds.Get(context.Background(), schemaVersionKey); err != datastore.ErrNotFound {
+		t.Fatalf("backed up schema version error = %v, want not found", err)
+	}
+	ds.Close()
+
+	// Reopening is a no-op
+	store, err = New(dir)
+	if err != nil {
+		t.Fatalf("New() reopen error: %v", err)
+	}
+	defer store.Close()
+	if report := store.Migrations(); len(report.Migrations) != 0 || report.FromVersion != SchemaVersion() || report.Backup != "" {
+		t.Fatalf("Migrations() after reopen = %+v, want none", report)
+	}
+}
+
+func TestDryRunMigrationsWritesNothing(t *testing.T) {
+	// A second migration depends on what the first would write
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = append(append([]Migration(nil), saved...), Migration{
+		Version:     len(saved) + 1,
+		Description: "tag versioned tracks",
+		Prefix:      "/tracks",
+		Rewrite: func(key datastore.Key, value []byte) ([]byte, error) {
+			var track map[string]any
+			if err := json.Unmarshal(value, &track); err != nil || track["ctid_version"] != float64(1) {
+				return nil, err
+			}
+			track["tagged"] = true
+			return json.Marshal(track)
+		},
+	})
+
+	dir := loadFixture(t, "schema-v0")
+	report, err := DryRunMigrations(dir, nil)
+	if err != nil {
+		t.Fatalf("DryRunMigrations() error: %v", err)
+	}
+	if !report.DryRun || report.Backup != "" || len(report.Migrations) != len(migrations) {
+		t.Fatalf("DryRunMigrations() = %+v, want every migration pending without a backup", report)
+	}
+	for _, m := range []MigrationResult{report.Migrations[0], report.Migrations[len(report.Migrations)-1]} {
+		if m.Changed != 1 {
+			t.Fatalf("dry run migration %d changed %d values, want 1", m.Version, m.Changed)
+		}
+	}
+
+	ds := openRaw(t, dir)
+	defer ds.Close()
+	if track := rawTrack(t, ds, "legacy"); track["ctid_version"] != nil || track["tagged"] != nil {
+		t.Fatalf("track after dry run = %v, want it unchanged", track)
+	}
+	if _, err := ds.Get(context.Background(), schemaVersionKey); err != datastore.ErrNotFound {
+		t.Fatalf("schema version after dry run error = %v, want not found", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "backups")); !os.IsNotExist(err) {
+		t.Fatalf("backups dir after dry run error = %v, want none", err)
+	}
+}
+
+func TestNewStampsFreshDatastores(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if report := store.Migrations(); report.FromVersion != SchemaVersion() || len(report.Migrations) != 0 {
+		t.Fatalf("Migrations() = %+v, want a fresh datastore at the latest version", report)
+	}
+	store.Close()
+	if _, err := os.Stat(filepath.Join(dir, "backups")); !os.IsNotExist(err) {
+		t.Fatalf("backups dir error = %v, want no backup of an empty datastore", err)
+	}
+
+	// A datastore from a newer build is left alone
+	ds := openRaw(t, dir)
+	if err := ds.Put(context.Background(), schemaVersionKey, []byte(strconv.Itoa(SchemaVersion()+1))); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	ds.Close()
+	if _, err := New(dir); err == nil {
+		t.Fatal("New() on a newer schema error = nil, want an error")
+	}
+}
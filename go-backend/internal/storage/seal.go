@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+// An encrypted datastore seals every record value with a vault keyring,
+// bound to its key so values cannot be swapped between records, and keys
+// its indexes by blinded values. Meta keys stay in the clear.
+
+var (
+	// ErrLocked is returned when opening an encrypted datastore without a
+	// keyring
+	ErrLocked = errors.New("datastore is encrypted")
+	// ErrWrongKey is returned when the keyring does not open the datastore
+	ErrWrongKey = errors.New("datastore is encrypted with another key")
+	// ErrNotSealed is returned for a value in the clear in an encrypted
+	// datastore, which only someone bypassing the keyring could have written
+	ErrNotSealed = errors.New("value is not sealed")
+)
+
+// Sealable is a Store keeping its records at rest, which a keyring can
+// encrypt
+type Sealable interface {
+	Store
+	Reseal(keyring *vault.Keyring) (int, error)
+}
+
+// canaryKey holds a value sealed with the key the datastore is encrypted
+// with, absent while it is not encrypted
+var canaryKey = datastore.NewKey("/meta/canary")
+
+// recordPrefixes hold the records an encrypted datastore seals, besides
+// the settings
+var recordPrefixes = []string{"/tracks", "/fingerprints", "/jobs", "/playlists"}
+
+// encode marshals a record stored under key, sealing it when the datastore
+// is encrypted
+func (s *Storage) encode(key datastore.Key, value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.seal(key, data)
+}
+
+// decode unmarshals a record stored under key
+func (s *Storage) decode(key datastore.Key, data []byte, value interface{}) error {
+	data, err := s.open(key, data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (s *Storage) seal(key datastore.Key, data []byte) ([]byte, error) {
+	if s.keyring == nil {
+		return data, nil
+	}
+	return s.keyring.Seal(data, key.String())
+}
+
+// open returns the plaintext of a stored value. Values written before the
+// datastore was encrypted are read as they are until its first reseal
+// completes; from then on a value in the clear is rejected.
+func (s *Storage) open(key datastore.Key, data []byte) ([]byte, error) {
+	if s.sealed && !vault.IsSealed(data) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotSealed)
+	}
+	return s.unseal(key, data)
+}
+
+// unseal is open accepting values in the clear, for reseal
+func (s *Storage) unseal(key datastore.Key, data []byte) ([]byte, error) {
+	if !vault.IsSealed(data) {
+		return data, nil
+	}
+	if s.keyring == nil {
+		return nil, ErrLocked
+	}
+	return s.keyring.Open(data, key.String())
+}
+
+// checkKeyring compares the keyring with the one the datastore was
+// encrypted with, and reports whether records must be resealed with it.
+// Once it has seen the canary, open rejects values in the clear.
+func (s *Storage) checkKeyring(keyring *vault.Keyring) (bool, error) {
+	canary, err := s.ds.Get(context.Background(), canaryKey)
+	if err == datastore.ErrNotFound {
+		return keyring != nil, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read canary: %w", err)
+	}
+	s.sealed = true
+	if keyring == nil {
+		return false, ErrLocked
+	}
+	if _, err := keyring.Open(canary, canaryKey.String()); err != nil {
+		return false, ErrWrongKey
+	}
+	return !keyring.IsCurrent(canary), nil
+}
+
+// Reseal seals every record with the current key of keyring, which from
+// then on encrypts the datastore, and rebuilds the indexes under its
+// blinding. The keyring must open what the datastore holds: a rotating
+// keyring keeps the previous key for that. It returns the number of
+// records sealed; an interrupted reseal runs again when the datastore is
+// next opened with the keyring.
+func (s *Storage) Reseal(keyring *vault.Keyring) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reseal(keyring)
+}
+
+func (s *Storage) reseal(keyring *vault.Keyring) (int, error) {
+	previous := s.keyring
+	s.keyring = keyring
+	n, err := s.resealRecords()
+	if err != nil {
+		s.keyring = previous
+		return n, err
+	}
+
+	// Every record now opens with keyring alone; the canary, written last,
+	// records that
+	ctx := context.Background()
+	if _, err := s.rebuildIndexes(); err != nil {
+		return n, err
+	}
+	canary, err := keyring.Seal([]byte(vault.Format), canaryKey.String())
+	if err != nil {
+		return n, err
+	}
+	if err := s.ds.Put(ctx, canaryKey, canary); err != nil {
+		return n, fmt.Errorf("failed to write canary: %w", err)
+	}
+	s.sealed = true
+	// Let badger drop the values it still holds in the clear or under the
+	// previous key; what it cannot discard yet goes with later compactions
+	s.ds.CollectGarbage(ctx)
+	return n, nil
+}
+
+// resealRecords seals the records not sealed with the current key in one
+// batch
+func (s *Storage) resealRecords() (int, error) {
+	ctx := context.Background()
+	var entries []query.Entry
+	for _, prefix := range recordPrefixes {
+		q, err := s.ds.Query(ctx, query.Query{Prefix: prefix})
+		if err != nil {
+			return 0, fmt.Errorf("failed to query %s: %w", prefix, err)
+		}
+		results, err := q.Rest()
+		q.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to query %s: %w", prefix, err)
+		}
+		entries = append(entries, results...)
+	}
+	settings, err := s.ds.Get(ctx, settingsKey)
+	if err == nil {
+		entries = append(entries, query.Entry{Key: settingsKey.String(), Value: settings})
+	} else if err != datastore.ErrNotFound {
+		return 0, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	batch, err := s.ds.Batch(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start batch: %w", err)
+	}
+	n := 0
+	for _, entry := range entries {
+		if s.keyring.IsCurrent(entry.Value) {
+			continue
+		}
+		key := datastore.NewKey(entry.Key)
+		data, err := s.unseal(key, entry.Value)
+		if err != nil {
+			return n, fmt.Errorf("%s: %w", key, err)
+		}
+		sealed, err := s.seal(key, data)
+		if err != nil {
+			return n, err
+		}
+		if err := batch.Put(ctx, key, sealed); err != nil {
+			return n, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		n++
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return n, fmt.Errorf("failed to commit resealed records: %w", err)
+	}
+	return n, nil
+}
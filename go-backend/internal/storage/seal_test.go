@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/vault"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+// assertNoPlaintext fails if any key or value of the datastore contains one
+// of the words
+func assertNoPlaintext(t *testing.T, s *Storage, words ...string) {
+	t.Helper()
+	q, err := s.ds.Query(context.Background(), query.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := q.Rest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		for _, word := range words {
+			if strings.Contains(strings.ToLower(entry.Key), word) || bytes.Contains(bytes.ToLower(entry.Value), []byte(word)) {
+				t.Fatalf("%s holds %q in the clear", entry.Key, word)
+			}
+		}
+	}
+}
+
+func TestOpenEncryptsExistingDatastore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	store.SaveTrack(&models.Track{ID: "track-1", CTID: "ctid-1", Title: "Night Drive", Artist: "Synthwave Band", Liked: true})
+	store.SavePlaylist(&models.Playlist{ID: "list-1", Name: "Road Trip"})
+	store.SaveSettings(models.Settings{WatchFolders: []string{"/music/secret-folder"}})
+	store.Close()
+
+	_, keyring, err := vault.Create(dir, vault.Secret{Passphrase: "pass"})
+	if err != nil {
+		t.Fatalf("vault.Create() error: %v", err)
+	}
+	store, err = Open(dir, keyring)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	assertNoPlaintext(t, store, "night", "synthwave", "road trip", "secret-folder")
+
+	tracks, err := store.FindTracksByToken("drive")
+	if err != nil || len(tracks) != 1 || tracks[0].Title != "Night Drive" {
+		t.Fatalf("FindTracksByToken() = %+v, %v", tracks, err)
+	}
+	if track, err := store.FindTrackByCTID("ctid-1"); err != nil || track.ID != "track-1" {
+		t.Fatalf("FindTrackByCTID() = %+v, %v", track, err)
+	}
+	if liked, _ := store.LikedTracks(); len(liked) != 1 {
+		t.Fatalf("LikedTracks() = %+v, want the liked track", liked)
+	}
+	if settings, err := store.GetSettings(); err != nil || len(settings.WatchFolders) != 1 {
+		t.Fatalf("GetSettings() = %+v, %v", settings, err)
+	}
+	store.SaveTrack(&models.Track{ID: "track-2", Title: "Morning Walk"})
+	assertNoPlaintext(t, store, "morning")
+	store.Close()
+
+	if _, err := New(dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("New() on encrypted datastore error = %v, want ErrLocked", err)
+	}
+	_, other, _ := vault.Create(t.TempDir(), vault.Secret{Passphrase: "pass"})
+	if _, err := Open(dir, other); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("Open() with another keyring error = %v, want ErrWrongKey", err)
+	}
+	if _, err := DryRunMigrations(dir, nil); !errors.Is(err, ErrLocked) {
+		t.Fatalf("DryRunMigrations() without keyring error = %v, want ErrLocked", err)
+	}
+}
+
+func TestResealAfterKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	secret := vault.Secret{Key: bytes.Repeat([]byte{1}, vault.KeySize)}
+	v, keyring, err := vault.Create(dir, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(dir, keyring)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	store.SaveTrack(&models.Track{ID: "track-1", Title: "Night Drive", Artist: "Band"})
+	store.SaveJob(&models.Job{ID: "track-1", TrackID: "track-1"})
+
+	rotated, err := v.BeginRotation(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Records sealed with the old key stay readable until resealed
+	if track, err := store.GetTrack("track-1"); err != nil || track.Title != "Night Drive" {
+		t.Fatalf("GetTrack() before reseal = %+v, %v", track, err)
+	}
+	n, err := store.Reseal(rotated)
+	if err != nil {
+		t.Fatalf("Reseal() error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("Reseal() = %d, want the track and the job resealed", n)
+	}
+	if err := v.FinishRotation(rotated); err != nil {
+		t.Fatal(err)
+	}
+	if tracks, err := store.FindTracksByToken("night"); err != nil || len(tracks) != 1 {
+		t.Fatalf("FindTracksByToken() after reseal = %+v, %v", tracks, err)
+	}
+	store.Close()
+
+	if _, err := Open(dir, keyring); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("Open() with the retired key error = %v, want ErrWrongKey", err)
+	}
+	store, err = Open(dir, rotated)
+	if err != nil {
+		t.Fatalf("Open() after rotation error: %v", err)
+	}
+	defer store.Close()
+	if job, err := store.GetJob("track-1"); err != nil || job.TrackID != "track-1" {
+		t.Fatalf("GetJob() after rotation = %+v, %v", job, err)
+	}
+}
+
+func TestEncryptedDatastoreRejectsValuesInTheClear(t *testing.T) {
+	dir := t.TempDir()
+	_, keyring, err := vault.Create(dir, vault.Secret{Passphrase: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(dir, keyring)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	store.SaveTrack(&models.Track{ID: "track-1", Title: "Night Drive"})
+	store.Close()
+
+	store, err = Open(dir, keyring)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer store.Close()
+	// As written by someone with access to the files but not the key
+	planted := []byte(`{"id":"track-2","title":"Planted","path":"/etc/passwd"}`)
+	if err := store.ds.Put(context.Background(), datastore.NewKey(trackKey("track-2")), planted); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetTrack("track-2"); !errors.Is(err, ErrNotSealed) {
+		t.Fatalf("GetTrack(planted) error = %v, want ErrNotSealed", err)
+	}
+	if tracks, err := store.GetAllTracks(); err != nil || len(tracks) != 1 || tracks[0].ID != "track-1" {
+		t.Fatalf("GetAllTracks() = %+v, %v; want only the sealed track", tracks, err)
+	}
+}
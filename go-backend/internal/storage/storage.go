@@ -2,27 +2,41 @@ package storage
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/vault"
 	"github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/query"
 	badger "github.com/ipfs/go-ds-badger"
 )
 
-// Storage manages local track storage
+// Storage is the Store kept in a badger datastore under the data directory
 type Storage struct {
-	ds   *badger.Datastore
-	mu   sync.RWMutex
-	path string
+	ds      *badger.Datastore
+	mu      sync.RWMutex
+	path    string
+	keyring *vault.Keyring // nil while the datastore is not encrypted
+	sealed  bool           // every record is sealed, so open rejects values in the clear
+
+	migration MigrationReport
 }
 
-// New creates a new storage instance
+// New creates a new storage instance, migrating the datastore to
+// SchemaVersion after backing it up. It fails with ErrLocked on an
+// encrypted datastore, which Open takes.
 func New(dataDir string) (*Storage, error) {
+	return Open(dataDir, nil)
+}
+
+// Open is New for a datastore encrypted with keyring. A datastore not yet
+// encrypted, or still holding records sealed with a key keyring keeps from
+// before a rotation, is resealed with its current key.
+func Open(dataDir string, keyring *vault.Keyring) (*Storage, error) {
 	dsPath := filepath.Join(dataDir, "datastore")
 	if err := os.MkdirAll(dsPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create datastore dir: %w", err)
@@ -33,27 +47,106 @@ func New(dataDir string) (*Storage, error) {
 		return nil, fmt.Errorf("failed to create datastore: %w", err)
 	}
 
-	return &Storage{
+	s := &Storage{
 		ds:   ds,
 		path: dataDir,
-	}, nil
+	}
+	reseal, err := s.checkKeyring(keyring)
+	if err != nil {
+		ds.Close()
+		return nil, err
+	}
+	s.keyring = keyring
+	if s.migration, err = s.migrate(false); err != nil {
+		ds.Close()
+		return nil, fmt.Errorf("failed to migrate datastore: %w", err)
+	}
+	if reseal {
+		if _, err := s.reseal(keyring); err != nil {
+			ds.Close()
+			return nil, fmt.Errorf("failed to encrypt datastore: %w", err)
+		}
+	} else if len(s.migration.Migrations) > 0 {
+		// Migrations rewrite values without maintaining the indexes
+		if _, err := s.rebuildIndexes(); err != nil {
+			ds.Close()
+			return nil, fmt.Errorf("failed to rebuild indexes: %w", err)
+		}
+	}
+	if err := s.ensureIndexes(); err != nil {
+		ds.Close()
+		return nil, fmt.Errorf("failed to build indexes: %w", err)
+	}
+	return s, nil
 }
 
-// SaveTrack saves a track to storage
+// SaveTrack saves a track to storage, updating its index entries in the
+// same transaction
 func (s *Storage) SaveTrack(track *models.Track) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	ctx := context.Background()
+	txn, err := s.ds.NewTransaction(ctx, false)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer txn.Discard(ctx)
+
+	if err := s.saveTrack(ctx, txn, track); err != nil {
+		return err
+	}
+	if err := txn.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to save track: %w", err)
+	}
+	return nil
+}
+
+// SaveTracks saves many tracks with one transaction per thousand, for
+// imports
+func (s *Storage) SaveTracks(tracks []*models.Track) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := context.Background()
+	for start := 0; start < len(tracks); start += saveTracksPerTxn {
+		txn, err := s.ds.NewTransaction(ctx, false)
+		if err != nil {
+			return fmt.Errorf("failed to start transaction: %w", err)
+		}
+		for _, track := range tracks[start:min(start+saveTracksPerTxn, len(tracks))] {
+			if err := s.saveTrack(ctx, txn, track); err != nil {
+				txn.Discard(ctx)
+				return err
+			}
+		}
+		if err := txn.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to save tracks: %w", err)
+		}
+	}
+	return nil
+}
+
+// saveTracksPerTxn keeps import transactions well below badger's size limit
+const saveTracksPerTxn = 1000
+
+func (s *Storage) saveTrack(ctx context.Context, txn datastore.Txn, track *models.Track) error {
 	key := datastore.NewKey(trackKey(track.ID))
-	data, err := json.Marshal(track)
+	data, err := s.encode(key, track)
 	if err != nil {
 		return fmt.Errorf("failed to marshal track: %w", err)
 	}
 
-	if err := s.ds.Put(context.Background(), key, data); err != nil {
+	old, err := s.getTrack(txn, track.ID)
+	if err != nil {
+		old = nil
+	}
+	if err := s.updateIndexes(ctx, txn, old, track); err != nil {
+		return fmt.Errorf("failed to update indexes: %w", err)
+	}
+	if err := txn.Put(ctx, key, data); err != nil {
 		return fmt.Errorf("failed to save track: %w", err)
 	}
-
 	return nil
 }
 
@@ -62,14 +155,18 @@ func (s *Storage) GetTrack(id string) (*models.Track, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	return s.getTrack(s.ds, id)
+}
+
+func (s *Storage) getTrack(r datastore.Read, id string) (*models.Track, error) {
 	key := datastore.NewKey(trackKey(id))
-	data, err := s.ds.Get(context.Background(), key)
+	data, err := r.Get(context.Background(), key)
 	if err != nil {
 		return nil, fmt.Errorf("track not found: %w", err)
 	}
 
 	var track models.Track
-	if err := json.Unmarshal(data, &track); err != nil {
+	if err := s.decode(key, data, &track); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal track: %w", err)
 	}
 
@@ -97,7 +194,7 @@ func (s *Storage) GetAllTracks() ([]*models.Track, error) {
 		}
 
 		var track models.Track
-		if err := json.Unmarshal(result.Value, &track); err != nil {
+		if err := s.decode(datastore.NewKey(result.Key), result.Value, &track); err != nil {
 			continue
 		}
 
@@ -107,97 +204,394 @@ func (s *Storage) GetAllTracks() ([]*models.Track, error) {
 	return tracks, nil
 }
 
-// DeleteTrack deletes a track
+// DeleteTrack deletes a track and its index entries
 func (s *Storage) DeleteTrack(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	key := datastore.NewKey(trackKey(id))
-	return s.ds.Delete(context.Background(), key)
+	ctx := context.Background()
+	txn, err := s.ds.NewTransaction(ctx, false)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer txn.Discard(ctx)
+
+	if old, err := s.getTrack(txn, id); err == nil {
+		for _, key := range s.indexKeys(old) {
+			if err := txn.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to update indexes: %w", err)
+			}
+		}
+	}
+	if err := txn.Delete(ctx, datastore.NewKey(trackKey(id))); err != nil {
+		return fmt.Errorf("failed to delete track: %w", err)
+	}
+	if err := txn.Delete(ctx, datastore.NewKey(fingerprintKey(id))); err != nil {
+		return fmt.Errorf("failed to delete fingerprint: %w", err)
+	}
+	return txn.Commit(ctx)
 }
 
-// FindTrackByCTID finds a track by CTID
+// FindTrackByCTID finds a track by CTID. A track migrated to a new CTID
+// algorithm is also found by its legacy CTID, so peers holding the old ID
+// can still fetch it.
 func (s *Storage) FindTrackByCTID(ctid string) (*models.Track, error) {
-	tracks, err := s.GetAllTracks()
-	if err != nil {
-		return nil, err
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	for _, track := range tracks {
-		if track.CTID == ctid {
-			return track, nil
+	for _, index := range []string{"ctid", "legacy"} {
+		tracks, err := s.tracksByIndex(index, ctid, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(tracks) > 0 {
+			return tracks[0], nil
 		}
 	}
 
-	return nil, fmt.Errorf("track not found")
+	return nil, fmt.Errorf("track not found: %w", ErrNotFound)
 }
 
-// FindTracksByToken finds tracks that match a token (local search)
+// FindTracksByCTID finds every track with a CTID or legacy CTID, such as
+// copies of one recording imported twice
+func (s *Storage) FindTracksByCTID(ctid string) ([]*models.Track, error) {
+	if ctid == "" {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tracks []*models.Track
+	for _, index := range []string{"ctid", "legacy"} {
+		found, err := s.tracksByIndex(index, ctid, 0)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, found...)
+	}
+	return tracks, nil
+}
+
+// FindTracksByToken finds tracks with a title or artist token, as produced
+// by Tokenize, starting with token (local search)
 func (s *Storage) FindTracksByToken(token string) ([]*models.Track, error) {
-	tracks, err := s.GetAllTracks()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token = strings.ToLower(token)
+	if s.keyring != nil {
+		// Every prefix of a token is indexed, blinded
+		return s.tracksByIndex("token", token, 0)
+	}
+	ids, err := s.prefixedIDs("token", token)
 	if err != nil {
 		return nil, err
 	}
+	return s.tracksByID(ids), nil
+}
+
+// FindTracksByArtist finds tracks by artist, ignoring case
+func (s *Storage) FindTracksByArtist(artist string) ([]*models.Track, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tracksByIndex("artist", strings.ToLower(strings.TrimSpace(artist)), 0)
+}
 
-	tokenLower := toLower(token)
-	var results []*models.Track
+// LikedTracks returns the tracks the user liked
+func (s *Storage) LikedTracks() ([]*models.Track, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	for _, track := range tracks {
-		if containsToken(track.Title, tokenLower) || containsToken(track.Artist, tokenLower) {
-			results = append(results, track)
+	ids, err := s.indexedIDs("liked", "", 0)
+	if err != nil {
+		return nil, err
+	}
+	tracks := make([]*models.Track, 0, len(ids))
+	for _, id := range ids {
+		if track, err := s.getTrack(s.ds, id); err == nil {
+			tracks = append(tracks, track)
 		}
 	}
+	return tracks, nil
+}
 
-	return results, nil
+// FindTracksByFingerprintKey finds tracks sharing a fingerprint key
+func (s *Storage) FindTracksByFingerprintKey(key string) ([]*models.Track, error) {
+	if key == "" {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tracksByIndex("fpkey", key, 0)
 }
 
-// Close closes the storage
-func (s *Storage) Close() error {
-	return s.ds.Close()
+// SaveFingerprint saves the encoded acoustic fingerprint of a track
+func (s *Storage) SaveFingerprint(trackID string, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := datastore.NewKey(fingerprintKey(trackID))
+	data, err := s.seal(key, []byte(fingerprint))
+	if err != nil {
+		return err
+	}
+	if err := s.ds.Put(context.Background(), key, data); err != nil {
+		return fmt.Errorf("failed to save fingerprint: %w", err)
+	}
+	return nil
 }
 
-func trackKey(id string) string {
-	return fmt.Sprintf("/tracks/%s", id)
+// GetFingerprint returns the encoded acoustic fingerprint of a track
+func (s *Storage) GetFingerprint(trackID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := datastore.NewKey(fingerprintKey(trackID))
+	data, err := s.ds.Get(context.Background(), key)
+	if err != nil {
+		return "", fmt.Errorf("fingerprint not found: %w", err)
+	}
+	if data, err = s.open(key, data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// MediaRefs counts the tracks referencing a file in the media store
+func (s *Storage) MediaRefs(hash string) (int, error) {
+	if hash == "" {
+		return 0, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids, err := s.indexedIDs("media", hash, 0)
+	return len(ids), err
+}
+
+// FindTracksByMediaHash finds the tracks referencing a file in the media
+// store
+func (s *Storage) FindTracksByMediaHash(hash string) ([]*models.Track, error) {
+	if hash == "" {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tracksByIndex("media", hash, 0)
 }
 
-func toLower(s string) string {
-	// Simple ASCII lowercase
-	result := make([]byte, len(s))
-	for i := 0; i < len(s); i++ {
-		if s[i] >= 'A' && s[i] <= 'Z' {
-			result[i] = s[i] + 32
-		} else {
-			result[i] = s[i]
+// SaveJob saves a CTR job
+func (s *Storage) SaveJob(job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := datastore.NewKey(jobKey(job.ID))
+	data, err := s.encode(key, job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	if err := s.ds.Put(context.Background(), key, data); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// GetJob retrieves a CTR job by ID
+func (s *Storage) GetJob(id string) (*models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := datastore.NewKey(jobKey(id))
+	data, err := s.ds.Get(context.Background(), key)
+	if err != nil {
+		return nil, fmt.Errorf("job not found: %w", err)
+	}
+
+	var job models.Job
+	if err := s.decode(key, data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetAllJobs returns all CTR jobs
+func (s *Storage) GetAllJobs() ([]*models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, err := s.ds.Query(context.Background(), query.Query{
+		Prefix: datastore.NewKey("/jobs/").String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	defer q.Close()
+
+	var jobs []*models.Job
+	for result := range q.Next() {
+		if result.Error != nil {
+			continue
+		}
+
+		var job models.Job
+		if err := s.decode(datastore.NewKey(result.Key), result.Value, &job); err != nil {
+			continue
 		}
+		jobs = append(jobs, &job)
 	}
-	return string(result)
+	return jobs, nil
 }
 
-func containsToken(text, token string) bool {
-	textLower := toLower(text)
-	return len(textLower) >= len(token) &&
-		(textLower == token ||
-			contains(textLower, token))
+// DeleteJob deletes a CTR job
+func (s *Storage) DeleteJob(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ds.Delete(context.Background(), datastore.NewKey(jobKey(id)))
 }
 
-func contains(s, substr string) bool {
-	if len(substr) == 0 {
-		return true
+// SavePlaylist saves a playlist
+func (s *Storage) SavePlaylist(playlist *models.Playlist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := datastore.NewKey(playlistKey(playlist.ID))
+	data, err := s.encode(key, playlist)
+	if err != nil {
+		return fmt.Errorf("failed to marshal playlist: %w", err)
 	}
-	if len(substr) > len(s) {
-		return false
+	if err := s.ds.Put(context.Background(), key, data); err != nil {
+		return fmt.Errorf("failed to save playlist: %w", err)
 	}
-	for i := 0; i <= len(s)-len(substr); i++ {
-		match := true
-		for j := 0; j < len(substr); j++ {
-			if s[i+j] != substr[j] {
-				match = false
-				break
-			}
+	return nil
+}
+
+// GetPlaylist retrieves a playlist by ID
+func (s *Storage) GetPlaylist(id string) (*models.Playlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := datastore.NewKey(playlistKey(id))
+	data, err := s.ds.Get(context.Background(), key)
+	if err != nil {
+		return nil, fmt.Errorf("playlist not found: %w", err)
+	}
+
+	var playlist models.Playlist
+	if err := s.decode(key, data, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal playlist: %w", err)
+	}
+	return &playlist, nil
+}
+
+// GetAllPlaylists returns all playlists
+func (s *Storage) GetAllPlaylists() ([]*models.Playlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	q, err := s.ds.Query(context.Background(), query.Query{
+		Prefix: datastore.NewKey("/playlists/").String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	defer q.Close()
+
+	var playlists []*models.Playlist
+	for result := range q.Next() {
+		if result.Error != nil {
+			continue
 		}
-		if match {
-			return true
+
+		var playlist models.Playlist
+		if err := s.decode(datastore.NewKey(result.Key), result.Value, &playlist); err != nil {
+			continue
+		}
+		playlists = append(playlists, &playlist)
+	}
+	return playlists, nil
+}
+
+// FindPlaylistByRecord finds the shared playlist published as a record.
+// Libraries hold few playlists, so they are scanned rather than indexed.
+func (s *Storage) FindPlaylistByRecord(recordID string) (*models.Playlist, error) {
+	playlists, err := s.GetAllPlaylists()
+	if err != nil {
+		return nil, err
+	}
+	for _, playlist := range playlists {
+		if playlist.Shared && playlist.RecordID == recordID {
+			return playlist, nil
 		}
 	}
-	return false
+	return nil, fmt.Errorf("playlist not found: %w", ErrNotFound)
+}
+
+// DeletePlaylist deletes a playlist
+func (s *Storage) DeletePlaylist(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ds.Delete(context.Background(), datastore.NewKey(playlistKey(id)))
+}
+
+// GetSettings returns the stored daemon settings, zero if none were saved
+func (s *Storage) GetSettings() (models.Settings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var settings models.Settings
+	data, err := s.ds.Get(context.Background(), settingsKey)
+	if err == datastore.ErrNotFound {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := s.decode(settingsKey, data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveSettings saves the daemon settings
+func (s *Storage) SaveSettings(settings models.Settings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.encode(settingsKey, settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := s.ds.Put(context.Background(), settingsKey, data); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
+
+// Close closes the storage
+func (s *Storage) Close() error {
+	return s.ds.Close()
+}
+
+// settingsKey holds the daemon settings
+var settingsKey = datastore.NewKey("/settings")
+
+func trackKey(id string) string {
+	return fmt.Sprintf("/tracks/%s", id)
+}
+
+func fingerprintKey(trackID string) string {
+	return fmt.Sprintf("/fingerprints/%s", trackID)
+}
+
+func jobKey(id string) string {
+	return fmt.Sprintf("/jobs/%s", id)
+}
+
+func playlistKey(id string) string {
+	return fmt.Sprintf("/playlists/%s", id)
 }
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/models"
+)
+
+// benchmarkSizes shows lookups staying flat as the library grows
+var benchmarkSizes = []int{1000, 10000, 50000}
+
+// newBenchmarkStore fills a store with n tracks; track i has CTID ctid-<i>,
+// the unique title token song<i> and shares its artist with one other track
+func newBenchmarkStore(b *testing.B, n int) *Storage {
+	b.Helper()
+	store, err := New(b.TempDir())
+	if err != nil {
+		b.Fatalf("New() error: %v", err)
+	}
+	b.Cleanup(func() { store.Close() })
+
+	tracks := make([]*models.Track, n)
+	for i := range tracks {
+		tracks[i] = &models.Track{
+			ID:         fmt.Sprintf("track-%d", i),
+			CTID:       fmt.Sprintf("ctid-%d", i),
+			Title:      fmt.Sprintf("Song%d", i),
+			Artist:     fmt.Sprintf("Artist%d", i/2),
+			Recognized: true,
+		}
+	}
+	if err := store.SaveTracks(tracks); err != nil {
+		b.Fatalf("SaveTracks() error: %v", err)
+	}
+	return store
+}
+
+func BenchmarkFindTrackByCTID(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tracks=%d", n), func(b *testing.B) {
+			store := newBenchmarkStore(b, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := store.FindTrackByCTID(fmt.Sprintf("ctid-%d", i%n)); err != nil {
+					b.Fatalf("FindTrackByCTID() error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindTracksByToken(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tracks=%d", n), func(b *testing.B) {
+			store := newBenchmarkStore(b, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Unique tokens keep the result size fixed across library sizes
+				if got, err := store.FindTracksByToken(fmt.Sprintf("song%d", i%n)); err != nil || len(got) != 1 {
+					b.Fatalf("FindTracksByToken() = %d tracks, %v; want 1", len(got), err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindTracksByArtist(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tracks=%d", n), func(b *testing.B) {
+			store := newBenchmarkStore(b, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				artist := fmt.Sprintf("Artist%d", i%n/2)
+				if got, err := store.FindTracksByArtist(artist); err != nil || len(got) != 2 {
+					b.Fatalf("FindTracksByArtist() = %d tracks, %v; want 2", len(got), err)
+				}
+			}
+		})
+	}
+}
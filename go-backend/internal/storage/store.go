@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/ipfs/go-datastore"
+)
+
+// ErrNotFound is wrapped by the errors Store returns for missing records
+var ErrNotFound = datastore.ErrNotFound
+
+// Store is the library storage services work with. Storage keeps it in
+// badger; Memory keeps it in memory for tests and ephemeral nodes. Every
+// implementation must pass the suite in storetest.
+//
+// Values are copies: changing a returned track does not change the stored
+// one until it is saved again.
+type Store interface {
+	// SaveTrack saves a track, replacing the one with the same ID
+	SaveTrack(track *models.Track) error
+	// SaveTracks saves many tracks, for imports
+	SaveTracks(tracks []*models.Track) error
+	// GetTrack retrieves a track by ID
+	GetTrack(id string) (*models.Track, error)
+	// GetAllTracks returns all tracks
+	GetAllTracks() ([]*models.Track, error)
+	// DeleteTrack deletes a track
+	DeleteTrack(id string) error
+	// FindTrackByCTID finds a track by CTID, then by legacy CTID
+	FindTrackByCTID(ctid string) (*models.Track, error)
+	// FindTracksByCTID finds every track with a CTID or legacy CTID
+	FindTracksByCTID(ctid string) ([]*models.Track, error)
+	// FindTracksByToken finds tracks with a title or artist token, as
+	// produced by Tokenize, starting with token
+	FindTracksByToken(token string) ([]*models.Track, error)
+	// FindTracksByArtist finds tracks by artist, ignoring case
+	FindTracksByArtist(artist string) ([]*models.Track, error)
+	// LikedTracks returns the tracks the user liked
+	LikedTracks() ([]*models.Track, error)
+	// FindTracksByFingerprintKey finds tracks sharing a fingerprint key
+	FindTracksByFingerprintKey(key string) ([]*models.Track, error)
+	// SaveFingerprint saves the encoded acoustic fingerprint of a track,
+	// kept apart from the track since only similarity lookups read it.
+	// DeleteTrack deletes it with the track.
+	SaveFingerprint(trackID string, fingerprint string) error
+	// GetFingerprint returns the encoded acoustic fingerprint of a track
+	GetFingerprint(trackID string) (string, error)
+	// MediaRefs counts the tracks referencing a file in the media store
+	MediaRefs(hash string) (int, error)
+	// FindTracksByMediaHash finds the tracks referencing a file in the
+	// media store
+	FindTracksByMediaHash(hash string) ([]*models.Track, error)
+
+	// SaveJob saves a CTR job
+	SaveJob(job *models.Job) error
+	// GetJob retrieves a CTR job by ID
+	GetJob(id string) (*models.Job, error)
+	// GetAllJobs returns all CTR jobs
+	GetAllJobs() ([]*models.Job, error)
+	// DeleteJob deletes a CTR job
+	DeleteJob(id string) error
+
+	// SavePlaylist saves a playlist
+	SavePlaylist(playlist *models.Playlist) error
+	// GetPlaylist retrieves a playlist by ID
+	GetPlaylist(id string) (*models.Playlist, error)
+	// GetAllPlaylists returns all playlists
+	GetAllPlaylists() ([]*models.Playlist, error)
+	// FindPlaylistByRecord finds the shared playlist published as a record
+	FindPlaylistByRecord(recordID string) (*models.Playlist, error)
+	// DeletePlaylist deletes a playlist
+	DeletePlaylist(id string) error
+
+	// GetSettings returns the daemon settings, zero if none were saved
+	GetSettings() (models.Settings, error)
+	// SaveSettings saves the daemon settings
+	SaveSettings(settings models.Settings) error
+
+	// Close releases the store
+	Close() error
+}
+
+var (
+	_ Store = (*Storage)(nil)
+	_ Store = (*Memory)(nil)
+)
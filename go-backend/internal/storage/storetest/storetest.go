@@ -0,0 +1,409 @@
+// Package storetest is the conformance suite every storage.Store
+// implementation must pass
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+// Run runs the suite, opening an empty store for every test with open. The
+// store is closed by the suite.
+func Run(t *testing.T, open func(t *testing.T) storage.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store storage.Store)
+	}{
+		{"TrackCRUD", testTrackCRUD},
+		{"ReturnedTracksAreCopies", testReturnedTracksAreCopies},
+		{"SaveTracks", testSaveTracks},
+		{"FingerprintsFollowTracks", testFingerprintsFollowTracks},
+		{"FindTrackByCTIDMatchesLegacyCTID", testFindTrackByCTIDMatchesLegacyCTID},
+		{"FindTracksByCTIDReturnsEveryCopy", testFindTracksByCTIDReturnsEveryCopy},
+		{"LookupsFollowSaves", testLookupsFollowSaves},
+		{"FindTracksByTokenMatchesPrefixes", testFindTracksByTokenMatchesPrefixes},
+		{"JobCRUD", testJobCRUD},
+		{"PlaylistCRUD", testPlaylistCRUD},
+		{"Settings", testSettings},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+			tc.fn(t, store)
+		})
+	}
+}
+
+func trackIDs(tracks []*models.Track) []string {
+	ids := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.ID)
+	}
+	return ids
+}
+
+func testTrackCRUD(t *testing.T, store storage.Store) {
+	track := &models.Track{
+		ID:         "track-1",
+		CTID:       "ctid-1",
+		Title:      "Night Drive",
+		Artist:     "CoTune Artist",
+		Path:       "C:/music/night-drive.wav",
+		Liked:      true,
+		Recognized: true,
+		Loudness:   &models.Loudness{IntegratedLUFS: -9.5},
+	}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+
+	got, err := store.GetTrack(track.ID)
+	if err != nil {
+		t.Fatalf("GetTrack() error: %v", err)
+	}
+	if got.Title != track.Title || got.Artist != track.Artist || got.CTID != track.CTID || got.Loudness == nil || got.Loudness.IntegratedLUFS != -9.5 {
+		t.Fatalf("GetTrack() = %+v, want %+v", got, track)
+	}
+	if _, err := store.GetTrack("missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetTrack(missing) error = %v, want ErrNotFound", err)
+	}
+
+	byCTID, err := store.FindTrackByCTID(track.CTID)
+	if err != nil || byCTID.ID != track.ID {
+		t.Fatalf("FindTrackByCTID() = %v, %v; want %s", byCTID, err, track.ID)
+	}
+	if byTitle, err := store.FindTracksByToken("drive"); err != nil || len(byTitle) != 1 || byTitle[0].ID != track.ID {
+		t.Fatalf("FindTracksByToken(title) = %v, %v; want %s", trackIDs(byTitle), err, track.ID)
+	}
+	if byArtist, err := store.FindTracksByToken("Artist"); err != nil || len(byArtist) != 1 {
+		t.Fatalf("FindTracksByToken(artist) = %v, %v; want %s", trackIDs(byArtist), err, track.ID)
+	}
+
+	if err := store.DeleteTrack(track.ID); err != nil {
+		t.Fatalf("DeleteTrack() error: %v", err)
+	}
+	if _, err := store.GetTrack(track.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetTrack() after delete error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.FindTrackByCTID(track.CTID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("FindTrackByCTID() after delete error = %v, want ErrNotFound", err)
+	}
+	if tracks, err := store.GetAllTracks(); err != nil || len(tracks) != 0 {
+		t.Fatalf("GetAllTracks() after delete = %v, %v; want none", trackIDs(tracks), err)
+	}
+}
+
+func testReturnedTracksAreCopies(t *testing.T, store storage.Store) {
+	track := &models.Track{ID: "t1", Title: "Original"}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	track.Title = "Changed after save"
+
+	got, _ := store.GetTrack("t1")
+	if got.Title != "Original" {
+		t.Fatalf("stored title = %q, want the saved one", got.Title)
+	}
+	got.Title = "Changed after get"
+	if again, _ := store.GetTrack("t1"); again.Title != "Original" {
+		t.Fatalf("stored title = %q after changing a returned track, want the saved one", again.Title)
+	}
+}
+
+func testSaveTracks(t *testing.T, store storage.Store) {
+	var tracks []*models.Track
+	for i := 0; i < 2500; i++ {
+		tracks = append(tracks, &models.Track{ID: fmt.Sprintf("t%04d", i), CTID: fmt.Sprintf("ctid-%d", i), Artist: "Bulk"})
+	}
+	if err := store.SaveTracks(tracks); err != nil {
+		t.Fatalf("SaveTracks() error: %v", err)
+	}
+
+	all, err := store.GetAllTracks()
+	if err != nil || len(all) != len(tracks) {
+		t.Fatalf("GetAllTracks() = %d tracks, %v; want %d", len(all), err, len(tracks))
+	}
+	if got, err := store.FindTrackByCTID("ctid-2499"); err != nil || got.ID != "t2499" {
+		t.Fatalf("FindTrackByCTID() = %v, %v; want t2499", got, err)
+	}
+	if byArtist, err := store.FindTracksByArtist(" bulk "); err != nil || len(byArtist) != len(tracks) {
+		t.Fatalf("FindTracksByArtist() = %d tracks, %v; want %d", len(byArtist), err, len(tracks))
+	}
+}
+
+func testFingerprintsFollowTracks(t *testing.T, store storage.Store) {
+	track := &models.Track{ID: "t1", CTID: "ctid-1", FingerprintKey: "fp1:18:abcd"}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	if _, err := store.GetFingerprint(track.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetFingerprint() before save error = %v, want ErrNotFound", err)
+	}
+	if err := store.SaveFingerprint(track.ID, "AQIDBA=="); err != nil {
+		t.Fatalf("SaveFingerprint() error: %v", err)
+	}
+	if fp, err := store.GetFingerprint(track.ID); err != nil || fp != "AQIDBA==" {
+		t.Fatalf("GetFingerprint() = %q, %v; want the saved fingerprint", fp, err)
+	}
+	if byKey, err := store.FindTracksByFingerprintKey(track.FingerprintKey); err != nil || len(byKey) != 1 {
+		t.Fatalf("FindTracksByFingerprintKey() = %v, %v; want %s", trackIDs(byKey), err, track.ID)
+	}
+
+	if err := store.DeleteTrack(track.ID); err != nil {
+		t.Fatalf("DeleteTrack() error: %v", err)
+	}
+	if _, err := store.GetFingerprint(track.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetFingerprint() after DeleteTrack error = %v, want ErrNotFound", err)
+	}
+}
+
+func testFindTrackByCTIDMatchesLegacyCTID(t *testing.T, store storage.Store) {
+	migrated := &models.Track{ID: "track-1", CTID: "ctid-v2", CTIDVersion: 2, LegacyCTID: "ctid-v1"}
+	// A track whose current CTID equals another's legacy one takes precedence
+	current := &models.Track{ID: "track-2", CTID: "ctid-shared", CTIDVersion: 2}
+	stale := &models.Track{ID: "track-3", CTID: "ctid-other", CTIDVersion: 2, LegacyCTID: "ctid-shared"}
+	for _, track := range []*models.Track{migrated, current, stale} {
+		if err := store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+
+	for ctid, wantID := range map[string]string{"ctid-v2": "track-1", "ctid-v1": "track-1", "ctid-shared": "track-2"} {
+		got, err := store.FindTrackByCTID(ctid)
+		if err != nil {
+			t.Fatalf("FindTrackByCTID(%q) error: %v", ctid, err)
+		}
+		if got.ID != wantID {
+			t.Fatalf("FindTrackByCTID(%q) ID = %q, want %q", ctid, got.ID, wantID)
+		}
+	}
+}
+
+func testFindTracksByCTIDReturnsEveryCopy(t *testing.T, store storage.Store) {
+	for _, track := range []*models.Track{
+		{ID: "copy-1", CTID: "ctid-a"},
+		{ID: "copy-2", CTID: "ctid-a", Unshared: true},
+		{ID: "migrated", CTID: "ctid-b", LegacyCTID: "ctid-a"},
+		{ID: "other", CTID: "ctid-c"},
+	} {
+		if err := store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+
+	got, err := store.FindTracksByCTID("ctid-a")
+	if err != nil {
+		t.Fatalf("FindTracksByCTID() error: %v", err)
+	}
+	ids := trackIDs(got)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"copy-1", "copy-2", "migrated"}) {
+		t.Fatalf("FindTracksByCTID() = %v, want both copies and the migrated track", ids)
+	}
+	if got, err := store.FindTracksByCTID("ctid-none"); err != nil || len(got) != 0 {
+		t.Fatalf("FindTracksByCTID(unknown) = %v, %v; want none", trackIDs(got), err)
+	}
+}
+
+func testFindTracksByTokenMatchesPrefixes(t *testing.T, store storage.Store) {
+	tracks := []*models.Track{
+		{ID: "t1", Title: "Help!", Artist: "The Beatles"},
+		{ID: "t2", Title: "Beat It", Artist: "Michael Jackson"},
+		{ID: "t3", Title: "Björk Beat", Artist: "Björk"},
+	}
+	if err := store.SaveTracks(tracks); err != nil {
+		t.Fatalf("SaveTracks() error: %v", err)
+	}
+	// Tokens match from their start, not inside them
+	for token, want := range map[string][]string{
+		"beat":     {"t1", "t2", "t3"},
+		"BEATL":    {"t1"},
+		"beatles":  {"t1"},
+		"björ":     {"t3"},
+		"eat":      nil,
+		"tles":     nil,
+		"beatlesx": nil,
+	} {
+		got, err := store.FindTracksByToken(token)
+		if err != nil {
+			t.Fatalf("FindTracksByToken(%s) error: %v", token, err)
+		}
+		ids := trackIDs(got)
+		slices.Sort(ids)
+		if !slices.Equal(ids, want) && !(len(ids) == 0 && len(want) == 0) {
+			t.Fatalf("FindTracksByToken(%s) = %v, want %v", token, ids, want)
+		}
+	}
+}
+
+func testLookupsFollowSaves(t *testing.T, store storage.Store) {
+	track := &models.Track{ID: "t1", CTID: "ctid-a", Title: "Back in Black", Artist: "AC/DC", Liked: true, FingerprintKey: "fp1", MediaHash: "m1"}
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	if got, err := store.FindTracksByToken("ac/dc"); err != nil || len(got) != 1 {
+		t.Fatalf("FindTracksByToken(ac/dc) = %v, %v; want t1", trackIDs(got), err)
+	}
+	if got, err := store.FindTracksByArtist("ac/dc"); err != nil || len(got) != 1 {
+		t.Fatalf("FindTracksByArtist(ac/dc) = %v, %v; want t1", trackIDs(got), err)
+	}
+	if got, err := store.LikedTracks(); err != nil || len(got) != 1 {
+		t.Fatalf("LikedTracks() = %v, %v; want t1", trackIDs(got), err)
+	}
+	if got, err := store.FindTracksByFingerprintKey("fp1"); err != nil || len(got) != 1 {
+		t.Fatalf("FindTracksByFingerprintKey(fp1) = %v, %v; want t1", trackIDs(got), err)
+	}
+	if refs, err := store.MediaRefs("m1"); err != nil || refs != 1 {
+		t.Fatalf("MediaRefs(m1) = %d, %v; want 1", refs, err)
+	}
+	second := &models.Track{ID: "t2", Title: "Copy", MediaHash: "m1"}
+	if err := store.SaveTrack(second); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	if got, err := store.FindTracksByMediaHash("m1"); err != nil || len(got) != 2 {
+		t.Fatalf("FindTracksByMediaHash(m1) = %v, %v; want t1 and t2", trackIDs(got), err)
+	}
+	if err := store.DeleteTrack("t2"); err != nil {
+		t.Fatalf("DeleteTrack() error: %v", err)
+	}
+
+	// Re-saving moves every lookup to the new values
+	track.CTID, track.LegacyCTID = "ctid-b", "ctid-a"
+	track.Title, track.Artist, track.Liked, track.FingerprintKey = "Thunderstruck", "AC-DC", false, ""
+	if err := store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	for token, want := range map[string]int{"black": 0, "ac/dc": 0, "thunderstruck": 1, "ac": 1, "dc": 1} {
+		if got, err := store.FindTracksByToken(token); err != nil || len(got) != want {
+			t.Fatalf("FindTracksByToken(%s) = %v, %v; want %d tracks", token, trackIDs(got), err, want)
+		}
+	}
+	if got, _ := store.FindTracksByArtist("ac/dc"); len(got) != 0 {
+		t.Fatalf("FindTracksByArtist(ac/dc) = %v after rename, want none", trackIDs(got))
+	}
+	if got, _ := store.LikedTracks(); len(got) != 0 {
+		t.Fatalf("LikedTracks() = %v after unlike, want none", trackIDs(got))
+	}
+	if refs, _ := store.MediaRefs("m1"); refs != 1 {
+		t.Fatalf("MediaRefs(m1) = %d after re-save, want 1", refs)
+	}
+	if got, _ := store.FindTracksByFingerprintKey("fp1"); len(got) != 0 {
+		t.Fatalf("FindTracksByFingerprintKey(fp1) = %v, want none", trackIDs(got))
+	}
+	if got, err := store.FindTrackByCTID("ctid-a"); err != nil || got.ID != "t1" {
+		t.Fatalf("FindTrackByCTID(legacy) = %v, %v; want t1", got, err)
+	}
+
+	if err := store.DeleteTrack("t1"); err != nil {
+		t.Fatalf("DeleteTrack() error: %v", err)
+	}
+	if _, err := store.FindTrackByCTID("ctid-b"); err == nil {
+		t.Fatal("FindTrackByCTID() after delete error = nil, want not found")
+	}
+	if got, _ := store.FindTracksByToken("thunderstruck"); len(got) != 0 {
+		t.Fatalf("FindTracksByToken() after delete = %v, want none", trackIDs(got))
+	}
+	if refs, _ := store.MediaRefs("m1"); refs != 0 {
+		t.Fatalf("MediaRefs(m1) after delete = %d, want 0", refs)
+	}
+}
+
+func testJobCRUD(t *testing.T, store storage.Store) {
+	job := &models.Job{ID: "t1", TrackID: "t1", State: models.JobQueued, CreatedAt: 100}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob() error: %v", err)
+	}
+	job.State, job.Attempts = models.JobFailed, 3
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob() error: %v", err)
+	}
+	if err := store.SaveJob(&models.Job{ID: "t2", TrackID: "t2", State: models.JobFailed}); err != nil {
+		t.Fatalf("SaveJob() error: %v", err)
+	}
+
+	got, err := store.GetJob("t1")
+	if err != nil || got.State != models.JobFailed || got.Attempts != 3 || got.CreatedAt != 100 {
+		t.Fatalf("GetJob() = %+v, %v; want the updated job", got, err)
+	}
+	if jobs, err := store.GetAllJobs(); err != nil || len(jobs) != 2 {
+		t.Fatalf("GetAllJobs() = %d jobs, %v; want 2", len(jobs), err)
+	}
+
+	if err := store.DeleteJob("t1"); err != nil {
+		t.Fatalf("DeleteJob() error: %v", err)
+	}
+	if _, err := store.GetJob("t1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetJob() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func testPlaylistCRUD(t *testing.T, store storage.Store) {
+	playlist := &models.Playlist{
+		ID:   "playlist-1",
+		Name: "Road Trip",
+		Entries: []models.PlaylistEntry{
+			{CTID: "ctid-2", Title: "Second"},
+			{CTID: "ctid-1", Title: "First"},
+		},
+	}
+	if err := store.SavePlaylist(playlist); err != nil {
+		t.Fatalf("SavePlaylist() error: %v", err)
+	}
+
+	got, err := store.GetPlaylist(playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylist() error: %v", err)
+	}
+	if got.Name != playlist.Name || len(got.Entries) != 2 || got.Entries[0].CTID != "ctid-2" {
+		t.Fatalf("GetPlaylist() = %+v, want entries in saved order", got)
+	}
+
+	// Only shared playlists are found by record
+	playlist.RecordID = "record-1"
+	if err := store.SavePlaylist(playlist); err != nil {
+		t.Fatalf("SavePlaylist() error: %v", err)
+	}
+	if _, err := store.FindPlaylistByRecord("record-1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("FindPlaylistByRecord() of an unshared playlist error = %v, want ErrNotFound", err)
+	}
+	playlist.Shared = true
+	if err := store.SavePlaylist(playlist); err != nil {
+		t.Fatalf("SavePlaylist() error: %v", err)
+	}
+	if found, err := store.FindPlaylistByRecord("record-1"); err != nil || found.ID != playlist.ID {
+		t.Fatalf("FindPlaylistByRecord() = %+v, %v; want %s", found, err, playlist.ID)
+	}
+
+	if err := store.DeletePlaylist(playlist.ID); err != nil {
+		t.Fatalf("DeletePlaylist() error: %v", err)
+	}
+	if _, err := store.GetPlaylist(playlist.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetPlaylist() after delete error = %v, want ErrNotFound", err)
+	}
+	if playlists, err := store.GetAllPlaylists(); err != nil || len(playlists) != 0 {
+		t.Fatalf("GetAllPlaylists() after delete = %+v, %v; want none", playlists, err)
+	}
+}
+
+func testSettings(t *testing.T, store storage.Store) {
+	settings, err := store.GetSettings()
+	if err != nil || settings.StorageBudget != 0 || len(settings.WatchFolders) != 0 {
+		t.Fatalf("GetSettings() = %+v, %v; want zero settings before any save", settings, err)
+	}
+
+	saved := models.Settings{StorageBudget: 1 << 30, WatchFolders: []string{"/music"}}
+	if err := store.SaveSettings(saved); err != nil {
+		t.Fatalf("SaveSettings() error: %v", err)
+	}
+	saved.WatchFolders[0] = "/changed"
+	settings, err = store.GetSettings()
+	if err != nil || settings.StorageBudget != 1<<30 || len(settings.WatchFolders) != 1 || settings.WatchFolders[0] != "/music" {
+		t.Fatalf("GetSettings() = %+v, %v; want the saved settings", settings, err)
+	}
+}
@@ -0,0 +1,116 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/cotune/go-backend/internal/artwork"
+)
+
+// ArtworkProtocol is the protocol ID for fetching covers by CTID
+const ArtworkProtocol = "/cotune/artwork/1.0.0"
+
+// ArtworkRequest asks a peer for the cover of a CTID
+type ArtworkRequest struct {
+	CTID string `json:"ctid"`
+	Size string `json:"size,omitempty"` // artwork.Size; empty means medium
+}
+
+// ArtworkResponse precedes the image, which follows as one raw
+// length-prefixed message unless Error is set
+type ArtworkResponse struct {
+	MIMEType string `json:"mime_type,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ErrNoArtwork is returned when a track has no cover
+var ErrNoArtwork = errors.New("no artwork")
+
+// LocalArtwork returns the cover of a local track
+func (s *Service) LocalArtwork(ctid string, size artwork.Size) ([]byte, string, error) {
+	if s.artwork == nil {
+		return nil, "", ErrNoArtwork
+	}
+	track, err := s.store.FindTrackByCTID(ctid)
+	if err != nil {
+		return nil, "", fmt.Errorf("track not found: %s", ctid)
+	}
+	if track.Artwork == "" {
+		return nil, "", ErrNoArtwork
+	}
+	data, mime, err := s.artwork.Get(track.Artwork, size)
+	if errors.Is(err, artwork.ErrNotFound) {
+		return nil, "", ErrNoArtwork
+	}
+	return data, mime, err
+}
+
+// handleArtwork serves covers of local tracks
+func (s *Service) handleArtwork(stream network.Stream) {
+	defer stream.Close()
+
+	var req ArtworkRequest
+	if err := readJSON(stream, &req); err != nil {
+		return
+	}
+	size, err := artwork.ParseSize(req.Size)
+	if err != nil {
+		writeJSON(stream, ArtworkResponse{Error: err.Error()})
+		return
+	}
+
+	data, mime, err := s.LocalArtwork(req.CTID, size)
+	if err != nil {
+		writeJSON(stream, ArtworkResponse{Error: err.Error()})
+		return
+	}
+	if err := writeJSON(stream, ArtworkResponse{MIMEType: mime}); err != nil {
+		return
+	}
+	writeMessage(stream, data)
+}
+
+// FetchArtwork fetches the cover of a CTID from a peer
+func (s *Service) FetchArtwork(ctx context.Context, peerID peer.ID, ctid string, size artwork.Size) ([]byte, string, error) {
+	if s.h.Network().Connectedness(peerID) != network.Connected {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		info := s.h.Peerstore().PeerInfo(peerID)
+		if err := s.h.Connect(ctx, info); err != nil {
+			return nil, "", fmt.Errorf("failed to connect to peer: %w", err)
+		}
+	}
+
+	stream, err := s.h.NewStream(ctx, peerID, protocol.ID(ArtworkProtocol))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	if err := writeJSON(stream, ArtworkRequest{CTID: ctid, Size: string(size)}); err != nil {
+		return nil, "", fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp ArtworkResponse
+	if err := readJSON(stream, &resp); err != nil {
+		return nil, "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		if resp.Error == ErrNoArtwork.Error() {
+			return nil, "", ErrNoArtwork
+		}
+		return nil, "", fmt.Errorf("artwork error: %s", resp.Error)
+	}
+	data, err := readMessage(stream)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read artwork: %w", err)
+	}
+	return data, resp.MIMEType, nil
+}
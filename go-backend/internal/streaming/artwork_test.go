@@ -0,0 +1,75 @@
+package streaming
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+func newTestPeers(t *testing.T) (server, client *Service) {
+	t.Helper()
+	net, err := mocknet.FullMeshLinked(2)
+	if err != nil {
+		t.Fatalf("mocknet.FullMeshLinked() error: %v", err)
+	}
+	t.Cleanup(func() { net.Close() })
+
+	services := make([]*Service, 2)
+	for i, h := range net.Hosts() {
+		store := storage.NewMemory()
+		covers, err := artwork.New(t.TempDir())
+		if err != nil {
+			t.Fatalf("artwork.New() error: %v", err)
+		}
+		services[i] = New(h, store, covers)
+	}
+	return services[0], services[1]
+}
+
+func TestFetchArtworkFromPeer(t *testing.T) {
+	server, client := newTestPeers(t)
+
+	var cover bytes.Buffer
+	if err := png.Encode(&cover, image.NewGray(image.Rect(0, 0, 500, 500))); err != nil {
+		t.Fatalf("png.Encode() error: %v", err)
+	}
+	hash, err := server.artwork.Put(cover.Bytes())
+	if err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	tracks := []*models.Track{
+		{ID: "covered", CTID: "ctid-covered", Artwork: hash},
+		{ID: "bare", CTID: "ctid-bare"},
+	}
+	for _, track := range tracks {
+		if err := server.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	data, mime, err := client.FetchArtwork(ctx, server.h.ID(), "ctid-covered", artwork.SizeOriginal)
+	if err != nil || mime != "image/png" || !bytes.Equal(data, cover.Bytes()) {
+		t.Fatalf("FetchArtwork(original) = %d bytes %s, %v; want the stored PNG", len(data), mime, err)
+	}
+	data, mime, err = client.FetchArtwork(ctx, server.h.ID(), "ctid-covered", artwork.SizeSmall)
+	if err != nil || mime != "image/jpeg" || len(data) == 0 {
+		t.Fatalf("FetchArtwork(small) = %d bytes %s, %v; want a JPEG thumbnail", len(data), mime, err)
+	}
+
+	if _, _, err := client.FetchArtwork(ctx, server.h.ID(), "ctid-bare", artwork.SizeMedium); !errors.Is(err, ErrNoArtwork) {
+		t.Fatalf("FetchArtwork(bare) error = %v, want ErrNoArtwork", err)
+	}
+	if _, _, err := client.FetchArtwork(ctx, server.h.ID(), "ctid-missing", artwork.SizeMedium); err == nil {
+		t.Fatal("FetchArtwork(missing) error = nil, want error")
+	}
+}
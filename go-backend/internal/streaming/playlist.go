@@ -0,0 +1,108 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/cotune/go-backend/internal/playlist"
+)
+
+// PlaylistProtocol is the protocol ID for fetching playlist records by ID
+const PlaylistProtocol = "/cotune/playlist/1.0.0"
+
+// PlaylistRequest asks a peer for a playlist record
+type PlaylistRequest struct {
+	ID string `json:"id"`
+}
+
+// PlaylistResponse precedes the encoded record, which follows as one raw
+// length-prefixed message unless Error is set
+type PlaylistResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// ErrPlaylistNotFound is returned for records a peer does not share
+var ErrPlaylistNotFound = errors.New("playlist not found")
+
+// LocalPlaylist returns the encoded record of a shared local playlist
+func (s *Service) LocalPlaylist(id string) ([]byte, error) {
+	pl, err := s.store.FindPlaylistByRecord(id)
+	if err != nil {
+		return nil, ErrPlaylistNotFound
+	}
+	recordID, data, err := playlist.NewRecord(pl, s.h.ID().String()).Encode()
+	if err != nil {
+		return nil, err
+	}
+	if recordID != id {
+		// Edited since it was published; the new record has another ID
+		return nil, ErrPlaylistNotFound
+	}
+	return data, nil
+}
+
+// handlePlaylist serves records of shared local playlists
+func (s *Service) handlePlaylist(stream network.Stream) {
+	defer stream.Close()
+
+	var req PlaylistRequest
+	if err := readJSON(stream, &req); err != nil {
+		return
+	}
+
+	data, err := s.LocalPlaylist(req.ID)
+	if err != nil {
+		writeJSON(stream, PlaylistResponse{Error: err.Error()})
+		return
+	}
+	if err := writeJSON(stream, PlaylistResponse{}); err != nil {
+		return
+	}
+	writeMessage(stream, data)
+}
+
+// FetchPlaylist fetches a playlist record from a peer and verifies it
+// against its ID
+func (s *Service) FetchPlaylist(ctx context.Context, peerID peer.ID, id string) (*playlist.Record, error) {
+	if s.h.Network().Connectedness(peerID) != network.Connected {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		info := s.h.Peerstore().PeerInfo(peerID)
+		if err := s.h.Connect(ctx, info); err != nil {
+			return nil, fmt.Errorf("failed to connect to peer: %w", err)
+		}
+	}
+
+	stream, err := s.h.NewStream(ctx, peerID, protocol.ID(PlaylistProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	if err := writeJSON(stream, PlaylistRequest{ID: id}); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp PlaylistResponse
+	if err := readJSON(stream, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		if resp.Error == ErrPlaylistNotFound.Error() {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, fmt.Errorf("playlist error: %s", resp.Error)
+	}
+	data, err := readMessage(stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	return playlist.Decode(id, data)
+}
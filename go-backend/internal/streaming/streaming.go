@@ -9,6 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cotune/go-backend/internal/artwork"
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
 	"github.com/cotune/go-backend/internal/storage"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
@@ -25,30 +28,79 @@ const (
 
 // Service handles streaming of audio files
 type Service struct {
-	h     host.Host
-	store *storage.Storage
-	mu    sync.RWMutex
+	h       host.Host
+	store   storage.Store
+	artwork *artwork.Store
+	mu      sync.RWMutex
+	// profiles are the encodings this peer transcodes to on request
+	profiles []audio.TranscodeProfile
+	// transcodes holds a slot per encode running for a peer
+	transcodes chan struct{}
+	// ctx ends the encodes still running when the service is closed
+	ctx    context.Context
+	cancel context.CancelFunc
+	// onServe is called when a peer requests a local track
+	onServe func(*models.Track)
 }
 
-// New creates a new streaming service
-func New(h host.Host, store *storage.Storage) *Service {
+// New creates a new streaming service. Covers are served from artworkStore.
+func New(h host.Host, store storage.Store, artworkStore *artwork.Store) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
 	svc := &Service{
-		h:     h,
-		store: store,
+		h:          h,
+		store:      store,
+		artwork:    artworkStore,
+		transcodes: make(chan struct{}, MaxTranscodes),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 
-	// Register stream handler
+	// Register stream handlers
 	h.SetStreamHandler(protocol.ID(StreamingProtocol), svc.handleStream)
+	h.SetStreamHandler(protocol.ID(TranscodeProtocol), svc.handleTranscodedStream)
+	h.SetStreamHandler(protocol.ID(ArtworkProtocol), svc.handleArtwork)
+	h.SetStreamHandler(protocol.ID(WaveformProtocol), svc.handleWaveform)
+	h.SetStreamHandler(protocol.ID(ProfilesProtocol), svc.handleProfiles)
+	h.SetStreamHandler(protocol.ID(PlaylistProtocol), svc.handlePlaylist)
 
 	return svc
 }
 
-// StreamRequest represents a streaming request
+// Close stops the encodes running for peers
+func (s *Service) Close() {
+	s.cancel()
+}
+
+// SetOnServe sets a callback triggered when a peer requests a local track,
+// before it is sent
+func (s *Service) SetOnServe(fn func(*models.Track)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onServe = fn
+}
+
+// served reports a track requested by a peer to the onServe callback
+func (s *Service) served(track *models.Track) {
+	s.mu.RLock()
+	onServe := s.onServe
+	s.mu.RUnlock()
+	if onServe != nil {
+		onServe(track)
+	}
+}
+
+// StreamRequest represents a streaming request. Setting Format asks the
+// provider to transcode, over TranscodeProtocol; the CTID still names the
+// canonical audio.
 type StreamRequest struct {
-	CTID string `json:"ctid"`
+	CTID    string `json:"ctid"`
+	Format  string `json:"format,omitempty"`  // audio.TranscodeOpus or audio.TranscodeMP3
+	Bitrate int    `json:"bitrate,omitempty"` // bits per second; zero for the profile default
 }
 
-// StreamChunk represents a chunk of audio data
+// StreamChunk represents a chunk of audio data. Transcoded streams have an
+// unknown length: their chunks carry Total 0 except the last, whose Total is
+// its Index plus one.
 type StreamChunk struct {
 	Index int    `json:"index"`
 	Data  []byte `json:"data"`
@@ -67,14 +119,19 @@ func (s *Service) handleStream(stream network.Stream) {
 
 	// Find track by CTID
 	track, err := s.store.FindTrackByCTID(req.CTID)
-	if err != nil {
+	if err != nil || track.Unshared || track.Broken != "" {
 		// Track not found
 		writeError(stream, fmt.Sprintf("track not found: %s", req.CTID))
 		return
 	}
+	s.served(track)
+	sendFile(stream, track.Path)
+}
 
+// sendFile streams a file in chunks of known total
+func sendFile(stream network.Stream, path string) {
 	// Open file
-	file, err := os.Open(track.Path)
+	file, err := os.Open(path)
 	if err != nil {
 		writeError(stream, fmt.Sprintf("failed to open file: %v", err))
 		return
@@ -121,6 +178,15 @@ func (s *Service) handleStream(stream network.Stream) {
 
 // StreamFromPeer streams a track from a peer
 func (s *Service) StreamFromPeer(ctx context.Context, peerID peer.ID, ctid string, outputPath string) error {
+	_, err := s.StreamFromPeerTranscoded(ctx, peerID, ctid, outputPath, StreamRequest{})
+	return err
+}
+
+// StreamFromPeerTranscoded streams a track from a peer, asking for the
+// encoding in opts when opts.Format is set. The header says what the peer
+// sent; it is nil for untranscoded requests, which get the original file,
+// and empty when the peer does not transcode at all.
+func (s *Service) StreamFromPeerTranscoded(ctx context.Context, peerID peer.ID, ctid string, outputPath string, opts StreamRequest) (*StreamHeader, error) {
 	// Connect to peer if not connected
 	if s.h.Network().Connectedness(peerID) != network.Connected {
 		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
@@ -128,57 +194,84 @@ func (s *Service) StreamFromPeer(ctx context.Context, peerID peer.ID, ctid strin
 
 		info := s.h.Peerstore().PeerInfo(peerID)
 		if err := s.h.Connect(ctx, info); err != nil {
-			return fmt.Errorf("failed to connect to peer: %w", err)
+			return nil, fmt.Errorf("failed to connect to peer: %w", err)
 		}
 	}
 
-	// Open stream
-	stream, err := s.h.NewStream(ctx, peerID, protocol.ID(StreamingProtocol))
+	// Open stream; a peer without TranscodeProtocol gets the plain request
+	protocols := []protocol.ID{StreamingProtocol}
+	if opts.Format != "" {
+		protocols = []protocol.ID{TranscodeProtocol, StreamingProtocol}
+	}
+	stream, err := s.h.NewStream(ctx, peerID, protocols...)
 	if err != nil {
-		return fmt.Errorf("failed to open stream: %w", err)
+		return nil, fmt.Errorf("failed to open stream: %w", err)
 	}
 	defer stream.Close()
 
 	// Send request
 	req := StreamRequest{CTID: ctid}
+	transcoding := stream.Protocol() == TranscodeProtocol
+	if transcoding {
+		req.Format, req.Bitrate = opts.Format, opts.Bitrate
+	}
 	if err := writeJSON(stream, req); err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var header *StreamHeader
+	if opts.Format != "" {
+		header = &StreamHeader{}
+	}
+	if transcoding {
+		if err := readJSON(stream, header); err != nil {
+			return nil, fmt.Errorf("failed to read stream header: %w", err)
+		}
+		if header.Error != "" {
+			return nil, fmt.Errorf("stream error: %s", header.Error)
+		}
 	}
 
 	// Create output file
 	outFile, err := os.Create(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outFile.Close()
 
 	// Receive chunks
+	complete := false
 	for {
 		var chunk StreamChunk
 		if err := readJSON(stream, &chunk); err != nil {
 			if err == io.EOF {
 				break
 			}
-			return fmt.Errorf("failed to read chunk: %w", err)
+			return nil, fmt.Errorf("failed to read chunk: %w", err)
 		}
 
 		// Check for error response
 		if chunk.Index == -1 && len(chunk.Data) > 0 {
-			return fmt.Errorf("stream error: %s", string(chunk.Data))
+			return nil, fmt.Errorf("stream error: %s", string(chunk.Data))
 		}
 
 		// Write chunk to file
 		if _, err := outFile.Write(chunk.Data); err != nil {
-			return fmt.Errorf("failed to write chunk: %w", err)
+			return nil, fmt.Errorf("failed to write chunk: %w", err)
 		}
 
 		// Last chunk
-		if chunk.Index >= chunk.Total-1 {
+		if chunk.Total > 0 && chunk.Index >= chunk.Total-1 {
+			complete = true
 			break
 		}
 	}
+	// Only the final chunk tells a finished transcode from a dropped one
+	if header != nil && header.Transcoded && !complete {
+		return nil, fmt.Errorf("transcoded stream ended early")
+	}
 
-	return nil
+	return header, nil
 }
 
 // Helper functions for simple binary protocol
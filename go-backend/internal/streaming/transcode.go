@@ -0,0 +1,195 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
+)
+
+const (
+	// ProfilesProtocol is the protocol ID for asking a peer which transcode
+	// profiles it offers
+	ProfilesProtocol = "/cotune/stream-profiles/1.0.0"
+	// TranscodeProtocol is the protocol ID for streams that ask for a
+	// format: a StreamHeader precedes the chunks
+	TranscodeProtocol = "/cotune/stream-transcode/1.0.0"
+	// MaxTranscodes is the number of encodes run for peers at once; further
+	// requests get the original file
+	MaxTranscodes = 2
+)
+
+// StreamHeader precedes the chunks of a stream that asked for a format
+type StreamHeader struct {
+	// Transcoded is false when the peer sent the original file instead,
+	// because it cannot encode the format or the original is already smaller
+	Transcoded bool   `json:"transcoded"`
+	Format     string `json:"format,omitempty"` // format sent: the requested one, or the original codec
+	Bitrate    int    `json:"bitrate,omitempty"`
+	MIMEType   string `json:"mime_type,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ProfilesResponse lists the transcode profiles of a peer
+type ProfilesResponse struct {
+	Profiles []audio.TranscodeProfile `json:"profiles"`
+}
+
+// SetTranscodeProfiles sets the encodings this peer produces on request,
+// normally the result of audio.ProbeTranscodeProfiles
+func (s *Service) SetTranscodeProfiles(profiles []audio.TranscodeProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles = profiles
+}
+
+// TranscodeProfiles returns the encodings this peer produces on request
+func (s *Service) TranscodeProfiles() []audio.TranscodeProfile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.profiles
+}
+
+// transcodeProfile returns the profile for a format, if this peer offers it
+func (s *Service) transcodeProfile(format string) (audio.TranscodeProfile, bool) {
+	for _, p := range s.TranscodeProfiles() {
+		if p.Format == format {
+			return p, true
+		}
+	}
+	return audio.TranscodeProfile{}, false
+}
+
+// handleTranscodedStream answers a request for a format with a header and
+// then either the transcoded audio or, when transcoding is not possible, not
+// worth it or every encoder is busy, the original file
+func (s *Service) handleTranscodedStream(stream network.Stream) {
+	defer stream.Close()
+
+	var req StreamRequest
+	if err := readJSON(stream, &req); err != nil {
+		return
+	}
+	track, err := s.store.FindTrackByCTID(req.CTID)
+	if err != nil || track.Unshared || track.Broken != "" {
+		writeJSON(stream, StreamHeader{Error: fmt.Sprintf("track not found: %s", req.CTID)})
+		return
+	}
+	s.served(track)
+
+	profile, ok := s.transcodeProfile(req.Format)
+	if !ok || alreadySmaller(track, req.Format, req.Bitrate) {
+		sendOriginal(stream, track)
+		return
+	}
+	bitrate, err := profile.ResolveBitrate(req.Bitrate)
+	if err != nil {
+		writeJSON(stream, StreamHeader{Error: err.Error()})
+		return
+	}
+	select {
+	case s.transcodes <- struct{}{}:
+		defer func() { <-s.transcodes }()
+	default:
+		sendOriginal(stream, track)
+		return
+	}
+
+	// Ends with the stream, or earlier when the service is closed
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	encoded, err := audio.Transcode(ctx, track.Path, profile.Format, bitrate)
+	if err != nil {
+		writeJSON(stream, StreamHeader{Error: err.Error()})
+		return
+	}
+	// Stops the encoder if the requester goes away before the end
+	defer encoded.Close()
+
+	header := StreamHeader{Transcoded: true, Format: profile.Format, Bitrate: bitrate, MIMEType: profile.MIMEType}
+	if err := writeJSON(stream, header); err != nil {
+		return
+	}
+	sendUnsized(stream, encoded)
+}
+
+// sendOriginal sends the original file of a track after a header saying so
+func sendOriginal(stream network.Stream, track *models.Track) {
+	header := StreamHeader{Bitrate: track.Bitrate}
+	if track.Format != nil {
+		header.Format = track.Format.Codec
+	}
+	if err := writeJSON(stream, header); err != nil {
+		return
+	}
+	sendFile(stream, track.Path)
+}
+
+// alreadySmaller reports whether the original is in the requested format at
+// no more than the requested bitrate, so transcoding would only lose quality
+func alreadySmaller(track *models.Track, format string, bitrate int) bool {
+	if track.Format == nil || track.Format.Codec != format || track.Bitrate == 0 {
+		return false
+	}
+	return bitrate == 0 || track.Bitrate <= bitrate
+}
+
+// sendUnsized streams a reader of unknown length; the last chunk carries
+// the total
+func sendUnsized(stream network.Stream, r io.Reader) {
+	buffer := make([]byte, ChunkSize)
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(r, buffer)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			writeError(stream, fmt.Sprintf("transcode error: %v", err))
+			return
+		}
+
+		chunk := StreamChunk{Index: index, Data: buffer[:n]}
+		if last {
+			chunk.Total = index + 1
+		}
+		if err := writeJSON(stream, chunk); err != nil || last {
+			return
+		}
+	}
+}
+
+// handleProfiles tells a peer which profiles this peer transcodes to
+func (s *Service) handleProfiles(stream network.Stream) {
+	defer stream.Close()
+	writeJSON(stream, ProfilesResponse{Profiles: s.TranscodeProfiles()})
+}
+
+// FetchProfiles asks a peer which transcode profiles it offers
+func (s *Service) FetchProfiles(ctx context.Context, peerID peer.ID) ([]audio.TranscodeProfile, error) {
+	if s.h.Network().Connectedness(peerID) != network.Connected {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		info := s.h.Peerstore().PeerInfo(peerID)
+		if err := s.h.Connect(ctx, info); err != nil {
+			return nil, fmt.Errorf("failed to connect to peer: %w", err)
+		}
+	}
+
+	stream, err := s.h.NewStream(ctx, peerID, protocol.ID(ProfilesProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	var resp ProfilesResponse
+	if err := readJSON(stream, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	return resp.Profiles, nil
+}
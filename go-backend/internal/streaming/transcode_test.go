@@ -0,0 +1,120 @@
+package streaming
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/audio"
+	"github.com/cotune/go-backend/internal/models"
+)
+
+func TestStreamTranscodedFromPeer(t *testing.T) {
+	// Fake encoder: 200000 bytes of payload, then the requested bitrate
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	body := `#!/bin/sh
+while [ "$1" != "-b:a" ]; do shift; done
+head -c 200000 /dev/zero | tr '\0' 'x'
+echo "bitrate=$2"
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	audio.SetFFmpegPath(script)
+	t.Cleanup(func() { audio.SetFFmpegPath("") })
+
+	server, client := newTestPeers(t)
+	opus := audio.TranscodeProfile{Format: audio.TranscodeOpus, MIMEType: "audio/ogg", MinBitrate: 16000, MaxBitrate: 256000, DefaultBitrate: 96000}
+	server.SetTranscodeProfiles([]audio.TranscodeProfile{opus})
+
+	original := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(original, []byte("fLaC original bytes"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	track := &models.Track{ID: "t", CTID: "ctid-flac", Path: original, Bitrate: 900000, Format: &models.AudioFormat{Codec: "flac"}}
+	if err := server.store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+
+	ctx := context.Background()
+	profiles, err := client.FetchProfiles(ctx, server.h.ID())
+	if err != nil || len(profiles) != 1 || profiles[0] != opus {
+		t.Fatalf("FetchProfiles() = %+v, %v; want the opus profile", profiles, err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	header, err := client.StreamFromPeerTranscoded(ctx, server.h.ID(), "ctid-flac", out, StreamRequest{Format: audio.TranscodeOpus, Bitrate: 64000})
+	if err != nil {
+		t.Fatalf("StreamFromPeerTranscoded(opus) error: %v", err)
+	}
+	if !header.Transcoded || header.Format != audio.TranscodeOpus || header.Bitrate != 64000 || header.MIMEType != "audio/ogg" {
+		t.Fatalf("opus header = %+v, want transcoded opus at 64000", header)
+	}
+	data, _ := os.ReadFile(out)
+	if len(data) != 200000+len("bitrate=64000\n") || !strings.HasSuffix(string(data), "bitrate=64000\n") {
+		t.Fatalf("transcoded output = %d bytes, want the whole encoder output", len(data))
+	}
+
+	// Formats the peer cannot encode fall back to the original file
+	header, err = client.StreamFromPeerTranscoded(ctx, server.h.ID(), "ctid-flac", out, StreamRequest{Format: audio.TranscodeMP3})
+	if err != nil || header.Transcoded || header.Format != "flac" {
+		t.Fatalf("StreamFromPeerTranscoded(mp3) = %+v, %v; want the original flac", header, err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "fLaC original bytes" {
+		t.Fatalf("fallback output = %q, want the original", data)
+	}
+
+	if _, err := client.StreamFromPeerTranscoded(ctx, server.h.ID(), "ctid-flac", out, StreamRequest{Format: audio.TranscodeOpus, Bitrate: 1000}); err == nil {
+		t.Fatal("StreamFromPeerTranscoded(1000bps) error = nil, want out of range")
+	}
+}
+
+func TestTranscodedRequestFallsBackToOriginal(t *testing.T) {
+	server, client := newTestPeers(t)
+	server.SetTranscodeProfiles([]audio.TranscodeProfile{{Format: audio.TranscodeOpus, MIMEType: "audio/ogg", MinBitrate: 16000, MaxBitrate: 256000, DefaultBitrate: 96000}})
+	original := filepath.Join(t.TempDir(), "track.flac")
+	if err := os.WriteFile(original, []byte("fLaC original bytes"), 0644); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	track := &models.Track{ID: "t", CTID: "ctid-flac", Path: original, Format: &models.AudioFormat{Codec: "flac"}}
+	if err := server.store.SaveTrack(track); err != nil {
+		t.Fatalf("SaveTrack() error: %v", err)
+	}
+	ctx := context.Background()
+	out := filepath.Join(t.TempDir(), "out")
+
+	// Every encoder busy: the original is sent instead of waiting
+	for i := 0; i < MaxTranscodes; i++ {
+		server.transcodes <- struct{}{}
+	}
+	header, err := client.StreamFromPeerTranscoded(ctx, server.h.ID(), "ctid-flac", out, StreamRequest{Format: audio.TranscodeOpus})
+	if err != nil || header.Transcoded || header.Format != "flac" {
+		t.Fatalf("StreamFromPeerTranscoded(busy) = %+v, %v; want the original flac", header, err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "fLaC original bytes" {
+		t.Fatalf("busy output = %q, want the original", data)
+	}
+
+	// A peer that predates transcoding only speaks the plain protocol
+	server.h.RemoveStreamHandler(TranscodeProtocol)
+	client.h.Peerstore().RemoveProtocols(server.h.ID(), TranscodeProtocol)
+	header, err = client.StreamFromPeerTranscoded(ctx, server.h.ID(), "ctid-flac", out, StreamRequest{Format: audio.TranscodeOpus})
+	if err != nil || header == nil || header.Transcoded {
+		t.Fatalf("StreamFromPeerTranscoded(old peer) = %+v, %v; want the original", header, err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "fLaC original bytes" {
+		t.Fatalf("old peer output = %q, want the whole original", data)
+	}
+}
+
+func TestAlreadySmallerSkipsTranscoding(t *testing.T) {
+	mp3 := &models.Track{Bitrate: 128000, Format: &models.AudioFormat{Codec: "mp3"}}
+	if !alreadySmaller(mp3, audio.TranscodeMP3, 192000) {
+		t.Fatal("alreadySmaller(128k mp3 for 192k mp3) = false, want true")
+	}
+	if alreadySmaller(mp3, audio.TranscodeMP3, 96000) || alreadySmaller(mp3, audio.TranscodeOpus, 192000) {
+		t.Fatal("alreadySmaller() = true for a lower bitrate or another format, want false")
+	}
+}
@@ -0,0 +1,108 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+// WaveformProtocol is the protocol ID for fetching waveform peaks by CTID
+const WaveformProtocol = "/cotune/waveform/1.0.0"
+
+// WaveformRequest asks a peer for the waveform of a CTID
+type WaveformRequest struct {
+	CTID     string `json:"ctid"`
+	MaxPeaks int    `json:"max_peaks,omitempty"` // drop finer levels; zero sends every level
+}
+
+// WaveformResponse precedes the encoded peaks, which follow as one raw
+// length-prefixed message unless Error is set
+type WaveformResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// ErrNoWaveform is returned when a track has no peaks yet
+var ErrNoWaveform = errors.New("no waveform")
+
+// LocalWaveform returns the waveform of a local track, keeping only levels
+// of at most maxPeaks peaks when maxPeaks is positive
+func (s *Service) LocalWaveform(ctid string, maxPeaks int) (*waveform.Peaks, error) {
+	track, err := s.store.FindTrackByCTID(ctid)
+	if err != nil {
+		return nil, fmt.Errorf("track not found: %s", ctid)
+	}
+	if !track.Waveform {
+		return nil, ErrNoWaveform
+	}
+	peaks, err := waveform.Load(waveform.PathFor(track.Path))
+	if err != nil {
+		return nil, err
+	}
+	return peaks.Trim(maxPeaks), nil
+}
+
+// handleWaveform serves waveforms of local tracks
+func (s *Service) handleWaveform(stream network.Stream) {
+	defer stream.Close()
+
+	var req WaveformRequest
+	if err := readJSON(stream, &req); err != nil {
+		return
+	}
+
+	peaks, err := s.LocalWaveform(req.CTID, req.MaxPeaks)
+	if err != nil {
+		writeJSON(stream, WaveformResponse{Error: err.Error()})
+		return
+	}
+	if err := writeJSON(stream, WaveformResponse{}); err != nil {
+		return
+	}
+	writeMessage(stream, peaks.Encode())
+}
+
+// FetchWaveform fetches the waveform of a CTID from a peer
+func (s *Service) FetchWaveform(ctx context.Context, peerID peer.ID, ctid string, maxPeaks int) (*waveform.Peaks, error) {
+	if s.h.Network().Connectedness(peerID) != network.Connected {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		info := s.h.Peerstore().PeerInfo(peerID)
+		if err := s.h.Connect(ctx, info); err != nil {
+			return nil, fmt.Errorf("failed to connect to peer: %w", err)
+		}
+	}
+
+	stream, err := s.h.NewStream(ctx, peerID, protocol.ID(WaveformProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	defer stream.Close()
+
+	if err := writeJSON(stream, WaveformRequest{CTID: ctid, MaxPeaks: maxPeaks}); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp WaveformResponse
+	if err := readJSON(stream, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		if resp.Error == ErrNoWaveform.Error() {
+			return nil, ErrNoWaveform
+		}
+		return nil, fmt.Errorf("waveform error: %s", resp.Error)
+	}
+	data, err := readMessage(stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read waveform: %w", err)
+	}
+	return waveform.Decode(data)
+}
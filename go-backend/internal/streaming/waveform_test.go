@@ -0,0 +1,48 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/waveform"
+)
+
+func TestFetchWaveformFromPeer(t *testing.T) {
+	server, client := newTestPeers(t)
+
+	b := waveform.NewBuilder(44100)
+	b.AddSamples(make([]int16, 100*waveform.BaseSamplesPerPeak))
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	if err := waveform.Save(waveform.PathFor(path), b.Peaks()); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	tracks := []*models.Track{
+		{ID: "drawn", CTID: "ctid-drawn", Path: path, Waveform: true},
+		{ID: "pending", CTID: "ctid-pending", Path: path},
+	}
+	for _, track := range tracks {
+		if err := server.store.SaveTrack(track); err != nil {
+			t.Fatalf("SaveTrack() error: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	peaks, err := client.FetchWaveform(ctx, server.h.ID(), "ctid-drawn", 0)
+	if err != nil || len(peaks.Levels) != waveform.NumLevels || peaks.Levels[0].Len() != 100 {
+		t.Fatalf("FetchWaveform(all) = %+v, %v; want every level", peaks, err)
+	}
+	peaks, err = client.FetchWaveform(ctx, server.h.ID(), "ctid-drawn", 10)
+	if err != nil || len(peaks.Levels) != 2 || peaks.Levels[0].Len() != 7 {
+		t.Fatalf("FetchWaveform(10) = %+v, %v; want the two coarsest levels", peaks, err)
+	}
+
+	if _, err := client.FetchWaveform(ctx, server.h.ID(), "ctid-pending", 0); !errors.Is(err, ErrNoWaveform) {
+		t.Fatalf("FetchWaveform(pending) error = %v, want ErrNoWaveform", err)
+	}
+	if _, err := client.FetchWaveform(ctx, server.h.ID(), "ctid-missing", 0); err == nil {
+		t.Fatal("FetchWaveform(missing) error = nil, want error")
+	}
+}
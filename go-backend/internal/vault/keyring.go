@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Sealed values start with sealMagic and the ID of the data key that sealed
+// them, followed by an XChaCha20-Poly1305 nonce and ciphertext
+var sealMagic = []byte("CTV1")
+
+const sealHeaderSize = 4 + 4 + chacha20poly1305.NonceSizeX
+
+var (
+	// ErrNotSealed is returned when opening a value that was never sealed
+	ErrNotSealed = errors.New("value is not sealed")
+	// ErrUnknownKey is returned when a value was sealed with a data key the
+	// keyring does not hold
+	ErrUnknownKey = errors.New("value sealed with an unknown key")
+)
+
+// IsSealed reports whether data was produced by Keyring.Seal
+func IsSealed(data []byte) bool {
+	return len(data) >= sealHeaderSize && bytes.HasPrefix(data, sealMagic)
+}
+
+// dataKey is one key records are sealed with
+type dataKey struct {
+	id     uint32
+	secret []byte
+	aead   cipher.AEAD
+	blind  []byte // HMAC key for index values
+}
+
+func newDataKeyFrom(id uint32, secret []byte) (dataKey, error) {
+	aead, err := chacha20poly1305.NewX(secret)
+	if err != nil {
+		return dataKey{}, err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("cotune-vault-blind"))
+	return dataKey{id: id, secret: secret, aead: aead, blind: mac.Sum(nil)}, nil
+}
+
+func (k dataKey) idString() string {
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], k.id)
+	return hex.EncodeToString(id[:])
+}
+
+// Keyring seals and opens values with an unlocked vault's data keys. New
+// values are sealed with the current key; during a rotation older keys
+// still open what they sealed.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys []dataKey // Current first
+}
+
+func newKeyring(keys []dataKey) *Keyring {
+	return &Keyring{keys: keys}
+}
+
+// KeyID identifies the current data key
+func (k *Keyring) KeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[0].idString()
+}
+
+// Rotating reports whether the keyring still holds older keys
+func (k *Keyring) Rotating() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.keys) > 1
+}
+
+// Seal encrypts plaintext with the current key. context binds the value to
+// where it is stored, such as its datastore key; Open must be given the
+// same.
+func (k *Keyring) Seal(plaintext []byte, context string) ([]byte, error) {
+	k.mu.RLock()
+	key := k.keys[0]
+	k.mu.RUnlock()
+
+	out := make([]byte, sealHeaderSize, sealHeaderSize+len(plaintext)+key.aead.Overhead())
+	copy(out, sealMagic)
+	binary.BigEndian.PutUint32(out[4:8], key.id)
+	nonce := out[8:sealHeaderSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return key.aead.Seal(out, nonce, plaintext, []byte(context)), nil
+}
+
+// Open decrypts a value sealed with any key of the keyring
+func (k *Keyring) Open(data []byte, context string) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, ErrNotSealed
+	}
+	key, ok := k.key(binary.BigEndian.Uint32(data[4:8]))
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	plaintext, err := key.aead.Open(nil, data[8:sealHeaderSize], data[sealHeaderSize:], []byte(context))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sealed value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// IsCurrent reports whether data was sealed with the current key
+func (k *Keyring) IsCurrent(data []byte) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return IsSealed(data) && binary.BigEndian.Uint32(data[4:8]) == k.keys[0].id
+}
+
+// Blind maps a value to an opaque one with the current key, so an index
+// can be looked up by value without storing it. Equal values blind equally
+// until the key is rotated.
+func (k *Keyring) Blind(value string) string {
+	k.mu.RLock()
+	key := k.keys[0]
+	k.mu.RUnlock()
+
+	mac := hmac.New(sha256.New, key.blind)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (k *Keyring) key(id uint32) (dataKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.id == id {
+			return key, true
+		}
+	}
+	return dataKey{}, false
+}
+
+// retire drops every key but the current one
+func (k *Keyring) retire() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = k.keys[:1:1]
+}
@@ -0,0 +1,455 @@
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// FileName is the file in the data directory describing the vault. Its
+// presence means the node's identity key and library are encrypted.
+const FileName = "vault.json"
+
+// Format identifies vault files
+const Format = "cotune-vault"
+
+// Version is the vault file layout this build reads and writes
+const Version = 1
+
+// Ways the key wrapping the data keys is derived from a Secret
+const (
+	KDFArgon2id = "argon2id" // from a passphrase
+	KDFRaw      = "raw"      // a 32-byte key held by a platform keystore
+)
+
+// KeySize is the size of a keystore key
+const KeySize = chacha20poly1305.KeySize
+
+var (
+	// ErrWrongSecret is returned when a secret does not open the vault
+	ErrWrongSecret = errors.New("wrong passphrase or key")
+	// ErrNoSecret is returned when a secret holds neither a passphrase nor
+	// a key
+	ErrNoSecret = errors.New("no passphrase or key given")
+)
+
+// Secret unlocks a vault: a passphrase, or a key from a platform keystore
+type Secret struct {
+	Passphrase string
+	Key        []byte
+}
+
+// IsZero reports whether the secret holds nothing
+func (s Secret) IsZero() bool {
+	return s.Passphrase == "" && len(s.Key) == 0
+}
+
+func (s Secret) kdf() (string, error) {
+	switch {
+	case s.Passphrase != "" && len(s.Key) > 0:
+		return "", fmt.Errorf("give either a passphrase or a key")
+	case s.Passphrase != "":
+		return KDFArgon2id, nil
+	case len(s.Key) == KeySize:
+		return KDFRaw, nil
+	case len(s.Key) > 0:
+		return "", fmt.Errorf("keystore key must be %d bytes, got %d", KeySize, len(s.Key))
+	}
+	return "", ErrNoSecret
+}
+
+// argon2Params are the Argon2id costs a passphrase was derived with
+type argon2Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+// defaultParams follow the second recommendation of RFC 9106, which fits
+// in a phone's memory
+var defaultParams = argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// wrappedKey is a data key sealed with the key derived from the secret
+type wrappedKey struct {
+	ID     string `json:"id"`
+	Sealed []byte `json:"sealed"`
+}
+
+type vaultFile struct {
+	Format    string        `json:"format"`
+	Version   int           `json:"version"`
+	KDF       string        `json:"kdf"`
+	Salt      []byte        `json:"salt,omitempty"`
+	Params    *argon2Params `json:"params,omitempty"`
+	Keys      []wrappedKey  `json:"keys"` // Current key first; older ones only while rotating
+	CreatedAt int64         `json:"created_at"`
+	RotatedAt int64         `json:"rotated_at,omitempty"`
+}
+
+// Info describes a vault without unlocking it
+type Info struct {
+	KDF       string `json:"kdf"`
+	KeyID     string `json:"key_id"`
+	Rotating  bool   `json:"rotating"` // Records may still be sealed with an older key
+	CreatedAt int64  `json:"created_at"`
+	RotatedAt int64  `json:"rotated_at,omitempty"`
+}
+
+// Vault holds the data keys the node's records are sealed with, each
+// wrapped with a key derived from the user's secret. Changing the secret
+// only rewraps the data keys; rotating adds a new data key, which the
+// caller reseals every record with before calling FinishRotation.
+type Vault struct {
+	path string
+
+	mu   sync.Mutex
+	file vaultFile
+}
+
+// Exists reports whether dataDir holds a vault
+func Exists(dataDir string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, FileName))
+	return err == nil
+}
+
+// Load reads the vault in dataDir. The error wraps os.ErrNotExist when the
+// node is not encrypted.
+func Load(dataDir string) (*Vault, error) {
+	path := filepath.Join(dataDir, FileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	file, err := parseFile(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{path: path, file: file}, nil
+}
+
+func parseFile(data []byte) (vaultFile, error) {
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return vaultFile{}, fmt.Errorf("invalid vault: %w", err)
+	}
+	if file.Format != Format {
+		return vaultFile{}, fmt.Errorf("not a vault: format %q", file.Format)
+	}
+	if file.Version != Version {
+		return vaultFile{}, fmt.Errorf("unsupported vault version %d", file.Version)
+	}
+	if len(file.Keys) == 0 {
+		return vaultFile{}, fmt.Errorf("invalid vault: no keys")
+	}
+	if file.KDF == KDFArgon2id && file.Params == nil {
+		return vaultFile{}, fmt.Errorf("invalid vault: no argon2id parameters")
+	}
+	return file, nil
+}
+
+// Create writes a new vault to dataDir with a fresh data key and returns it
+// unlocked
+func Create(dataDir string, secret Secret) (*Vault, *Keyring, error) {
+	path := filepath.Join(dataDir, FileName)
+	if _, err := os.Stat(path); err == nil {
+		return nil, nil, fmt.Errorf("vault already exists")
+	}
+
+	v := &Vault{path: path, file: vaultFile{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now().Unix(),
+	}}
+	kek, err := v.file.setSecret(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := newDataKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := wrap(kek, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	v.file.Keys = []wrappedKey{wrapped}
+	if err := v.save(); err != nil {
+		return nil, nil, err
+	}
+	return v, newKeyring([]dataKey{key}), nil
+}
+
+// NewDetached creates a vault kept with data outside the data directory,
+// such as a backup archive, with a fresh data key. It returns the vault's
+// contents, to be stored next to the data, and the keyring to seal it with.
+func NewDetached(secret Secret) ([]byte, *Keyring, error) {
+	file := vaultFile{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now().Unix(),
+	}
+	kek, err := file.setSecret(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := newDataKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := wrap(kek, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file.Keys = []wrappedKey{wrapped}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal vault: %w", err)
+	}
+	return data, newKeyring([]dataKey{key}), nil
+}
+
+// OpenDetached unlocks a vault written by NewDetached with the secret
+func OpenDetached(data []byte, secret Secret) (*Keyring, error) {
+	file, err := parseFile(data)
+	if err != nil {
+		return nil, err
+	}
+	v := &Vault{file: file}
+	return v.Unlock(secret)
+}
+
+// Info describes the vault
+func (v *Vault) Info() Info {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return Info{
+		KDF:       v.file.KDF,
+		KeyID:     v.file.Keys[0].ID,
+		Rotating:  len(v.file.Keys) > 1,
+		CreatedAt: v.file.CreatedAt,
+		RotatedAt: v.file.RotatedAt,
+	}
+}
+
+// Unlock opens the data keys with the secret. The keyring holds more than
+// one key when a rotation was interrupted.
+func (v *Vault) Unlock(secret Secret) (*Keyring, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys, _, err := v.unwrap(secret)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyring(keys), nil
+}
+
+// ChangeSecret rewraps the data keys for a new secret, which may also switch
+// between a passphrase and a keystore key
+func (v *Vault) ChangeSecret(old, secret Secret) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys, _, err := v.unwrap(old)
+	if err != nil {
+		return err
+	}
+	file := v.file
+	kek, err := file.setSecret(secret)
+	if err != nil {
+		return err
+	}
+	file.Keys = nil
+	for _, key := range keys {
+		wrapped, err := wrap(kek, key)
+		if err != nil {
+			return err
+		}
+		file.Keys = append(file.Keys, wrapped)
+	}
+	return v.replace(file)
+}
+
+// BeginRotation adds a new current data key and returns a keyring holding
+// it and the older keys, which still open records sealed before. The older
+// keys stay in the vault until FinishRotation, so an interrupted reseal
+// resumes on the next unlock.
+func (v *Vault) BeginRotation(secret Secret) (*Keyring, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys, kek, err := v.unwrap(secret)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newDataKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := wrap(kek, key)
+	if err != nil {
+		return nil, err
+	}
+	file := v.file
+	file.Keys = append([]wrappedKey{wrapped}, file.Keys...)
+	if err := v.replace(file); err != nil {
+		return nil, err
+	}
+	return newKeyring(append([]dataKey{key}, keys...)), nil
+}
+
+// FinishRotation drops every key but the current one from the vault and the
+// keyring, once everything sealed with them was resealed
+func (v *Vault) FinishRotation(k *Keyring) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.file.Keys[0].ID != k.KeyID() {
+		return fmt.Errorf("keyring does not belong to this vault")
+	}
+	if len(v.file.Keys) > 1 {
+		file := v.file
+		file.Keys = file.Keys[:1]
+		file.RotatedAt = time.Now().Unix()
+		if err := v.replace(file); err != nil {
+			return err
+		}
+	}
+	k.retire()
+	return nil
+}
+
+// unwrap opens every data key, returning them with the key that wraps them
+func (v *Vault) unwrap(secret Secret) ([]dataKey, []byte, error) {
+	kdf, err := secret.kdf()
+	if err != nil {
+		return nil, nil, err
+	}
+	if kdf != v.file.KDF {
+		if v.file.KDF == KDFRaw {
+			return nil, nil, fmt.Errorf("vault is unlocked with a keystore key, not a passphrase")
+		}
+		return nil, nil, fmt.Errorf("vault is unlocked with a passphrase, not a key")
+	}
+	kek := v.file.deriveKey(secret)
+
+	keys := make([]dataKey, 0, len(v.file.Keys))
+	for _, wrapped := range v.file.Keys {
+		key, err := unwrap(kek, wrapped)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, kek, nil
+}
+
+// setSecret switches the file to the secret's KDF with a fresh salt and
+// returns the derived key
+func (f *vaultFile) setSecret(secret Secret) ([]byte, error) {
+	kdf, err := secret.kdf()
+	if err != nil {
+		return nil, err
+	}
+	f.KDF, f.Salt, f.Params = kdf, nil, nil
+	if kdf == KDFArgon2id {
+		f.Salt = make([]byte, 16)
+		if _, err := rand.Read(f.Salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		params := defaultParams
+		f.Params = &params
+	}
+	return f.deriveKey(secret), nil
+}
+
+func (f *vaultFile) deriveKey(secret Secret) []byte {
+	if f.KDF == KDFRaw {
+		return secret.Key
+	}
+	return argon2.IDKey([]byte(secret.Passphrase), f.Salt, f.Params.Time, f.Params.Memory, f.Params.Threads, KeySize)
+}
+
+// replace saves file as the vault, keeping the previous one if that fails
+func (v *Vault) replace(file vaultFile) error {
+	previous := v.file
+	v.file = file
+	if err := v.save(); err != nil {
+		v.file = previous
+		return err
+	}
+	return nil
+}
+
+// save writes the vault through a temporary file so a crash leaves either
+// the old or the new one
+func (v *Vault) save() error {
+	data, err := json.MarshalIndent(v.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := os.Rename(tmp, v.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	return nil
+}
+
+func newDataKey() (dataKey, error) {
+	secret := make([]byte, KeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return dataKey{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	var id [4]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return dataKey{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return newDataKeyFrom(binary.BigEndian.Uint32(id[:]), secret)
+}
+
+func wrap(kek []byte, key dataKey) (wrappedKey, error) {
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return wrappedKey{}, err
+	}
+	id := key.idString()
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(key.secret)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return wrappedKey{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return wrappedKey{ID: id, Sealed: aead.Seal(nonce, nonce, key.secret, []byte("cotune-vault:"+id))}, nil
+}
+
+func unwrap(kek []byte, wrapped wrappedKey) (dataKey, error) {
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return dataKey{}, err
+	}
+	idBytes, err := hex.DecodeString(wrapped.ID)
+	if err != nil || len(idBytes) != 4 {
+		return dataKey{}, fmt.Errorf("invalid vault: key id %q", wrapped.ID)
+	}
+	if len(wrapped.Sealed) < aead.NonceSize() {
+		return dataKey{}, fmt.Errorf("invalid vault: key %s truncated", wrapped.ID)
+	}
+	nonce, sealed := wrapped.Sealed[:aead.NonceSize()], wrapped.Sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, sealed, []byte("cotune-vault:"+wrapped.ID))
+	if err != nil {
+		return dataKey{}, ErrWrongSecret
+	}
+	return newDataKeyFrom(binary.BigEndian.Uint32(idBytes), secret)
+}
@@ -0,0 +1,150 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	// Keep passphrase derivation cheap in tests
+	defaultParams = argon2Params{Time: 1, Memory: 1024, Threads: 1}
+}
+
+func TestPassphraseVaultUnlocksAndSeals(t *testing.T) {
+	dir := t.TempDir()
+	secret := Secret{Passphrase: "correct horse"}
+	if Exists(dir) {
+		t.Fatal("Exists() before Create")
+	}
+	_, keyring, err := Create(dir, secret)
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if _, _, err := Create(dir, secret); err == nil {
+		t.Fatal("second Create() succeeded")
+	}
+
+	sealed, err := keyring.Seal([]byte(`{"title":"Song"}`), "/tracks/a")
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("Song")) {
+		t.Fatalf("sealed value %q leaks its plaintext", sealed)
+	}
+
+	v, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if _, err := v.Unlock(Secret{Passphrase: "wrong"}); !errors.Is(err, ErrWrongSecret) {
+		t.Fatalf("Unlock(wrong) error = %v, want ErrWrongSecret", err)
+	}
+	if _, err := v.Unlock(Secret{Key: make([]byte, KeySize)}); err == nil {
+		t.Fatal("passphrase vault unlocked with a key")
+	}
+	unlocked, err := v.Unlock(secret)
+	if err != nil {
+		t.Fatalf("Unlock() error: %v", err)
+	}
+	plaintext, err := unlocked.Open(sealed, "/tracks/a")
+	if err != nil || string(plaintext) != `{"title":"Song"}` {
+		t.Fatalf("Open() = %q, %v", plaintext, err)
+	}
+	if _, err := unlocked.Open(sealed, "/tracks/b"); err == nil {
+		t.Fatal("value opened under another context")
+	}
+	if _, err := unlocked.Open([]byte(`{}`), "/tracks/a"); !errors.Is(err, ErrNotSealed) {
+		t.Fatalf("Open(plaintext) error = %v, want ErrNotSealed", err)
+	}
+	if unlocked.Blind("artist") != keyring.Blind("artist") || keyring.Blind("artist") == keyring.Blind("other") {
+		t.Fatal("Blind() not deterministic per value")
+	}
+
+	if _, err := Load(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Load(empty dir) error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestChangeSecretKeepsDataKeys(t *testing.T) {
+	dir := t.TempDir()
+	v, keyring, err := Create(dir, Secret{Passphrase: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := keyring.Seal([]byte("record"), "ctx")
+
+	key := bytes.Repeat([]byte{7}, KeySize)
+	if err := v.ChangeSecret(Secret{Passphrase: "wrong"}, Secret{Key: key}); !errors.Is(err, ErrWrongSecret) {
+		t.Fatalf("ChangeSecret(wrong) error = %v", err)
+	}
+	if err := v.ChangeSecret(Secret{Passphrase: "old"}, Secret{Key: key}); err != nil {
+		t.Fatalf("ChangeSecret() error: %v", err)
+	}
+
+	v, _ = Load(dir)
+	if v.Info().KDF != KDFRaw {
+		t.Fatalf("kdf = %s, want raw", v.Info().KDF)
+	}
+	if _, err := v.Unlock(Secret{Passphrase: "old"}); err == nil {
+		t.Fatal("old passphrase still unlocks")
+	}
+	unlocked, err := v.Unlock(Secret{Key: key})
+	if err != nil {
+		t.Fatalf("Unlock(key) error: %v", err)
+	}
+	if plaintext, err := unlocked.Open(sealed, "ctx"); err != nil || string(plaintext) != "record" {
+		t.Fatalf("Open() after ChangeSecret = %q, %v", plaintext, err)
+	}
+}
+
+func TestRotationKeepsOldKeyUntilFinished(t *testing.T) {
+	dir := t.TempDir()
+	secret := Secret{Passphrase: "pass"}
+	v, keyring, err := Create(dir, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldID := keyring.KeyID()
+	old, _ := keyring.Seal([]byte("old record"), "ctx")
+
+	rotated, err := v.BeginRotation(secret)
+	if err != nil {
+		t.Fatalf("BeginRotation() error: %v", err)
+	}
+	if rotated.KeyID() == oldID || !rotated.Rotating() || !v.Info().Rotating {
+		t.Fatalf("rotation did not add a current key: %+v", v.Info())
+	}
+	fresh, _ := rotated.Seal([]byte("new record"), "ctx")
+	if !rotated.IsCurrent(fresh) || rotated.IsCurrent(old) {
+		t.Fatal("IsCurrent() does not tell keys apart")
+	}
+
+	// An interrupted rotation resumes with both keys
+	reloaded, _ := Load(dir)
+	resumed, err := reloaded.Unlock(secret)
+	if err != nil {
+		t.Fatalf("Unlock() during rotation error: %v", err)
+	}
+	if _, err := resumed.Open(old, "ctx"); err != nil {
+		t.Fatalf("old record unreadable during rotation: %v", err)
+	}
+
+	if err := reloaded.FinishRotation(resumed); err != nil {
+		t.Fatalf("FinishRotation() error: %v", err)
+	}
+	if resumed.Rotating() || reloaded.Info().RotatedAt == 0 {
+		t.Fatalf("rotation not finished: %+v", reloaded.Info())
+	}
+	if _, err := resumed.Open(old, "ctx"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Open(old) after rotation error = %v, want ErrUnknownKey", err)
+	}
+	if plaintext, err := resumed.Open(fresh, "ctx"); err != nil || string(plaintext) != "new record" {
+		t.Fatalf("Open(new) = %q, %v", plaintext, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName+".tmp")); err == nil {
+		t.Fatal("temporary vault file left behind")
+	}
+}
@@ -0,0 +1,616 @@
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+// DefaultSettle is how long a path has to be quiet before a change to it is
+// handled, so that files still being copied are not imported half written
+const DefaultSettle = 2 * time.Second
+
+// audioExts are the files picked up from watched folders
+var audioExts = map[string]bool{
+	".mp3": true, ".flac": true, ".ogg": true, ".oga": true, ".opus": true,
+	".m4a": true, ".aac": true, ".wav": true, ".aif": true, ".aiff": true,
+	".wma": true, ".ape": true, ".wv": true,
+}
+
+// Library is what the watcher changes the library through
+type Library interface {
+	// ImportFile adds a file in place, recording the SHA256 of its content
+	ImportFile(ctx context.Context, path string, hash string) (*models.Track, error)
+	// MoveTrack points a track at the new path of its renamed or moved file
+	MoveTrack(trackID string, path string) error
+	// RemoveTrack removes a track whose file was deleted
+	RemoveTrack(trackID string) error
+}
+
+// Progress reports scans and the changes made to the library
+type Progress struct {
+	Scanning   bool  `json:"scanning"`
+	Found      int   `json:"found"`      // Audio files found by the current or last scan
+	Scanned    int   `json:"scanned"`    // Of those, checked against the library
+	Imported   int   `json:"imported"`   // Files added since start
+	Moved      int   `json:"moved"`      // Tracks that followed a renamed or moved file
+	Removed    int   `json:"removed"`    // Tracks whose file was deleted
+	Duplicates int   `json:"duplicates"` // Files skipped because their content is already in the library
+	Failed     int   `json:"failed"`
+	LastScan   int64 `json:"last_scan,omitempty"` // Unix time the last scan finished
+}
+
+// Service keeps the library in step with watched folders. Folders are
+// scanned recursively when added and on start, then followed through
+// inotify. New audio files are imported in place; a file whose content is
+// already in the library is skipped as a duplicate, or, if the track
+// holding it lost its file, taken as that file moved. Tracks whose file is
+// deleted are removed.
+type Service struct {
+	store  storage.Store
+	lib    Library
+	logger *slog.Logger
+	settle time.Duration
+
+	fsw     *fsnotify.Watcher
+	changed chan string
+	scans   chan string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu       sync.Mutex
+	folders  []string
+	pending  map[string]*time.Timer // paths waiting for changes to settle
+	gone     map[string]bool        // missing paths waiting one more settle; only run uses it
+	progress Progress
+}
+
+// New creates a watcher importing through lib
+func New(store storage.Store, lib Library, logger *slog.Logger) *Service {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		store:   store,
+		lib:     lib,
+		logger:  logger,
+		settle:  DefaultSettle,
+		changed: make(chan string, 1024),
+		scans:   make(chan string, 64),
+		pending: make(map[string]*time.Timer),
+		gone:    make(map[string]bool),
+	}
+}
+
+// SetSettle sets how long changes must be quiet before they are handled
+func (s *Service) SetSettle(d time.Duration) {
+	if d > 0 {
+		s.settle = d
+	}
+}
+
+// Start watches folders and scans them in the background
+func (s *Service) Start(folders []string) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	s.fsw = fsw
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+	go s.readEvents()
+	go s.run()
+
+	for _, folder := range folders {
+		folder, err := CleanFolder(folder)
+		if err != nil {
+			continue
+		}
+		if err := s.Add(folder); err != nil {
+			// A folder on a disk not mounted yet stays watched for a rescan
+			s.logger.Warn("watch-folder-error", "folder", folder, "error", err)
+			if errors.Is(err, os.ErrNotExist) {
+				s.mu.Lock()
+				s.folders = append(s.folders, folder)
+				s.mu.Unlock()
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops watching
+func (s *Service) Close() error {
+	if s.fsw == nil {
+		return nil
+	}
+	s.cancel()
+	err := s.fsw.Close()
+	<-s.done
+
+	s.mu.Lock()
+	for path, timer := range s.pending {
+		timer.Stop()
+		delete(s.pending, path)
+	}
+	s.mu.Unlock()
+	return err
+}
+
+// Add starts watching a folder and scans it
+func (s *Service) Add(folder string) error {
+	if s.fsw == nil {
+		return fmt.Errorf("watcher not started")
+	}
+	folder, err := CleanFolder(folder)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(folder)
+	if err != nil {
+		return fmt.Errorf("failed to open folder: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a folder: %s", folder)
+	}
+
+	s.mu.Lock()
+	for _, watched := range s.folders {
+		if within(folder, watched) || within(watched, folder) {
+			s.mu.Unlock()
+			return fmt.Errorf("%s overlaps watched folder %s", folder, watched)
+		}
+	}
+	s.folders = append(s.folders, folder)
+	s.mu.Unlock()
+
+	s.Rescan(folder)
+	return nil
+}
+
+// Remove stops watching a folder. Its tracks stay in the library.
+func (s *Service) Remove(folder string) error {
+	folder, err := CleanFolder(folder)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	found := false
+	for i, watched := range s.folders {
+		if watched == folder {
+			s.folders = append(s.folders[:i], s.folders[i+1:]...)
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		return fmt.Errorf("folder not watched: %s", folder)
+	}
+
+	if s.fsw != nil {
+		for _, dir := range s.fsw.WatchList() {
+			if within(dir, folder) {
+				s.fsw.Remove(dir)
+			}
+		}
+	}
+	return nil
+}
+
+// Rescan scans a watched folder, or every folder if folder is empty
+func (s *Service) Rescan(folder string) {
+	folders := []string{folder}
+	if folder == "" {
+		folders = s.Folders()
+	}
+	for _, f := range folders {
+		s.mu.Lock()
+		s.progress.Scanning = true
+		s.mu.Unlock()
+		select {
+		case s.scans <- f:
+		default:
+			s.logger.Warn("watch-scan-dropped", "folder", f)
+		}
+	}
+}
+
+// Folders returns the watched folders
+func (s *Service) Folders() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.folders...)
+}
+
+// Progress returns the scan progress and the changes made so far
+func (s *Service) Progress() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress
+}
+
+// CleanFolder returns the absolute, clean form of a folder path
+func CleanFolder(folder string) (string, error) {
+	if strings.TrimSpace(folder) == "" {
+		return "", fmt.Errorf("folder is required")
+	}
+	abs, err := filepath.Abs(folder)
+	if err != nil {
+		return "", fmt.Errorf("invalid folder: %w", err)
+	}
+	return abs, nil
+}
+
+// run handles scans and settled changes one at a time, so that each sees
+// the library the previous one left
+func (s *Service) run() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case folder := <-s.scans:
+			s.scan(folder)
+		case path := <-s.changed:
+			paths := []string{path}
+			for more := true; more; {
+				select {
+				case path := <-s.changed:
+					paths = append(paths, path)
+				default:
+					more = false
+				}
+			}
+			s.sync(paths)
+		}
+	}
+}
+
+// readEvents keeps inotify drained while run is busy with a scan
+func (s *Service) readEvents() {
+	for {
+		select {
+		case event, ok := <-s.fsw.Events:
+			if !ok {
+				return
+			}
+			s.onEvent(event)
+		case err, ok := <-s.fsw.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Warn("watch-error", "error", err)
+		}
+	}
+}
+
+// onEvent schedules a changed path
+func (s *Service) onEvent(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod || hidden(event.Name) {
+		return
+	}
+	s.schedule(event.Name)
+}
+
+// schedule hands a path to run once it has been quiet for settle
+func (s *Service) schedule(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if timer, ok := s.pending[path]; ok {
+		timer.Reset(s.settle)
+		return
+	}
+	s.pending[path] = time.AfterFunc(s.settle, func() {
+		s.mu.Lock()
+		delete(s.pending, path)
+		s.mu.Unlock()
+		select {
+		case s.changed <- path:
+		case <-s.ctx.Done():
+		}
+	})
+}
+
+// scan brings the library in step with a folder: new files are imported,
+// moved ones followed and tracks of deleted ones removed
+func (s *Service) scan(folder string) {
+	started := time.Now()
+	if !exists(folder) {
+		s.logger.Warn("watch-folder-missing", "folder", folder)
+		s.count(func(p *Progress) { p.Scanning = len(s.scans) > 0 })
+		return
+	}
+	files, err := s.walk(folder)
+	if err != nil {
+		s.logger.Warn("watch-scan-error", "folder", folder, "error", err)
+	}
+
+	s.mu.Lock()
+	s.progress.Found, s.progress.Scanned = len(files), 0
+	s.mu.Unlock()
+
+	snap, err := s.snapshot()
+	if err != nil {
+		s.logger.Warn("watch-scan-error", "folder", folder, "error", err)
+		files = nil
+	}
+	for _, path := range files {
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.syncFile(snap, path)
+		s.count(func(p *Progress) { p.Scanned++ })
+	}
+	if snap != nil && s.watched(folder) {
+		s.removeMissing(snap, folder)
+	}
+
+	progress := s.count(func(p *Progress) {
+		p.Scanning = len(s.scans) > 0
+		p.LastScan = time.Now().Unix()
+	})
+	s.logger.Info("watch-scan-done", "folder", folder, "files", len(files), "imported", progress.Imported,
+		"moved", progress.Moved, "removed", progress.Removed, "duplicates", progress.Duplicates,
+		"duration_ms", time.Since(started).Milliseconds())
+}
+
+// sync handles settled changes. A missing path is checked again a settle
+// later, and files present are handled before missing ones, so that a
+// rename seen as a removal and a creation moves the track.
+func (s *Service) sync(paths []string) {
+	snap, err := s.snapshot()
+	if err != nil {
+		s.logger.Warn("watch-sync-error", "error", err)
+		return
+	}
+
+	var gone []string
+	for _, path := range paths {
+		if !s.watched(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err == nil {
+			delete(s.gone, path)
+		}
+		switch {
+		case err != nil && !s.gone[path]:
+			s.gone[path] = true
+			s.schedule(path)
+		case err != nil:
+			delete(s.gone, path)
+			gone = append(gone, path)
+		case info.IsDir():
+			// New or moved in; not watched yet
+			files, err := s.walk(path)
+			if err != nil {
+				s.logger.Warn("watch-scan-error", "folder", path, "error", err)
+			}
+			for _, file := range files {
+				s.syncFile(snap, file)
+			}
+		case info.Mode().IsRegular() && isAudio(path):
+			s.syncFile(snap, path)
+		}
+	}
+	for _, path := range gone {
+		s.removeMissing(snap, path)
+	}
+}
+
+// syncFile imports a file unless the library has it. Content already in
+// the library is a duplicate, or the moved file of a track that lost it.
+func (s *Service) syncFile(snap *snapshot, path string) {
+	if _, ok := snap.byPath[path]; ok {
+		return
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		s.fail(path, err)
+		return
+	}
+
+	if track, ok := snap.byHash[hash]; ok {
+		if track.MediaHash == "" && !exists(track.Path) {
+			if err := s.lib.MoveTrack(track.ID, path); err != nil {
+				s.fail(path, err)
+				return
+			}
+			delete(snap.byPath, track.Path)
+			track.Path = path
+			snap.byPath[path] = track
+			s.count(func(p *Progress) { p.Moved++ })
+			s.logger.Info("watch-track-moved", "track_id", track.ID, "path", path)
+			return
+		}
+		s.count(func(p *Progress) { p.Duplicates++ })
+		return
+	}
+
+	track, err := s.lib.ImportFile(s.ctx, path, hash)
+	if err != nil {
+		s.fail(path, err)
+		return
+	}
+	snap.add(track)
+	s.count(func(p *Progress) { p.Imported++ })
+}
+
+// removeMissing removes the tracks of files at or under path that no
+// longer exist. Files in the media store are not watched, and nothing is
+// removed while a watched folder itself is gone, as with an unmounted disk.
+func (s *Service) removeMissing(snap *snapshot, path string) {
+	if root := s.root(path); root == "" || !exists(root) {
+		return
+	}
+	for trackPath, track := range snap.byPath {
+		if track.MediaHash != "" || !within(trackPath, path) || exists(trackPath) {
+			continue
+		}
+		if err := s.lib.RemoveTrack(track.ID); err != nil {
+			s.fail(trackPath, err)
+			continue
+		}
+		snap.remove(track)
+		s.count(func(p *Progress) { p.Removed++ })
+		s.logger.Info("watch-track-removed", "track_id", track.ID, "path", trackPath)
+	}
+}
+
+// walk watches the folders under root and returns the audio files in them
+func (s *Service) walk(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			s.logger.Warn("watch-walk-error", "path", path, "error", err)
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path != root && hidden(path) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if err := s.fsw.Add(path); err != nil {
+				s.logger.Warn("watch-add-error", "path", path, "error", err)
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() && isAudio(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// watched reports whether path is in a watched folder
+func (s *Service) watched(path string) bool {
+	return s.root(path) != ""
+}
+
+// root returns the watched folder path is in, or "" if none
+func (s *Service) root(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, folder := range s.folders {
+		if within(path, folder) {
+			return folder
+		}
+	}
+	return ""
+}
+
+func (s *Service) count(update func(*Progress)) Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(&s.progress)
+	return s.progress
+}
+
+func (s *Service) fail(path string, err error) {
+	s.count(func(p *Progress) { p.Failed++ })
+	s.logger.Warn("watch-import-error", "path", path, "error", err)
+}
+
+// snapshot indexes the library by path and by content hash for one scan or
+// batch of changes
+type snapshot struct {
+	byPath map[string]*models.Track
+	byHash map[string]*models.Track
+}
+
+func (s *Service) snapshot() (*snapshot, error) {
+	tracks, err := s.store.GetAllTracks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracks: %w", err)
+	}
+	snap := &snapshot{
+		byPath: make(map[string]*models.Track, len(tracks)),
+		byHash: make(map[string]*models.Track, len(tracks)),
+	}
+	for _, track := range tracks {
+		snap.add(track)
+	}
+	return snap, nil
+}
+
+func (snap *snapshot) add(track *models.Track) {
+	if track.Path != "" {
+		snap.byPath[track.Path] = track
+	}
+	for _, hash := range []string{track.MediaHash, track.FileHash} {
+		if hash == "" {
+			continue
+		}
+		// A track that still has its file is the better match
+		if other, ok := snap.byHash[hash]; !ok || !exists(other.Path) {
+			snap.byHash[hash] = track
+		}
+	}
+}
+
+func (snap *snapshot) remove(track *models.Track) {
+	delete(snap.byPath, track.Path)
+	for _, hash := range []string{track.MediaHash, track.FileHash} {
+		if snap.byHash[hash] == track {
+			delete(snap.byHash, hash)
+		}
+	}
+}
+
+// within reports whether path is dir or under it
+func within(path string, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// hidden reports whether a path names a dot file, such as the partial
+// downloads many tools write
+func hidden(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".")
+}
+
+func isAudio(path string) bool {
+	return audioExts[strings.ToLower(filepath.Ext(path))]
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil || !errors.Is(err, os.ErrNotExist)
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
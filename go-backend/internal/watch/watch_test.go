@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cotune/go-backend/internal/models"
+	"github.com/cotune/go-backend/internal/storage"
+)
+
+// fakeLibrary applies the watcher's changes to storage directly
+type fakeLibrary struct {
+	store storage.Store
+	next  int
+}
+
+func (l *fakeLibrary) ImportFile(ctx context.Context, path string, hash string) (*models.Track, error) {
+	l.next++
+	track := &models.Track{ID: fmt.Sprint(l.next), Path: path, FileHash: hash}
+	return track, l.store.SaveTrack(track)
+}
+
+func (l *fakeLibrary) MoveTrack(trackID string, path string) error {
+	track, err := l.store.GetTrack(trackID)
+	if err != nil {
+		return err
+	}
+	track.Path = path
+	return l.store.SaveTrack(track)
+}
+
+func (l *fakeLibrary) RemoveTrack(trackID string) error {
+	return l.store.DeleteTrack(trackID)
+}
+
+func writeFile(t *testing.T, path string, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func trackPaths(t *testing.T, store storage.Store) map[string]bool {
+	t.Helper()
+	tracks, err := store.GetAllTracks()
+	if err != nil {
+		t.Fatalf("GetAllTracks() error: %v", err)
+	}
+	paths := make(map[string]bool, len(tracks))
+	for _, track := range tracks {
+		paths[track.Path] = true
+	}
+	return paths
+}
+
+func TestWatcherImportsMovesAndRemovesFiles(t *testing.T) {
+	store := storage.NewMemory()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.mp3"), "first song")
+	writeFile(t, filepath.Join(dir, "sub", "b.flac"), "second song")
+	writeFile(t, filepath.Join(dir, "sub", "copy of a.mp3"), "first song")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not audio")
+	writeFile(t, filepath.Join(dir, ".partial.mp3"), "still downloading")
+
+	w := New(store, &fakeLibrary{store: store}, nil)
+	w.SetSettle(50 * time.Millisecond)
+	if err := w.Start([]string{dir}); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer w.Close()
+
+	waitFor(t, "initial scan", func() bool { return w.Progress().LastScan != 0 })
+	if progress := w.Progress(); progress.Found != 3 || progress.Imported != 2 || progress.Duplicates != 1 {
+		t.Fatalf("scan progress = %+v, want 3 found, 2 imported, 1 duplicate", progress)
+	}
+
+	// Both a rename and a new file arrive as events
+	moved := filepath.Join(dir, "sub", "renamed.mp3")
+	if err := os.Rename(filepath.Join(dir, "a.mp3"), moved); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub", "deeper", "c.ogg"), "third song")
+	waitFor(t, "rename and import", func() bool {
+		p := w.Progress()
+		return p.Moved == 1 && p.Imported == 3
+	})
+	if paths := trackPaths(t, store); !paths[moved] || paths[filepath.Join(dir, "a.mp3")] || len(paths) != 3 {
+		t.Fatalf("tracks at %v, want the renamed file followed", paths)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "sub", "b.flac")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "removal", func() bool { return w.Progress().Removed == 1 })
+	if paths := trackPaths(t, store); paths[filepath.Join(dir, "sub", "b.flac")] || len(paths) != 2 {
+		t.Fatalf("tracks at %v, want the deleted file's track removed", paths)
+	}
+}
+
+func TestAddRejectsOverlappingFolders(t *testing.T) {
+	store := storage.NewMemory()
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "inner"), 0755)
+	w := New(store, &fakeLibrary{store: store}, nil)
+	if err := w.Start([]string{dir}); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Add(filepath.Join(dir, "inner")); err == nil {
+		t.Fatal("Add() accepted a folder inside a watched one")
+	}
+	if err := w.Remove(dir); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if err := w.Add(filepath.Join(dir, "inner")); err != nil {
+		t.Fatalf("Add() after Remove() error: %v", err)
+	}
+}
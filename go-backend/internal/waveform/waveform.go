@@ -0,0 +1,253 @@
+package waveform
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// BaseSamplesPerPeak is the finest resolution: about 11.6 ms at 44.1 kHz
+	BaseSamplesPerPeak = 512
+	// LevelFactor is how many peaks of one level merge into one of the next
+	LevelFactor = 4
+	// NumLevels is the number of resolutions kept; the coarsest has one peak
+	// per 32768 samples, about 0.74 s at 44.1 kHz
+	NumLevels = 4
+
+	// FileExt is appended to the audio path to name its peaks file
+	FileExt = ".peaks"
+	// MaxBytes bounds an encoded peaks file, enough for a 12 hour recording
+	MaxBytes = 4 << 20
+
+	magic         = "CTPK"
+	formatVersion = 1
+)
+
+// ErrInvalid is returned for data that is not a peaks file
+var ErrInvalid = errors.New("invalid peaks data")
+
+// Peaks is a multi-resolution waveform overview of mono PCM
+type Peaks struct {
+	SampleRate int     `json:"sample_rate"`
+	Levels     []Level `json:"levels"` // finest first
+}
+
+// Level is one resolution of a waveform. Peaks holds a minimum and maximum
+// per window of SamplesPerPeak samples, interleaved and scaled to 8 bits.
+type Level struct {
+	SamplesPerPeak int    `json:"samples_per_peak"`
+	Peaks          []int8 `json:"peaks"`
+}
+
+// Len returns the number of min/max pairs in the level
+func (l Level) Len() int {
+	return len(l.Peaks) / 2
+}
+
+// Builder accumulates peaks from mono 16-bit PCM written as little-endian
+// bytes, the same stream the CTID is hashed from
+type Builder struct {
+	sampleRate int
+	odd        []byte // incomplete sample carried between Write calls
+	min, max   int16
+	fill       int
+	base       []int8
+}
+
+// NewBuilder creates a Builder for mono PCM at the given sample rate
+func NewBuilder(sampleRate int) *Builder {
+	return &Builder{sampleRate: sampleRate}
+}
+
+// Write feeds little-endian 16-bit samples; a sample may be split across
+// calls
+func (b *Builder) Write(p []byte) (int, error) {
+	data := p
+	if len(b.odd) > 0 {
+		data = append(b.odd, p...)
+	}
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	b.odd = append(b.odd[:0], data[len(samples)*2:]...)
+	b.AddSamples(samples)
+	return len(p), nil
+}
+
+// AddSamples feeds mono PCM samples
+func (b *Builder) AddSamples(pcm []int16) {
+	for _, sample := range pcm {
+		if b.fill == 0 || sample < b.min {
+			b.min = sample
+		}
+		if b.fill == 0 || sample > b.max {
+			b.max = sample
+		}
+		b.fill++
+		if b.fill == BaseSamplesPerPeak {
+			b.flush()
+		}
+	}
+}
+
+func (b *Builder) flush() {
+	b.base = append(b.base, int8(b.min>>8), int8(b.max>>8))
+	b.fill = 0
+}
+
+// Peaks returns the waveform of all samples fed so far, or nil if nothing
+// was fed. A trailing partial window becomes a peak of its own.
+func (b *Builder) Peaks() *Peaks {
+	base := append([]int8(nil), b.base...)
+	if b.fill > 0 {
+		base = append(base, int8(b.min>>8), int8(b.max>>8))
+	}
+	if len(base) == 0 {
+		return nil
+	}
+
+	p := &Peaks{
+		SampleRate: b.sampleRate,
+		Levels:     []Level{{SamplesPerPeak: BaseSamplesPerPeak, Peaks: base}},
+	}
+	for len(p.Levels) < NumLevels {
+		p.Levels = append(p.Levels, merge(p.Levels[len(p.Levels)-1]))
+	}
+	return p
+}
+
+// merge derives the next coarser level by combining LevelFactor peaks
+func merge(l Level) Level {
+	n := (l.Len() + LevelFactor - 1) / LevelFactor
+	out := Level{SamplesPerPeak: l.SamplesPerPeak * LevelFactor, Peaks: make([]int8, 0, n*2)}
+	for i := 0; i < l.Len(); i += LevelFactor {
+		lo, hi := l.Peaks[i*2], l.Peaks[i*2+1]
+		for j := i + 1; j < min(i+LevelFactor, l.Len()); j++ {
+			lo = min(lo, l.Peaks[j*2])
+			hi = max(hi, l.Peaks[j*2+1])
+		}
+		out.Peaks = append(out.Peaks, lo, hi)
+	}
+	return out
+}
+
+// Trim returns the levels with at most maxPeaks peaks, so a preview only
+// carries the resolutions it can draw. The coarsest level is always kept.
+// A maxPeaks of zero or less keeps every level.
+func (p *Peaks) Trim(maxPeaks int) *Peaks {
+	if maxPeaks <= 0 {
+		return p
+	}
+	trimmed := &Peaks{SampleRate: p.SampleRate}
+	for _, level := range p.Levels {
+		if level.Len() <= maxPeaks {
+			trimmed.Levels = append(trimmed.Levels, level)
+		}
+	}
+	if len(trimmed.Levels) == 0 && len(p.Levels) > 0 {
+		trimmed.Levels = p.Levels[len(p.Levels)-1:]
+	}
+	return trimmed
+}
+
+// Encode serializes peaks as the magic "CTPK", a format version byte, the
+// sample rate and level count, then per level its samples per peak, peak
+// count and peaks. Integers are little-endian uint32 except the level count.
+func (p *Peaks) Encode() []byte {
+	size := len(magic) + 1 + 4 + 1
+	for _, level := range p.Levels {
+		size += 8 + len(level.Peaks)
+	}
+	buf := make([]byte, 0, size)
+	buf = append(buf, magic...)
+	buf = append(buf, formatVersion)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(p.SampleRate))
+	buf = append(buf, byte(len(p.Levels)))
+	for _, level := range p.Levels {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(level.SamplesPerPeak))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(level.Len()))
+		for _, v := range level.Peaks {
+			buf = append(buf, byte(v))
+		}
+	}
+	return buf
+}
+
+// Decode parses data written by Encode
+func Decode(data []byte) (*Peaks, error) {
+	if len(data) > MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalid, len(data))
+	}
+	header := len(magic) + 1 + 4 + 1
+	if len(data) < header || string(data[:len(magic)]) != magic {
+		return nil, ErrInvalid
+	}
+	if v := data[len(magic)]; v != formatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalid, v)
+	}
+	p := &Peaks{SampleRate: int(binary.LittleEndian.Uint32(data[len(magic)+1:]))}
+	levels := int(data[header-1])
+	data = data[header:]
+
+	for i := 0; i < levels; i++ {
+		if len(data) < 8 {
+			return nil, ErrInvalid
+		}
+		spp := binary.LittleEndian.Uint32(data)
+		count := binary.LittleEndian.Uint32(data[4:])
+		data = data[8:]
+		if spp == 0 || uint64(count)*2 > uint64(len(data)) {
+			return nil, ErrInvalid
+		}
+		level := Level{SamplesPerPeak: int(spp), Peaks: make([]int8, count*2)}
+		for j := range level.Peaks {
+			level.Peaks[j] = int8(data[j])
+		}
+		data = data[count*2:]
+		p.Levels = append(p.Levels, level)
+	}
+	if len(data) != 0 {
+		return nil, ErrInvalid
+	}
+	return p, nil
+}
+
+// PathFor returns where the peaks of an audio file are stored: beside it
+func PathFor(audioPath string) string {
+	return audioPath + FileExt
+}
+
+// Save writes peaks through a temporary file so readers never see a partial
+// file
+func Save(path string, p *Peaks) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".peaks-*")
+	if err != nil {
+		return fmt.Errorf("failed to write peaks: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(p.Encode()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write peaks: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write peaks: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write peaks: %w", err)
+	}
+	return nil
+}
+
+// Load reads a peaks file written by Save
+func Load(path string) (*Peaks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read peaks: %w", err)
+	}
+	return Decode(data)
+}
@@ -0,0 +1,109 @@
+package waveform
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuilderLevels(t *testing.T) {
+	b := NewBuilder(44100)
+	// 10 full base windows then a partial one; window i peaks at ±i*256
+	var pcm []int16
+	for i := 0; i < 10; i++ {
+		for j := 0; j < BaseSamplesPerPeak; j++ {
+			v := int16(i * 256)
+			if j%2 == 1 {
+				v = -v
+			}
+			pcm = append(pcm, v)
+		}
+	}
+	pcm = append(pcm, 100*256, -100*256)
+
+	// Feed bytes in odd-sized chunks to split samples across writes
+	data := make([]byte, len(pcm)*2)
+	for i, v := range pcm {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(v))
+	}
+	for len(data) > 0 {
+		n := min(len(data), 333)
+		b.Write(data[:n])
+		data = data[n:]
+	}
+
+	p := b.Peaks()
+	if p == nil || p.SampleRate != 44100 || len(p.Levels) != NumLevels {
+		t.Fatalf("Peaks() = %+v, want %d levels at 44100Hz", p, NumLevels)
+	}
+	base := p.Levels[0]
+	if base.SamplesPerPeak != BaseSamplesPerPeak || base.Len() != 11 {
+		t.Fatalf("base level = %d samples per peak, %d peaks; want %d, 11", base.SamplesPerPeak, base.Len(), BaseSamplesPerPeak)
+	}
+	if base.Peaks[6] != -3 || base.Peaks[7] != 3 || base.Peaks[20] != -100 || base.Peaks[21] != 100 {
+		t.Fatalf("base peaks = %v, want ±3 at index 3 and ±100 for the partial window", base.Peaks)
+	}
+
+	next := p.Levels[1]
+	want := []int8{-3, 3, -7, 7, -100, 100}
+	if next.SamplesPerPeak != BaseSamplesPerPeak*LevelFactor || !reflect.DeepEqual(next.Peaks, want) {
+		t.Fatalf("level 1 = %d, %v; want %d, %v", next.SamplesPerPeak, next.Peaks, BaseSamplesPerPeak*LevelFactor, want)
+	}
+	if last := p.Levels[NumLevels-1]; last.Len() != 1 || last.Peaks[0] != -100 || last.Peaks[1] != 100 {
+		t.Fatalf("coarsest level = %v, want one ±100 peak", last.Peaks)
+	}
+}
+
+func TestEmptyBuilderHasNoPeaks(t *testing.T) {
+	if p := NewBuilder(44100).Peaks(); p != nil {
+		t.Fatalf("Peaks() = %+v, want nil", p)
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	b := NewBuilder(44100)
+	pcm := make([]int16, 5000)
+	for i := range pcm {
+		pcm[i] = int16(i*37%65536 - 32768)
+	}
+	b.AddSamples(pcm)
+	p := b.Peaks()
+
+	path := filepath.Join(t.TempDir(), "track.mp3"+FileExt)
+	if err := Save(path, p); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Fatalf("Load() = %+v, want %+v", got, p)
+	}
+
+	data := p.Encode()
+	for _, bad := range [][]byte{nil, []byte("RIFF0000000"), data[:len(data)-1], append(data, 0)} {
+		if _, err := Decode(bad); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("Decode(%d bytes) error = %v, want ErrInvalid", len(bad), err)
+		}
+	}
+}
+
+func TestTrimKeepsLevelsThatFit(t *testing.T) {
+	p := &Peaks{SampleRate: 44100, Levels: []Level{
+		{SamplesPerPeak: 512, Peaks: make([]int8, 2*64)},
+		{SamplesPerPeak: 2048, Peaks: make([]int8, 2*16)},
+		{SamplesPerPeak: 8192, Peaks: make([]int8, 2*4)},
+	}}
+	if got := p.Trim(16); len(got.Levels) != 2 || got.Levels[0].SamplesPerPeak != 2048 {
+		t.Fatalf("Trim(16) = %+v, want the two coarser levels", got.Levels)
+	}
+	if got := p.Trim(2); len(got.Levels) != 1 || got.Levels[0].SamplesPerPeak != 8192 {
+		t.Fatalf("Trim(2) = %+v, want the coarsest level", got.Levels)
+	}
+	if got := p.Trim(0); len(got.Levels) != 3 {
+		t.Fatalf("Trim(0) = %+v, want every level", got.Levels)
+	}
+}